@@ -2,14 +2,27 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"log"
 	"log/slog"
 	"os"
 	"os/signal"
 
+	"github.com/cybre/salesforge-assignment/internal/bounce"
 	"github.com/cybre/salesforge-assignment/internal/config"
+	"github.com/cybre/salesforge-assignment/internal/contact"
 	"github.com/cybre/salesforge-assignment/internal/database"
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/reply"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
 	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/stats"
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	"github.com/cybre/salesforge-assignment/internal/task"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
 	"github.com/cybre/salesforge-assignment/internal/transport/http"
 	"github.com/cybre/salesforge-assignment/pkg/logging"
 )
@@ -36,9 +49,85 @@ func main() {
 
 	sequenceRepo := sequence.NewPostgresRepository(db)
 	sequenceService := sequence.NewService(sequenceRepo)
-	server := http.NewServer(sequenceService)
+	contactRepo := contact.NewPostgresRepository(db)
+	contactService := contact.NewService(contactRepo)
+	enrollmentRepo := enrollment.NewPostgresRepository(db)
+	enrollmentService := enrollment.NewService(enrollmentRepo, sequenceService)
+
+	if config.Tracking.Secret == "" {
+		config.Tracking.Secret = randomSecret()
+		logger.Warn("TRACKING_SECRET is not set, tracking links will stop working when the server restarts")
+	}
+
+	trackingService, err := tracking.NewService(tracking.NewPostgresRepository(db), config.Tracking)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	statsService := stats.NewService(stats.NewPostgresRepository(db), sequenceRepo)
+	suppressionService := suppression.NewService(suppression.NewPostgresRepository(db), trackingService)
+	bounceService := bounce.NewService(bounce.NewPostgresRepository(db), sequenceRepo, config.Bounce)
+	replyService := reply.NewService(reply.NewPostgresRepository(db))
+	taskService := task.NewService(task.NewPostgresRepository(db))
+
+	serverOpts := []http.Option{
+		http.WithContactService(contactService),
+		http.WithEnrollmentService(enrollmentService),
+		http.WithTrackingService(trackingService),
+		http.WithStatsService(statsService),
+		http.WithSuppressionService(suppressionService),
+		http.WithBounceService(bounceService),
+		http.WithReplyService(replyService),
+		http.WithTaskService(taskService),
+	}
+
+	mailer, err := mail.NewMailer(config.Mail)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	schedulerOpts := []scheduler.Option{
+		scheduler.WithTracker(trackingService),
+		scheduler.WithSuppressionList(suppressionService),
+	}
+
+	// Mailboxes are disabled without a key, since a generated key would make the stored
+	// SMTP passwords unreadable after a restart.
+	if config.Mailbox.EncryptionKey == "" {
+		logger.Warn("MAILBOX_ENCRYPTION_KEY is not set, mailboxes are disabled and every email is sent from MAIL_FROM")
+	} else {
+		cipher, err := mailbox.NewCipher(config.Mailbox.EncryptionKey)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+
+		mailboxService := mailbox.NewService(mailbox.NewPostgresRepository(db, cipher), sequenceRepo)
+		serverOpts = append(serverOpts, http.WithMailboxService(mailboxService))
+		schedulerOpts = append(schedulerOpts, scheduler.WithMailboxes(mailboxService, mailbox.NewMailers(config.Mail, mailer)))
+	}
+
+	server := http.NewServer(sequenceService, serverOpts...)
+
+	sched := scheduler.NewScheduler(scheduler.NewPostgresRepository(db), sequenceRepo, contactRepo, mailer, config.Scheduler, schedulerOpts...)
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		sched.Run(ctx)
+	}()
 
 	if err := server.Start(ctx, config.Port); err != nil {
 		log.Fatalf(err.Error())
 	}
+
+	<-schedulerDone
+}
+
+// randomSecret generates a secret for signing tracking tokens.
+func randomSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
 }
@@ -0,0 +1,289 @@
+// Package bounce processes delivery status notifications of sent emails. Hard bounces stop
+// every enrollment of the recipient and suppress their address, and soft bounces resend the
+// step with backoff until the retries run out.
+package bounce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+// ErrAlreadyBounced is returned when a bounce of a message was already recorded.
+var ErrAlreadyBounced = errors.New("bounce of message was already recorded")
+
+const (
+	DefaultMaxRetries = 3
+	DefaultRetryDelay = time.Hour
+)
+
+// Config contains the settings for processing bounces.
+type Config struct {
+	// MaxRetries is how many times a step is resent after soft bounces before the address is
+	// treated as dead. Zero disables retries, so soft bounces are treated as hard bounces.
+	MaxRetries int
+	// RetryDelay is how long to wait before resending a step after its first soft bounce. The
+	// delay doubles with every further soft bounce.
+	RetryDelay time.Duration
+}
+
+// WithDefaults returns a copy of the config with unset options replaced by their defaults.
+// MaxRetries is left as it is, since zero disables retries.
+func (c Config) WithDefaults() Config {
+	if c.RetryDelay == 0 {
+		c.RetryDelay = DefaultRetryDelay
+	}
+
+	return c
+}
+
+// Type is the kind of a bounce.
+type Type string
+
+const (
+	// TypeHard bounces are permanent failures, e.g. because the address does not exist.
+	TypeHard Type = "hard"
+	// TypeSoft bounces are temporary failures, e.g. because the mailbox is full.
+	TypeSoft Type = "soft"
+)
+
+// Message is a sent message a delivery status notification is about.
+type Message struct {
+	ID           int
+	EnrollmentID int
+	SequenceID   int
+	// StepID is nil when the step was deleted before sequences had versions.
+	StepID    *int
+	ContactID int
+	// Version is the version of the sequence the enrollment is pinned to, or zero when it is
+	// sent the draft steps.
+	Version int
+	// BounceType is set when a bounce of the message was already processed.
+	BounceType Type
+}
+
+// Retry resends a step of an enrollment after a soft bounce.
+type Retry struct {
+	EnrollmentID int
+	// CurrentStep is the position of the bounced step.
+	CurrentStep int
+	RetryAt     time.Time
+}
+
+// Outcome is what processing the delivery status of a recipient resulted in.
+type Outcome string
+
+const (
+	// OutcomeHardBounce means the recipient's enrollments were stopped and their address suppressed.
+	OutcomeHardBounce Outcome = "hardBounce"
+	// OutcomeSoftBounce means the step is resent at RetryAt, when set.
+	OutcomeSoftBounce Outcome = "softBounce"
+	// OutcomeIgnored means the message did not bounce.
+	OutcomeIgnored Outcome = "ignored"
+	// OutcomeUnmatched means the report is not about a message we sent.
+	OutcomeUnmatched Outcome = "unmatched"
+	// OutcomeDuplicate means a bounce of the message was already processed.
+	OutcomeDuplicate Outcome = "duplicate"
+)
+
+// RecipientResult is the result of processing the delivery status of a recipient.
+type RecipientResult struct {
+	Recipient string  `json:"recipient"`
+	Action    string  `json:"action"`
+	Status    string  `json:"status,omitempty"`
+	Type      Type    `json:"type,omitempty"`
+	Outcome   Outcome `json:"outcome"`
+	MessageID int     `json:"messageId,omitempty"`
+	// RetryAt is when the step is resent after a soft bounce.
+	RetryAt *time.Time `json:"retryAt,omitempty"`
+}
+
+// Result is the result of processing a delivery status notification.
+type Result struct {
+	Recipients []RecipientResult `json:"recipients"`
+}
+
+// Repository represents the data layer for bounces.
+type Repository interface {
+	// FindMessage finds a sent message by the value of its Message-ID header or, when that is
+	// empty, the latest one sent to the recipient.
+	FindMessage(ctx context.Context, messageID string, recipient string) (Message, bool, error)
+	CountSoftBounces(ctx context.Context, enrollmentID int, stepID int) (int, error)
+	// RecordSoftBounce records a soft bounce and resends the step if given, unless the
+	// enrollment was stopped or has moved on since. It reports whether the step is resent.
+	// It returns ErrAlreadyBounced when a bounce of the message was already recorded.
+	RecordSoftBounce(ctx context.Context, messageID int, retry *Retry) (bool, error)
+	// RecordHardBounce records a hard bounce, suppresses the address of the recipient and stops
+	// all of their active and paused enrollments. It returns ErrAlreadyBounced when a bounce of
+	// the message was already recorded.
+	RecordHardBounce(ctx context.Context, messageID int) error
+}
+
+// SequenceRepository gets the sequences of bounced messages.
+type SequenceRepository interface {
+	GetSequence(ctx context.Context, id int) (sequence.Sequence, bool, error)
+	// GetSequenceVersion gets a sequence with the steps of the version instead of the draft.
+	GetSequenceVersion(ctx context.Context, id int, version int) (sequence.Sequence, bool, error)
+}
+
+// Service contains the business logic for bounces.
+type Service struct {
+	repo      Repository
+	sequences SequenceRepository
+	config    Config
+}
+
+// NewService creates a new bounce service.
+func NewService(repo Repository, sequences SequenceRepository, config Config) *Service {
+	return &Service{
+		repo:      repo,
+		sequences: sequences,
+		config:    config.WithDefaults(),
+	}
+}
+
+// ProcessDSN processes a raw delivery status notification. Processing the same notification
+// again has no further effect.
+func (s Service) ProcessDSN(ctx context.Context, r io.Reader) (Result, error) {
+	report, err := ParseDSN(r)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Recipients: make([]RecipientResult, len(report.Recipients))}
+	for i, recipient := range report.Recipients {
+		result.Recipients[i], err = s.process(ctx, report.OriginalMessageID, recipient)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to process bounce for %s: %w", recipient.Address, err)
+		}
+	}
+
+	return result, nil
+}
+
+func (s Service) process(ctx context.Context, originalMessageID string, recipient Recipient) (RecipientResult, error) {
+	result := RecipientResult{
+		Recipient: recipient.Address,
+		Action:    recipient.Action,
+		Status:    recipient.Status,
+		Type:      recipient.Type(),
+		Outcome:   OutcomeIgnored,
+	}
+
+	if result.Type == "" {
+		return result, nil
+	}
+
+	msg, exists, err := s.repo.FindMessage(ctx, originalMessageID, recipient.Address)
+	if err != nil {
+		return RecipientResult{}, fmt.Errorf("failed to find message: %w", err)
+	}
+
+	if !exists {
+		result.Outcome = OutcomeUnmatched
+		return result, nil
+	}
+
+	result.MessageID = msg.ID
+
+	// Concurrent deliveries of the same notification can both get past this check, but only
+	// the first one records the bounce.
+	if msg.BounceType != "" {
+		result.Outcome = OutcomeDuplicate
+		return result, nil
+	}
+
+	if result.Type == TypeSoft {
+		retry, ok, err := s.retry(ctx, msg)
+		if err != nil {
+			return RecipientResult{}, err
+		}
+
+		// Out of retries, the address is treated as dead.
+		if !ok {
+			result.Type = TypeHard
+		} else {
+			rescheduled, err := s.repo.RecordSoftBounce(ctx, msg.ID, retry)
+			if errors.Is(err, ErrAlreadyBounced) {
+				result.Outcome = OutcomeDuplicate
+				return result, nil
+			}
+
+			if err != nil {
+				return RecipientResult{}, fmt.Errorf("failed to record soft bounce: %w", err)
+			}
+
+			result.Outcome = OutcomeSoftBounce
+			if rescheduled {
+				result.RetryAt = &retry.RetryAt
+			}
+
+			return result, nil
+		}
+	}
+
+	err = s.repo.RecordHardBounce(ctx, msg.ID)
+	if errors.Is(err, ErrAlreadyBounced) {
+		result.Outcome = OutcomeDuplicate
+		return result, nil
+	}
+
+	if err != nil {
+		return RecipientResult{}, fmt.Errorf("failed to record hard bounce: %w", err)
+	}
+
+	result.Outcome = OutcomeHardBounce
+	return result, nil
+}
+
+// retry schedules resending the bounced step, doubling the delay with every soft bounce of the
+// step. The step is looked up in the version the enrollment is pinned to, since its position
+// in the draft may differ. It reports false when the step was already retried as often as
+// allowed. The retry is nil when the step no longer exists and cannot be resent.
+func (s Service) retry(ctx context.Context, msg Message) (*Retry, bool, error) {
+	if msg.StepID == nil {
+		return nil, true, nil
+	}
+
+	bounces, err := s.repo.CountSoftBounces(ctx, msg.EnrollmentID, *msg.StepID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to count soft bounces: %w", err)
+	}
+
+	if bounces >= s.config.MaxRetries {
+		return nil, false, nil
+	}
+
+	var (
+		seq    sequence.Sequence
+		exists bool
+	)
+	if msg.Version > 0 {
+		seq, exists, err = s.sequences.GetSequenceVersion(ctx, msg.SequenceID, msg.Version)
+	} else {
+		seq, exists, err = s.sequences.GetSequence(ctx, msg.SequenceID)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get sequence: %w", err)
+	}
+
+	if !exists {
+		return nil, true, nil
+	}
+
+	for i, step := range seq.Steps {
+		if step.ID == *msg.StepID {
+			return &Retry{
+				EnrollmentID: msg.EnrollmentID,
+				CurrentStep:  i,
+				RetryAt:      time.Now().Add(s.config.RetryDelay << bounces),
+			}, true, nil
+		}
+	}
+
+	return nil, true, nil
+}
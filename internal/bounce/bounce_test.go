@@ -0,0 +1,253 @@
+package bounce_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/bounce"
+	"github.com/cybre/salesforge-assignment/internal/bounce/testdata"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+func TestService_ProcessDSN(t *testing.T) {
+	stepID := 7
+	seq := sequence.Sequence{
+		ID:    1,
+		Steps: []sequence.Step{{ID: 6}, {ID: stepID}},
+	}
+
+	// The draft was reordered and step 8 deleted since version 2 was published.
+	removedStepID := 8
+	pinned := sequence.Sequence{
+		ID:               1,
+		PublishedVersion: 2,
+		Steps:            []sequence.Step{{ID: removedStepID}, {ID: 6}, {ID: stepID}},
+	}
+
+	testCases := []struct {
+		name            string
+		fixture         string
+		message         bounce.Message
+		exists          bool
+		softBounces     int
+		retriesDisabled bool
+		rescheduled     bool
+		recordErr       error
+		expectedOutcome bounce.Outcome
+		expectedType    bounce.Type
+		expectedRetry   *bounce.Retry
+		expectedHard    bool
+	}{
+		{
+			name:            "Hard bounce",
+			fixture:         "testdata/hard_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1, StepID: &stepID},
+			exists:          true,
+			expectedOutcome: bounce.OutcomeHardBounce,
+			expectedType:    bounce.TypeHard,
+			expectedHard:    true,
+		},
+		{
+			name:            "Soft bounce is retried",
+			fixture:         "testdata/soft_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1, StepID: &stepID},
+			exists:          true,
+			softBounces:     1,
+			rescheduled:     true,
+			expectedOutcome: bounce.OutcomeSoftBounce,
+			expectedType:    bounce.TypeSoft,
+			expectedRetry:   &bounce.Retry{EnrollmentID: 2, CurrentStep: 1, RetryAt: time.Now().Add(2 * time.Hour)},
+		},
+		{
+			name:            "Soft bounce of a stopped enrollment",
+			fixture:         "testdata/soft_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1, StepID: &stepID},
+			exists:          true,
+			expectedOutcome: bounce.OutcomeSoftBounce,
+			expectedType:    bounce.TypeSoft,
+			expectedRetry:   &bounce.Retry{EnrollmentID: 2, CurrentStep: 1, RetryAt: time.Now().Add(time.Hour)},
+		},
+		{
+			name:            "Soft bounce is retried at the position of the pinned version",
+			fixture:         "testdata/soft_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1, StepID: &stepID, Version: 2},
+			exists:          true,
+			rescheduled:     true,
+			expectedOutcome: bounce.OutcomeSoftBounce,
+			expectedType:    bounce.TypeSoft,
+			expectedRetry:   &bounce.Retry{EnrollmentID: 2, CurrentStep: 2, RetryAt: time.Now().Add(time.Hour)},
+		},
+		{
+			name:            "Soft bounce of a step deleted from the draft",
+			fixture:         "testdata/soft_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1, StepID: &removedStepID, Version: 2},
+			exists:          true,
+			rescheduled:     true,
+			expectedOutcome: bounce.OutcomeSoftBounce,
+			expectedType:    bounce.TypeSoft,
+			expectedRetry:   &bounce.Retry{EnrollmentID: 2, CurrentStep: 0, RetryAt: time.Now().Add(time.Hour)},
+		},
+		{
+			name:            "Soft bounce of a deleted step",
+			fixture:         "testdata/soft_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1},
+			exists:          true,
+			expectedOutcome: bounce.OutcomeSoftBounce,
+			expectedType:    bounce.TypeSoft,
+		},
+		{
+			name:            "Out of retries",
+			fixture:         "testdata/soft_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1, StepID: &stepID},
+			exists:          true,
+			softBounces:     3,
+			expectedOutcome: bounce.OutcomeHardBounce,
+			expectedType:    bounce.TypeHard,
+			expectedHard:    true,
+		},
+		{
+			name:            "Retries disabled",
+			fixture:         "testdata/soft_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1, StepID: &stepID},
+			exists:          true,
+			retriesDisabled: true,
+			expectedOutcome: bounce.OutcomeHardBounce,
+			expectedType:    bounce.TypeHard,
+			expectedHard:    true,
+		},
+		{
+			name:            "Already processed",
+			fixture:         "testdata/hard_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1, StepID: &stepID, BounceType: bounce.TypeHard},
+			exists:          true,
+			expectedOutcome: bounce.OutcomeDuplicate,
+			expectedType:    bounce.TypeHard,
+		},
+		{
+			name:            "Soft bounce recorded concurrently",
+			fixture:         "testdata/soft_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1, StepID: &stepID},
+			exists:          true,
+			recordErr:       bounce.ErrAlreadyBounced,
+			expectedOutcome: bounce.OutcomeDuplicate,
+			expectedType:    bounce.TypeSoft,
+			expectedRetry:   &bounce.Retry{EnrollmentID: 2, CurrentStep: 1, RetryAt: time.Now().Add(time.Hour)},
+		},
+		{
+			name:            "Hard bounce recorded concurrently",
+			fixture:         "testdata/hard_bounce.eml",
+			message:         bounce.Message{ID: 3, EnrollmentID: 2, SequenceID: 1, StepID: &stepID},
+			exists:          true,
+			recordErr:       bounce.ErrAlreadyBounced,
+			expectedOutcome: bounce.OutcomeDuplicate,
+			expectedType:    bounce.TypeHard,
+			expectedHard:    true,
+		},
+		{
+			name:            "Unknown message",
+			fixture:         "testdata/hard_bounce.eml",
+			expectedOutcome: bounce.OutcomeUnmatched,
+			expectedType:    bounce.TypeHard,
+		},
+		{
+			name:            "Delayed",
+			fixture:         "testdata/delayed.eml",
+			expectedOutcome: bounce.OutcomeIgnored,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				hard  bool
+				retry *bounce.Retry
+			)
+
+			repo := testdata.MockRepo{
+				FindMessageFn: func(ctx context.Context, messageID string, recipient string) (bounce.Message, bool, error) {
+					return tc.message, tc.exists, nil
+				},
+				CountSoftBouncesFn: func(ctx context.Context, enrollmentID int, stepID int) (int, error) {
+					return tc.softBounces, nil
+				},
+				RecordSoftBounceFn: func(ctx context.Context, messageID int, r *bounce.Retry) (bool, error) {
+					retry = r
+					return tc.rescheduled, tc.recordErr
+				},
+				RecordHardBounceFn: func(ctx context.Context, messageID int) error {
+					hard = true
+					return tc.recordErr
+				},
+			}
+
+			sequences := testdata.MockSequenceRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					return seq, id == seq.ID, nil
+				},
+				GetSequenceVersionFn: func(ctx context.Context, id int, version int) (sequence.Sequence, bool, error) {
+					return pinned, id == pinned.ID && version == pinned.PublishedVersion, nil
+				},
+			}
+
+			config := bounce.Config{MaxRetries: bounce.DefaultMaxRetries}
+			if tc.retriesDisabled {
+				config.MaxRetries = 0
+			}
+
+			svc := bounce.NewService(repo, sequences, config)
+
+			f, err := os.Open(tc.fixture)
+			if err != nil {
+				t.Fatalf("Failed to open fixture: %v", err)
+			}
+			defer f.Close()
+
+			result, err := svc.ProcessDSN(context.Background(), f)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if len(result.Recipients) != 1 {
+				t.Fatalf("Expected 1 recipient, got: %d", len(result.Recipients))
+			}
+
+			r := result.Recipients[0]
+			if r.Outcome != tc.expectedOutcome {
+				t.Errorf("Expected outcome: %q, got: %q", tc.expectedOutcome, r.Outcome)
+			}
+
+			if r.Type != tc.expectedType {
+				t.Errorf("Expected type: %q, got: %q", tc.expectedType, r.Type)
+			}
+
+			if hard != tc.expectedHard {
+				t.Errorf("Expected hard bounce to be recorded: %t, got: %t", tc.expectedHard, hard)
+			}
+
+			if tc.expectedRetry == nil {
+				if retry != nil {
+					t.Errorf("Expected no retry, got: %+v", retry)
+				}
+				return
+			}
+
+			if retry == nil {
+				t.Fatalf("Expected retry: %+v, got none", tc.expectedRetry)
+			}
+
+			if retry.EnrollmentID != tc.expectedRetry.EnrollmentID || retry.CurrentStep != tc.expectedRetry.CurrentStep {
+				t.Errorf("Expected retry: %+v, got: %+v", tc.expectedRetry, retry)
+			}
+
+			if d := retry.RetryAt.Sub(tc.expectedRetry.RetryAt); d < -time.Minute || d > time.Minute {
+				t.Errorf("Expected retry at: %s, got: %s", tc.expectedRetry.RetryAt, retry.RetryAt)
+			}
+
+			if (r.RetryAt != nil) != tc.rescheduled {
+				t.Errorf("Expected retry time to be reported: %t, got: %v", tc.rescheduled, r.RetryAt)
+			}
+		})
+	}
+}
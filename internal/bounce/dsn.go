@@ -0,0 +1,192 @@
+package bounce
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidDSN is returned when a message is not an RFC 3464 delivery status notification.
+var ErrInvalidDSN = errors.New("message is not a delivery status notification")
+
+// statusPattern matches RFC 3463 enhanced status codes.
+var statusPattern = regexp.MustCompile(`^[245]\.\d{1,3}\.\d{1,3}$`)
+
+// Report is a parsed delivery status notification.
+type Report struct {
+	// OriginalMessageID is the Message-ID of the message the report is about, without angle
+	// brackets. It is empty when the report does not include the original headers.
+	OriginalMessageID string
+	Recipients        []Recipient
+}
+
+// Recipient is the delivery status of the message for one of its recipients.
+type Recipient struct {
+	// Address is the final recipient in lower case.
+	Address string
+	// Action is failed, delayed, delivered, relayed or expanded.
+	Action string
+	// Status is the enhanced status code, e.g. 5.1.1.
+	Status     string
+	Diagnostic string
+}
+
+// Type classifies the delivery status as a hard or soft bounce. It is empty when the message
+// did not bounce, including when delivery is merely delayed and still being retried.
+func (r Recipient) Type() Type {
+	if r.Action != "failed" {
+		return ""
+	}
+
+	// A full mailbox may have room again later.
+	if strings.HasPrefix(r.Status, "4.") || r.Status == "5.2.2" {
+		return TypeSoft
+	}
+
+	return TypeHard
+}
+
+// ParseDSN parses a raw RFC 5322 message containing a multipart/report delivery status
+// notification.
+func ParseDSN(r io.Reader) (Report, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return Report{}, fmt.Errorf("%w: %s", ErrInvalidDSN, err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" || params["boundary"] == "" {
+		return Report{}, fmt.Errorf("%w: content type must be multipart/report", ErrInvalidDSN)
+	}
+
+	var (
+		report    Report
+		hasStatus bool
+	)
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Report{}, fmt.Errorf("%w: %s", ErrInvalidDSN, err)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		body := partBody(part)
+
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			recipients, err := parseDeliveryStatus(body)
+			if err != nil {
+				return Report{}, fmt.Errorf("%w: %s", ErrInvalidDSN, err)
+			}
+
+			report.Recipients = append(report.Recipients, recipients...)
+			hasStatus = true
+		case "message/rfc822", "message/global", "text/rfc822-headers", "message/global-headers":
+			header, err := readHeader(body)
+			if err != nil {
+				continue
+			}
+
+			report.OriginalMessageID = strings.Trim(strings.TrimSpace(header.Get("Message-Id")), "<>")
+		}
+	}
+
+	if !hasStatus {
+		return Report{}, fmt.Errorf("%w: delivery status part is missing", ErrInvalidDSN)
+	}
+
+	return report, nil
+}
+
+// partBody returns the decoded body of a part. Quoted-printable parts are already decoded by
+// the multipart reader.
+func partBody(part *multipart.Part) io.Reader {
+	if strings.EqualFold(strings.TrimSpace(part.Header.Get("Content-Transfer-Encoding")), "base64") {
+		return base64.NewDecoder(base64.StdEncoding, part)
+	}
+
+	return part
+}
+
+// parseDeliveryStatus parses the per-recipient fields of a message/delivery-status part.
+// The fields about the message as a whole, which come first, are skipped.
+func parseDeliveryStatus(body io.Reader) ([]Recipient, error) {
+	reader := textproto.NewReader(bufio.NewReader(body))
+
+	var recipients []Recipient
+	for {
+		fields, err := reader.ReadMIMEHeader()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if fields.Get("Final-Recipient") != "" {
+			recipient := Recipient{
+				Address:    strings.ToLower(strings.Trim(fieldValue(fields.Get("Final-Recipient")), "<>")),
+				Action:     strings.ToLower(strings.TrimSpace(fields.Get("Action"))),
+				Status:     firstWord(fields.Get("Status")),
+				Diagnostic: fieldValue(fields.Get("Diagnostic-Code")),
+			}
+
+			if recipient.Action == "" {
+				return nil, fmt.Errorf("action of recipient %q is missing", recipient.Address)
+			}
+
+			if recipient.Status != "" && !statusPattern.MatchString(recipient.Status) {
+				return nil, fmt.Errorf("status %q is invalid", recipient.Status)
+			}
+
+			recipients = append(recipients, recipient)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients")
+	}
+
+	return recipients, nil
+}
+
+// readHeader reads the header of an attached message, which may be all there is.
+func readHeader(body io.Reader) (textproto.MIMEHeader, error) {
+	header, err := textproto.NewReader(bufio.NewReader(body)).ReadMIMEHeader()
+	if err != nil && (err != io.EOF || len(header) == 0) {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// fieldValue strips the type of a typed field value such as "rfc822; jane@example.com".
+func fieldValue(value string) string {
+	if _, v, ok := strings.Cut(value, ";"); ok {
+		value = v
+	}
+
+	return strings.TrimSpace(value)
+}
+
+func firstWord(value string) string {
+	if fields := strings.Fields(value); len(fields) > 0 {
+		return fields[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,142 @@
+package bounce_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/bounce"
+)
+
+func TestParseDSN(t *testing.T) {
+	testCases := []struct {
+		name         string
+		fixture      string
+		expected     bounce.Report
+		expectedType bounce.Type
+	}{
+		{
+			name:    "Hard bounce",
+			fixture: "testdata/hard_bounce.eml",
+			expected: bounce.Report{
+				OriginalMessageID: "original-message@example.com",
+				Recipients: []bounce.Recipient{{
+					Address:    "jane@example.com",
+					Action:     "failed",
+					Status:     "5.1.1",
+					Diagnostic: "550 5.1.1 <jane@example.com>: Recipient address rejected: User unknown in virtual mailbox table",
+				}},
+			},
+			expectedType: bounce.TypeHard,
+		},
+		{
+			name:    "Soft bounce",
+			fixture: "testdata/soft_bounce.eml",
+			expected: bounce.Report{
+				OriginalMessageID: "original-message@example.com",
+				Recipients: []bounce.Recipient{{
+					Address:    "jane@example.com",
+					Action:     "failed",
+					Status:     "5.2.2",
+					Diagnostic: "552 5.2.2 Mailbox full",
+				}},
+			},
+			expectedType: bounce.TypeSoft,
+		},
+		{
+			name:    "Delayed",
+			fixture: "testdata/delayed.eml",
+			expected: bounce.Report{
+				Recipients: []bounce.Recipient{{
+					Address: "jane@example.com",
+					Action:  "delayed",
+					Status:  "4.4.1",
+				}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := os.Open(tc.fixture)
+			if err != nil {
+				t.Fatalf("Failed to open fixture: %v", err)
+			}
+			defer f.Close()
+
+			report, err := bounce.ParseDSN(f)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(report, tc.expected) {
+				t.Errorf("Expected report: %+v, got: %+v", tc.expected, report)
+			}
+
+			if bounceType := report.Recipients[0].Type(); bounceType != tc.expectedType {
+				t.Errorf("Expected bounce type: %q, got: %q", tc.expectedType, bounceType)
+			}
+		})
+	}
+}
+
+func TestParseDSN_Invalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		message string
+	}{
+		{
+			name:    "Not a message",
+			message: "hello",
+		},
+		{
+			name:    "Not a report",
+			message: "Content-Type: text/plain\n\nHi there\n",
+		},
+		{
+			name:    "Missing delivery status",
+			message: "Content-Type: multipart/report; boundary=b\n\n--b\nContent-Type: text/plain\n\nBounced\n--b--\n",
+		},
+		{
+			name:    "Missing action",
+			message: "Content-Type: multipart/report; boundary=b\n\n--b\nContent-Type: message/delivery-status\n\nReporting-MTA: dns; mx.example.net\n\nFinal-Recipient: rfc822; jane@example.com\nStatus: 5.1.1\n--b--\n",
+		},
+		{
+			name:    "Invalid status",
+			message: "Content-Type: multipart/report; boundary=b\n\n--b\nContent-Type: message/delivery-status\n\nFinal-Recipient: rfc822; jane@example.com\nAction: failed\nStatus: 550\n--b--\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := bounce.ParseDSN(strings.NewReader(tc.message)); !errors.Is(err, bounce.ErrInvalidDSN) {
+				t.Errorf("Expected ErrInvalidDSN, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestRecipient_Type(t *testing.T) {
+	testCases := []struct {
+		action   string
+		status   string
+		expected bounce.Type
+	}{
+		{"failed", "5.1.1", bounce.TypeHard},
+		{"failed", "", bounce.TypeHard},
+		{"failed", "5.2.2", bounce.TypeSoft},
+		{"failed", "4.2.1", bounce.TypeSoft},
+		{"delayed", "4.4.1", ""},
+		{"delivered", "2.0.0", ""},
+		{"relayed", "2.0.0", ""},
+	}
+
+	for _, tc := range testCases {
+		r := bounce.Recipient{Action: tc.action, Status: tc.status}
+		if bounceType := r.Type(); bounceType != tc.expected {
+			t.Errorf("Expected %s with status %q to be %q, got: %q", tc.action, tc.status, tc.expected, bounceType)
+		}
+	}
+}
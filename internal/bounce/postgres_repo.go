@@ -0,0 +1,212 @@
+package bounce
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresRepository is a repository for bounces using Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository creates a new Postgres repository.
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+const findMessageByIDQuery = `
+SELECT message.id, message.enrollment_id, message.sequence_id, message.step_id, message.contact_id, message.bounce_type, enrollment.version
+FROM message JOIN enrollment ON enrollment.id = message.enrollment_id
+WHERE message.message_id = $1 AND message.status = 'sent';
+`
+
+const findMessageByRecipientQuery = `
+SELECT message.id, message.enrollment_id, message.sequence_id, message.step_id, message.contact_id, message.bounce_type, enrollment.version
+FROM message JOIN contact ON contact.id = message.contact_id JOIN enrollment ON enrollment.id = message.enrollment_id
+WHERE contact.email = $1 AND message.status = 'sent'
+ORDER BY message.sent_at DESC LIMIT 1;
+`
+
+// FindMessage finds a sent message by the value of its Message-ID header or, when that is
+// empty, the latest one sent to the recipient.
+func (r PostgresRepository) FindMessage(ctx context.Context, messageID string, recipient string) (Message, bool, error) {
+	query, arg := findMessageByIDQuery, messageID
+	if messageID == "" {
+		query, arg = findMessageByRecipientQuery, recipient
+	}
+
+	row := MessageRow{}
+	if err := r.db.GetContext(ctx, &row, query, arg); err != nil {
+		if err == sql.ErrNoRows {
+			return Message{}, false, nil
+		}
+
+		return Message{}, false, err
+	}
+
+	return row.ToMessage(), true, nil
+}
+
+const countSoftBouncesQuery = `
+SELECT COUNT(*) FROM message WHERE enrollment_id = $1 AND step_id = $2 AND bounce_type = 'soft';
+`
+
+// CountSoftBounces counts the soft bounces of a step of an enrollment.
+func (r PostgresRepository) CountSoftBounces(ctx context.Context, enrollmentID int, stepID int) (int, error) {
+	var count int
+	if err := r.db.GetContext(ctx, &count, countSoftBouncesQuery, enrollmentID, stepID); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+const recordBounceQuery = `
+UPDATE message SET bounce_type = $1, bounced_at = NOW() WHERE id = $2 AND bounce_type IS NULL;
+`
+
+// recordBounce records the bounce of a message. It returns ErrAlreadyBounced when a bounce of
+// the message was recorded first, e.g. by a concurrent delivery of the same notification.
+func recordBounce(ctx context.Context, tx *sqlx.Tx, bounceType Type, messageID int) error {
+	result, err := tx.ExecContext(ctx, recordBounceQuery, bounceType, messageID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrAlreadyBounced
+	}
+
+	return nil
+}
+
+// retryStepQuery only resends the step when the enrollment is waiting for the step after it,
+// or finished with it, so a stopped enrollment stays stopped and later steps are not resent.
+const retryStepQuery = `
+UPDATE enrollment SET state = 'active', current_step = $1, next_send_at = $2, updated_at = NOW()
+WHERE id = $3 AND state IN ('active', 'finished') AND current_step = $1 + 1;
+`
+
+// RecordSoftBounce records a soft bounce and resends the step if given, unless the enrollment
+// was stopped or has moved on since. It reports whether the step is resent, and returns
+// ErrAlreadyBounced without resending it when a bounce of the message was already recorded.
+func (r PostgresRepository) RecordSoftBounce(ctx context.Context, messageID int, retry *Retry) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	var rescheduled bool
+	if err := func() error {
+		if err := recordBounce(ctx, tx, TypeSoft, messageID); err != nil {
+			return err
+		}
+
+		if retry == nil {
+			return nil
+		}
+
+		result, err := tx.ExecContext(ctx, retryStepQuery, retry.CurrentStep, retry.RetryAt, retry.EnrollmentID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		rescheduled = affected > 0
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return rescheduled, nil
+}
+
+const suppressRecipientQuery = `
+INSERT INTO suppression (type, value, reason)
+SELECT 'email', contact.email, 'bounce' FROM message JOIN contact ON contact.id = message.contact_id WHERE message.id = $1
+ON CONFLICT (type, value) DO NOTHING;
+`
+
+const stopEnrollmentsQuery = `
+UPDATE enrollment SET state = 'bounced', next_send_at = NULL, updated_at = NOW()
+WHERE contact_id = (SELECT contact_id FROM message WHERE id = $1) AND state IN ('active', 'paused');
+`
+
+const recordBounceEventQuery = `
+INSERT INTO event (message_id, sequence_id, step_id, type) SELECT id, sequence_id, step_id, 'bounce' FROM message WHERE id = $1;
+`
+
+// RecordHardBounce records a hard bounce for the sequence stats, suppresses the address of the
+// recipient and stops all of their active and paused enrollments in a single transaction. It
+// returns ErrAlreadyBounced without changing anything when a bounce of the message was already
+// recorded.
+func (r PostgresRepository) RecordHardBounce(ctx context.Context, messageID int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if err := func() error {
+		if err := recordBounce(ctx, tx, TypeHard, messageID); err != nil {
+			return err
+		}
+
+		for _, query := range []string{suppressRecipientQuery, stopEnrollmentsQuery, recordBounceEventQuery} {
+			if _, err := tx.ExecContext(ctx, query, messageID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MessageRow represents a row of the message table.
+type MessageRow struct {
+	ID           int            `db:"id"`
+	EnrollmentID int            `db:"enrollment_id"`
+	SequenceID   int            `db:"sequence_id"`
+	StepID       sql.NullInt64  `db:"step_id"`
+	ContactID    int            `db:"contact_id"`
+	BounceType   sql.NullString `db:"bounce_type"`
+	Version      sql.NullInt64  `db:"version"`
+}
+
+// ToMessage converts the row to a message.
+func (r MessageRow) ToMessage() Message {
+	msg := Message{
+		ID:           r.ID,
+		EnrollmentID: r.EnrollmentID,
+		SequenceID:   r.SequenceID,
+		ContactID:    r.ContactID,
+		BounceType:   Type(r.BounceType.String),
+		Version:      int(r.Version.Int64),
+	}
+
+	if r.StepID.Valid {
+		stepID := int(r.StepID.Int64)
+		msg.StepID = &stepID
+	}
+
+	return msg
+}
@@ -0,0 +1,46 @@
+package bounce_test
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/bounce"
+)
+
+func TestMessageRow_ToMessage(t *testing.T) {
+	stepID := 4
+
+	testCases := []struct {
+		name     string
+		row      bounce.MessageRow
+		expected bounce.Message
+	}{
+		{
+			name: "Bounced",
+			row: bounce.MessageRow{
+				ID:           1,
+				EnrollmentID: 2,
+				SequenceID:   3,
+				StepID:       sql.NullInt64{Int64: 4, Valid: true},
+				ContactID:    5,
+				BounceType:   sql.NullString{String: "soft", Valid: true},
+				Version:      sql.NullInt64{Int64: 2, Valid: true},
+			},
+			expected: bounce.Message{ID: 1, EnrollmentID: 2, SequenceID: 3, StepID: &stepID, ContactID: 5, BounceType: bounce.TypeSoft, Version: 2},
+		},
+		{
+			name:     "Deleted step",
+			row:      bounce.MessageRow{ID: 1, EnrollmentID: 2, SequenceID: 3, ContactID: 5},
+			expected: bounce.Message{ID: 1, EnrollmentID: 2, SequenceID: 3, ContactID: 5},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if msg := tc.row.ToMessage(); !reflect.DeepEqual(msg, tc.expected) {
+				t.Errorf("Expected message: %+v, got: %+v", tc.expected, msg)
+			}
+		})
+	}
+}
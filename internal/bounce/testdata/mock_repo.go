@@ -0,0 +1,44 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/bounce"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+type MockRepo struct {
+	FindMessageFn      func(ctx context.Context, messageID string, recipient string) (bounce.Message, bool, error)
+	CountSoftBouncesFn func(ctx context.Context, enrollmentID int, stepID int) (int, error)
+	RecordSoftBounceFn func(ctx context.Context, messageID int, retry *bounce.Retry) (bool, error)
+	RecordHardBounceFn func(ctx context.Context, messageID int) error
+}
+
+func (m MockRepo) FindMessage(ctx context.Context, messageID string, recipient string) (bounce.Message, bool, error) {
+	return m.FindMessageFn(ctx, messageID, recipient)
+}
+
+func (m MockRepo) CountSoftBounces(ctx context.Context, enrollmentID int, stepID int) (int, error) {
+	return m.CountSoftBouncesFn(ctx, enrollmentID, stepID)
+}
+
+func (m MockRepo) RecordSoftBounce(ctx context.Context, messageID int, retry *bounce.Retry) (bool, error) {
+	return m.RecordSoftBounceFn(ctx, messageID, retry)
+}
+
+func (m MockRepo) RecordHardBounce(ctx context.Context, messageID int) error {
+	return m.RecordHardBounceFn(ctx, messageID)
+}
+
+type MockSequenceRepo struct {
+	GetSequenceFn        func(ctx context.Context, id int) (sequence.Sequence, bool, error)
+	GetSequenceVersionFn func(ctx context.Context, id int, version int) (sequence.Sequence, bool, error)
+}
+
+func (m MockSequenceRepo) GetSequence(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+	return m.GetSequenceFn(ctx, id)
+}
+
+func (m MockSequenceRepo) GetSequenceVersion(ctx context.Context, id int, version int) (sequence.Sequence, bool, error) {
+	return m.GetSequenceVersionFn(ctx, id, version)
+}
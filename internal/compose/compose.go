@@ -0,0 +1,150 @@
+// Package compose builds RFC 5322 emails with an HTML part and a plain text alternative.
+package compose
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Email is an email to a single recipient.
+type Email struct {
+	// MessageID is the globally unique ID of the email, without angle brackets.
+	MessageID string
+	From      string
+	To        string
+	Subject   string
+	Date      time.Time
+	HTML      string
+	// Text is the plain text alternative of the HTML. It is derived from the HTML when empty.
+	Text string
+	// ListUnsubscribe are the URIs, e.g. https: or mailto:, the recipient can unsubscribe with.
+	ListUnsubscribe []string
+	// OneClickUnsubscribe advertises RFC 8058 one-click unsubscribing through the https URI.
+	OneClickUnsubscribe bool
+}
+
+// Build formats the email as a multipart/alternative message with quoted-printable parts.
+func (e Email) Build() ([]byte, error) {
+	from, err := netmail.ParseAddress(e.From)
+	if err != nil {
+		return nil, fmt.Errorf("from address is invalid: %w", err)
+	}
+
+	to, err := netmail.ParseAddress(e.To)
+	if err != nil {
+		return nil, fmt.Errorf("to address is invalid: %w", err)
+	}
+
+	if e.MessageID == "" {
+		return nil, errors.New("message id is required")
+	}
+
+	text := e.Text
+	if text == "" {
+		text = TextFromHTML(e.HTML)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	if err := writePart(parts, "text/plain; charset=UTF-8", text); err != nil {
+		return nil, err
+	}
+
+	if err := writePart(parts, "text/html; charset=UTF-8", e.HTML); err != nil {
+		return nil, err
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("UTF-8", e.Subject)},
+		{"Date", e.Date.Format(time.RFC1123Z)},
+		{"Message-ID", "<" + e.MessageID + ">"},
+		{"MIME-Version", "1.0"},
+	}
+
+	if len(e.ListUnsubscribe) > 0 {
+		uris := make([]string, len(e.ListUnsubscribe))
+		for i, uri := range e.ListUnsubscribe {
+			uris[i] = "<" + uri + ">"
+		}
+		headers = append(headers, [2]string{"List-Unsubscribe", strings.Join(uris, ", ")})
+
+		if e.OneClickUnsubscribe {
+			headers = append(headers, [2]string{"List-Unsubscribe-Post", "List-Unsubscribe=One-Click"})
+		}
+	}
+
+	headers = append(headers, [2]string{"Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": parts.Boundary()})})
+
+	var msg bytes.Buffer
+	for _, header := range headers {
+		// Line breaks in a header value would allow injecting headers.
+		if strings.ContainsAny(header[1], "\r\n") {
+			return nil, fmt.Errorf("%s header cannot contain line breaks", header[0])
+		}
+
+		fmt.Fprintf(&msg, "%s: %s\r\n", header[0], header[1])
+	}
+
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// writePart writes content as a quoted-printable part of the given type.
+func writePart(parts *multipart.Writer, contentType string, content string) error {
+	w, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+
+	return qp.Close()
+}
+
+// NewMessageID creates a unique message ID for an email sent from the given domain.
+func NewMessageID(domain string) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b) + "@" + domain
+}
+
+// Domain returns the domain of an email address, or localhost if it has none.
+func Domain(address string) string {
+	if addr, err := netmail.ParseAddress(address); err == nil {
+		address = addr.Address
+	}
+
+	if i := strings.LastIndex(address, "@"); i >= 0 && i < len(address)-1 {
+		return address[i+1:]
+	}
+
+	return "localhost"
+}
@@ -0,0 +1,179 @@
+package compose_test
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/compose"
+)
+
+func TestEmail_Build(t *testing.T) {
+	email := compose.Email{
+		MessageID:           "abc123@example.com",
+		From:                "Zoë Sales <sales@example.com>",
+		To:                  "jane@example.com",
+		Subject:             "Grüße aus München",
+		Date:                time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		HTML:                `<p>Hi Jane,</p><p>a line that is long enough to be wrapped by the quoted-printable encoding, which limits lines to 76 characters.</p>`,
+		ListUnsubscribe:     []string{"https://example.com/u/token", "mailto:sales@example.com?subject=unsubscribe"},
+		OneClickUnsubscribe: true,
+	}
+
+	raw, err := email.Build()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	msg, err := netmail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Failed to parse email: %v", err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != email.Subject {
+		t.Errorf("Expected subject: %q, got: %q (%v)", email.Subject, subject, err)
+	}
+
+	if strings.ContainsFunc(msg.Header.Get("Subject"), func(r rune) bool { return r > 127 }) {
+		t.Errorf("Expected subject to be encoded, got: %q", msg.Header.Get("Subject"))
+	}
+
+	from, err := msg.Header.AddressList("From")
+	if err != nil || len(from) != 1 || from[0].Name != "Zoë Sales" || from[0].Address != "sales@example.com" {
+		t.Errorf("Unexpected from: %v (%v)", from, err)
+	}
+
+	expectedHeaders := map[string]string{
+		"To":                    "<jane@example.com>",
+		"Date":                  "Fri, 01 Mar 2024 09:30:00 +0000",
+		"Message-Id":            "<abc123@example.com>",
+		"Mime-Version":          "1.0",
+		"List-Unsubscribe":      "<https://example.com/u/token>, <mailto:sales@example.com?subject=unsubscribe>",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	for key, expected := range expectedHeaders {
+		if value := msg.Header.Get(key); value != expected {
+			t.Errorf("Expected %s header: %q, got: %q", key, expected, value)
+		}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative, got: %q (%v)", mediaType, err)
+	}
+
+	// Every line must stay within the limits of RFC 5322
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 998 {
+			t.Errorf("Line exceeds 998 characters: %q", line)
+		}
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	expectedParts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", "Hi Jane,\r\n\r\na line that is long enough to be wrapped by the quoted-printable encoding, which limits lines to 76 characters."},
+		{"text/html; charset=UTF-8", email.HTML},
+	}
+
+	for _, expected := range expectedParts {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+
+		if part.Header.Get("Content-Type") != expected.contentType {
+			t.Errorf("Expected content type: %q, got: %q", expected.contentType, part.Header.Get("Content-Type"))
+		}
+
+		// The multipart reader decodes quoted-printable parts transparently
+		content, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+
+		if string(content) != expected.content {
+			t.Errorf("Expected content: %q, got: %q", expected.content, content)
+		}
+	}
+
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("Expected 2 parts, got more (%v)", err)
+	}
+}
+
+func TestEmail_Build_Invalid(t *testing.T) {
+	valid := compose.Email{MessageID: "abc@example.com", From: "sales@example.com", To: "jane@example.com", Subject: "Hi", HTML: "<p>Hi</p>"}
+
+	testCases := []struct {
+		name  string
+		email func(e compose.Email) compose.Email
+	}{
+		{
+			name:  "Invalid from",
+			email: func(e compose.Email) compose.Email { e.From = "sales"; return e },
+		},
+		{
+			name:  "Invalid to",
+			email: func(e compose.Email) compose.Email { e.To = ""; return e },
+		},
+		{
+			name:  "Missing message ID",
+			email: func(e compose.Email) compose.Email { e.MessageID = ""; return e },
+		},
+		{
+			name: "Header injection",
+			email: func(e compose.Email) compose.Email {
+				e.ListUnsubscribe = []string{"https://example.com\r\nBcc: x@example.com"}
+				return e
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.email(valid).Build(); err == nil {
+				t.Errorf("Expected error, got: nil")
+			}
+		})
+	}
+}
+
+func TestDomain(t *testing.T) {
+	testCases := []struct {
+		address  string
+		expected string
+	}{
+		{"sales@example.com", "example.com"},
+		{"Sales <sales@mail.example.com>", "mail.example.com"},
+		{"sales", "localhost"},
+	}
+
+	for _, tc := range testCases {
+		if domain := compose.Domain(tc.address); domain != tc.expected {
+			t.Errorf("Expected domain of %q: %q, got: %q", tc.address, tc.expected, domain)
+		}
+	}
+}
+
+func TestNewMessageID(t *testing.T) {
+	a := compose.NewMessageID("example.com")
+	b := compose.NewMessageID("example.com")
+
+	if a == b {
+		t.Errorf("Expected unique message IDs, got: %q twice", a)
+	}
+
+	if !strings.HasSuffix(a, "@example.com") {
+		t.Errorf("Expected message ID of example.com, got: %q", a)
+	}
+}
@@ -0,0 +1,107 @@
+package compose
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// htmlTagPattern matches the tags commonly found in HTML email content.
+	htmlTagPattern = regexp.MustCompile(`(?i)<(html|body|p|div|br|a|span|table|strong|b|i|em|u|ul|ol|li|h[1-6]|img|hr)\b[^>]*>`)
+
+	paragraphBreakPattern = regexp.MustCompile(`\n\s*\n`)
+
+	scriptPattern     = regexp.MustCompile(`(?is)<script\b.*?</script\s*>`)
+	stylePattern      = regexp.MustCompile(`(?is)<style\b.*?</style\s*>`)
+	headPattern       = regexp.MustCompile(`(?is)<head\b.*?</head\s*>`)
+	commentPattern    = regexp.MustCompile(`(?s)<!--.*?-->`)
+	linkPattern       = regexp.MustCompile(`(?is)<a\b[^>]*?\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))[^>]*>(.*?)</a\s*>`)
+	lineBreakPattern  = regexp.MustCompile(`(?i)<br\b[^>]*>`)
+	listItemPattern   = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	blockEndPattern   = regexp.MustCompile(`(?i)</(p|div|h[1-6]|ul|ol|table|blockquote)\s*>|<hr\b[^>]*>`)
+	rowEndPattern     = regexp.MustCompile(`(?i)</tr\s*>`)
+	tagPattern        = regexp.MustCompile(`(?s)<[^>]*>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+	spacedLinePattern = regexp.MustCompile(` *\n *`)
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// IsHTML reports whether content contains HTML markup rather than plain text.
+func IsHTML(content string) bool {
+	return htmlTagPattern.MatchString(content)
+}
+
+// HTMLFromText converts plain text to HTML. Blank lines separate paragraphs and single line
+// breaks are kept.
+func HTMLFromText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var sb strings.Builder
+	for _, paragraph := range paragraphBreakPattern.Split(strings.TrimSpace(text), -1) {
+		if paragraph == "" {
+			continue
+		}
+
+		lines := strings.Split(paragraph, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+
+		sb.WriteString("<p>")
+		sb.WriteString(strings.Join(lines, "<br>\n"))
+		sb.WriteString("</p>\n")
+	}
+
+	return sb.String()
+}
+
+// AppendToBody appends an HTML fragment to the content, keeping it inside the body of full
+// HTML documents.
+func AppendToBody(content string, fragment string) string {
+	if i := strings.LastIndex(strings.ToLower(content), "</body>"); i >= 0 {
+		return content[:i] + fragment + content[i:]
+	}
+
+	return content + fragment
+}
+
+// TextFromHTML derives a readable plain text version of HTML content. Links are written as
+// their text followed by the target in parentheses.
+func TextFromHTML(content string) string {
+	for _, pattern := range []*regexp.Regexp{headPattern, scriptPattern, stylePattern, commentPattern} {
+		content = pattern.ReplaceAllString(content, "")
+	}
+
+	// Whitespace in HTML source is not significant, so line breaks only come from markup.
+	content = whitespacePattern.ReplaceAllString(content, " ")
+
+	content = linkPattern.ReplaceAllStringFunc(content, func(link string) string {
+		m := linkPattern.FindStringSubmatch(link)
+		// Both are unescaped along with the rest of the content.
+		href := m[1] + m[2] + m[3]
+		text := strings.TrimSpace(tagPattern.ReplaceAllString(m[4], ""))
+
+		if text == "" || text == href || strings.HasPrefix(href, "mailto:") {
+			if text == "" {
+				return href
+			}
+			return text
+		}
+
+		return text + " (" + href + ")"
+	})
+
+	content = lineBreakPattern.ReplaceAllString(content, "\n")
+	content = listItemPattern.ReplaceAllString(content, "\n- ")
+	content = blockEndPattern.ReplaceAllString(content, "\n\n")
+	content = rowEndPattern.ReplaceAllString(content, "\n")
+	content = tagPattern.ReplaceAllString(content, "")
+	content = html.UnescapeString(content)
+
+	content = strings.ReplaceAll(content, "\u00a0", " ")
+	content = spacedLinePattern.ReplaceAllString(content, "\n")
+	content = blankLinesPattern.ReplaceAllString(content, "\n\n")
+
+	return strings.TrimSpace(content)
+}
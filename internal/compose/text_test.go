@@ -0,0 +1,99 @@
+package compose_test
+
+import (
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/compose"
+)
+
+func TestTextFromHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "Paragraphs and line breaks",
+			html:     "<p>Hi Jane,</p>\n<p>first line<br>second   line</p>",
+			expected: "Hi Jane,\n\nfirst line\nsecond line",
+		},
+		{
+			name:     "Links",
+			html:     `<p>Book a <a href="https://example.com/call?a=1&amp;b=2">call</a> or visit <a href='https://example.com'>https://example.com</a>.</p>`,
+			expected: "Book a call (https://example.com/call?a=1&b=2) or visit https://example.com.",
+		},
+		{
+			name:     "Mailto links",
+			html:     `<p>Write to <a href="mailto:sales@example.com">sales@example.com</a></p>`,
+			expected: "Write to sales@example.com",
+		},
+		{
+			name:     "Lists",
+			html:     "<ul><li>One</li><li>Two</li></ul><p>Done</p>",
+			expected: "- One\n- Two\n\nDone",
+		},
+		{
+			name:     "Head, styles, scripts and comments",
+			html:     "<html><head><title>Hi</title><style>p { color: red; }</style></head><body><!-- hidden --><script>alert(1)</script><p>Visible</p></body></html>",
+			expected: "Visible",
+		},
+		{
+			name:     "Entities",
+			html:     "<p>Fish &amp; chips&nbsp;&lt;3</p>",
+			expected: "Fish & chips <3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if text := compose.TextFromHTML(tc.html); text != tc.expected {
+				t.Errorf("Expected text: %q, got: %q", tc.expected, text)
+			}
+		})
+	}
+}
+
+func TestHTMLFromText(t *testing.T) {
+	text := "Hi Jane,\n\nFish & chips\nat <noon>?\n"
+	expected := "<p>Hi Jane,</p>\n<p>Fish &amp; chips<br>\nat &lt;noon&gt;?</p>\n"
+
+	if html := compose.HTMLFromText(text); html != expected {
+		t.Errorf("Expected HTML: %q, got: %q", expected, html)
+	}
+}
+
+func TestIsHTML(t *testing.T) {
+	testCases := []struct {
+		content  string
+		expected bool
+	}{
+		{"<p>Hi</p>", true},
+		{"Hi<br/>there", true},
+		{`<A HREF="https://example.com">link</A>`, true},
+		{"Hi there", false},
+		{"1 < 2 and 3 > 2", false},
+		{"Hi {{firstName}}", false},
+	}
+
+	for _, tc := range testCases {
+		if isHTML := compose.IsHTML(tc.content); isHTML != tc.expected {
+			t.Errorf("Expected IsHTML(%q) to be %t, got: %t", tc.content, tc.expected, isHTML)
+		}
+	}
+}
+
+func TestAppendToBody(t *testing.T) {
+	testCases := []struct {
+		content  string
+		expected string
+	}{
+		{"<p>Hi</p>", "<p>Hi</p><p>Bye</p>"},
+		{"<html><BODY><p>Hi</p></BODY></html>", "<html><BODY><p>Hi</p><p>Bye</p></BODY></html>"},
+	}
+
+	for _, tc := range testCases {
+		if html := compose.AppendToBody(tc.content, "<p>Bye</p>"); html != tc.expected {
+			t.Errorf("Expected HTML: %q, got: %q", tc.expected, html)
+		}
+	}
+}
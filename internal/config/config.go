@@ -3,13 +3,25 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
+	"github.com/cybre/salesforge-assignment/internal/bounce"
 	"github.com/cybre/salesforge-assignment/internal/database"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
 )
 
 type Config struct {
-	Port     string
-	Database database.Config
+	Port      string
+	Database  database.Config
+	Mail      mail.Config
+	Scheduler scheduler.Config
+	Tracking  tracking.Config
+	Bounce    bounce.Config
+	Mailbox   mailbox.Config
 }
 
 func LoadConfig() Config {
@@ -20,8 +32,25 @@ func LoadConfig() Config {
 		password = MustGetEnv("DATABASE_PASSWORD")
 	}
 
+	smtpPassword := GetEnv("SMTP_PASSWORD", "")
+	if val, ok := os.LookupEnv("SMTP_PASSWORD_FILE"); ok {
+		smtpPassword = ReadSecret(val)
+	}
+
+	trackingSecret := GetEnv("TRACKING_SECRET", "")
+	if val, ok := os.LookupEnv("TRACKING_SECRET_FILE"); ok {
+		trackingSecret = ReadSecret(val)
+	}
+
+	mailboxKey := GetEnv("MAILBOX_ENCRYPTION_KEY", "")
+	if val, ok := os.LookupEnv("MAILBOX_ENCRYPTION_KEY_FILE"); ok {
+		mailboxKey = ReadSecret(val)
+	}
+
+	port := GetEnv("PORT", "3000")
+
 	return Config{
-		Port: GetEnv("PORT", "3000"),
+		Port: port,
 		Database: database.Config{
 			Host:     MustGetEnv("DATABASE_HOST"),
 			Port:     MustGetEnv("DATABASE_PORT"),
@@ -29,6 +58,41 @@ func LoadConfig() Config {
 			User:     MustGetEnv("DATABASE_USER"),
 			Password: password,
 		},
+		Mail: mail.Config{
+			Driver: mail.Driver(GetEnv("MAIL_DRIVER", string(mail.DriverLog))),
+			SMTP: mail.SMTPConfig{
+				Host:           GetEnv("SMTP_HOST", ""),
+				Port:           GetEnv("SMTP_PORT", "587"),
+				Username:       GetEnv("SMTP_USERNAME", ""),
+				Password:       smtpPassword,
+				Auth:           mail.SMTPAuth(GetEnv("SMTP_AUTH", string(mail.SMTPAuthNone))),
+				Security:       mail.SMTPSecurity(GetEnv("SMTP_SECURITY", string(mail.SMTPSecuritySTARTTLS))),
+				ConnectTimeout: GetDurationEnv("SMTP_CONNECT_TIMEOUT", mail.DefaultSMTPConnectTimeout),
+				SendTimeout:    GetDurationEnv("SMTP_SEND_TIMEOUT", mail.DefaultSMTPSendTimeout),
+			},
+			CaptureDir: GetEnv("MAIL_CAPTURE_DIR", ""),
+		},
+		Scheduler: scheduler.Config{
+			From:            GetEnv("MAIL_FROM", "no-reply@localhost"),
+			Interval:        GetDurationEnv("SCHEDULER_INTERVAL", scheduler.DefaultInterval),
+			BatchSize:       GetIntEnv("SCHEDULER_BATCH_SIZE", scheduler.DefaultBatchSize),
+			Lease:           GetDurationEnv("SCHEDULER_LEASE", scheduler.DefaultLease),
+			RetryDelay:      GetDurationEnv("SCHEDULER_RETRY_DELAY", scheduler.DefaultRetryDelay),
+			MaxSendAttempts: GetIntEnv("SCHEDULER_MAX_SEND_ATTEMPTS", scheduler.DefaultMaxSendAttempts),
+		},
+		Tracking: tracking.Config{
+			BaseURL:        GetEnv("TRACKING_BASE_URL", "http://localhost:"+port),
+			Secret:         trackingSecret,
+			DedupeWindow:   GetDurationEnv("TRACKING_DEDUPE_WINDOW", tracking.DefaultDedupeWindow),
+			PrefetchWindow: GetDurationEnv("TRACKING_PREFETCH_WINDOW", tracking.DefaultPrefetchWindow),
+		},
+		Bounce: bounce.Config{
+			MaxRetries: GetNonNegativeIntEnv("BOUNCE_MAX_RETRIES", bounce.DefaultMaxRetries),
+			RetryDelay: GetDurationEnv("BOUNCE_RETRY_DELAY", bounce.DefaultRetryDelay),
+		},
+		Mailbox: mailbox.Config{
+			EncryptionKey: mailboxKey,
+		},
 	}
 }
 
@@ -40,6 +104,48 @@ func GetEnv(key, fallback string) string {
 	return fallback
 }
 
+func GetIntEnv(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 1 {
+		panic(fmt.Sprintf("%s must be a positive integer", key))
+	}
+
+	return i
+}
+
+func GetNonNegativeIntEnv(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		panic(fmt.Sprintf("%s must be a non-negative integer", key))
+	}
+
+	return i
+}
+
+func GetDurationEnv(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		panic(fmt.Sprintf("%s must be a positive duration, e.g. 30s", key))
+	}
+
+	return d
+}
+
 func MustGetEnv(key string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
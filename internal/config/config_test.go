@@ -4,9 +4,15 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/cybre/salesforge-assignment/internal/bounce"
 	"github.com/cybre/salesforge-assignment/internal/config"
 	"github.com/cybre/salesforge-assignment/internal/database"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
 )
 
 func TestGetEnv(t *testing.T) {
@@ -31,6 +37,93 @@ func TestGetEnv(t *testing.T) {
 	}
 }
 
+func TestGetIntEnv(t *testing.T) {
+	// Test case 1: Environment variable exists
+	key := "MY_INT_ENV_VAR"
+	os.Setenv(key, "42")
+	defer os.Unsetenv(key)
+
+	result := config.GetIntEnv(key, 1)
+	if result != 42 {
+		t.Errorf("Expected %d, but got %d", 42, result)
+	}
+
+	// Test case 2: Environment variable does not exist
+	result = config.GetIntEnv("NON_EXISTENT_ENV_VAR", 7)
+	if result != 7 {
+		t.Errorf("Expected %d, but got %d", 7, result)
+	}
+
+	// Test case 3: Environment variable is not a positive integer
+	os.Setenv(key, "many")
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic, but got none")
+		}
+	}()
+
+	config.GetIntEnv(key, 1)
+}
+
+func TestGetNonNegativeIntEnv(t *testing.T) {
+	// Test case 1: Environment variable is zero
+	key := "MY_NON_NEGATIVE_INT_ENV_VAR"
+	os.Setenv(key, "0")
+	defer os.Unsetenv(key)
+
+	result := config.GetNonNegativeIntEnv(key, 3)
+	if result != 0 {
+		t.Errorf("Expected %d, but got %d", 0, result)
+	}
+
+	// Test case 2: Environment variable does not exist
+	result = config.GetNonNegativeIntEnv("NON_EXISTENT_ENV_VAR", 7)
+	if result != 7 {
+		t.Errorf("Expected %d, but got %d", 7, result)
+	}
+
+	// Test case 3: Environment variable is negative
+	os.Setenv(key, "-1")
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic, but got none")
+		}
+	}()
+
+	config.GetNonNegativeIntEnv(key, 1)
+}
+
+func TestGetDurationEnv(t *testing.T) {
+	// Test case 1: Environment variable exists
+	key := "MY_DURATION_ENV_VAR"
+	os.Setenv(key, "90s")
+	defer os.Unsetenv(key)
+
+	result := config.GetDurationEnv(key, time.Second)
+	if result != 90*time.Second {
+		t.Errorf("Expected %s, but got %s", 90*time.Second, result)
+	}
+
+	// Test case 2: Environment variable does not exist
+	result = config.GetDurationEnv("NON_EXISTENT_ENV_VAR", time.Minute)
+	if result != time.Minute {
+		t.Errorf("Expected %s, but got %s", time.Minute, result)
+	}
+
+	// Test case 3: Environment variable is not a duration
+	os.Setenv(key, "soon")
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic, but got none")
+		}
+	}()
+
+	config.GetDurationEnv(key, time.Second)
+}
+
 func TestMustGetEnv(t *testing.T) {
 	// Test case 1: Environment variable exists
 	key := "MY_ENV_VAR"
@@ -92,6 +185,15 @@ func TestLoadConfig(t *testing.T) {
 	os.Setenv("DATABASE_NAME", "mydb")
 	os.Setenv("DATABASE_USER", "myuser")
 	os.Setenv("DATABASE_PASSWORD_FILE", "test_secret.txt")
+	os.Setenv("MAIL_FROM", "sales@example.com")
+	os.Setenv("MAIL_DRIVER", "smtp")
+	os.Setenv("SMTP_HOST", "smtp.example.com")
+	os.Setenv("SMTP_AUTH", "login")
+	os.Setenv("SMTP_USERNAME", "sales")
+	os.Setenv("SMTP_PASSWORD", "smtpsecret")
+	os.Setenv("TRACKING_SECRET", "trackingsecret123")
+	os.Setenv("SCHEDULER_INTERVAL", "30s")
+	os.Setenv("MAILBOX_ENCRYPTION_KEY", "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff")
 	os.WriteFile("test_secret.txt", []byte("mysecret"), 0644)
 
 	defer func() {
@@ -102,6 +204,15 @@ func TestLoadConfig(t *testing.T) {
 		os.Unsetenv("DATABASE_USER")
 		os.Unsetenv("DATABASE_PASSWORD")
 		os.Unsetenv("DATABASE_PASSWORD_FILE")
+		os.Unsetenv("MAIL_FROM")
+		os.Unsetenv("MAIL_DRIVER")
+		os.Unsetenv("SMTP_HOST")
+		os.Unsetenv("SMTP_AUTH")
+		os.Unsetenv("SMTP_USERNAME")
+		os.Unsetenv("SMTP_PASSWORD")
+		os.Unsetenv("TRACKING_SECRET")
+		os.Unsetenv("SCHEDULER_INTERVAL")
+		os.Unsetenv("MAILBOX_ENCRYPTION_KEY")
 		os.Remove("test_secret.txt")
 	}()
 
@@ -114,6 +225,40 @@ func TestLoadConfig(t *testing.T) {
 			User:     "myuser",
 			Password: "mysecret",
 		},
+		Mail: mail.Config{
+			Driver: mail.DriverSMTP,
+			SMTP: mail.SMTPConfig{
+				Host:           "smtp.example.com",
+				Port:           "587",
+				Username:       "sales",
+				Password:       "smtpsecret",
+				Auth:           mail.SMTPAuthLogin,
+				Security:       mail.SMTPSecuritySTARTTLS,
+				ConnectTimeout: mail.DefaultSMTPConnectTimeout,
+				SendTimeout:    mail.DefaultSMTPSendTimeout,
+			},
+		},
+		Scheduler: scheduler.Config{
+			From:            "sales@example.com",
+			Interval:        30 * time.Second,
+			BatchSize:       scheduler.DefaultBatchSize,
+			Lease:           scheduler.DefaultLease,
+			RetryDelay:      scheduler.DefaultRetryDelay,
+			MaxSendAttempts: scheduler.DefaultMaxSendAttempts,
+		},
+		Tracking: tracking.Config{
+			BaseURL:        "http://localhost:4000",
+			Secret:         "trackingsecret123",
+			DedupeWindow:   tracking.DefaultDedupeWindow,
+			PrefetchWindow: tracking.DefaultPrefetchWindow,
+		},
+		Bounce: bounce.Config{
+			MaxRetries: bounce.DefaultMaxRetries,
+			RetryDelay: bounce.DefaultRetryDelay,
+		},
+		Mailbox: mailbox.Config{
+			EncryptionKey: "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff",
+		},
 	}
 
 	result := config.LoadConfig()
@@ -0,0 +1,252 @@
+package contact
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrContactNotFound is returned when a contact with the given ID is not found.
+	ErrContactNotFound = errors.New("contact with given ID not found")
+
+	// ErrContactValidation is returned when a contact model fails validation.
+	ErrContactValidation = errors.New("contact model is invalid")
+
+	// ErrContactExists is returned when a contact with the same email already exists.
+	ErrContactExists = errors.New("contact with given email already exists")
+)
+
+const (
+	// maxNameLength is the maximum length of the name and company fields.
+	maxNameLength = 255
+
+	// maxEmailLength is the maximum length of an email address as per RFC 5321.
+	maxEmailLength = 320
+)
+
+// customFieldKeyPattern matches the keys custom fields can be referenced by in merge fields.
+var customFieldKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// Contact represents a recipient of sequence emails.
+type Contact struct {
+	ID        int    `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Company   string `json:"company"`
+	// TimeZone is the IANA time zone of the contact, such as Europe/Zagreb. Sequences with a
+	// sending schedule use the time zone of the schedule when it is empty.
+	TimeZone string `json:"timeZone"`
+	// CustomFields holds arbitrary values, available to merge fields as custom.<key>.
+	CustomFields map[string]any `json:"customFields"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+}
+
+// Normalize returns a copy of the contact with surrounding whitespace removed and
+// the email address normalized. An invalid email address is left as is for Validate
+// to report.
+func (c Contact) Normalize() Contact {
+	c.Email = strings.TrimSpace(c.Email)
+	if email, err := NormalizeEmail(c.Email); err == nil {
+		c.Email = email
+	}
+
+	c.FirstName = strings.TrimSpace(c.FirstName)
+	c.LastName = strings.TrimSpace(c.LastName)
+	c.Company = strings.TrimSpace(c.Company)
+	c.TimeZone = strings.TrimSpace(c.TimeZone)
+
+	if c.CustomFields == nil {
+		c.CustomFields = map[string]any{}
+	}
+
+	return c
+}
+
+// Validate validates the contact model.
+func (c Contact) Validate() error {
+	if c.Email == "" {
+		return errors.New("email is required")
+	}
+
+	if _, err := NormalizeEmail(c.Email); err != nil {
+		return err
+	}
+
+	if len(c.FirstName) > maxNameLength || len(c.LastName) > maxNameLength || len(c.Company) > maxNameLength {
+		return fmt.Errorf("first name, last name and company cannot be longer than %d characters", maxNameLength)
+	}
+
+	if c.TimeZone != "" {
+		if err := validateTimeZone(c.TimeZone); err != nil {
+			return err
+		}
+	}
+
+	for key := range c.CustomFields {
+		if !customFieldKeyPattern.MatchString(key) {
+			return fmt.Errorf("custom field key %q may only contain letters, digits and underscores", key)
+		}
+	}
+
+	return nil
+}
+
+// Fields returns the merge field values of the contact.
+func (c Contact) Fields() map[string]string {
+	fields := map[string]string{
+		"email":     c.Email,
+		"firstName": c.FirstName,
+		"lastName":  c.LastName,
+		"company":   c.Company,
+	}
+
+	for key, value := range c.CustomFields {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			fields["custom."+key] = v
+		case float64, bool, json.Number:
+			fields["custom."+key] = fmt.Sprint(v)
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+
+			fields["custom."+key] = string(data)
+		}
+	}
+
+	return fields
+}
+
+// NormalizeEmail validates a bare email address and returns it in lower case.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+
+	if len(email) > maxEmailLength {
+		return "", fmt.Errorf("email cannot be longer than %d characters", maxEmailLength)
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != email {
+		return "", fmt.Errorf("email %q is invalid", email)
+	}
+
+	return strings.ToLower(addr.Address), nil
+}
+
+// validateTimeZone checks that the name is a known IANA time zone.
+func validateTimeZone(name string) error {
+	// LoadLocation also accepts file paths and the zone of the server, which are not time zones.
+	if name == "Local" || strings.HasPrefix(name, "/") {
+		return fmt.Errorf("time zone %q is invalid", name)
+	}
+
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("time zone %q is invalid", name)
+	}
+
+	return nil
+}
+
+// Repository represents the data layer for contacts.
+type Repository interface {
+	CreateContact(ctx context.Context, c Contact) (Contact, error)
+	GetContact(ctx context.Context, id int) (Contact, bool, error)
+	UpdateContact(ctx context.Context, c Contact) (Contact, bool, error)
+	DeleteContact(ctx context.Context, id int) (bool, error)
+	ListContacts(ctx context.Context, filter ListFilter) ([]Contact, error)
+	UpsertContacts(ctx context.Context, contacts []Contact) ([]bool, error)
+}
+
+// Service represents the service layer for contacts.
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new contact service.
+func NewService(repo Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// CreateContact creates a new contact and returns it with its assigned ID.
+func (s Service) CreateContact(ctx context.Context, c Contact) (Contact, error) {
+	c = c.Normalize()
+	if err := c.Validate(); err != nil {
+		return Contact{}, fmt.Errorf("%w: %s", ErrContactValidation, err)
+	}
+
+	created, err := s.repo.CreateContact(ctx, c)
+	if err != nil {
+		if errors.Is(err, ErrContactExists) {
+			return Contact{}, err
+		}
+
+		return Contact{}, fmt.Errorf("failed to create contact: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetContact gets a contact by ID.
+func (s Service) GetContact(ctx context.Context, id int) (Contact, error) {
+	c, exists, err := s.repo.GetContact(ctx, id)
+	if err != nil {
+		return Contact{}, fmt.Errorf("failed to get contact: %w", err)
+	}
+
+	if !exists {
+		return Contact{}, ErrContactNotFound
+	}
+
+	return c, nil
+}
+
+// UpdateContact replaces the fields of a contact and returns the updated contact.
+func (s Service) UpdateContact(ctx context.Context, c Contact) (Contact, error) {
+	c = c.Normalize()
+	if err := c.Validate(); err != nil {
+		return Contact{}, fmt.Errorf("%w: %s", ErrContactValidation, err)
+	}
+
+	updated, exists, err := s.repo.UpdateContact(ctx, c)
+	if err != nil {
+		if errors.Is(err, ErrContactExists) {
+			return Contact{}, err
+		}
+
+		return Contact{}, fmt.Errorf("failed to update contact: %w", err)
+	}
+
+	if !exists {
+		return Contact{}, ErrContactNotFound
+	}
+
+	return updated, nil
+}
+
+// DeleteContact deletes a contact.
+func (s Service) DeleteContact(ctx context.Context, id int) error {
+	exists, err := s.repo.DeleteContact(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+
+	if !exists {
+		return ErrContactNotFound
+	}
+
+	return nil
+}
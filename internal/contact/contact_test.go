@@ -0,0 +1,409 @@
+package contact_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	"github.com/cybre/salesforge-assignment/internal/contact/testdata"
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	testCases := []struct {
+		name     string
+		email    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "Valid email",
+			email:    "jane@example.com",
+			expected: "jane@example.com",
+		},
+		{
+			name:     "Mixed case and whitespace",
+			email:    "  Jane.Doe@Example.COM ",
+			expected: "jane.doe@example.com",
+		},
+		{
+			name:  "Missing domain",
+			email: "jane@",
+			err:   errors.New(`email "jane@" is invalid`),
+		},
+		{
+			name:  "Display name",
+			email: "Jane <jane@example.com>",
+			err:   errors.New(`email "Jane <jane@example.com>" is invalid`),
+		},
+		{
+			name:  "Not an email",
+			email: "jane",
+			err:   errors.New(`email "jane" is invalid`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			email, err := contact.NormalizeEmail(tc.email)
+			if err == nil && tc.err != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.err)
+			} else if err != nil && tc.err == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.err != nil && err.Error() != tc.err.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.err, err)
+			}
+
+			if email != tc.expected {
+				t.Errorf("Expected email: %q, got: %q", tc.expected, email)
+			}
+		})
+	}
+}
+
+func TestContact_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contact  contact.Contact
+		expected error
+	}{
+		{
+			name:     "Valid contact",
+			contact:  contact.Contact{Email: "jane@example.com", FirstName: "Jane", CustomFields: map[string]any{"plan_2": "Pro"}},
+			expected: nil,
+		},
+		{
+			name:     "Missing email",
+			contact:  contact.Contact{FirstName: "Jane"},
+			expected: errors.New("email is required"),
+		},
+		{
+			name:     "Invalid email",
+			contact:  contact.Contact{Email: "jane.example.com"},
+			expected: errors.New(`email "jane.example.com" is invalid`),
+		},
+		{
+			name:     "Invalid custom field key",
+			contact:  contact.Contact{Email: "jane@example.com", CustomFields: map[string]any{"job title": "CEO"}},
+			expected: errors.New(`custom field key "job title" may only contain letters, digits and underscores`),
+		},
+		{
+			name:     "Valid time zone",
+			contact:  contact.Contact{Email: "jane@example.com", TimeZone: "America/New_York"},
+			expected: nil,
+		},
+		{
+			name:     "Invalid time zone",
+			contact:  contact.Contact{Email: "jane@example.com", TimeZone: "Mars/Olympus_Mons"},
+			expected: errors.New(`time zone "Mars/Olympus_Mons" is invalid`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.contact.Validate()
+			if err == nil && tc.expected != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.expected)
+			} else if err != nil && tc.expected == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.expected != nil && err.Error() != tc.expected.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestContact_Fields(t *testing.T) {
+	c := contact.Contact{
+		Email:     "jane@example.com",
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Company:   "Acme",
+		CustomFields: map[string]any{
+			"plan":    "Pro",
+			"seats":   float64(25),
+			"trial":   true,
+			"tags":    []any{"a", "b"},
+			"manager": nil,
+		},
+	}
+
+	expected := map[string]string{
+		"email":        "jane@example.com",
+		"firstName":    "Jane",
+		"lastName":     "Doe",
+		"company":      "Acme",
+		"custom.plan":  "Pro",
+		"custom.seats": "25",
+		"custom.trial": "true",
+		"custom.tags":  `["a","b"]`,
+	}
+
+	if fields := c.Fields(); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected fields: %v, got: %v", expected, fields)
+	}
+}
+
+func TestService_CreateContact(t *testing.T) {
+	ctx := context.Background()
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name        string
+		contact     contact.Contact
+		expected    contact.Contact
+		expectedErr error
+		repoErr     error
+	}{
+		{
+			name:     "Valid contact",
+			contact:  contact.Contact{Email: " Jane@Example.com", FirstName: " Jane "},
+			expected: contact.Contact{ID: 1, Email: "jane@example.com", FirstName: "Jane", CustomFields: map[string]any{}},
+		},
+		{
+			name:        "Invalid contact",
+			contact:     contact.Contact{Email: "jane"},
+			expectedErr: contact.ErrContactValidation,
+		},
+		{
+			name:        "Duplicate email",
+			contact:     contact.Contact{Email: "jane@example.com"},
+			expectedErr: contact.ErrContactExists,
+			repoErr:     contact.ErrContactExists,
+		},
+		{
+			name:        "Failed to create contact",
+			contact:     contact.Contact{Email: "jane@example.com"},
+			expectedErr: repoErr,
+			repoErr:     repoErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testdata.MockRepo{
+				CreateContactFn: func(ctx context.Context, c contact.Contact) (contact.Contact, error) {
+					if tc.repoErr != nil {
+						return contact.Contact{}, tc.repoErr
+					}
+
+					c.ID = 1
+					return c, nil
+				},
+			}
+
+			svc := contact.NewService(repo)
+			created, err := svc.CreateContact(ctx, tc.contact)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if err == nil && !reflect.DeepEqual(created, tc.expected) {
+				t.Errorf("Expected contact: %+v, got: %+v", tc.expected, created)
+			}
+		})
+	}
+}
+
+func TestService_GetContact(t *testing.T) {
+	ctx := context.Background()
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name        string
+		id          int
+		expectedErr error
+		repository  contact.Repository
+	}{
+		{
+			name: "Existing contact",
+			id:   1,
+			repository: testdata.MockRepo{
+				GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+					return contact.Contact{ID: id, Email: "jane@example.com"}, true, nil
+				},
+			},
+		},
+		{
+			name:        "Contact does not exist",
+			id:          2,
+			expectedErr: contact.ErrContactNotFound,
+			repository: testdata.MockRepo{
+				GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+					return contact.Contact{}, false, nil
+				},
+			},
+		},
+		{
+			name:        "Failed to get contact",
+			id:          3,
+			expectedErr: repoErr,
+			repository: testdata.MockRepo{
+				GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+					return contact.Contact{}, false, repoErr
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := contact.NewService(tc.repository)
+			c, err := svc.GetContact(ctx, tc.id)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if err == nil && c.ID != tc.id {
+				t.Errorf("Expected contact ID: %d, got: %d", tc.id, c.ID)
+			}
+		})
+	}
+}
+
+func TestService_UpdateContact(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name        string
+		contact     contact.Contact
+		expectedErr error
+		repository  contact.Repository
+	}{
+		{
+			name:    "Valid update",
+			contact: contact.Contact{ID: 1, Email: "jane@example.com"},
+			repository: testdata.MockRepo{
+				UpdateContactFn: func(ctx context.Context, c contact.Contact) (contact.Contact, bool, error) {
+					return c, true, nil
+				},
+			},
+		},
+		{
+			name:        "Invalid contact",
+			contact:     contact.Contact{ID: 1},
+			expectedErr: contact.ErrContactValidation,
+		},
+		{
+			name:        "Contact does not exist",
+			contact:     contact.Contact{ID: 2, Email: "jane@example.com"},
+			expectedErr: contact.ErrContactNotFound,
+			repository: testdata.MockRepo{
+				UpdateContactFn: func(ctx context.Context, c contact.Contact) (contact.Contact, bool, error) {
+					return contact.Contact{}, false, nil
+				},
+			},
+		},
+		{
+			name:        "Duplicate email",
+			contact:     contact.Contact{ID: 3, Email: "john@example.com"},
+			expectedErr: contact.ErrContactExists,
+			repository: testdata.MockRepo{
+				UpdateContactFn: func(ctx context.Context, c contact.Contact) (contact.Contact, bool, error) {
+					return contact.Contact{}, false, contact.ErrContactExists
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := contact.NewService(tc.repository)
+			_, err := svc.UpdateContact(ctx, tc.contact)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestService_DeleteContact(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name        string
+		exists      bool
+		expectedErr error
+	}{
+		{
+			name:   "Existing contact",
+			exists: true,
+		},
+		{
+			name:        "Contact does not exist",
+			exists:      false,
+			expectedErr: contact.ErrContactNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := contact.NewService(testdata.MockRepo{
+				DeleteContactFn: func(ctx context.Context, id int) (bool, error) {
+					return tc.exists, nil
+				},
+			})
+
+			if err := svc.DeleteContact(ctx, 1); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestService_ListContacts(t *testing.T) {
+	ctx := context.Background()
+
+	contacts := []contact.Contact{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	var received contact.ListFilter
+	svc := contact.NewService(testdata.MockRepo{
+		ListContactsFn: func(ctx context.Context, filter contact.ListFilter) ([]contact.Contact, error) {
+			received = filter
+
+			var page []contact.Contact
+			for _, c := range contacts {
+				if c.ID > filter.AfterID && len(page) < filter.Limit {
+					page = append(page, c)
+				}
+			}
+
+			return page, nil
+		},
+	})
+
+	first, err := svc.ListContacts(ctx, contact.ListQuery{Search: "acme", Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if received.Search != "acme" || received.Limit != 3 {
+		t.Errorf("Expected filter with search %q and limit 3, got: %+v", "acme", received)
+	}
+
+	if len(first.Contacts) != 2 || first.NextCursor == "" {
+		t.Fatalf("Expected 2 contacts and a next cursor, got: %+v", first)
+	}
+
+	second, err := svc.ListContacts(ctx, contact.ListQuery{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(second.Contacts) != 1 || second.Contacts[0].ID != 3 || second.NextCursor != "" {
+		t.Errorf("Expected only the last contact without a next cursor, got: %+v", second)
+	}
+
+	if _, err := svc.ListContacts(ctx, contact.ListQuery{Cursor: "not a cursor"}); !errors.Is(err, contact.ErrInvalidListQuery) {
+		t.Errorf("Expected error: %v, got: %v", contact.ErrInvalidListQuery, err)
+	}
+
+	if _, err := svc.ListContacts(ctx, contact.ListQuery{Limit: listing.MaxLimit + 1}); !errors.Is(err, contact.ErrInvalidListQuery) {
+		t.Errorf("Expected error: %v, got: %v", contact.ErrInvalidListQuery, err)
+	}
+}
@@ -0,0 +1,256 @@
+package contact
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidImport is returned when an import cannot be started, e.g. because of an invalid mapping.
+var ErrInvalidImport = errors.New("import is invalid")
+
+// ImportBatchSize is the number of contacts upserted per transaction during an import.
+const ImportBatchSize = 500
+
+// Contact fields a CSV column can be mapped to. Custom fields are mapped to custom.<key>.
+const (
+	FieldEmail     = "email"
+	FieldFirstName = "firstName"
+	FieldLastName  = "lastName"
+	FieldCompany   = "company"
+	FieldTimeZone  = "timeZone"
+
+	customFieldPrefix = "custom."
+)
+
+// Mapping maps CSV column headers to contact fields. Columns that are not mapped are ignored.
+type Mapping map[string]string
+
+// DefaultMapping maps the columns whose headers are contact field names to those fields.
+func DefaultMapping(header []string) Mapping {
+	mapping := Mapping{}
+	for _, column := range header {
+		column = strings.TrimSpace(column)
+		for _, field := range []string{FieldEmail, FieldFirstName, FieldLastName, FieldCompany, FieldTimeZone} {
+			if strings.EqualFold(column, field) {
+				mapping[column] = field
+			}
+		}
+	}
+
+	return mapping
+}
+
+// Validate validates the mapping.
+func (m Mapping) Validate() error {
+	mapped := make(map[string]string, len(m))
+	for column, field := range m {
+		switch {
+		case field == FieldEmail, field == FieldFirstName, field == FieldLastName, field == FieldCompany, field == FieldTimeZone:
+		case strings.HasPrefix(field, customFieldPrefix):
+			if key := strings.TrimPrefix(field, customFieldPrefix); !customFieldKeyPattern.MatchString(key) {
+				return fmt.Errorf("custom field key %q may only contain letters, digits and underscores", key)
+			}
+		default:
+			return fmt.Errorf("column %q is mapped to unknown field %q", column, field)
+		}
+
+		if other, ok := mapped[field]; ok {
+			return fmt.Errorf("columns %q and %q are both mapped to %q", other, column, field)
+		}
+		mapped[field] = column
+	}
+
+	if _, ok := mapped[FieldEmail]; !ok {
+		return errors.New("a column must be mapped to email")
+	}
+
+	return nil
+}
+
+// ImportReport summarises the outcome of an import.
+type ImportReport struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	// Skipped is the number of rows that were not imported, each with an entry in Errors.
+	Skipped int        `json:"skipped"`
+	Errors  []RowError `json:"errors"`
+}
+
+// RowError describes why a row was not imported.
+type RowError struct {
+	// Row is the line number of the row in the CSV, counting the header as line 1.
+	Row   int    `json:"row"`
+	Email string `json:"email,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportContacts reads contacts from a CSV with a header row and upserts them by email.
+// Rows are read one at a time and written in batches of ImportBatchSize, each in its own
+// transaction. Blank cells do not overwrite the existing values of updated contacts.
+// A nil mapping maps columns by their header, see DefaultMapping.
+func (s Service) ImportContacts(ctx context.Context, r io.Reader, mapping Mapping) (ImportReport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return ImportReport{}, fmt.Errorf("%w: file is empty", ErrInvalidImport)
+		}
+
+		return ImportReport{}, fmt.Errorf("%w: failed to read header: %s", ErrInvalidImport, err)
+	}
+
+	// Spreadsheet applications commonly prefix the file with a byte order mark.
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	if mapping == nil {
+		mapping = DefaultMapping(header)
+	}
+
+	if err := mapping.Validate(); err != nil {
+		return ImportReport{}, fmt.Errorf("%w: %s", ErrInvalidImport, err)
+	}
+
+	columns := make([]string, len(header))
+	matched := make(map[string]bool, len(mapping))
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+		field, ok := mapping[column]
+		if !ok {
+			continue
+		}
+
+		// A repeated column would silently overwrite the values of the first one.
+		if matched[column] {
+			return ImportReport{}, fmt.Errorf("%w: mapped column %q appears more than once in the header", ErrInvalidImport, column)
+		}
+		matched[column] = true
+		columns[i] = field
+	}
+
+	missing := []string{}
+	for column := range mapping {
+		if !matched[column] {
+			missing = append(missing, strconv.Quote(column))
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return ImportReport{}, fmt.Errorf("%w: mapped columns %s are missing from the header", ErrInvalidImport, strings.Join(missing, ", "))
+	}
+
+	report := ImportReport{Errors: []RowError{}}
+	seen := map[string]int{}
+	batch := make([]Contact, 0, ImportBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		created, err := s.repo.UpsertContacts(ctx, batch)
+		if err != nil {
+			return fmt.Errorf("failed to import contacts: %w", err)
+		}
+
+		for _, isNew := range created {
+			if isNew {
+				report.Created++
+			} else {
+				report.Updated++
+			}
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	skip := func(row int, email string, err string) {
+		report.Skipped++
+		report.Errors = append(report.Errors, RowError{Row: row, Email: email, Error: err})
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return report, fmt.Errorf("failed to read csv: %w", err)
+			}
+
+			skip(parseErr.StartLine, "", parseErr.Err.Error())
+			continue
+		}
+
+		row, _ := reader.FieldPos(0)
+
+		c := contactFromRecord(record, columns).Normalize()
+		if err := c.Validate(); err != nil {
+			skip(row, c.Email, err.Error())
+			continue
+		}
+
+		if first, ok := seen[c.Email]; ok {
+			skip(row, c.Email, fmt.Sprintf("email already imported from row %d", first))
+			continue
+		}
+		seen[c.Email] = row
+
+		batch = append(batch, c)
+		if len(batch) == ImportBatchSize {
+			if err := flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// contactFromRecord builds a contact from the cells of a CSV record. Blank custom field
+// cells are left out so they do not overwrite existing values.
+func contactFromRecord(record []string, columns []string) Contact {
+	c := Contact{CustomFields: map[string]any{}}
+	for i, field := range columns {
+		if field == "" || i >= len(record) {
+			continue
+		}
+
+		value := strings.TrimSpace(record[i])
+		switch field {
+		case FieldEmail:
+			c.Email = value
+		case FieldFirstName:
+			c.FirstName = value
+		case FieldLastName:
+			c.LastName = value
+		case FieldCompany:
+			c.Company = value
+		case FieldTimeZone:
+			c.TimeZone = value
+		default:
+			if value != "" {
+				c.CustomFields[strings.TrimPrefix(field, customFieldPrefix)] = value
+			}
+		}
+	}
+
+	return c
+}
@@ -0,0 +1,210 @@
+package contact_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	"github.com/cybre/salesforge-assignment/internal/contact/testdata"
+)
+
+func TestMapping_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mapping  contact.Mapping
+		expected error
+	}{
+		{
+			name:     "Valid mapping",
+			mapping:  contact.Mapping{"E-mail": "email", "Name": "firstName", "Plan": "custom.plan"},
+			expected: nil,
+		},
+		{
+			name:     "Missing email",
+			mapping:  contact.Mapping{"Name": "firstName"},
+			expected: errors.New("a column must be mapped to email"),
+		},
+		{
+			name:     "Unknown field",
+			mapping:  contact.Mapping{"E-mail": "email", "Phone": "phone"},
+			expected: errors.New(`column "Phone" is mapped to unknown field "phone"`),
+		},
+		{
+			name:     "Invalid custom field key",
+			mapping:  contact.Mapping{"E-mail": "email", "Job title": "custom.job title"},
+			expected: errors.New(`custom field key "job title" may only contain letters, digits and underscores`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.mapping.Validate()
+			if err == nil && tc.expected != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.expected)
+			} else if err != nil && tc.expected == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.expected != nil && err.Error() != tc.expected.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestService_ImportContacts(t *testing.T) {
+	ctx := context.Background()
+
+	existing := map[string]bool{"john@example.com": true}
+
+	var upserted [][]contact.Contact
+	repo := testdata.MockRepo{
+		UpsertContactsFn: func(ctx context.Context, contacts []contact.Contact) ([]bool, error) {
+			upserted = append(upserted, append([]contact.Contact(nil), contacts...))
+
+			created := make([]bool, len(contacts))
+			for i, c := range contacts {
+				created[i] = !existing[c.Email]
+			}
+
+			return created, nil
+		},
+	}
+
+	csv := "\ufeffE-mail,First name,Plan,Notes\n" +
+		"Jane@Example.com,Jane,Pro,ignored\n" +
+		"john@example.com,John,,\n" +
+		"not-an-email,Nobody,Free,\n" +
+		"JANE@example.com,Janet,Free,\n" +
+		"\"broken,quote\n"
+
+	mapping := contact.Mapping{"E-mail": "email", "First name": "firstName", "Plan": "custom.plan"}
+
+	svc := contact.NewService(repo)
+	report, err := svc.ImportContacts(ctx, strings.NewReader(csv), mapping)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expectedReport := contact.ImportReport{
+		Created: 1,
+		Updated: 1,
+		Skipped: 3,
+		Errors: []contact.RowError{
+			{Row: 4, Email: "not-an-email", Error: `email "not-an-email" is invalid`},
+			{Row: 5, Email: "jane@example.com", Error: "email already imported from row 2"},
+			{Row: 6, Error: `extraneous or missing " in quoted-field`},
+		},
+	}
+	if !reflect.DeepEqual(report, expectedReport) {
+		t.Errorf("Expected report: %+v, got: %+v", expectedReport, report)
+	}
+
+	expectedUpserts := [][]contact.Contact{{
+		{Email: "jane@example.com", FirstName: "Jane", CustomFields: map[string]any{"plan": "Pro"}},
+		{Email: "john@example.com", FirstName: "John", CustomFields: map[string]any{}},
+	}}
+	if !reflect.DeepEqual(upserted, expectedUpserts) {
+		t.Errorf("Expected upserts: %+v, got: %+v", expectedUpserts, upserted)
+	}
+}
+
+func TestService_ImportContacts_Batches(t *testing.T) {
+	ctx := context.Background()
+
+	var sb strings.Builder
+	sb.WriteString("email\n")
+	for i := 0; i < contact.ImportBatchSize+1; i++ {
+		fmt.Fprintf(&sb, "user%d@example.com\n", i)
+	}
+
+	var batches []int
+	repo := testdata.MockRepo{
+		UpsertContactsFn: func(ctx context.Context, contacts []contact.Contact) ([]bool, error) {
+			batches = append(batches, len(contacts))
+
+			created := make([]bool, len(contacts))
+			for i := range created {
+				created[i] = true
+			}
+
+			return created, nil
+		},
+	}
+
+	svc := contact.NewService(repo)
+	report, err := svc.ImportContacts(ctx, strings.NewReader(sb.String()), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if report.Created != contact.ImportBatchSize+1 || report.Skipped != 0 {
+		t.Errorf("Expected %d created contacts, got: %+v", contact.ImportBatchSize+1, report)
+	}
+
+	if !reflect.DeepEqual(batches, []int{contact.ImportBatchSize, 1}) {
+		t.Errorf("Expected batches of %d and 1 contacts, got: %v", contact.ImportBatchSize, batches)
+	}
+}
+
+func TestService_ImportContacts_Invalid(t *testing.T) {
+	ctx := context.Background()
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name        string
+		csv         string
+		mapping     contact.Mapping
+		expectedErr error
+	}{
+		{
+			name:        "Empty file",
+			csv:         "",
+			expectedErr: contact.ErrInvalidImport,
+		},
+		{
+			name:        "No email column",
+			csv:         "name\nJane\n",
+			expectedErr: contact.ErrInvalidImport,
+		},
+		{
+			name:        "Mapped column missing from header",
+			csv:         "email\njane@example.com\n",
+			mapping:     contact.Mapping{"email": "email", "Company": "company"},
+			expectedErr: contact.ErrInvalidImport,
+		},
+		{
+			name:        "Mapped column repeated in header",
+			csv:         "email,name,email\njane@example.com,Jane,john@example.com\n",
+			mapping:     contact.Mapping{"email": "email", "Company": "company"},
+			expectedErr: contact.ErrInvalidImport,
+		},
+		{
+			name:        "Mapped column repeated in header with every column present",
+			csv:         "email,Company,email\njane@example.com,Acme,john@example.com\n",
+			mapping:     contact.Mapping{"email": "email", "Company": "company"},
+			expectedErr: contact.ErrInvalidImport,
+		},
+		{
+			name:        "Failed to upsert contacts",
+			csv:         "email\njane@example.com\n",
+			expectedErr: repoErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := contact.NewService(testdata.MockRepo{
+				UpsertContactsFn: func(ctx context.Context, contacts []contact.Contact) ([]bool, error) {
+					return nil, repoErr
+				},
+			})
+
+			if _, err := svc.ImportContacts(ctx, strings.NewReader(tc.csv), tc.mapping); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+)
+
+// ErrInvalidListQuery is returned when the options for listing contacts are invalid.
+var ErrInvalidListQuery = errors.New("list query is invalid")
+
+// ListQuery represents the options for listing contacts. Contacts are listed in order of ID.
+type ListQuery struct {
+	// Search filters contacts whose email, name or company contains the given text, ignoring case.
+	Search string
+	Limit  int
+	// Cursor is the opaque cursor returned with the previous page.
+	Cursor string
+}
+
+// ListFilter is the list query as understood by the repository.
+type ListFilter struct {
+	Search string
+	listing.Filter
+}
+
+// Page is a page of contacts.
+type Page struct {
+	Contacts []Contact `json:"contacts"`
+	// NextCursor is the cursor for the next page. It is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListContacts lists contacts matching the query, one page at a time.
+func (s Service) ListContacts(ctx context.Context, query ListQuery) (Page, error) {
+	window, err := listing.NewFilter(query.Limit, query.Cursor)
+	if err != nil {
+		return Page{}, fmt.Errorf("%w: %s", ErrInvalidListQuery, err)
+	}
+
+	filter := ListFilter{
+		Search: query.Search,
+		Filter: window,
+	}
+
+	contacts, err := s.repo.ListContacts(ctx, filter)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to list contacts: %w", err)
+	}
+
+	page := Page{}
+	page.Contacts, page.NextCursor = listing.Trim(contacts, filter.Filter, func(c Contact) int { return c.ID })
+
+	return page, nil
+}
@@ -0,0 +1,261 @@
+package contact
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres error code for unique constraint violations.
+const uniqueViolation = "23505"
+
+// PostgresRepository is a repository containing contacts using Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository creates a new Postgres repository.
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+const createContactQuery = `
+INSERT INTO contact (email, first_name, last_name, company, time_zone, custom_fields) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, email, first_name, last_name, company, time_zone, custom_fields, created_at, updated_at;
+`
+
+// CreateContact creates a new contact and returns it as stored.
+func (r PostgresRepository) CreateContact(ctx context.Context, c Contact) (Contact, error) {
+	customFields, err := json.Marshal(c.CustomFields)
+	if err != nil {
+		return Contact{}, err
+	}
+
+	row := ContactRow{}
+	if err := r.db.QueryRowxContext(ctx, createContactQuery, c.Email, c.FirstName, c.LastName, c.Company, c.TimeZone, customFields).StructScan(&row); err != nil {
+		return Contact{}, translateError(err)
+	}
+
+	return row.ToContact()
+}
+
+const getContactQuery = `
+SELECT id, email, first_name, last_name, company, time_zone, custom_fields, created_at, updated_at FROM contact WHERE id = $1;
+`
+
+// GetContact gets a contact by ID.
+func (r PostgresRepository) GetContact(ctx context.Context, id int) (Contact, bool, error) {
+	row := ContactRow{}
+	if err := r.db.GetContext(ctx, &row, getContactQuery, id); err != nil {
+		if err == sql.ErrNoRows {
+			return Contact{}, false, nil
+		}
+
+		return Contact{}, false, err
+	}
+
+	c, err := row.ToContact()
+	if err != nil {
+		return Contact{}, false, err
+	}
+
+	return c, true, nil
+}
+
+const updateContactQuery = `
+UPDATE contact SET email = $1, first_name = $2, last_name = $3, company = $4, time_zone = $5, custom_fields = $6, updated_at = NOW() WHERE id = $7
+RETURNING id, email, first_name, last_name, company, time_zone, custom_fields, created_at, updated_at;
+`
+
+// UpdateContact replaces the fields of a contact and returns it as stored.
+func (r PostgresRepository) UpdateContact(ctx context.Context, c Contact) (Contact, bool, error) {
+	customFields, err := json.Marshal(c.CustomFields)
+	if err != nil {
+		return Contact{}, false, err
+	}
+
+	row := ContactRow{}
+	if err := r.db.QueryRowxContext(ctx, updateContactQuery, c.Email, c.FirstName, c.LastName, c.Company, c.TimeZone, customFields, c.ID).StructScan(&row); err != nil {
+		if err == sql.ErrNoRows {
+			return Contact{}, false, nil
+		}
+
+		return Contact{}, false, translateError(err)
+	}
+
+	updated, err := row.ToContact()
+	if err != nil {
+		return Contact{}, false, err
+	}
+
+	return updated, true, nil
+}
+
+const deleteContactQuery = `
+DELETE FROM contact WHERE id = $1;
+`
+
+// DeleteContact deletes a contact.
+func (r PostgresRepository) DeleteContact(ctx context.Context, id int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, deleteContactQuery, id)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+const listContactsQuery = `
+SELECT id, email, first_name, last_name, company, time_zone, custom_fields, created_at, updated_at FROM contact %s ORDER BY id LIMIT %d;
+`
+
+// ListContacts lists the contacts matching the filter in order of ID.
+func (r PostgresRepository) ListContacts(ctx context.Context, filter ListFilter) ([]Contact, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Search != "" {
+		pattern := arg("%" + listing.EscapeLike(filter.Search) + "%")
+		conditions = append(conditions, fmt.Sprintf("(email ILIKE %[1]s OR first_name ILIKE %[1]s OR last_name ILIKE %[1]s OR company ILIKE %[1]s)", pattern))
+	}
+
+	if filter.AfterID > 0 {
+		conditions = append(conditions, "id > "+arg(filter.AfterID))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows := []ContactRow{}
+	if err := r.db.SelectContext(ctx, &rows, fmt.Sprintf(listContactsQuery, where, filter.Limit), args...); err != nil {
+		return nil, err
+	}
+
+	contacts := make([]Contact, len(rows))
+	for i, row := range rows {
+		c, err := row.ToContact()
+		if err != nil {
+			return nil, err
+		}
+
+		contacts[i] = c
+	}
+
+	return contacts, nil
+}
+
+const upsertContactQuery = `
+INSERT INTO contact (email, first_name, last_name, company, time_zone, custom_fields) VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (email) DO UPDATE SET
+    first_name = COALESCE(NULLIF(EXCLUDED.first_name, ''), contact.first_name),
+    last_name = COALESCE(NULLIF(EXCLUDED.last_name, ''), contact.last_name),
+    company = COALESCE(NULLIF(EXCLUDED.company, ''), contact.company),
+    time_zone = COALESCE(NULLIF(EXCLUDED.time_zone, ''), contact.time_zone),
+    custom_fields = contact.custom_fields || EXCLUDED.custom_fields,
+    updated_at = NOW()
+RETURNING xmax = 0;
+`
+
+// UpsertContacts creates contacts or updates the contacts with the same email in a single
+// transaction. Empty names, companies and time zones keep their existing values and custom fields are
+// merged into the existing ones. It reports for every contact whether it was created.
+func (r PostgresRepository) UpsertContacts(ctx context.Context, contacts []Contact) ([]bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]bool, len(contacts))
+	if err := func() error {
+		for i, c := range contacts {
+			customFields, err := json.Marshal(c.CustomFields)
+			if err != nil {
+				return err
+			}
+
+			// xmax is zero for rows inserted rather than updated by the statement.
+			if err := tx.QueryRowxContext(ctx, upsertContactQuery, c.Email, c.FirstName, c.LastName, c.Company, c.TimeZone, customFields).Scan(&created[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// translateError converts constraint violations into domain errors.
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "contact_email_key" {
+		return ErrContactExists
+	}
+
+	return err
+}
+
+// ContactRow represents a row of the contact table.
+type ContactRow struct {
+	ID           int       `db:"id"`
+	Email        string    `db:"email"`
+	FirstName    string    `db:"first_name"`
+	LastName     string    `db:"last_name"`
+	Company      string    `db:"company"`
+	TimeZone     string    `db:"time_zone"`
+	CustomFields []byte    `db:"custom_fields"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// ToContact converts the row to a contact domain model.
+func (r ContactRow) ToContact() (Contact, error) {
+	customFields := map[string]any{}
+	if len(r.CustomFields) > 0 {
+		if err := json.Unmarshal(r.CustomFields, &customFields); err != nil {
+			return Contact{}, fmt.Errorf("failed to decode custom fields of contact %d: %w", r.ID, err)
+		}
+	}
+
+	return Contact{
+		ID:           r.ID,
+		Email:        r.Email,
+		FirstName:    r.FirstName,
+		LastName:     r.LastName,
+		Company:      r.Company,
+		TimeZone:     r.TimeZone,
+		CustomFields: customFields,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package contact_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+)
+
+func TestContactRow_ToContact(t *testing.T) {
+	now := time.Now()
+
+	row := contact.ContactRow{
+		ID:           1,
+		Email:        "jane@example.com",
+		FirstName:    "Jane",
+		LastName:     "Doe",
+		Company:      "Acme",
+		CustomFields: []byte(`{"plan": "Pro", "seats": 25}`),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	expected := contact.Contact{
+		ID:           1,
+		Email:        "jane@example.com",
+		FirstName:    "Jane",
+		LastName:     "Doe",
+		Company:      "Acme",
+		CustomFields: map[string]any{"plan": "Pro", "seats": float64(25)},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	c, err := row.ToContact()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("Expected contact: %+v, got: %+v", expected, c)
+	}
+
+	row.CustomFields = []byte(`not json`)
+	if _, err := row.ToContact(); err == nil {
+		t.Error("Expected error for malformed custom fields, got: nil")
+	}
+}
@@ -0,0 +1,40 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+)
+
+type MockRepo struct {
+	CreateContactFn  func(ctx context.Context, c contact.Contact) (contact.Contact, error)
+	GetContactFn     func(ctx context.Context, id int) (contact.Contact, bool, error)
+	UpdateContactFn  func(ctx context.Context, c contact.Contact) (contact.Contact, bool, error)
+	DeleteContactFn  func(ctx context.Context, id int) (bool, error)
+	ListContactsFn   func(ctx context.Context, filter contact.ListFilter) ([]contact.Contact, error)
+	UpsertContactsFn func(ctx context.Context, contacts []contact.Contact) ([]bool, error)
+}
+
+func (m MockRepo) CreateContact(ctx context.Context, c contact.Contact) (contact.Contact, error) {
+	return m.CreateContactFn(ctx, c)
+}
+
+func (m MockRepo) GetContact(ctx context.Context, id int) (contact.Contact, bool, error) {
+	return m.GetContactFn(ctx, id)
+}
+
+func (m MockRepo) UpdateContact(ctx context.Context, c contact.Contact) (contact.Contact, bool, error) {
+	return m.UpdateContactFn(ctx, c)
+}
+
+func (m MockRepo) DeleteContact(ctx context.Context, id int) (bool, error) {
+	return m.DeleteContactFn(ctx, id)
+}
+
+func (m MockRepo) ListContacts(ctx context.Context, filter contact.ListFilter) ([]contact.Contact, error) {
+	return m.ListContactsFn(ctx, filter)
+}
+
+func (m MockRepo) UpsertContacts(ctx context.Context, contacts []contact.Contact) ([]bool, error) {
+	return m.UpsertContactsFn(ctx, contacts)
+}
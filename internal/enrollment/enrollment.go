@@ -0,0 +1,209 @@
+package enrollment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+// ErrEnrollmentValidation is returned when a request to enroll contacts is invalid.
+var ErrEnrollmentValidation = errors.New("enrollment request is invalid")
+
+// MaxEnrollContacts is the largest number of contacts that can be enrolled at once.
+const MaxEnrollContacts = 1000
+
+// State is the progress of a contact through a sequence.
+type State string
+
+const (
+	// StateActive enrollments are sent their next step when it is due.
+	StateActive State = "active"
+	// StatePaused enrollments are not sent anything until they are resumed.
+	StatePaused State = "paused"
+	// StateFinished enrollments have been sent every step.
+	StateFinished State = "finished"
+	// StateReplied enrollments were stopped because the contact replied.
+	StateReplied State = "replied"
+	// StateBounced enrollments were stopped because an email to the contact bounced.
+	StateBounced State = "bounced"
+	// StateUnsubscribed enrollments were stopped because the contact unsubscribed.
+	StateUnsubscribed State = "unsubscribed"
+)
+
+// Valid reports whether the state is one of the known states.
+func (s State) Valid() bool {
+	switch s {
+	case StateActive, StatePaused, StateFinished, StateReplied, StateBounced, StateUnsubscribed:
+		return true
+	}
+
+	return false
+}
+
+// Enrollment represents a contact enrolled into a sequence.
+type Enrollment struct {
+	ID         int   `json:"id"`
+	SequenceID int   `json:"sequenceId"`
+	ContactID  int   `json:"contactId"`
+	State      State `json:"state"`
+	// Version is the published version of the sequence the contact is sent, which is kept when
+	// the sequence is published again. It is nil for contacts enrolled before sequences had
+	// versions, who are sent the current steps.
+	Version *int `json:"version"`
+	// CurrentStep is the position of the next step to send.
+	CurrentStep int `json:"currentStep"`
+	// NextSendAt is when the next step is due. It is nil when nothing more will be sent, or
+	// while the enrollment waits for the task of a manual step.
+	NextSendAt *time.Time `json:"nextSendAt"`
+	// MailboxID is the mailbox the contact is emailed from once the first step was sent.
+	MailboxID *int      `json:"mailboxId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SkipReason explains why a contact was not enrolled.
+type SkipReason string
+
+const (
+	SkipContactNotFound SkipReason = "contactNotFound"
+	SkipAlreadyEnrolled SkipReason = "alreadyEnrolled"
+)
+
+// SkippedContact is a contact that was not enrolled.
+type SkippedContact struct {
+	ContactID int        `json:"contactId"`
+	Reason    SkipReason `json:"reason"`
+}
+
+// EnrollResult is the outcome of enrolling contacts into a sequence.
+type EnrollResult struct {
+	Enrolled []Enrollment     `json:"enrolled"`
+	Skipped  []SkippedContact `json:"skipped"`
+}
+
+// Repository represents the data layer for enrollments.
+type Repository interface {
+	CreateEnrollments(ctx context.Context, enrollments []Enrollment) ([]Enrollment, error)
+	// ContactTimeZones returns the time zones of the given contacts that exist by their IDs.
+	ContactTimeZones(ctx context.Context, contactIDs []int) (map[int]string, error)
+	ListEnrollments(ctx context.Context, filter ListFilter) ([]Enrollment, error)
+}
+
+// SequenceService gets the sequences contacts are enrolled into.
+type SequenceService interface {
+	GetEnrollableSequence(ctx context.Context, id int) (sequence.Sequence, error)
+}
+
+// Service represents the service layer for enrollments.
+type Service struct {
+	repo      Repository
+	sequences SequenceService
+}
+
+// NewService creates a new enrollment service.
+func NewService(repo Repository, sequences SequenceService) *Service {
+	return &Service{
+		repo:      repo,
+		sequences: sequences,
+	}
+}
+
+// Enroll enrolls contacts into the latest published version of a sequence. The first step is
+// scheduled after its delay, counted from now, within the sending window of the sequence in
+// the time zone of each contact. Contacts that do not exist or are already enrolled are
+// skipped.
+func (s Service) Enroll(ctx context.Context, sequenceID int, contactIDs []int) (EnrollResult, error) {
+	if err := validateContactIDs(contactIDs); err != nil {
+		return EnrollResult{}, fmt.Errorf("%w: %s", ErrEnrollmentValidation, err)
+	}
+
+	seq, err := s.sequences.GetEnrollableSequence(ctx, sequenceID)
+	if err != nil {
+		return EnrollResult{}, err
+	}
+
+	contactIDs = dedupe(contactIDs)
+	timeZones, err := s.repo.ContactTimeZones(ctx, contactIDs)
+	if err != nil {
+		return EnrollResult{}, fmt.Errorf("failed to look up contacts: %w", err)
+	}
+
+	result := EnrollResult{Enrolled: []Enrollment{}, Skipped: []SkippedContact{}}
+	now := time.Now()
+
+	enrollments := make([]Enrollment, 0, len(timeZones))
+	for _, id := range contactIDs {
+		timeZone, found := timeZones[id]
+		if !found {
+			result.Skipped = append(result.Skipped, SkippedContact{ContactID: id, Reason: SkipContactNotFound})
+			continue
+		}
+
+		nextSendAt := seq.DueAt(0, now, timeZone)
+		enrollments = append(enrollments, Enrollment{
+			SequenceID: sequenceID,
+			ContactID:  id,
+			State:      StateActive,
+			Version:    &seq.PublishedVersion,
+			NextSendAt: &nextSendAt,
+		})
+	}
+
+	if len(enrollments) == 0 {
+		return result, nil
+	}
+
+	created, err := s.repo.CreateEnrollments(ctx, enrollments)
+	if err != nil {
+		return EnrollResult{}, fmt.Errorf("failed to enroll contacts: %w", err)
+	}
+
+	enrolled := make(map[int]bool, len(created))
+	for _, e := range created {
+		enrolled[e.ContactID] = true
+	}
+
+	for _, e := range enrollments {
+		if !enrolled[e.ContactID] {
+			result.Skipped = append(result.Skipped, SkippedContact{ContactID: e.ContactID, Reason: SkipAlreadyEnrolled})
+		}
+	}
+
+	result.Enrolled = created
+	return result, nil
+}
+
+func validateContactIDs(contactIDs []int) error {
+	if len(contactIDs) == 0 {
+		return errors.New("contactIds are required")
+	}
+
+	if len(contactIDs) > MaxEnrollContacts {
+		return fmt.Errorf("at most %d contacts can be enrolled at once", MaxEnrollContacts)
+	}
+
+	for _, id := range contactIDs {
+		if id < 1 {
+			return fmt.Errorf("contact ID %d is invalid", id)
+		}
+	}
+
+	return nil
+}
+
+// dedupe removes repeated IDs, keeping the first occurrence of each.
+func dedupe(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,278 @@
+package enrollment_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/enrollment/testdata"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+)
+
+func TestService_Enroll(t *testing.T) {
+	ctx := context.Background()
+
+	sequences := testdata.MockSequenceService{
+		GetEnrollableSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, error) {
+			return sequence.Sequence{
+				ID:               id,
+				Name:             "Test Sequence",
+				PublishedVersion: 3,
+				Steps:            []sequence.Step{{ID: 1, Subject: "Subject", Content: "Content", Delay: sequence.Delay{Amount: 2, Unit: sequence.DelayUnitHours}}},
+			}, nil
+		},
+	}
+
+	var received []enrollment.Enrollment
+	repo := testdata.MockRepo{
+		ContactTimeZonesFn: func(ctx context.Context, contactIDs []int) (map[int]string, error) {
+			return map[int]string{1: "", 2: "", 3: ""}, nil
+		},
+		CreateEnrollmentsFn: func(ctx context.Context, enrollments []enrollment.Enrollment) ([]enrollment.Enrollment, error) {
+			received = enrollments
+
+			// Contact 2 is already enrolled.
+			var created []enrollment.Enrollment
+			for i, e := range enrollments {
+				if e.ContactID != 2 {
+					e.ID = i + 10
+					created = append(created, e)
+				}
+			}
+
+			return created, nil
+		},
+	}
+
+	before := time.Now()
+
+	svc := enrollment.NewService(repo, sequences)
+	result, err := svc.Enroll(ctx, 5, []int{1, 2, 4, 1, 3})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(received) != 3 {
+		t.Fatalf("Expected 3 enrollments to be created, got: %d", len(received))
+	}
+
+	for _, e := range received {
+		if e.SequenceID != 5 || e.State != enrollment.StateActive || e.CurrentStep != 0 {
+			t.Errorf("Unexpected enrollment: %+v", e)
+		}
+
+		if e.Version == nil || *e.Version != 3 {
+			t.Errorf("Expected enrollment to be pinned to version 3, got: %v", e.Version)
+		}
+
+		if e.NextSendAt == nil || e.NextSendAt.Before(before.Add(2*time.Hour)) || e.NextSendAt.After(time.Now().Add(2*time.Hour)) {
+			t.Errorf("Expected first step to be due in 2 hours, got: %v", e.NextSendAt)
+		}
+	}
+
+	enrolledIDs := []int{}
+	for _, e := range result.Enrolled {
+		enrolledIDs = append(enrolledIDs, e.ContactID)
+	}
+
+	if !reflect.DeepEqual(enrolledIDs, []int{1, 3}) {
+		t.Errorf("Expected contacts 1 and 3 to be enrolled, got: %v", enrolledIDs)
+	}
+
+	expectedSkipped := []enrollment.SkippedContact{
+		{ContactID: 4, Reason: enrollment.SkipContactNotFound},
+		{ContactID: 2, Reason: enrollment.SkipAlreadyEnrolled},
+	}
+	if !reflect.DeepEqual(result.Skipped, expectedSkipped) {
+		t.Errorf("Expected skipped contacts: %+v, got: %+v", expectedSkipped, result.Skipped)
+	}
+}
+
+func TestService_Enroll_ContactTimeZones(t *testing.T) {
+	ctx := context.Background()
+
+	// The window is one hour long every day, so the first step is due within it wherever the
+	// contact is.
+	schedule := sequence.Schedule{
+		Days:      []sequence.Weekday{sequence.Monday, sequence.Tuesday, sequence.Wednesday, sequence.Thursday, sequence.Friday, sequence.Saturday, sequence.Sunday},
+		StartHour: 9,
+		EndHour:   10,
+		TimeZone:  "UTC",
+	}
+
+	sequences := testdata.MockSequenceService{
+		GetEnrollableSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, error) {
+			return sequence.Sequence{ID: id, Schedule: &schedule, Steps: []sequence.Step{{ID: 1}}}, nil
+		},
+	}
+
+	timeZones := map[int]string{1: "", 2: "Asia/Tokyo"}
+	repo := testdata.MockRepo{
+		ContactTimeZonesFn: func(ctx context.Context, contactIDs []int) (map[int]string, error) {
+			return timeZones, nil
+		},
+		CreateEnrollmentsFn: func(ctx context.Context, enrollments []enrollment.Enrollment) ([]enrollment.Enrollment, error) {
+			return enrollments, nil
+		},
+	}
+
+	svc := enrollment.NewService(repo, sequences)
+	result, err := svc.Enroll(ctx, 5, []int{1, 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(result.Enrolled) != 2 {
+		t.Fatalf("Expected 2 enrollments, got: %d", len(result.Enrolled))
+	}
+
+	for _, e := range result.Enrolled {
+		loc := time.UTC
+		if timeZone := timeZones[e.ContactID]; timeZone != "" {
+			loc, _ = time.LoadLocation(timeZone)
+		}
+
+		if e.NextSendAt == nil || e.NextSendAt.In(loc).Hour() != 9 {
+			t.Errorf("Expected contact %d to be due within the window in %s, got: %v", e.ContactID, loc, e.NextSendAt)
+		}
+	}
+}
+
+func TestService_Enroll_Errors(t *testing.T) {
+	ctx := context.Background()
+	repoErr := errors.New("repository error")
+
+	sequenceErr := func(err error) testdata.MockSequenceService {
+		return testdata.MockSequenceService{
+			GetEnrollableSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, error) {
+				return sequence.Sequence{}, err
+			},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		contactIDs  []int
+		sequences   enrollment.SequenceService
+		expectedErr error
+	}{
+		{
+			name:        "No contacts",
+			contactIDs:  nil,
+			sequences:   sequenceErr(nil),
+			expectedErr: enrollment.ErrEnrollmentValidation,
+		},
+		{
+			name:        "Invalid contact ID",
+			contactIDs:  []int{1, 0},
+			sequences:   sequenceErr(nil),
+			expectedErr: enrollment.ErrEnrollmentValidation,
+		},
+		{
+			name:        "Too many contacts",
+			contactIDs:  make([]int, enrollment.MaxEnrollContacts+1),
+			sequences:   sequenceErr(nil),
+			expectedErr: enrollment.ErrEnrollmentValidation,
+		},
+		{
+			name:        "Sequence does not exist",
+			contactIDs:  []int{1},
+			sequences:   sequenceErr(sequence.ErrSequenceNotFound),
+			expectedErr: sequence.ErrSequenceNotFound,
+		},
+		{
+			name:        "Sequence is archived",
+			contactIDs:  []int{1},
+			sequences:   sequenceErr(sequence.ErrSequenceArchived),
+			expectedErr: sequence.ErrSequenceArchived,
+		},
+		{
+			name:        "Sequence has no steps",
+			contactIDs:  []int{1},
+			sequences:   sequenceErr(sequence.ErrSequenceValidation),
+			expectedErr: sequence.ErrSequenceValidation,
+		},
+		{
+			name:       "Failed to look up contacts",
+			contactIDs: []int{1},
+			sequences: testdata.MockSequenceService{
+				GetEnrollableSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, error) {
+					return sequence.Sequence{ID: id, Steps: []sequence.Step{{ID: 1}}}, nil
+				},
+			},
+			expectedErr: repoErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testdata.MockRepo{
+				ContactTimeZonesFn: func(ctx context.Context, contactIDs []int) (map[int]string, error) {
+					return nil, repoErr
+				},
+			}
+
+			svc := enrollment.NewService(repo, tc.sequences)
+			if _, err := svc.Enroll(ctx, 1, tc.contactIDs); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestService_ListEnrollments(t *testing.T) {
+	ctx := context.Background()
+
+	enrollments := []enrollment.Enrollment{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	var received enrollment.ListFilter
+	svc := enrollment.NewService(testdata.MockRepo{
+		ListEnrollmentsFn: func(ctx context.Context, filter enrollment.ListFilter) ([]enrollment.Enrollment, error) {
+			received = filter
+
+			var page []enrollment.Enrollment
+			for _, e := range enrollments {
+				if e.ID > filter.AfterID && len(page) < filter.Limit {
+					page = append(page, e)
+				}
+			}
+
+			return page, nil
+		},
+	}, testdata.MockSequenceService{})
+
+	first, err := svc.ListEnrollments(ctx, enrollment.ListQuery{SequenceID: 1, State: enrollment.StateActive, Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expectedFilter := enrollment.ListFilter{SequenceID: 1, State: enrollment.StateActive, Filter: listing.Filter{Limit: 3}}
+	if received != expectedFilter {
+		t.Errorf("Expected filter: %+v, got: %+v", expectedFilter, received)
+	}
+
+	if len(first.Enrollments) != 2 || first.NextCursor == "" {
+		t.Fatalf("Expected 2 enrollments and a next cursor, got: %+v", first)
+	}
+
+	second, err := svc.ListEnrollments(ctx, enrollment.ListQuery{SequenceID: 1, Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(second.Enrollments) != 1 || second.Enrollments[0].ID != 3 || second.NextCursor != "" {
+		t.Errorf("Expected only the last enrollment without a next cursor, got: %+v", second)
+	}
+
+	if _, err := svc.ListEnrollments(ctx, enrollment.ListQuery{SequenceID: 1, State: "sleeping"}); !errors.Is(err, enrollment.ErrInvalidListQuery) {
+		t.Errorf("Expected error: %v, got: %v", enrollment.ErrInvalidListQuery, err)
+	}
+
+	if _, err := svc.ListEnrollments(ctx, enrollment.ListQuery{SequenceID: 1, Cursor: "not a cursor"}); !errors.Is(err, enrollment.ErrInvalidListQuery) {
+		t.Errorf("Expected error: %v, got: %v", enrollment.ErrInvalidListQuery, err)
+	}
+}
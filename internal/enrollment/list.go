@@ -0,0 +1,74 @@
+package enrollment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+)
+
+// ErrInvalidListQuery is returned when the options for listing enrollments are invalid.
+var ErrInvalidListQuery = errors.New("list query is invalid")
+
+// ListQuery represents the options for listing the enrollments of a sequence. Enrollments
+// are listed in order of ID.
+type ListQuery struct {
+	SequenceID int
+	// State filters enrollments by state. All states are listed when it is empty.
+	State State
+	Limit int
+	// Cursor is the opaque cursor returned with the previous page.
+	Cursor string
+}
+
+// Validate validates the list query.
+func (q ListQuery) Validate() error {
+	if q.State != "" && !q.State.Valid() {
+		return fmt.Errorf("state %q is unknown", q.State)
+	}
+
+	return nil
+}
+
+// ListFilter is the list query as understood by the repository.
+type ListFilter struct {
+	SequenceID int
+	State      State
+	listing.Filter
+}
+
+// Page is a page of enrollments.
+type Page struct {
+	Enrollments []Enrollment `json:"enrollments"`
+	// NextCursor is the cursor for the next page. It is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListEnrollments lists the enrollments of a sequence matching the query, one page at a time.
+func (s Service) ListEnrollments(ctx context.Context, query ListQuery) (Page, error) {
+	if err := query.Validate(); err != nil {
+		return Page{}, fmt.Errorf("%w: %s", ErrInvalidListQuery, err)
+	}
+
+	window, err := listing.NewFilter(query.Limit, query.Cursor)
+	if err != nil {
+		return Page{}, fmt.Errorf("%w: %s", ErrInvalidListQuery, err)
+	}
+
+	filter := ListFilter{
+		SequenceID: query.SequenceID,
+		State:      query.State,
+		Filter:     window,
+	}
+
+	enrollments, err := s.repo.ListEnrollments(ctx, filter)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to list enrollments: %w", err)
+	}
+
+	page := Page{}
+	page.Enrollments, page.NextCursor = listing.Trim(enrollments, filter.Filter, func(e Enrollment) int { return e.ID })
+
+	return page, nil
+}
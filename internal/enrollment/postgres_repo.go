@@ -0,0 +1,172 @@
+package enrollment
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// PostgresRepository is a repository containing enrollments using Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository creates a new Postgres repository.
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+const createEnrollmentQuery = `
+INSERT INTO enrollment (sequence_id, contact_id, state, version, current_step, next_send_at) VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (sequence_id, contact_id) DO NOTHING
+RETURNING id, sequence_id, contact_id, state, version, current_step, next_send_at, mailbox_id, created_at, updated_at;
+`
+
+// CreateEnrollments creates enrollments in a single transaction and returns the created
+// enrollments. Enrollments of contacts already enrolled into the sequence are skipped.
+func (r PostgresRepository) CreateEnrollments(ctx context.Context, enrollments []Enrollment) ([]Enrollment, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]Enrollment, 0, len(enrollments))
+	if err := func() error {
+		for _, e := range enrollments {
+			row := EnrollmentRow{}
+			err := tx.QueryRowxContext(ctx, createEnrollmentQuery, e.SequenceID, e.ContactID, e.State, e.Version, e.CurrentStep, e.NextSendAt).StructScan(&row)
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			created = append(created, row.ToEnrollment())
+		}
+
+		return nil
+	}(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+const contactTimeZonesQuery = `
+SELECT id, time_zone FROM contact WHERE id = ANY($1);
+`
+
+// ContactTimeZones returns the time zones of the given contacts that exist by their IDs. The
+// time zone is empty for contacts without one.
+func (r PostgresRepository) ContactTimeZones(ctx context.Context, contactIDs []int) (map[int]string, error) {
+	rows := []ContactTimeZoneRow{}
+	if err := r.db.SelectContext(ctx, &rows, contactTimeZonesQuery, pq.Array(contactIDs)); err != nil {
+		return nil, err
+	}
+
+	timeZones := make(map[int]string, len(rows))
+	for _, row := range rows {
+		timeZones[row.ID] = row.TimeZone
+	}
+
+	return timeZones, nil
+}
+
+const listEnrollmentsQuery = `
+SELECT id, sequence_id, contact_id, state, version, current_step, next_send_at, mailbox_id, created_at, updated_at FROM enrollment %s ORDER BY id LIMIT %d;
+`
+
+// ListEnrollments lists the enrollments matching the filter in order of ID.
+func (r PostgresRepository) ListEnrollments(ctx context.Context, filter ListFilter) ([]Enrollment, error) {
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"sequence_id = " + arg(filter.SequenceID)}
+
+	if filter.State != "" {
+		conditions = append(conditions, "state = "+arg(filter.State))
+	}
+
+	if filter.AfterID > 0 {
+		conditions = append(conditions, "id > "+arg(filter.AfterID))
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	rows := []EnrollmentRow{}
+	if err := r.db.SelectContext(ctx, &rows, fmt.Sprintf(listEnrollmentsQuery, where, filter.Limit), args...); err != nil {
+		return nil, err
+	}
+
+	enrollments := make([]Enrollment, len(rows))
+	for i, row := range rows {
+		enrollments[i] = row.ToEnrollment()
+	}
+
+	return enrollments, nil
+}
+
+// EnrollmentRow represents a row of the enrollment table.
+type EnrollmentRow struct {
+	ID          int           `db:"id"`
+	SequenceID  int           `db:"sequence_id"`
+	ContactID   int           `db:"contact_id"`
+	State       string        `db:"state"`
+	Version     sql.NullInt64 `db:"version"`
+	CurrentStep int           `db:"current_step"`
+	NextSendAt  sql.NullTime  `db:"next_send_at"`
+	MailboxID   sql.NullInt64 `db:"mailbox_id"`
+	CreatedAt   time.Time     `db:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+}
+
+// ToEnrollment converts the row to an enrollment domain model.
+func (r EnrollmentRow) ToEnrollment() Enrollment {
+	e := Enrollment{
+		ID:          r.ID,
+		SequenceID:  r.SequenceID,
+		ContactID:   r.ContactID,
+		State:       State(r.State),
+		CurrentStep: r.CurrentStep,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+
+	if r.NextSendAt.Valid {
+		e.NextSendAt = &r.NextSendAt.Time
+	}
+
+	if r.Version.Valid {
+		version := int(r.Version.Int64)
+		e.Version = &version
+	}
+
+	if r.MailboxID.Valid {
+		mailboxID := int(r.MailboxID.Int64)
+		e.MailboxID = &mailboxID
+	}
+
+	return e
+}
+
+// ContactTimeZoneRow represents the time zone of a row of the contact table.
+type ContactTimeZoneRow struct {
+	ID       int    `db:"id"`
+	TimeZone string `db:"time_zone"`
+}
@@ -0,0 +1,59 @@
+package enrollment_test
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+)
+
+func TestEnrollmentRow_ToEnrollment(t *testing.T) {
+	now := time.Now()
+	mailboxID := 4
+	version := 2
+
+	testCases := []struct {
+		name     string
+		row      enrollment.EnrollmentRow
+		expected enrollment.Enrollment
+	}{
+		{
+			name: "Active enrollment",
+			row:  enrollment.EnrollmentRow{ID: 1, SequenceID: 2, ContactID: 3, State: "active", CurrentStep: 1, NextSendAt: sql.NullTime{Time: now, Valid: true}, CreatedAt: now, UpdatedAt: now},
+			expected: enrollment.Enrollment{
+				ID: 1, SequenceID: 2, ContactID: 3, State: enrollment.StateActive, CurrentStep: 1, NextSendAt: &now, CreatedAt: now, UpdatedAt: now,
+			},
+		},
+		{
+			name: "Enrollment with mailbox",
+			row:  enrollment.EnrollmentRow{ID: 1, SequenceID: 2, ContactID: 3, State: "active", CurrentStep: 1, NextSendAt: sql.NullTime{Time: now, Valid: true}, MailboxID: sql.NullInt64{Int64: 4, Valid: true}, CreatedAt: now, UpdatedAt: now},
+			expected: enrollment.Enrollment{
+				ID: 1, SequenceID: 2, ContactID: 3, State: enrollment.StateActive, CurrentStep: 1, NextSendAt: &now, MailboxID: &mailboxID, CreatedAt: now, UpdatedAt: now,
+			},
+		},
+		{
+			name: "Enrollment pinned to a version",
+			row:  enrollment.EnrollmentRow{ID: 1, SequenceID: 2, ContactID: 3, State: "active", Version: sql.NullInt64{Int64: 2, Valid: true}, NextSendAt: sql.NullTime{Time: now, Valid: true}, CreatedAt: now, UpdatedAt: now},
+			expected: enrollment.Enrollment{
+				ID: 1, SequenceID: 2, ContactID: 3, State: enrollment.StateActive, Version: &version, NextSendAt: &now, CreatedAt: now, UpdatedAt: now,
+			},
+		},
+		{
+			name: "Finished enrollment",
+			row:  enrollment.EnrollmentRow{ID: 1, SequenceID: 2, ContactID: 3, State: "finished", CurrentStep: 2, CreatedAt: now, UpdatedAt: now},
+			expected: enrollment.Enrollment{
+				ID: 1, SequenceID: 2, ContactID: 3, State: enrollment.StateFinished, CurrentStep: 2, CreatedAt: now, UpdatedAt: now,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if e := tc.row.ToEnrollment(); !reflect.DeepEqual(e, tc.expected) {
+				t.Errorf("Expected enrollment: %+v, got: %+v", tc.expected, e)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+type MockRepo struct {
+	CreateEnrollmentsFn func(ctx context.Context, enrollments []enrollment.Enrollment) ([]enrollment.Enrollment, error)
+	ContactTimeZonesFn  func(ctx context.Context, contactIDs []int) (map[int]string, error)
+	ListEnrollmentsFn   func(ctx context.Context, filter enrollment.ListFilter) ([]enrollment.Enrollment, error)
+}
+
+func (m MockRepo) CreateEnrollments(ctx context.Context, enrollments []enrollment.Enrollment) ([]enrollment.Enrollment, error) {
+	return m.CreateEnrollmentsFn(ctx, enrollments)
+}
+
+func (m MockRepo) ContactTimeZones(ctx context.Context, contactIDs []int) (map[int]string, error) {
+	return m.ContactTimeZonesFn(ctx, contactIDs)
+}
+
+func (m MockRepo) ListEnrollments(ctx context.Context, filter enrollment.ListFilter) ([]enrollment.Enrollment, error) {
+	return m.ListEnrollmentsFn(ctx, filter)
+}
+
+type MockSequenceService struct {
+	GetEnrollableSequenceFn func(ctx context.Context, id int) (sequence.Sequence, error)
+}
+
+func (m MockSequenceService) GetEnrollableSequence(ctx context.Context, id int) (sequence.Sequence, error) {
+	return m.GetEnrollableSequenceFn(ctx, id)
+}
@@ -0,0 +1,80 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CapturedMessage is an email kept by the capture mailer.
+type CapturedMessage struct {
+	Message
+	// Raw is the email formatted as RFC 5322.
+	Raw []byte
+}
+
+// CaptureMailer is a mailer that keeps emails instead of sending them, so tests and local
+// development can check outgoing mail without a real server.
+type CaptureMailer struct {
+	dir string
+
+	mu       sync.Mutex
+	sent     int
+	messages []CapturedMessage
+}
+
+// NewCaptureMailer creates a new capture mailer. Emails are written to the given directory
+// as .eml files, or kept in memory when it is empty. Emails written to files are not kept in
+// memory, so a long running server does not hold on to every email it sent.
+func NewCaptureMailer(dir string) (*CaptureMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create capture directory: %w", err)
+		}
+	}
+
+	return &CaptureMailer{
+		dir: dir,
+	}, nil
+}
+
+// Send captures the email.
+func (m *CaptureMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+
+	raw, err := msg.Format(now)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent++
+	if m.dir == "" {
+		m.messages = append(m.messages, CapturedMessage{Message: msg, Raw: raw})
+		return nil
+	}
+
+	name := fmt.Sprintf("%s-%d-%d.eml", now.UTC().Format("20060102T150405"), m.sent, msg.ID)
+	if err := os.WriteFile(filepath.Join(m.dir, name), raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	return nil
+}
+
+// Messages returns the emails kept in memory in the order they were sent. It is empty when
+// emails are written to a directory.
+func (m *CaptureMailer) Messages() []CapturedMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := make([]CapturedMessage, len(m.messages))
+	copy(messages, m.messages)
+
+	return messages
+}
@@ -0,0 +1,109 @@
+// Package mail sends emails through a configurable driver.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/compose"
+	"github.com/cybre/salesforge-assignment/pkg/logging"
+)
+
+// Driver is the implementation used to send emails.
+type Driver string
+
+const (
+	// DriverLog logs emails instead of sending them.
+	DriverLog Driver = "log"
+
+	// DriverSMTP sends emails through an SMTP server.
+	DriverSMTP Driver = "smtp"
+
+	// DriverCapture keeps emails in memory or writes them to .eml files.
+	DriverCapture Driver = "capture"
+)
+
+// Config contains the settings for sending emails.
+type Config struct {
+	Driver Driver
+	SMTP   SMTPConfig
+	// CaptureDir is the directory the capture driver writes .eml files to. Emails are only
+	// kept in memory when it is empty.
+	CaptureDir string
+}
+
+// Message is an email to a single recipient.
+type Message struct {
+	// ID is the ID of the message record the email was created for.
+	ID int
+	// MessageID is the value of the Message-ID header, without angle brackets.
+	MessageID string
+	From      string
+	To        string
+	Subject   string
+	HTML      string
+	// Text is the plain text alternative of the HTML. It is derived from the HTML when empty.
+	Text string
+	// ListUnsubscribe are the URIs the recipient can unsubscribe with.
+	ListUnsubscribe []string
+	// OneClickUnsubscribe advertises RFC 8058 one-click unsubscribing.
+	OneClickUnsubscribe bool
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer creates the mailer selected by the config.
+func NewMailer(config Config) (Mailer, error) {
+	switch config.Driver {
+	case DriverLog, "":
+		return NewLogMailer(), nil
+	case DriverSMTP:
+		return NewSMTPMailer(config.SMTP)
+	case DriverCapture:
+		return NewCaptureMailer(config.CaptureDir)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", config.Driver)
+	}
+}
+
+// Format formats the message as an RFC 5322 email sent at the given date.
+func (m Message) Format(date time.Time) ([]byte, error) {
+	return compose.Email{
+		MessageID:           m.MessageID,
+		From:                m.From,
+		To:                  m.To,
+		Subject:             m.Subject,
+		Date:                date,
+		HTML:                m.HTML,
+		Text:                m.Text,
+		ListUnsubscribe:     m.ListUnsubscribe,
+		OneClickUnsubscribe: m.OneClickUnsubscribe,
+	}.Build()
+}
+
+// LogMailer is a mailer that logs emails instead of sending them.
+type LogMailer struct{}
+
+// NewLogMailer creates a new log mailer.
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send logs the email.
+func (m LogMailer) Send(ctx context.Context, msg Message) error {
+	logging.FromContext(ctx).LogAttrs(ctx, slog.LevelInfo, "EMAIL",
+		slog.Int("message_id", msg.ID),
+		slog.String("message_id_header", msg.MessageID),
+		slog.String("from", msg.From),
+		slog.String("to", msg.To),
+		slog.String("subject", msg.Subject),
+		slog.Int("body_size", len(msg.HTML)),
+	)
+
+	return nil
+}
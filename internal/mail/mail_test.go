@@ -0,0 +1,165 @@
+package mail_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/mail"
+)
+
+func TestMessage_Format(t *testing.T) {
+	msg := mail.Message{
+		MessageID:       "abc@example.com",
+		From:            "sales@example.com",
+		To:              "jane@example.com",
+		Subject:         "Hello",
+		HTML:            "<p>Hi Jane</p>",
+		ListUnsubscribe: []string{"mailto:sales@example.com?subject=unsubscribe"},
+	}
+
+	raw, err := msg.Format(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for _, expected := range []string{
+		"Message-ID: <abc@example.com>\r\n",
+		"Date: Fri, 01 Mar 2024 09:30:00 +0000\r\n",
+		"List-Unsubscribe: <mailto:sales@example.com?subject=unsubscribe>\r\n",
+		"Content-Type: multipart/alternative;",
+		"<p>Hi Jane</p>",
+	} {
+		if !strings.Contains(string(raw), expected) {
+			t.Errorf("Expected email to contain %q, got: %q", expected, raw)
+		}
+	}
+
+	// Line breaks in the subject are encoded instead of starting a new header
+	msg.Subject = "Hello\r\nBcc: everyone@example.com"
+	raw, err = msg.Format(time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if strings.Contains(string(raw), "\r\nBcc:") {
+		t.Errorf("Expected subject line break to be encoded, got: %q", raw)
+	}
+}
+
+func TestNewMailer(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        mail.Config
+		expectedType  mail.Mailer
+		expectedError bool
+	}{
+		{
+			name:         "Default driver",
+			config:       mail.Config{},
+			expectedType: &mail.LogMailer{},
+		},
+		{
+			name:         "SMTP driver",
+			config:       mail.Config{Driver: mail.DriverSMTP, SMTP: mail.SMTPConfig{Host: "smtp.example.com", Port: "587"}},
+			expectedType: &mail.SMTPMailer{},
+		},
+		{
+			name:          "SMTP driver without host",
+			config:        mail.Config{Driver: mail.DriverSMTP},
+			expectedError: true,
+		},
+		{
+			name:         "Capture driver",
+			config:       mail.Config{Driver: mail.DriverCapture},
+			expectedType: &mail.CaptureMailer{},
+		},
+		{
+			name:          "Unknown driver",
+			config:        mail.Config{Driver: "pigeon"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mailer, err := mail.NewMailer(tc.config)
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("Expected error, got: nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if reflect.TypeOf(mailer) != reflect.TypeOf(tc.expectedType) {
+				t.Errorf("Expected mailer: %T, got: %T", tc.expectedType, mailer)
+			}
+		})
+	}
+}
+
+func TestCaptureMailer_Send(t *testing.T) {
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	msg := mail.Message{ID: 7, MessageID: "abc@example.com", From: "sales@example.com", To: "jane@example.com", Subject: "Hello", HTML: "<p>Hi</p>"}
+	if err := mailer.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	messages := mailer.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 captured email, got: %d", len(messages))
+	}
+
+	if !reflect.DeepEqual(messages[0].Message, msg) {
+		t.Errorf("Expected message: %+v, got: %+v", msg, messages[0].Message)
+	}
+
+	if !strings.Contains(string(messages[0].Raw), "Subject: Hello\r\n") {
+		t.Errorf("Expected raw email with subject, got: %q", messages[0].Raw)
+	}
+}
+
+func TestCaptureMailer_Send_Directory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+
+	mailer, err := mail.NewCaptureMailer(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	msg := mail.Message{ID: 7, MessageID: "abc@example.com", From: "sales@example.com", To: "jane@example.com", Subject: "Hello", HTML: "<p>Hi</p>"}
+	if err := mailer.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Emails written to files are not kept in memory
+	if messages := mailer.Messages(); len(messages) != 0 {
+		t.Errorf("Expected no emails in memory, got: %d", len(messages))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected 1 .eml file, got: %v (%v)", files, err)
+	}
+
+	written, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("Failed to read captured email: %v", err)
+	}
+
+	if !strings.Contains(string(written), "Subject: Hello\r\n") {
+		t.Errorf("Expected email with subject, got: %q", written)
+	}
+}
@@ -0,0 +1,281 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// ErrRecipientRejected is returned when the SMTP server permanently rejects the recipient of an
+// email, e.g. because the address does not exist. Sending to it again fails the same way.
+var ErrRecipientRejected = errors.New("smtp server permanently rejected recipient")
+
+// SMTPSecurity is how the connection to the SMTP server is secured.
+type SMTPSecurity string
+
+const (
+	// SMTPSecurityNone sends emails over a plain connection.
+	SMTPSecurityNone SMTPSecurity = "none"
+
+	// SMTPSecuritySTARTTLS upgrades a plain connection with STARTTLS, usually on port 587.
+	SMTPSecuritySTARTTLS SMTPSecurity = "starttls"
+
+	// SMTPSecurityTLS connects with implicit TLS, usually on port 465.
+	SMTPSecurityTLS SMTPSecurity = "tls"
+)
+
+// SMTPAuth is the mechanism used to authenticate with the SMTP server.
+type SMTPAuth string
+
+const (
+	SMTPAuthNone  SMTPAuth = "none"
+	SMTPAuthPlain SMTPAuth = "plain"
+	SMTPAuthLogin SMTPAuth = "login"
+)
+
+const (
+	DefaultSMTPConnectTimeout = 10 * time.Second
+	DefaultSMTPSendTimeout    = 30 * time.Second
+)
+
+// SMTPConfig contains the settings for sending emails through an SMTP server.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Auth     SMTPAuth
+	Security SMTPSecurity
+	// ConnectTimeout is how long to wait for the connection, including the TLS handshake.
+	ConnectTimeout time.Duration
+	// SendTimeout is how long the whole conversation with the server may take once connected.
+	SendTimeout time.Duration
+}
+
+// WithDefaults returns a copy of the config with unset options replaced by their defaults.
+func (c SMTPConfig) WithDefaults() SMTPConfig {
+	if c.Auth == "" {
+		c.Auth = SMTPAuthNone
+	}
+
+	if c.Security == "" {
+		c.Security = SMTPSecuritySTARTTLS
+	}
+
+	if c.ConnectTimeout == 0 {
+		c.ConnectTimeout = DefaultSMTPConnectTimeout
+	}
+
+	if c.SendTimeout == 0 {
+		c.SendTimeout = DefaultSMTPSendTimeout
+	}
+
+	return c
+}
+
+// Validate validates the SMTP config.
+func (c SMTPConfig) Validate() error {
+	if c.Host == "" {
+		return errors.New("host is required")
+	}
+
+	if c.Port == "" {
+		return errors.New("port is required")
+	}
+
+	switch c.Security {
+	case SMTPSecurityNone, SMTPSecuritySTARTTLS, SMTPSecurityTLS:
+	default:
+		return fmt.Errorf("security must be one of %q, %q or %q", SMTPSecurityNone, SMTPSecuritySTARTTLS, SMTPSecurityTLS)
+	}
+
+	switch c.Auth {
+	case SMTPAuthNone:
+	case SMTPAuthPlain, SMTPAuthLogin:
+		if c.Username == "" {
+			return fmt.Errorf("username is required for %s auth", c.Auth)
+		}
+	default:
+		return fmt.Errorf("auth must be one of %q, %q or %q", SMTPAuthNone, SMTPAuthPlain, SMTPAuthLogin)
+	}
+
+	return nil
+}
+
+// SMTPMailer sends emails through an SMTP server, opening a connection per email.
+type SMTPMailer struct {
+	config SMTPConfig
+	// tlsConfig is used for STARTTLS and implicit TLS connections.
+	tlsConfig *tls.Config
+}
+
+// NewSMTPMailer creates a new SMTP mailer.
+func NewSMTPMailer(config SMTPConfig) (*SMTPMailer, error) {
+	config = config.WithDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("smtp config is invalid: %w", err)
+	}
+
+	return &SMTPMailer{
+		config:    config,
+		tlsConfig: &tls.Config{ServerName: config.Host},
+	}, nil
+}
+
+// Send sends the email.
+func (m SMTPMailer) Send(ctx context.Context, msg Message) error {
+	from, err := netmail.ParseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("from address is invalid: %w", err)
+	}
+
+	to, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("to address is invalid: %w", err)
+	}
+
+	raw, err := msg.Format(time.Now())
+	if err != nil {
+		return err
+	}
+
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+
+	deadline := time.Now().Add(m.config.SendTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to greet smtp server: %w", err)
+	}
+	defer client.Close()
+
+	if m.config.Security == SMTPSecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+
+		if err := client.StartTLS(m.tlsConfig); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+
+	if auth := m.auth(); auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("smtp server rejected sender: %w", err)
+	}
+
+	if err := client.Rcpt(to.Address); err != nil {
+		var reply *textproto.Error
+		if errors.As(err, &reply) && reply.Code >= 500 {
+			return fmt.Errorf("%w: %s", ErrRecipientRejected, err)
+		}
+
+		return fmt.Errorf("smtp server rejected recipient: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp server rejected data: %w", err)
+	}
+
+	if _, err := w.Write(raw); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp server rejected message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// dial connects to the SMTP server, with implicit TLS if configured.
+func (m SMTPMailer) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	dialer := &net.Dialer{Timeout: m.config.ConnectTimeout}
+
+	if m.config.Security == SMTPSecurityTLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: m.tlsConfig}
+		return tlsDialer.DialContext(ctx, "tcp", addr)
+	}
+
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// auth returns the configured authentication mechanism, if any.
+func (m SMTPMailer) auth() smtp.Auth {
+	switch m.config.Auth {
+	case SMTPAuthPlain:
+		return smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	case SMTPAuthLogin:
+		return &loginAuth{host: m.config.Host, username: m.config.Username, password: m.config.Password}
+	default:
+		return nil
+	}
+}
+
+// loginAuth implements the LOGIN authentication mechanism, which net/smtp lacks but many
+// servers, e.g. Office 365, still expect.
+type loginAuth struct {
+	host     string
+	username string
+	password string
+}
+
+// Start begins the authentication. Like smtp.PlainAuth, it refuses to send credentials over
+// an unencrypted connection to anything but localhost.
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+
+	return "LOGIN", nil, nil
+}
+
+// Next answers the username and password challenges of the server.
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,312 @@
+package mail_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/mail"
+)
+
+// smtpServer is a minimal SMTP server that records the conversation of a single client.
+type smtpServer struct {
+	listener net.Listener
+	starttls bool
+	// rcptReply replaces the reply to RCPT when set.
+	rcptReply string
+
+	mu       sync.Mutex
+	auth     string
+	from     string
+	to       string
+	data     string
+	finished chan struct{}
+}
+
+func newSMTPServer(t *testing.T, starttls bool) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &smtpServer{listener: listener, starttls: starttls, finished: make(chan struct{})}
+	go s.serve()
+
+	return s
+}
+
+func (s *smtpServer) port() string {
+	return strconv.Itoa(s.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (s *smtpServer) serve() {
+	defer close(s.finished)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) {
+		conn.Write([]byte(strings.Join(lines, "\r\n") + "\r\n"))
+	}
+	readLine := func() string {
+		line, _ := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n")
+	}
+	decode := func(s string) string {
+		b, _ := base64.StdEncoding.DecodeString(s)
+		return string(b)
+	}
+
+	reply("220 localhost ESMTP")
+	for {
+		line := readLine()
+		verb, arg, _ := strings.Cut(line, " ")
+
+		s.mu.Lock()
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			if s.starttls {
+				reply("250-localhost", "250-STARTTLS", "250 AUTH PLAIN LOGIN")
+			} else {
+				reply("250-localhost", "250 AUTH PLAIN LOGIN")
+			}
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			if mechanism == "LOGIN" {
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+				username := decode(readLine())
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+				password := decode(readLine())
+				s.auth = "LOGIN " + username + ":" + password
+			} else {
+				s.auth = "PLAIN " + strings.ReplaceAll(decode(initial), "\x00", ":")
+			}
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.from = arg
+			reply("250 OK")
+		case "RCPT":
+			s.to = arg
+			if s.rcptReply != "" {
+				reply(s.rcptReply)
+			} else {
+				reply("250 OK")
+			}
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil || line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			s.mu.Unlock()
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+		s.mu.Unlock()
+	}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	testCases := []struct {
+		name         string
+		auth         mail.SMTPAuth
+		expectedAuth string
+	}{
+		{
+			name:         "No auth",
+			auth:         mail.SMTPAuthNone,
+			expectedAuth: "",
+		},
+		{
+			name:         "PLAIN auth",
+			auth:         mail.SMTPAuthPlain,
+			expectedAuth: "PLAIN :user:secret",
+		},
+		{
+			name:         "LOGIN auth",
+			auth:         mail.SMTPAuthLogin,
+			expectedAuth: "LOGIN user:secret",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newSMTPServer(t, false)
+
+			mailer, err := mail.NewSMTPMailer(mail.SMTPConfig{
+				Host:     "127.0.0.1",
+				Port:     server.port(),
+				Username: "user",
+				Password: "secret",
+				Auth:     tc.auth,
+				Security: mail.SMTPSecurityNone,
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			err = mailer.Send(context.Background(), mail.Message{
+				MessageID: "abc@example.com",
+				From:      "Sales <sales@example.com>",
+				To:        "jane@example.com",
+				Subject:   "Hello",
+				HTML:      "<p>Hi Jane, how are you?</p>",
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			<-server.finished
+
+			if server.auth != tc.expectedAuth {
+				t.Errorf("Expected auth: %q, got: %q", tc.expectedAuth, server.auth)
+			}
+
+			if server.from != "FROM:<sales@example.com>" {
+				t.Errorf("Expected sender: %q, got: %q", "FROM:<sales@example.com>", server.from)
+			}
+
+			if server.to != "TO:<jane@example.com>" {
+				t.Errorf("Expected recipient: %q, got: %q", "TO:<jane@example.com>", server.to)
+			}
+
+			if !strings.Contains(server.data, "Message-ID: <abc@example.com>\r\n") || !strings.Contains(server.data, "<p>Hi Jane, how are you?</p>") {
+				t.Errorf("Unexpected data: %q", server.data)
+			}
+		})
+	}
+}
+
+func TestSMTPMailer_Send_STARTTLSNotSupported(t *testing.T) {
+	server := newSMTPServer(t, false)
+
+	mailer, err := mail.NewSMTPMailer(mail.SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Security: mail.SMTPSecuritySTARTTLS,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	err = mailer.Send(context.Background(), mail.Message{MessageID: "abc@example.com", From: "sales@example.com", To: "jane@example.com", Subject: "Hello", HTML: "<p>Hi</p>"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("Expected STARTTLS error, got: %v", err)
+	}
+}
+
+func TestSMTPMailer_Send_RecipientRejected(t *testing.T) {
+	testCases := []struct {
+		name      string
+		rcptReply string
+		permanent bool
+	}{
+		{
+			name:      "Permanent rejection",
+			rcptReply: "550 5.1.1 User unknown",
+			permanent: true,
+		},
+		{
+			name:      "Temporary rejection",
+			rcptReply: "450 4.2.1 Try again later",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newSMTPServer(t, false)
+			server.mu.Lock()
+			server.rcptReply = tc.rcptReply
+			server.mu.Unlock()
+
+			mailer, err := mail.NewSMTPMailer(mail.SMTPConfig{
+				Host:     "127.0.0.1",
+				Port:     server.port(),
+				Security: mail.SMTPSecurityNone,
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			err = mailer.Send(context.Background(), mail.Message{MessageID: "abc@example.com", From: "sales@example.com", To: "jane@example.com", Subject: "Hello", HTML: "<p>Hi</p>"})
+			if err == nil {
+				t.Fatalf("Expected an error, got: nil")
+			}
+
+			if errors.Is(err, mail.ErrRecipientRejected) != tc.permanent {
+				t.Errorf("Expected permanent rejection: %t, got: %v", tc.permanent, err)
+			}
+		})
+	}
+}
+
+func TestSMTPConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        mail.SMTPConfig
+		expectedError string
+	}{
+		{
+			name:   "Valid config",
+			config: mail.SMTPConfig{Host: "smtp.example.com", Port: "587", Username: "user", Auth: mail.SMTPAuthLogin},
+		},
+		{
+			name:          "Missing host",
+			config:        mail.SMTPConfig{Port: "587"},
+			expectedError: "host is required",
+		},
+		{
+			name:          "Missing port",
+			config:        mail.SMTPConfig{Host: "smtp.example.com"},
+			expectedError: "port is required",
+		},
+		{
+			name:          "Unknown security",
+			config:        mail.SMTPConfig{Host: "smtp.example.com", Port: "587", Security: "ssl"},
+			expectedError: `security must be one of "none", "starttls" or "tls"`,
+		},
+		{
+			name:          "Unknown auth",
+			config:        mail.SMTPConfig{Host: "smtp.example.com", Port: "587", Auth: "cram-md5"},
+			expectedError: `auth must be one of "none", "plain" or "login"`,
+		},
+		{
+			name:          "Auth without username",
+			config:        mail.SMTPConfig{Host: "smtp.example.com", Port: "587", Auth: mail.SMTPAuthPlain},
+			expectedError: "username is required for plain auth",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.WithDefaults().Validate()
+			if tc.expectedError == "" && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+
+			if tc.expectedError != "" && (err == nil || err.Error() != tc.expectedError) {
+				t.Errorf("Expected error: %s, got: %v", tc.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package mailbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// KeySize is the size of the key SMTP passwords are encrypted with.
+const KeySize = 32
+
+// ErrDecrypt is returned when a ciphertext was not encrypted with the key of the cipher.
+var ErrDecrypt = errors.New("failed to decrypt ciphertext")
+
+// Cipher encrypts the SMTP passwords of mailboxes at rest with AES-256-GCM.
+//
+// A ciphertext is a random nonce followed by the sealed password.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a new cipher with a key given as 64 hex characters. Surrounding whitespace
+// is ignored, such as the trailing newline of a key file.
+func NewCipher(key string) (*Cipher, error) {
+	raw, err := hex.DecodeString(strings.TrimSpace(key))
+	if err != nil || len(raw) != KeySize {
+		return nil, errors.New("mailbox encryption key must be 64 hex characters")
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{
+		aead: aead,
+	}, nil
+}
+
+// Encrypt encrypts a plaintext with a random nonce.
+func (c Cipher) Encrypt(plaintext string) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, []byte(plaintext), nil), nil
+}
+
+// Decrypt decrypts a ciphertext created with Encrypt.
+func (c Cipher) Decrypt(ciphertext []byte) (string, error) {
+	if len(ciphertext) < c.aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+
+	nonce, sealed := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,68 @@
+package mailbox_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+)
+
+const testKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestCipher(t *testing.T) {
+	cipher, err := mailbox.NewCipher(testKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	ciphertext, err := cipher.Encrypt("hunter2")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if strings.Contains(string(ciphertext), "hunter2") {
+		t.Errorf("Expected the ciphertext not to contain the plaintext")
+	}
+
+	again, _ := cipher.Encrypt("hunter2")
+	if string(again) == string(ciphertext) {
+		t.Errorf("Expected every encryption to use a new nonce")
+	}
+
+	if plaintext, err := cipher.Decrypt(ciphertext); err != nil || plaintext != "hunter2" {
+		t.Errorf("Expected hunter2, got: %q, %v", plaintext, err)
+	}
+
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := cipher.Decrypt(ciphertext); !errors.Is(err, mailbox.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for a tampered ciphertext, got: %v", err)
+	}
+
+	other, _ := mailbox.NewCipher(strings.Repeat("ab", mailbox.KeySize))
+	if _, err := other.Decrypt(again); !errors.Is(err, mailbox.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for another key, got: %v", err)
+	}
+}
+
+func TestNewCipher_InvalidKey(t *testing.T) {
+	for _, key := range []string{"", "not hex", strings.Repeat("ab", 16)} {
+		if _, err := mailbox.NewCipher(key); err == nil {
+			t.Errorf("Expected an error for key %q", key)
+		}
+	}
+}
+
+func TestNewCipher_KeyFileNewline(t *testing.T) {
+	cipher, err := mailbox.NewCipher(testKey + "\n")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// The key is the same as without the newline
+	other, _ := mailbox.NewCipher(testKey)
+	ciphertext, _ := other.Encrypt("hunter2")
+	if plaintext, err := cipher.Decrypt(ciphertext); err != nil || plaintext != "hunter2" {
+		t.Errorf("Expected hunter2, got: %q, %v", plaintext, err)
+	}
+}
@@ -0,0 +1,62 @@
+package mailbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+)
+
+// ErrInvalidListQuery is returned when the options for listing mailboxes are invalid.
+var ErrInvalidListQuery = errors.New("list query is invalid")
+
+// ListQuery represents the options for listing mailboxes. Mailboxes are listed in order of ID.
+type ListQuery struct {
+	// Search filters mailboxes whose email or display name contains the given text, ignoring case.
+	Search string
+	Limit  int
+	// Cursor is the opaque cursor returned with the previous page.
+	Cursor string
+}
+
+// ListFilter is the list query as understood by the repository.
+type ListFilter struct {
+	Search string
+	listing.Filter
+}
+
+// Page is a page of mailboxes.
+type Page struct {
+	Mailboxes []Mailbox `json:"mailboxes"`
+	// NextCursor is the cursor for the next page. It is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListMailboxes lists mailboxes matching the query with their capacity today, one page at a time.
+func (s Service) ListMailboxes(ctx context.Context, query ListQuery) (Page, error) {
+	window, err := listing.NewFilter(query.Limit, query.Cursor)
+	if err != nil {
+		return Page{}, fmt.Errorf("%w: %s", ErrInvalidListQuery, err)
+	}
+
+	filter := ListFilter{
+		Search: query.Search,
+		Filter: window,
+	}
+
+	mailboxes, err := s.repo.ListMailboxes(ctx, filter)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to list mailboxes: %w", err)
+	}
+
+	page := Page{}
+	mailboxes, page.NextCursor = listing.Trim(mailboxes, filter.Filter, func(m Mailbox) int { return m.ID })
+
+	page.Mailboxes, err = s.withCapacity(ctx, mailboxes)
+	if err != nil {
+		return Page{}, err
+	}
+
+	return page, nil
+}
@@ -0,0 +1,578 @@
+// Package mailbox keeps the sender mailboxes emails are sent from, with their SMTP credentials
+// and daily sending quotas, and rotates the emails of sequences across pools of mailboxes.
+package mailbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+var (
+	// ErrMailboxNotFound is returned when a mailbox with the given ID is not found.
+	ErrMailboxNotFound = errors.New("mailbox with given ID not found")
+
+	// ErrMailboxValidation is returned when a mailbox model fails validation.
+	ErrMailboxValidation = errors.New("mailbox model is invalid")
+
+	// ErrMailboxExists is returned when a mailbox with the same email already exists.
+	ErrMailboxExists = errors.New("mailbox with given email already exists")
+
+	// ErrNoMailboxes is returned when a sequence has no mailboxes to send from.
+	ErrNoMailboxes = errors.New("sequence has no mailboxes")
+
+	// ErrQuotaExceeded is returned when the mailboxes of a sequence have sent as many emails
+	// today as they are allowed to.
+	ErrQuotaExceeded = errors.New("daily sending quota exceeded")
+)
+
+const (
+	// MaxDailyLimit is the largest number of emails a mailbox can be allowed to send a day.
+	MaxDailyLimit = 10000
+
+	// MaxSequenceMailboxes is the largest number of mailboxes a sequence can send from.
+	MaxSequenceMailboxes = 100
+
+	// maxDisplayNameLength is the maximum length of the display name of a mailbox.
+	maxDisplayNameLength = 255
+
+	// dayLayout is the layout of days, which start at midnight UTC.
+	dayLayout = "2006-01-02"
+)
+
+// Config contains the settings for mailboxes.
+type Config struct {
+	// EncryptionKey encrypts the SMTP passwords of mailboxes, as 64 hex characters.
+	EncryptionKey string
+}
+
+// SMTP contains the settings for sending emails from a mailbox.
+type SMTP struct {
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	Username string `json:"username"`
+	// Password is never returned by the API. Leaving it empty when updating a mailbox keeps
+	// the current password.
+	Password string            `json:"password,omitempty"`
+	Auth     mail.SMTPAuth     `json:"auth"`
+	Security mail.SMTPSecurity `json:"security"`
+}
+
+// RampUp raises the daily limit of a mailbox gradually while it is warmed up.
+type RampUp struct {
+	// StartDate is the first day of the ramp-up, as YYYY-MM-DD. Nothing is sent before it.
+	StartDate string `json:"startDate"`
+	// InitialLimit is the daily limit on the first day.
+	InitialLimit int `json:"initialLimit"`
+	// DailyIncrease is added to the limit every following day until it reaches the daily
+	// limit of the mailbox.
+	DailyIncrease int `json:"dailyIncrease"`
+}
+
+// Capacity is how many emails a mailbox can send on a day.
+type Capacity struct {
+	Day       string `json:"day"`
+	Limit     int    `json:"limit"`
+	Sent      int    `json:"sent"`
+	Remaining int    `json:"remaining"`
+}
+
+// Mailbox is an email account emails are sent from.
+type Mailbox struct {
+	ID          int    `json:"id"`
+	Email       string `json:"email"`
+	DisplayName string `json:"displayName"`
+	SMTP        SMTP   `json:"smtp"`
+	// DailyLimit is the largest number of emails the mailbox sends a day, once warmed up.
+	DailyLimit int     `json:"dailyLimit"`
+	RampUp     *RampUp `json:"rampUp,omitempty"`
+	// Capacity is the capacity of the mailbox today. It is only set by the API.
+	Capacity  *Capacity `json:"capacity,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Normalize returns a copy of the mailbox with surrounding whitespace removed, the email
+// address normalized and unset SMTP options replaced by their defaults. An invalid email
+// address is left as is for Validate to report.
+func (m Mailbox) Normalize() Mailbox {
+	m.Email = strings.TrimSpace(m.Email)
+	if email, err := contact.NormalizeEmail(m.Email); err == nil {
+		m.Email = email
+	}
+
+	m.DisplayName = strings.TrimSpace(m.DisplayName)
+	m.SMTP.Host = strings.TrimSpace(m.SMTP.Host)
+	m.SMTP.Port = strings.TrimSpace(m.SMTP.Port)
+	m.SMTP.Username = strings.TrimSpace(m.SMTP.Username)
+
+	if m.SMTP.Auth == "" {
+		m.SMTP.Auth = mail.SMTPAuthNone
+	}
+
+	if m.SMTP.Security == "" {
+		m.SMTP.Security = mail.SMTPSecuritySTARTTLS
+	}
+
+	return m
+}
+
+// Validate validates the mailbox model.
+func (m Mailbox) Validate() error {
+	if m.Email == "" {
+		return errors.New("email is required")
+	}
+
+	if _, err := contact.NormalizeEmail(m.Email); err != nil {
+		return err
+	}
+
+	if len(m.DisplayName) > maxDisplayNameLength {
+		return fmt.Errorf("display name cannot be longer than %d characters", maxDisplayNameLength)
+	}
+
+	if err := m.SMTPConfig().Validate(); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	if m.DailyLimit < 1 || m.DailyLimit > MaxDailyLimit {
+		return fmt.Errorf("daily limit must be between 1 and %d", MaxDailyLimit)
+	}
+
+	if m.RampUp != nil {
+		if _, err := time.Parse(dayLayout, m.RampUp.StartDate); err != nil {
+			return errors.New("ramp-up start date must be a date formatted as YYYY-MM-DD")
+		}
+
+		if m.RampUp.InitialLimit < 1 || m.RampUp.InitialLimit > m.DailyLimit {
+			return errors.New("ramp-up initial limit must be between 1 and the daily limit")
+		}
+
+		if m.RampUp.DailyIncrease < 1 {
+			return errors.New("ramp-up daily increase must be at least 1")
+		}
+	}
+
+	return nil
+}
+
+// SMTPConfig returns the settings for sending emails from the mailbox.
+func (m Mailbox) SMTPConfig() mail.SMTPConfig {
+	return mail.SMTPConfig{
+		Host:     m.SMTP.Host,
+		Port:     m.SMTP.Port,
+		Username: m.SMTP.Username,
+		Password: m.SMTP.Password,
+		Auth:     m.SMTP.Auth,
+		Security: m.SMTP.Security,
+	}
+}
+
+// From returns the value of the From header of emails sent from the mailbox.
+func (m Mailbox) From() string {
+	return (&netmail.Address{Name: m.DisplayName, Address: m.Email}).String()
+}
+
+// LimitOn returns how many emails the mailbox may send on the day of t, in UTC. During the
+// ramp-up the limit starts at the initial limit and grows every day until it reaches the daily
+// limit.
+func (m Mailbox) LimitOn(t time.Time) int {
+	if m.RampUp == nil {
+		return m.DailyLimit
+	}
+
+	start, err := time.Parse(dayLayout, m.RampUp.StartDate)
+	if err != nil {
+		return m.DailyLimit
+	}
+
+	day := Day(t)
+	if day.Before(start) {
+		return 0
+	}
+
+	days := int(day.Sub(start).Hours() / 24)
+	return min(m.RampUp.InitialLimit+days*m.RampUp.DailyIncrease, m.DailyLimit)
+}
+
+// CapacityOn returns the capacity of the mailbox on the day of t, in UTC, after sending the
+// given number of emails that day.
+func (m Mailbox) CapacityOn(t time.Time, sent int) Capacity {
+	limit := m.LimitOn(t)
+
+	return Capacity{
+		Day:       Day(t).Format(dayLayout),
+		Limit:     limit,
+		Sent:      sent,
+		Remaining: max(limit-sent, 0),
+	}
+}
+
+// withoutPassword returns a copy of the mailbox without its SMTP password.
+func (m Mailbox) withoutPassword() Mailbox {
+	m.SMTP.Password = ""
+	return m
+}
+
+// Day returns the start of the day of t in UTC. Quotas are counted per day in UTC.
+func Day(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// NextDay returns the start of the day after the day of t in UTC, when quotas are reset.
+func NextDay(t time.Time) time.Time {
+	return Day(t).AddDate(0, 0, 1)
+}
+
+// Repository represents the data layer for mailboxes.
+type Repository interface {
+	CreateMailbox(ctx context.Context, m Mailbox) (Mailbox, error)
+	GetMailbox(ctx context.Context, id int) (Mailbox, bool, error)
+	UpdateMailbox(ctx context.Context, m Mailbox) (Mailbox, bool, error)
+	DeleteMailbox(ctx context.Context, id int) (bool, error)
+	ListMailboxes(ctx context.Context, filter ListFilter) ([]Mailbox, error)
+	ExistingMailboxIDs(ctx context.Context, ids []int) ([]int, error)
+	SetSequenceMailboxes(ctx context.Context, sequenceID int, ids []int) error
+	ListSequenceMailboxes(ctx context.Context, sequenceID int) ([]Mailbox, error)
+	CountSent(ctx context.Context, ids []int, day time.Time) (map[int]int, error)
+	Reserve(ctx context.Context, id int, day time.Time, limit int) (bool, error)
+	Release(ctx context.Context, id int, day time.Time) error
+}
+
+// SequenceRepository gets the sequences mailboxes are attached to.
+type SequenceRepository interface {
+	GetSequence(ctx context.Context, id int) (sequence.Sequence, bool, error)
+}
+
+// Service contains the business logic for mailboxes.
+type Service struct {
+	repo      Repository
+	sequences SequenceRepository
+}
+
+// NewService creates a new mailbox service.
+func NewService(repo Repository, sequences SequenceRepository) *Service {
+	return &Service{
+		repo:      repo,
+		sequences: sequences,
+	}
+}
+
+// CreateMailbox creates a new mailbox and returns it with its assigned ID and capacity.
+func (s Service) CreateMailbox(ctx context.Context, m Mailbox) (Mailbox, error) {
+	m = m.Normalize()
+	if err := m.Validate(); err != nil {
+		return Mailbox{}, fmt.Errorf("%w: %s", ErrMailboxValidation, err)
+	}
+
+	if m.SMTP.Auth != mail.SMTPAuthNone && m.SMTP.Password == "" {
+		return Mailbox{}, fmt.Errorf("%w: smtp: password is required for %s auth", ErrMailboxValidation, m.SMTP.Auth)
+	}
+
+	created, err := s.repo.CreateMailbox(ctx, m)
+	if err != nil {
+		if errors.Is(err, ErrMailboxExists) {
+			return Mailbox{}, err
+		}
+
+		return Mailbox{}, fmt.Errorf("failed to create mailbox: %w", err)
+	}
+
+	withCapacity, err := s.withCapacity(ctx, []Mailbox{created})
+	if err != nil {
+		return Mailbox{}, err
+	}
+
+	return withCapacity[0], nil
+}
+
+// GetMailbox gets a mailbox by ID with its capacity today.
+func (s Service) GetMailbox(ctx context.Context, id int) (Mailbox, error) {
+	m, exists, err := s.repo.GetMailbox(ctx, id)
+	if err != nil {
+		return Mailbox{}, fmt.Errorf("failed to get mailbox: %w", err)
+	}
+
+	if !exists {
+		return Mailbox{}, ErrMailboxNotFound
+	}
+
+	withCapacity, err := s.withCapacity(ctx, []Mailbox{m})
+	if err != nil {
+		return Mailbox{}, err
+	}
+
+	return withCapacity[0], nil
+}
+
+// UpdateMailbox replaces the fields of a mailbox and returns the updated mailbox. An empty
+// SMTP password keeps the current password.
+func (s Service) UpdateMailbox(ctx context.Context, m Mailbox) (Mailbox, error) {
+	m = m.Normalize()
+	if err := m.Validate(); err != nil {
+		return Mailbox{}, fmt.Errorf("%w: %s", ErrMailboxValidation, err)
+	}
+
+	updated, exists, err := s.repo.UpdateMailbox(ctx, m)
+	if err != nil {
+		if errors.Is(err, ErrMailboxExists) {
+			return Mailbox{}, err
+		}
+
+		return Mailbox{}, fmt.Errorf("failed to update mailbox: %w", err)
+	}
+
+	if !exists {
+		return Mailbox{}, ErrMailboxNotFound
+	}
+
+	withCapacity, err := s.withCapacity(ctx, []Mailbox{updated})
+	if err != nil {
+		return Mailbox{}, err
+	}
+
+	return withCapacity[0], nil
+}
+
+// DeleteMailbox deletes a mailbox and removes it from the sequences sending from it.
+// Enrollments sending from it continue from another mailbox of their sequence.
+func (s Service) DeleteMailbox(ctx context.Context, id int) error {
+	exists, err := s.repo.DeleteMailbox(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete mailbox: %w", err)
+	}
+
+	if !exists {
+		return ErrMailboxNotFound
+	}
+
+	return nil
+}
+
+// SetSequenceMailboxes replaces the pool of mailboxes the emails of a sequence are sent from.
+// Without mailboxes, emails are sent from the default sender.
+func (s Service) SetSequenceMailboxes(ctx context.Context, sequenceID int, ids []int) error {
+	if err := validateMailboxIDs(ids); err != nil {
+		return fmt.Errorf("%w: %s", ErrMailboxValidation, err)
+	}
+
+	seq, exists, err := s.sequences.GetSequence(ctx, sequenceID)
+	if err != nil {
+		return fmt.Errorf("failed to get sequence: %w", err)
+	}
+
+	if !exists {
+		return sequence.ErrSequenceNotFound
+	}
+
+	if seq.Archived() {
+		return sequence.ErrSequenceArchived
+	}
+
+	ids = dedupe(ids)
+	existing, err := s.repo.ExistingMailboxIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to look up mailboxes: %w", err)
+	}
+
+	found := make(map[int]bool, len(existing))
+	for _, id := range existing {
+		found[id] = true
+	}
+
+	for _, id := range ids {
+		if !found[id] {
+			return fmt.Errorf("%w: mailbox %d does not exist", ErrMailboxValidation, id)
+		}
+	}
+
+	if err := s.repo.SetSequenceMailboxes(ctx, sequenceID, ids); err != nil {
+		return fmt.Errorf("failed to set sequence mailboxes: %w", err)
+	}
+
+	return nil
+}
+
+// ListSequenceMailboxes lists the mailboxes the emails of a sequence are sent from, with their
+// capacity today.
+func (s Service) ListSequenceMailboxes(ctx context.Context, sequenceID int) ([]Mailbox, error) {
+	_, exists, err := s.sequences.GetSequence(ctx, sequenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sequence: %w", err)
+	}
+
+	if !exists {
+		return nil, sequence.ErrSequenceNotFound
+	}
+
+	mailboxes, err := s.repo.ListSequenceMailboxes(ctx, sequenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sequence mailboxes: %w", err)
+	}
+
+	return s.withCapacity(ctx, mailboxes)
+}
+
+// withCapacity sets the capacity of the mailboxes today and removes their passwords, as
+// mailboxes are returned by the API.
+func (s Service) withCapacity(ctx context.Context, mailboxes []Mailbox) ([]Mailbox, error) {
+	now := time.Now()
+	sent, err := s.repo.CountSent(ctx, mailboxIDs(mailboxes), Day(now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sent emails: %w", err)
+	}
+
+	for i, m := range mailboxes {
+		capacity := m.CapacityOn(now, sent[m.ID])
+		mailboxes[i] = m.withoutPassword()
+		mailboxes[i].Capacity = &capacity
+	}
+
+	return mailboxes, nil
+}
+
+// Reserve reserves the sending of an email from a mailbox of a sequence at the given time and
+// returns the mailbox, including its SMTP password. The mailbox already assigned to the
+// enrollment is used when there is one, so a contact keeps hearing from the same mailbox.
+// Otherwise the mailbox of the sequence with the most remaining capacity is picked.
+//
+// It returns ErrNoMailboxes when nothing is assigned and the sequence has no mailboxes, and
+// ErrQuotaExceeded when the mailboxes that can be used have no capacity left today.
+func (s Service) Reserve(ctx context.Context, sequenceID int, assignedID int, now time.Time) (Mailbox, error) {
+	day := Day(now)
+
+	if assignedID != 0 {
+		m, exists, err := s.repo.GetMailbox(ctx, assignedID)
+		if err != nil {
+			return Mailbox{}, fmt.Errorf("failed to get mailbox: %w", err)
+		}
+
+		if exists {
+			return s.reserve(ctx, m, day, now)
+		}
+	}
+
+	mailboxes, err := s.repo.ListSequenceMailboxes(ctx, sequenceID)
+	if err != nil {
+		return Mailbox{}, fmt.Errorf("failed to list sequence mailboxes: %w", err)
+	}
+
+	if len(mailboxes) == 0 {
+		return Mailbox{}, ErrNoMailboxes
+	}
+
+	sent, err := s.repo.CountSent(ctx, mailboxIDs(mailboxes), day)
+	if err != nil {
+		return Mailbox{}, fmt.Errorf("failed to count sent emails: %w", err)
+	}
+
+	// Another worker may use up a mailbox between counting and reserving, so the next one is
+	// tried until one is reserved.
+	for _, m := range rotation(mailboxes, sent, now) {
+		reserved, err := s.reserve(ctx, m, day, now)
+		if errors.Is(err, ErrQuotaExceeded) {
+			continue
+		}
+
+		return reserved, err
+	}
+
+	return Mailbox{}, ErrQuotaExceeded
+}
+
+func (s Service) reserve(ctx context.Context, m Mailbox, day time.Time, now time.Time) (Mailbox, error) {
+	limit := m.LimitOn(now)
+	if limit == 0 {
+		return Mailbox{}, ErrQuotaExceeded
+	}
+
+	reserved, err := s.repo.Reserve(ctx, m.ID, day, limit)
+	if err != nil {
+		return Mailbox{}, fmt.Errorf("failed to reserve mailbox %d: %w", m.ID, err)
+	}
+
+	if !reserved {
+		return Mailbox{}, ErrQuotaExceeded
+	}
+
+	return m, nil
+}
+
+// Release gives back a send reserved at the given time that did not happen.
+func (s Service) Release(ctx context.Context, mailboxID int, now time.Time) error {
+	if err := s.repo.Release(ctx, mailboxID, Day(now)); err != nil {
+		return fmt.Errorf("failed to release mailbox %d: %w", mailboxID, err)
+	}
+
+	return nil
+}
+
+// rotation orders the mailboxes with capacity left on the day of now by their remaining
+// capacity, most first, so emails are spread across the mailboxes in proportion to their
+// limits. Ties go to the mailbox with the lowest ID.
+func rotation(mailboxes []Mailbox, sent map[int]int, now time.Time) []Mailbox {
+	remaining := make(map[int]int, len(mailboxes))
+	available := make([]Mailbox, 0, len(mailboxes))
+	for _, m := range mailboxes {
+		if r := m.CapacityOn(now, sent[m.ID]).Remaining; r > 0 {
+			remaining[m.ID] = r
+			available = append(available, m)
+		}
+	}
+
+	sort.SliceStable(available, func(i, j int) bool {
+		if remaining[available[i].ID] != remaining[available[j].ID] {
+			return remaining[available[i].ID] > remaining[available[j].ID]
+		}
+
+		return available[i].ID < available[j].ID
+	})
+
+	return available
+}
+
+func validateMailboxIDs(ids []int) error {
+	if len(ids) > MaxSequenceMailboxes {
+		return fmt.Errorf("at most %d mailboxes can be attached to a sequence", MaxSequenceMailboxes)
+	}
+
+	for _, id := range ids {
+		if id < 1 {
+			return fmt.Errorf("mailbox ID %d is invalid", id)
+		}
+	}
+
+	return nil
+}
+
+func mailboxIDs(mailboxes []Mailbox) []int {
+	ids := make([]int, len(mailboxes))
+	for i, m := range mailboxes {
+		ids[i] = m.ID
+	}
+
+	return ids
+}
+
+// dedupe removes repeated IDs, keeping the first occurrence of each.
+func dedupe(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
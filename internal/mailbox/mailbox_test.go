@@ -0,0 +1,458 @@
+package mailbox_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/mailbox/testdata"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+func validMailbox() mailbox.Mailbox {
+	return mailbox.Mailbox{
+		Email:       "jane@example.com",
+		DisplayName: "Jane Doe",
+		SMTP: mailbox.SMTP{
+			Host:     "smtp.example.com",
+			Port:     "587",
+			Username: "jane",
+			Password: "hunter2",
+			Auth:     mail.SMTPAuthPlain,
+		},
+		DailyLimit: 50,
+	}
+}
+
+func TestMailbox_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		modify   func(m *mailbox.Mailbox)
+		expected error
+	}{
+		{
+			name:   "Valid mailbox",
+			modify: func(m *mailbox.Mailbox) {},
+		},
+		{
+			name: "Valid ramp-up",
+			modify: func(m *mailbox.Mailbox) {
+				m.RampUp = &mailbox.RampUp{StartDate: "2024-05-01", InitialLimit: 5, DailyIncrease: 5}
+			},
+		},
+		{
+			name:     "Missing email",
+			modify:   func(m *mailbox.Mailbox) { m.Email = "" },
+			expected: errors.New("email is required"),
+		},
+		{
+			name:     "Invalid email",
+			modify:   func(m *mailbox.Mailbox) { m.Email = "jane.example.com" },
+			expected: errors.New(`email "jane.example.com" is invalid`),
+		},
+		{
+			name:     "Missing SMTP host",
+			modify:   func(m *mailbox.Mailbox) { m.SMTP.Host = "" },
+			expected: errors.New("smtp: host is required"),
+		},
+		{
+			name:     "Daily limit out of range",
+			modify:   func(m *mailbox.Mailbox) { m.DailyLimit = 0 },
+			expected: errors.New("daily limit must be between 1 and 10000"),
+		},
+		{
+			name: "Malformed ramp-up start date",
+			modify: func(m *mailbox.Mailbox) {
+				m.RampUp = &mailbox.RampUp{StartDate: "01/05/2024", InitialLimit: 5, DailyIncrease: 5}
+			},
+			expected: errors.New("ramp-up start date must be a date formatted as YYYY-MM-DD"),
+		},
+		{
+			name: "Ramp-up initial limit above daily limit",
+			modify: func(m *mailbox.Mailbox) {
+				m.RampUp = &mailbox.RampUp{StartDate: "2024-05-01", InitialLimit: 60, DailyIncrease: 5}
+			},
+			expected: errors.New("ramp-up initial limit must be between 1 and the daily limit"),
+		},
+		{
+			name: "Ramp-up without increase",
+			modify: func(m *mailbox.Mailbox) {
+				m.RampUp = &mailbox.RampUp{StartDate: "2024-05-01", InitialLimit: 5}
+			},
+			expected: errors.New("ramp-up daily increase must be at least 1"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := validMailbox()
+			tc.modify(&m)
+
+			err := m.Normalize().Validate()
+			if err == nil && tc.expected != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.expected)
+			} else if err != nil && tc.expected == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.expected != nil && err.Error() != tc.expected.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestMailbox_LimitOn(t *testing.T) {
+	m := mailbox.Mailbox{
+		DailyLimit: 40,
+		RampUp:     &mailbox.RampUp{StartDate: "2024-05-10", InitialLimit: 10, DailyIncrease: 8},
+	}
+
+	testCases := []struct {
+		t        time.Time
+		expected int
+	}{
+		{time.Date(2024, 5, 9, 23, 59, 0, 0, time.UTC), 0},
+		{time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), 10},
+		{time.Date(2024, 5, 11, 12, 0, 0, 0, time.UTC), 18},
+		// Days are counted in UTC, so this is still 11 May.
+		{time.Date(2024, 5, 12, 1, 0, 0, 0, time.FixedZone("UTC+5", 5*60*60)), 18},
+		{time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), 34},
+		{time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC), 40},
+		{time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 40},
+	}
+
+	for _, tc := range testCases {
+		if limit := m.LimitOn(tc.t); limit != tc.expected {
+			t.Errorf("Expected limit on %s: %d, got: %d", tc.t, tc.expected, limit)
+		}
+	}
+
+	m.RampUp = nil
+	if limit := m.LimitOn(time.Now()); limit != 40 {
+		t.Errorf("Expected the daily limit without a ramp-up, got: %d", limit)
+	}
+}
+
+func TestMailbox_From(t *testing.T) {
+	m := mailbox.Mailbox{Email: "jane@example.com", DisplayName: "Jane Doe"}
+	if from := m.From(); from != `"Jane Doe" <jane@example.com>` {
+		t.Errorf("Unexpected from: %s", from)
+	}
+
+	m.DisplayName = ""
+	if from := m.From(); from != "<jane@example.com>" {
+		t.Errorf("Unexpected from: %s", from)
+	}
+}
+
+func TestService_CreateMailbox(t *testing.T) {
+	testCases := []struct {
+		name     string
+		modify   func(m *mailbox.Mailbox)
+		repoErr  error
+		expected error
+	}{
+		{
+			name:   "Success",
+			modify: func(m *mailbox.Mailbox) {},
+		},
+		{
+			name:     "Validation error",
+			modify:   func(m *mailbox.Mailbox) { m.DailyLimit = -1 },
+			expected: mailbox.ErrMailboxValidation,
+		},
+		{
+			name:     "Missing password",
+			modify:   func(m *mailbox.Mailbox) { m.SMTP.Password = "" },
+			expected: mailbox.ErrMailboxValidation,
+		},
+		{
+			name:     "Duplicate email",
+			modify:   func(m *mailbox.Mailbox) {},
+			repoErr:  mailbox.ErrMailboxExists,
+			expected: mailbox.ErrMailboxExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testdata.MockRepo{
+				CreateMailboxFn: func(ctx context.Context, m mailbox.Mailbox) (mailbox.Mailbox, error) {
+					if m.SMTP.Password != "hunter2" {
+						t.Errorf("Expected the password to be stored, got: %q", m.SMTP.Password)
+					}
+
+					m.ID = 1
+					return m, tc.repoErr
+				},
+				CountSentFn: func(ctx context.Context, ids []int, day time.Time) (map[int]int, error) {
+					return map[int]int{1: 12}, nil
+				},
+			}
+
+			svc := mailbox.NewService(repo, testdata.MockSequenceRepo{})
+
+			m := validMailbox()
+			tc.modify(&m)
+
+			created, err := svc.CreateMailbox(context.Background(), m)
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expected, err)
+			}
+
+			if tc.expected != nil {
+				return
+			}
+
+			if created.ID != 1 || created.SMTP.Password != "" {
+				t.Errorf("Expected the created mailbox without its password, got: %+v", created)
+			}
+
+			if created.Capacity == nil || created.Capacity.Sent != 12 || created.Capacity.Remaining != 38 {
+				t.Errorf("Unexpected capacity: %+v", created.Capacity)
+			}
+		})
+	}
+}
+
+func TestService_GetMailbox(t *testing.T) {
+	repo := testdata.MockRepo{
+		GetMailboxFn: func(ctx context.Context, id int) (mailbox.Mailbox, bool, error) {
+			m := validMailbox()
+			m.ID = id
+			return m, id == 1, nil
+		},
+		CountSentFn: func(ctx context.Context, ids []int, day time.Time) (map[int]int, error) {
+			if !day.Equal(mailbox.Day(time.Now())) {
+				t.Errorf("Expected usage of today, got: %s", day)
+			}
+
+			return map[int]int{1: 60}, nil
+		},
+	}
+
+	svc := mailbox.NewService(repo, testdata.MockSequenceRepo{})
+
+	m, err := svc.GetMailbox(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if m.SMTP.Password != "" || m.Capacity == nil || m.Capacity.Remaining != 0 || m.Capacity.Limit != 50 {
+		t.Errorf("Unexpected mailbox: %+v, %+v", m, m.Capacity)
+	}
+
+	if _, err := svc.GetMailbox(context.Background(), 2); !errors.Is(err, mailbox.ErrMailboxNotFound) {
+		t.Errorf("Expected ErrMailboxNotFound, got: %v", err)
+	}
+}
+
+func TestService_SetSequenceMailboxes(t *testing.T) {
+	testCases := []struct {
+		name        string
+		sequenceID  int
+		ids         []int
+		expectedSet []int
+		expectedErr error
+	}{
+		{
+			name:        "Success",
+			sequenceID:  1,
+			ids:         []int{3, 2, 3},
+			expectedSet: []int{3, 2},
+		},
+		{
+			name:        "Remove all mailboxes",
+			sequenceID:  1,
+			ids:         []int{},
+			expectedSet: []int{},
+		},
+		{
+			name:        "Unknown mailbox",
+			sequenceID:  1,
+			ids:         []int{2, 9},
+			expectedErr: mailbox.ErrMailboxValidation,
+		},
+		{
+			name:        "Invalid mailbox ID",
+			sequenceID:  1,
+			ids:         []int{0},
+			expectedErr: mailbox.ErrMailboxValidation,
+		},
+		{
+			name:        "Sequence not found",
+			sequenceID:  2,
+			ids:         []int{2},
+			expectedErr: sequence.ErrSequenceNotFound,
+		},
+		{
+			name:        "Archived sequence",
+			sequenceID:  3,
+			ids:         []int{2},
+			expectedErr: sequence.ErrSequenceArchived,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var set []int
+			repo := testdata.MockRepo{
+				ExistingMailboxIDsFn: func(ctx context.Context, ids []int) ([]int, error) {
+					existing := []int{}
+					for _, id := range ids {
+						if id == 2 || id == 3 {
+							existing = append(existing, id)
+						}
+					}
+
+					return existing, nil
+				},
+				SetSequenceMailboxesFn: func(ctx context.Context, sequenceID int, ids []int) error {
+					set = ids
+					return nil
+				},
+			}
+
+			sequences := testdata.MockSequenceRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					seq := sequence.Sequence{ID: id}
+					if id == 3 {
+						archivedAt := time.Now()
+						seq.ArchivedAt = &archivedAt
+					}
+
+					return seq, id != 2, nil
+				},
+			}
+
+			svc := mailbox.NewService(repo, sequences)
+
+			err := svc.SetSequenceMailboxes(context.Background(), tc.sequenceID, tc.ids)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if !reflect.DeepEqual(set, tc.expectedSet) {
+				t.Errorf("Expected mailboxes %v to be set, got: %v", tc.expectedSet, set)
+			}
+		})
+	}
+}
+
+func TestService_Reserve(t *testing.T) {
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+
+	pool := []mailbox.Mailbox{
+		{ID: 1, Email: "one@example.com", DailyLimit: 20},
+		{ID: 2, Email: "two@example.com", DailyLimit: 50},
+		{ID: 3, Email: "three@example.com", DailyLimit: 50, RampUp: &mailbox.RampUp{StartDate: "2024-05-15", InitialLimit: 5, DailyIncrease: 5}},
+	}
+
+	testCases := []struct {
+		name       string
+		sequenceID int
+		assignedID int
+		sent       map[int]int
+		// full are the mailboxes another worker used up after they were counted.
+		full        map[int]bool
+		expectedID  int
+		expectedErr error
+	}{
+		{
+			name:       "Most remaining capacity",
+			sequenceID: 1,
+			sent:       map[int]int{2: 45},
+			expectedID: 1,
+		},
+		{
+			name:       "Ramp-up limit",
+			sequenceID: 1,
+			sent:       map[int]int{1: 18, 2: 48},
+			expectedID: 3,
+		},
+		{
+			name:       "Assigned mailbox is sticky",
+			sequenceID: 1,
+			assignedID: 2,
+			sent:       map[int]int{2: 45},
+			expectedID: 2,
+		},
+		{
+			name:        "Assigned mailbox is full",
+			sequenceID:  1,
+			assignedID:  2,
+			full:        map[int]bool{2: true},
+			expectedErr: mailbox.ErrQuotaExceeded,
+		},
+		{
+			name:       "Assigned mailbox was deleted",
+			sequenceID: 1,
+			assignedID: 9,
+			expectedID: 2,
+		},
+		{
+			name:       "Used up by another worker",
+			sequenceID: 1,
+			full:       map[int]bool{2: true},
+			expectedID: 1,
+		},
+		{
+			name:        "All mailboxes full",
+			sequenceID:  1,
+			sent:        map[int]int{1: 20, 2: 50, 3: 5},
+			expectedErr: mailbox.ErrQuotaExceeded,
+		},
+		{
+			name:        "No mailboxes",
+			sequenceID:  2,
+			expectedErr: mailbox.ErrNoMailboxes,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testdata.MockRepo{
+				GetMailboxFn: func(ctx context.Context, id int) (mailbox.Mailbox, bool, error) {
+					for _, m := range pool {
+						if m.ID == id {
+							return m, true, nil
+						}
+					}
+
+					return mailbox.Mailbox{}, false, nil
+				},
+				ListSequenceMailboxesFn: func(ctx context.Context, sequenceID int) ([]mailbox.Mailbox, error) {
+					if sequenceID == 2 {
+						return nil, nil
+					}
+
+					return pool, nil
+				},
+				CountSentFn: func(ctx context.Context, ids []int, day time.Time) (map[int]int, error) {
+					return tc.sent, nil
+				},
+				ReserveFn: func(ctx context.Context, id int, day time.Time, limit int) (bool, error) {
+					if !day.Equal(time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)) {
+						t.Errorf("Unexpected day: %s", day)
+					}
+
+					return !tc.full[id] && tc.sent[id] < limit, nil
+				},
+			}
+
+			svc := mailbox.NewService(repo, testdata.MockSequenceRepo{})
+
+			m, err := svc.Reserve(context.Background(), tc.sequenceID, tc.assignedID, now)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if m.ID != tc.expectedID {
+				t.Errorf("Expected mailbox %d, got: %d", tc.expectedID, m.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package mailbox
+
+import (
+	"github.com/cybre/salesforge-assignment/internal/mail"
+)
+
+// Mailers creates the mailers emails of mailboxes are sent with.
+type Mailers struct {
+	config   mail.Config
+	fallback mail.Mailer
+}
+
+// NewMailers creates mailers for the mail config. The fallback mailer is used for every
+// mailbox unless the config selects the SMTP driver.
+func NewMailers(config mail.Config, fallback mail.Mailer) *Mailers {
+	return &Mailers{
+		config:   config,
+		fallback: fallback,
+	}
+}
+
+// Mailer returns a mailer sending through the SMTP server of the mailbox with the configured
+// timeouts. When emails are logged or captured instead, e.g. during development, the fallback
+// mailer is returned.
+func (m Mailers) Mailer(mb Mailbox) (mail.Mailer, error) {
+	if m.config.Driver != mail.DriverSMTP {
+		return m.fallback, nil
+	}
+
+	config := mb.SMTPConfig()
+	config.ConnectTimeout = m.config.SMTP.ConnectTimeout
+	config.SendTimeout = m.config.SMTP.SendTimeout
+
+	return mail.NewSMTPMailer(config)
+}
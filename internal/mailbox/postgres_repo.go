@@ -0,0 +1,385 @@
+package mailbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres error code for unique constraint violations.
+const uniqueViolation = "23505"
+
+// PostgresRepository is a repository containing mailboxes using Postgres. SMTP passwords are
+// encrypted with the cipher before they are stored.
+type PostgresRepository struct {
+	db     *sqlx.DB
+	cipher *Cipher
+}
+
+// NewPostgresRepository creates a new Postgres repository.
+func NewPostgresRepository(db *sqlx.DB, cipher *Cipher) *PostgresRepository {
+	return &PostgresRepository{
+		db:     db,
+		cipher: cipher,
+	}
+}
+
+const mailboxColumns = `id, email, display_name, smtp_host, smtp_port, smtp_username, smtp_password, smtp_auth, smtp_security, daily_limit, ramp_up, created_at, updated_at`
+
+const createMailboxQuery = `
+INSERT INTO mailbox (email, display_name, smtp_host, smtp_port, smtp_username, smtp_password, smtp_auth, smtp_security, daily_limit, ramp_up)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING ` + mailboxColumns + `;
+`
+
+// CreateMailbox creates a new mailbox and returns it as stored.
+func (r PostgresRepository) CreateMailbox(ctx context.Context, m Mailbox) (Mailbox, error) {
+	password, rampUp, err := r.encode(m)
+	if err != nil {
+		return Mailbox{}, err
+	}
+
+	row := MailboxRow{}
+	if err := r.db.QueryRowxContext(ctx, createMailboxQuery, m.Email, m.DisplayName, m.SMTP.Host, m.SMTP.Port, m.SMTP.Username, password, m.SMTP.Auth, m.SMTP.Security, m.DailyLimit, rampUp).StructScan(&row); err != nil {
+		return Mailbox{}, translateError(err)
+	}
+
+	return row.ToMailbox(r.cipher)
+}
+
+const getMailboxQuery = `
+SELECT ` + mailboxColumns + ` FROM mailbox WHERE id = $1;
+`
+
+// GetMailbox gets a mailbox by ID.
+func (r PostgresRepository) GetMailbox(ctx context.Context, id int) (Mailbox, bool, error) {
+	row := MailboxRow{}
+	if err := r.db.GetContext(ctx, &row, getMailboxQuery, id); err != nil {
+		if err == sql.ErrNoRows {
+			return Mailbox{}, false, nil
+		}
+
+		return Mailbox{}, false, err
+	}
+
+	m, err := row.ToMailbox(r.cipher)
+	if err != nil {
+		return Mailbox{}, false, err
+	}
+
+	return m, true, nil
+}
+
+// updateMailboxQuery keeps the current password when the new one is null.
+const updateMailboxQuery = `
+UPDATE mailbox SET email = $1, display_name = $2, smtp_host = $3, smtp_port = $4, smtp_username = $5,
+    smtp_password = COALESCE($6, smtp_password), smtp_auth = $7, smtp_security = $8, daily_limit = $9, ramp_up = $10, updated_at = NOW()
+WHERE id = $11
+RETURNING ` + mailboxColumns + `;
+`
+
+// UpdateMailbox replaces the fields of a mailbox and returns it as stored. An empty SMTP
+// password keeps the current password.
+func (r PostgresRepository) UpdateMailbox(ctx context.Context, m Mailbox) (Mailbox, bool, error) {
+	password, rampUp, err := r.encode(m)
+	if err != nil {
+		return Mailbox{}, false, err
+	}
+
+	row := MailboxRow{}
+	if err := r.db.QueryRowxContext(ctx, updateMailboxQuery, m.Email, m.DisplayName, m.SMTP.Host, m.SMTP.Port, m.SMTP.Username, password, m.SMTP.Auth, m.SMTP.Security, m.DailyLimit, rampUp, m.ID).StructScan(&row); err != nil {
+		if err == sql.ErrNoRows {
+			return Mailbox{}, false, nil
+		}
+
+		return Mailbox{}, false, translateError(err)
+	}
+
+	updated, err := row.ToMailbox(r.cipher)
+	if err != nil {
+		return Mailbox{}, false, err
+	}
+
+	return updated, true, nil
+}
+
+// encode encrypts the password of a mailbox, which is null when empty, and encodes its ramp-up.
+func (r PostgresRepository) encode(m Mailbox) ([]byte, []byte, error) {
+	var password []byte
+	if m.SMTP.Password != "" {
+		var err error
+		if password, err = r.cipher.Encrypt(m.SMTP.Password); err != nil {
+			return nil, nil, fmt.Errorf("failed to encrypt password: %w", err)
+		}
+	}
+
+	var rampUp []byte
+	if m.RampUp != nil {
+		var err error
+		if rampUp, err = json.Marshal(m.RampUp); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return password, rampUp, nil
+}
+
+const deleteMailboxQuery = `
+DELETE FROM mailbox WHERE id = $1;
+`
+
+// DeleteMailbox deletes a mailbox by ID and reports whether it existed.
+func (r PostgresRepository) DeleteMailbox(ctx context.Context, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, deleteMailboxQuery, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+const listMailboxesQuery = `
+SELECT ` + mailboxColumns + ` FROM mailbox %s ORDER BY id LIMIT %d;
+`
+
+// ListMailboxes lists the mailboxes matching the filter in order of ID.
+func (r PostgresRepository) ListMailboxes(ctx context.Context, filter ListFilter) ([]Mailbox, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Search != "" {
+		pattern := arg("%" + listing.EscapeLike(filter.Search) + "%")
+		conditions = append(conditions, fmt.Sprintf("(email ILIKE %[1]s OR display_name ILIKE %[1]s)", pattern))
+	}
+
+	if filter.AfterID > 0 {
+		conditions = append(conditions, "id > "+arg(filter.AfterID))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows := []MailboxRow{}
+	if err := r.db.SelectContext(ctx, &rows, fmt.Sprintf(listMailboxesQuery, where, filter.Limit), args...); err != nil {
+		return nil, err
+	}
+
+	return r.toMailboxes(rows)
+}
+
+const existingMailboxIDsQuery = `
+SELECT id FROM mailbox WHERE id = ANY($1);
+`
+
+// ExistingMailboxIDs returns the IDs of the given mailboxes that exist.
+func (r PostgresRepository) ExistingMailboxIDs(ctx context.Context, ids []int) ([]int, error) {
+	existing := []int{}
+	if err := r.db.SelectContext(ctx, &existing, existingMailboxIDsQuery, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+const deleteSequenceMailboxesQuery = `
+DELETE FROM sequence_mailbox WHERE sequence_id = $1;
+`
+
+const addSequenceMailboxesQuery = `
+INSERT INTO sequence_mailbox (sequence_id, mailbox_id) SELECT $1, UNNEST($2::INTEGER[]);
+`
+
+// SetSequenceMailboxes replaces the mailboxes of a sequence in a single transaction.
+func (r PostgresRepository) SetSequenceMailboxes(ctx context.Context, sequenceID int, ids []int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if err := func() error {
+		if _, err := tx.ExecContext(ctx, deleteSequenceMailboxesQuery, sequenceID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, addSequenceMailboxesQuery, sequenceID, pq.Array(ids))
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const listSequenceMailboxesQuery = `
+SELECT ` + mailboxColumns + ` FROM mailbox
+WHERE id IN (SELECT mailbox_id FROM sequence_mailbox WHERE sequence_id = $1)
+ORDER BY id;
+`
+
+// ListSequenceMailboxes lists the mailboxes of a sequence in order of ID.
+func (r PostgresRepository) ListSequenceMailboxes(ctx context.Context, sequenceID int) ([]Mailbox, error) {
+	rows := []MailboxRow{}
+	if err := r.db.SelectContext(ctx, &rows, listSequenceMailboxesQuery, sequenceID); err != nil {
+		return nil, err
+	}
+
+	return r.toMailboxes(rows)
+}
+
+const countSentQuery = `
+SELECT mailbox_id, sent FROM mailbox_usage WHERE mailbox_id = ANY($1) AND day = $2;
+`
+
+// CountSent counts the emails sent, or reserved to be sent, from the given mailboxes on a day.
+// Mailboxes that sent nothing are left out.
+func (r PostgresRepository) CountSent(ctx context.Context, ids []int, day time.Time) (map[int]int, error) {
+	rows := []struct {
+		MailboxID int `db:"mailbox_id"`
+		Sent      int `db:"sent"`
+	}{}
+	if err := r.db.SelectContext(ctx, &rows, countSentQuery, pq.Array(ids), day); err != nil {
+		return nil, err
+	}
+
+	sent := make(map[int]int, len(rows))
+	for _, row := range rows {
+		sent[row.MailboxID] = row.Sent
+	}
+
+	return sent, nil
+}
+
+// reserveQuery counts a send against the usage of a mailbox for a day, unless the usage has
+// reached the limit. Concurrent reservations are serialized by the row lock of the upsert, so
+// the limit is never exceeded.
+const reserveQuery = `
+INSERT INTO mailbox_usage (mailbox_id, day, sent) VALUES ($1, $2, 1)
+ON CONFLICT (mailbox_id, day) DO UPDATE SET sent = mailbox_usage.sent + 1 WHERE mailbox_usage.sent < $3
+RETURNING sent;
+`
+
+// Reserve counts a send against the usage of a mailbox for a day and reports whether it was
+// within the limit.
+func (r PostgresRepository) Reserve(ctx context.Context, id int, day time.Time, limit int) (bool, error) {
+	var sent int
+	if err := r.db.QueryRowxContext(ctx, reserveQuery, id, day, limit).Scan(&sent); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+const releaseQuery = `
+UPDATE mailbox_usage SET sent = sent - 1 WHERE mailbox_id = $1 AND day = $2 AND sent > 0;
+`
+
+// Release gives back a send reserved against the usage of a mailbox for a day.
+func (r PostgresRepository) Release(ctx context.Context, id int, day time.Time) error {
+	_, err := r.db.ExecContext(ctx, releaseQuery, id, day)
+	return err
+}
+
+func (r PostgresRepository) toMailboxes(rows []MailboxRow) ([]Mailbox, error) {
+	mailboxes := make([]Mailbox, len(rows))
+	for i, row := range rows {
+		m, err := row.ToMailbox(r.cipher)
+		if err != nil {
+			return nil, err
+		}
+
+		mailboxes[i] = m
+	}
+
+	return mailboxes, nil
+}
+
+// translateError converts constraint violations into domain errors.
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "mailbox_email_key" {
+		return ErrMailboxExists
+	}
+
+	return err
+}
+
+// MailboxRow represents a row of the mailbox table.
+type MailboxRow struct {
+	ID           int       `db:"id"`
+	Email        string    `db:"email"`
+	DisplayName  string    `db:"display_name"`
+	SMTPHost     string    `db:"smtp_host"`
+	SMTPPort     string    `db:"smtp_port"`
+	SMTPUsername string    `db:"smtp_username"`
+	SMTPPassword []byte    `db:"smtp_password"`
+	SMTPAuth     string    `db:"smtp_auth"`
+	SMTPSecurity string    `db:"smtp_security"`
+	DailyLimit   int       `db:"daily_limit"`
+	RampUp       []byte    `db:"ramp_up"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// ToMailbox converts the row to a mailbox domain model, decrypting its password with the cipher.
+func (r MailboxRow) ToMailbox(cipher *Cipher) (Mailbox, error) {
+	m := Mailbox{
+		ID:          r.ID,
+		Email:       r.Email,
+		DisplayName: r.DisplayName,
+		SMTP: SMTP{
+			Host:     r.SMTPHost,
+			Port:     r.SMTPPort,
+			Username: r.SMTPUsername,
+			Auth:     mail.SMTPAuth(r.SMTPAuth),
+			Security: mail.SMTPSecurity(r.SMTPSecurity),
+		},
+		DailyLimit: r.DailyLimit,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+
+	if len(r.SMTPPassword) > 0 {
+		password, err := cipher.Decrypt(r.SMTPPassword)
+		if err != nil {
+			return Mailbox{}, fmt.Errorf("failed to decrypt password of mailbox %d: %w", r.ID, err)
+		}
+
+		m.SMTP.Password = password
+	}
+
+	if len(r.RampUp) > 0 {
+		m.RampUp = &RampUp{}
+		if err := json.Unmarshal(r.RampUp, m.RampUp); err != nil {
+			return Mailbox{}, fmt.Errorf("failed to decode ramp-up of mailbox %d: %w", r.ID, err)
+		}
+	}
+
+	return m, nil
+}
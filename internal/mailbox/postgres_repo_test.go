@@ -0,0 +1,71 @@
+package mailbox_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+)
+
+func TestMailboxRow_ToMailbox(t *testing.T) {
+	cipher, err := mailbox.NewCipher(testKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	password, err := cipher.Encrypt("hunter2")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	now := time.Now()
+	row := mailbox.MailboxRow{
+		ID:           1,
+		Email:        "jane@example.com",
+		DisplayName:  "Jane Doe",
+		SMTPHost:     "smtp.example.com",
+		SMTPPort:     "465",
+		SMTPUsername: "jane",
+		SMTPPassword: password,
+		SMTPAuth:     "login",
+		SMTPSecurity: "tls",
+		DailyLimit:   50,
+		RampUp:       []byte(`{"startDate":"2024-05-01","initialLimit":5,"dailyIncrease":5}`),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	expected := mailbox.Mailbox{
+		ID:          1,
+		Email:       "jane@example.com",
+		DisplayName: "Jane Doe",
+		SMTP: mailbox.SMTP{
+			Host:     "smtp.example.com",
+			Port:     "465",
+			Username: "jane",
+			Password: "hunter2",
+			Auth:     mail.SMTPAuthLogin,
+			Security: mail.SMTPSecurityTLS,
+		},
+		DailyLimit: 50,
+		RampUp:     &mailbox.RampUp{StartDate: "2024-05-01", InitialLimit: 5, DailyIncrease: 5},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	m, err := row.ToMailbox(cipher)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected mailbox: %+v, got: %+v", expected, m)
+	}
+
+	row.SMTPPassword = []byte("not encrypted")
+	if _, err := row.ToMailbox(cipher); err == nil {
+		t.Errorf("Expected an error for a password that cannot be decrypted")
+	}
+}
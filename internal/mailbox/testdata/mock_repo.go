@@ -0,0 +1,75 @@
+package testdata
+
+import (
+	"context"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+type MockRepo struct {
+	CreateMailboxFn         func(ctx context.Context, m mailbox.Mailbox) (mailbox.Mailbox, error)
+	GetMailboxFn            func(ctx context.Context, id int) (mailbox.Mailbox, bool, error)
+	UpdateMailboxFn         func(ctx context.Context, m mailbox.Mailbox) (mailbox.Mailbox, bool, error)
+	DeleteMailboxFn         func(ctx context.Context, id int) (bool, error)
+	ListMailboxesFn         func(ctx context.Context, filter mailbox.ListFilter) ([]mailbox.Mailbox, error)
+	ExistingMailboxIDsFn    func(ctx context.Context, ids []int) ([]int, error)
+	SetSequenceMailboxesFn  func(ctx context.Context, sequenceID int, ids []int) error
+	ListSequenceMailboxesFn func(ctx context.Context, sequenceID int) ([]mailbox.Mailbox, error)
+	CountSentFn             func(ctx context.Context, ids []int, day time.Time) (map[int]int, error)
+	ReserveFn               func(ctx context.Context, id int, day time.Time, limit int) (bool, error)
+	ReleaseFn               func(ctx context.Context, id int, day time.Time) error
+}
+
+func (m MockRepo) CreateMailbox(ctx context.Context, mb mailbox.Mailbox) (mailbox.Mailbox, error) {
+	return m.CreateMailboxFn(ctx, mb)
+}
+
+func (m MockRepo) GetMailbox(ctx context.Context, id int) (mailbox.Mailbox, bool, error) {
+	return m.GetMailboxFn(ctx, id)
+}
+
+func (m MockRepo) UpdateMailbox(ctx context.Context, mb mailbox.Mailbox) (mailbox.Mailbox, bool, error) {
+	return m.UpdateMailboxFn(ctx, mb)
+}
+
+func (m MockRepo) DeleteMailbox(ctx context.Context, id int) (bool, error) {
+	return m.DeleteMailboxFn(ctx, id)
+}
+
+func (m MockRepo) ListMailboxes(ctx context.Context, filter mailbox.ListFilter) ([]mailbox.Mailbox, error) {
+	return m.ListMailboxesFn(ctx, filter)
+}
+
+func (m MockRepo) ExistingMailboxIDs(ctx context.Context, ids []int) ([]int, error) {
+	return m.ExistingMailboxIDsFn(ctx, ids)
+}
+
+func (m MockRepo) SetSequenceMailboxes(ctx context.Context, sequenceID int, ids []int) error {
+	return m.SetSequenceMailboxesFn(ctx, sequenceID, ids)
+}
+
+func (m MockRepo) ListSequenceMailboxes(ctx context.Context, sequenceID int) ([]mailbox.Mailbox, error) {
+	return m.ListSequenceMailboxesFn(ctx, sequenceID)
+}
+
+func (m MockRepo) CountSent(ctx context.Context, ids []int, day time.Time) (map[int]int, error) {
+	return m.CountSentFn(ctx, ids, day)
+}
+
+func (m MockRepo) Reserve(ctx context.Context, id int, day time.Time, limit int) (bool, error) {
+	return m.ReserveFn(ctx, id, day, limit)
+}
+
+func (m MockRepo) Release(ctx context.Context, id int, day time.Time) error {
+	return m.ReleaseFn(ctx, id, day)
+}
+
+type MockSequenceRepo struct {
+	GetSequenceFn func(ctx context.Context, id int) (sequence.Sequence, bool, error)
+}
+
+func (m MockSequenceRepo) GetSequence(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+	return m.GetSequenceFn(ctx, id)
+}
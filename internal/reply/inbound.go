@@ -0,0 +1,87 @@
+package reply
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidMessage is returned when an inbound message is not a valid RFC 5322 message.
+var ErrInvalidMessage = errors.New("message is invalid")
+
+// msgIDPattern matches the message IDs of In-Reply-To and References headers.
+var msgIDPattern = regexp.MustCompile(`<([^<>\s]+)>`)
+
+// Inbound is a parsed inbound message.
+type Inbound struct {
+	// References are the IDs of the messages the message replies to, without angle brackets,
+	// starting with the most recent one.
+	References []string
+	// Automatic is set for messages sent without a person's involvement, such as out of office
+	// replies, delivery status notifications and read receipts.
+	Automatic bool
+}
+
+// ParseMessage parses the headers of a raw RFC 5322 message.
+func ParseMessage(r io.Reader) (Inbound, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return Inbound{}, fmt.Errorf("%w: %s", ErrInvalidMessage, err)
+	}
+
+	return Inbound{
+		References: references(msg.Header),
+		Automatic:  isAutomatic(msg.Header),
+	}, nil
+}
+
+// references returns the IDs of In-Reply-To, followed by those of References from the most
+// recent to the oldest, without duplicates.
+func references(header mail.Header) []string {
+	var (
+		ids  []string
+		seen = map[string]bool{}
+	)
+
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, match := range msgIDPattern.FindAllStringSubmatch(header.Get("In-Reply-To"), -1) {
+		add(match[1])
+	}
+
+	refs := msgIDPattern.FindAllStringSubmatch(header.Get("References"), -1)
+	for i := len(refs) - 1; i >= 0; i-- {
+		add(refs[i][1])
+	}
+
+	return ids
+}
+
+// isAutomatic reports whether the headers mark the message as sent automatically, following
+// RFC 3834 and the headers common auto responders use.
+func isAutomatic(header mail.Header) bool {
+	if value := strings.ToLower(strings.TrimSpace(header.Get("Auto-Submitted"))); value != "" && value != "no" {
+		return true
+	}
+
+	if header.Get("X-Autoreply") != "" || header.Get("X-Autorespond") != "" {
+		return true
+	}
+
+	switch strings.ToLower(strings.TrimSpace(header.Get("Precedence"))) {
+	case "auto_reply", "bulk", "junk", "list":
+		return true
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "multipart/report"
+}
@@ -0,0 +1,81 @@
+package reply_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/reply"
+)
+
+func TestParseMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		expected reply.Inbound
+	}{
+		{
+			name: "Reply",
+			message: "From: Jane Doe <Jane@Example.com>\r\n" +
+				"To: sales@example.com\r\n" +
+				"Subject: Re: Hello\r\n" +
+				"In-Reply-To: <second@example.com>\r\n" +
+				"References: <first@example.com>\r\n <second@example.com>\r\n" +
+				"\r\n" +
+				"Sounds good, let's talk.\r\n",
+			expected: reply.Inbound{
+				References: []string{"second@example.com", "first@example.com"},
+			},
+		},
+		{
+			name:     "References only",
+			message:  "From: jane@example.com\r\nReferences: <first@example.com> <second@example.com>\r\n\r\nHi\r\n",
+			expected: reply.Inbound{References: []string{"second@example.com", "first@example.com"}},
+		},
+		{
+			name:     "Not a reply",
+			message:  "From: jane@example.com\r\nSubject: Hello\r\n\r\nHi\r\n",
+			expected: reply.Inbound{},
+		},
+		{
+			name:     "Out of office",
+			message:  "From: jane@example.com\r\nAuto-Submitted: auto-replied\r\nIn-Reply-To: <first@example.com>\r\n\r\nAway until Monday\r\n",
+			expected: reply.Inbound{References: []string{"first@example.com"}, Automatic: true},
+		},
+		{
+			name:     "Auto responder",
+			message:  "From: jane@example.com\r\nX-Autoreply: yes\r\nIn-Reply-To: <first@example.com>\r\n\r\nAway until Monday\r\n",
+			expected: reply.Inbound{References: []string{"first@example.com"}, Automatic: true},
+		},
+		{
+			name:     "Not auto submitted",
+			message:  "From: jane@example.com\r\nAuto-Submitted: no\r\nIn-Reply-To: <first@example.com>\r\n\r\nHi\r\n",
+			expected: reply.Inbound{References: []string{"first@example.com"}},
+		},
+		{
+			name:     "Delivery status notification",
+			message:  "From: MAILER-DAEMON@mx.example.net\r\nContent-Type: multipart/report; report-type=delivery-status; boundary=b\r\nIn-Reply-To: <first@example.com>\r\n\r\n--b--\r\n",
+			expected: reply.Inbound{References: []string{"first@example.com"}, Automatic: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inbound, err := reply.ParseMessage(strings.NewReader(tc.message))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(inbound, tc.expected) {
+				t.Errorf("Expected message: %+v, got: %+v", tc.expected, inbound)
+			}
+		})
+	}
+}
+
+func TestParseMessage_Invalid(t *testing.T) {
+	if _, err := reply.ParseMessage(strings.NewReader("hello")); !errors.Is(err, reply.ErrInvalidMessage) {
+		t.Errorf("Expected ErrInvalidMessage, got: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package reply
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// PostgresRepository is a repository for replies using Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository creates a new Postgres repository.
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+const findMessageQuery = `
+SELECT id, enrollment_id FROM message WHERE message_id = ANY($1) AND status = 'sent' ORDER BY sent_at DESC LIMIT 1;
+`
+
+// FindMessage finds the most recently sent of the messages with the given Message-ID header
+// values.
+func (r PostgresRepository) FindMessage(ctx context.Context, messageIDs []string) (Message, bool, error) {
+	row := MessageRow{}
+	if err := r.db.GetContext(ctx, &row, findMessageQuery, pq.Array(messageIDs)); err != nil {
+		if err == sql.ErrNoRows {
+			return Message{}, false, nil
+		}
+
+		return Message{}, false, err
+	}
+
+	return row.ToMessage(), true, nil
+}
+
+// recordReplyEventQuery records one reply per enrollment, so a contact replying to several
+// steps counts once in the sequence stats.
+const recordReplyEventQuery = `
+INSERT INTO event (message_id, sequence_id, step_id, type)
+SELECT id, sequence_id, step_id, 'reply' FROM message WHERE id = $1
+AND NOT EXISTS (
+	SELECT 1 FROM event JOIN message AS replied ON replied.id = event.message_id
+	WHERE event.type = 'reply' AND replied.enrollment_id = message.enrollment_id
+);
+`
+
+// stopEnrollmentQuery leaves bounced and unsubscribed enrollments as they are. Finished
+// enrollments are marked as replied too, as the reply is what the sequence was for.
+const stopEnrollmentQuery = `
+UPDATE enrollment SET state = 'replied', next_send_at = NULL, updated_at = NOW()
+WHERE id = (SELECT enrollment_id FROM message WHERE id = $1) AND state IN ('active', 'paused', 'finished');
+`
+
+// RecordReply records a reply to a message for the sequence stats and stops its enrollment as
+// replied in a single transaction. It reports false when a reply of the enrollment was already
+// recorded.
+func (r PostgresRepository) RecordReply(ctx context.Context, messageID int) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	var recorded bool
+	if err := func() error {
+		result, err := tx.ExecContext(ctx, recordReplyEventQuery, messageID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		recorded = affected > 0
+
+		_, err = tx.ExecContext(ctx, stopEnrollmentQuery, messageID)
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return recorded, nil
+}
+
+// MessageRow represents a row of the message table.
+type MessageRow struct {
+	ID           int `db:"id"`
+	EnrollmentID int `db:"enrollment_id"`
+}
+
+// ToMessage converts the row to a message.
+func (r MessageRow) ToMessage() Message {
+	return Message{
+		ID:           r.ID,
+		EnrollmentID: r.EnrollmentID,
+	}
+}
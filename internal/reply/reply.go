@@ -0,0 +1,101 @@
+// Package reply detects replies to sent emails and stops the sequence for contacts who reply.
+package reply
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Message is a sent message a reply is about.
+type Message struct {
+	ID           int
+	EnrollmentID int
+}
+
+// Outcome is what processing an inbound message resulted in.
+type Outcome string
+
+const (
+	// OutcomeReplied means the enrollment was stopped as replied.
+	OutcomeReplied Outcome = "replied"
+	// OutcomeDuplicate means a reply of the enrollment was already processed.
+	OutcomeDuplicate Outcome = "duplicate"
+	// OutcomeUnmatched means the message is not a reply to a message we sent.
+	OutcomeUnmatched Outcome = "unmatched"
+	// OutcomeIgnored means the message was sent automatically, e.g. as an out of office reply.
+	OutcomeIgnored Outcome = "ignored"
+)
+
+// Result is the result of processing an inbound message.
+type Result struct {
+	Outcome      Outcome `json:"outcome"`
+	MessageID    int     `json:"messageId,omitempty"`
+	EnrollmentID int     `json:"enrollmentId,omitempty"`
+}
+
+// Repository represents the data layer for replies.
+type Repository interface {
+	// FindMessage finds the most recently sent of the messages with the given Message-ID
+	// header values.
+	FindMessage(ctx context.Context, messageIDs []string) (Message, bool, error)
+	// RecordReply records a reply to a message and stops its enrollment as replied. It reports
+	// false when a reply of the enrollment was already recorded.
+	RecordReply(ctx context.Context, messageID int) (bool, error)
+}
+
+// Service contains the business logic for replies.
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new reply service.
+func NewService(repo Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// ProcessMessage processes a raw inbound message. Replies to sent messages, matched through
+// their In-Reply-To and References headers, stop the enrollment of the message so none of the
+// remaining steps are sent. Processing the same reply again has no further effect.
+func (s Service) ProcessMessage(ctx context.Context, r io.Reader) (Result, error) {
+	inbound, err := ParseMessage(r)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if inbound.Automatic {
+		return Result{Outcome: OutcomeIgnored}, nil
+	}
+
+	if len(inbound.References) == 0 {
+		return Result{Outcome: OutcomeUnmatched}, nil
+	}
+
+	msg, exists, err := s.repo.FindMessage(ctx, inbound.References)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to find message: %w", err)
+	}
+
+	if !exists {
+		return Result{Outcome: OutcomeUnmatched}, nil
+	}
+
+	result := Result{
+		Outcome:      OutcomeReplied,
+		MessageID:    msg.ID,
+		EnrollmentID: msg.EnrollmentID,
+	}
+
+	recorded, err := s.repo.RecordReply(ctx, msg.ID)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to record reply: %w", err)
+	}
+
+	if !recorded {
+		result.Outcome = OutcomeDuplicate
+	}
+
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package reply_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/reply"
+	"github.com/cybre/salesforge-assignment/internal/reply/testdata"
+)
+
+func TestService_ProcessMessage(t *testing.T) {
+	const replyMessage = "From: jane@example.com\r\nIn-Reply-To: <second@example.com>\r\nReferences: <first@example.com> <second@example.com>\r\n\r\nLet's talk\r\n"
+
+	testCases := []struct {
+		name               string
+		message            string
+		exists             bool
+		duplicate          bool
+		expected           reply.Result
+		expectedMessageIDs []string
+		expectedRecorded   bool
+	}{
+		{
+			name:               "Reply",
+			message:            replyMessage,
+			exists:             true,
+			expected:           reply.Result{Outcome: reply.OutcomeReplied, MessageID: 3, EnrollmentID: 2},
+			expectedMessageIDs: []string{"second@example.com", "first@example.com"},
+			expectedRecorded:   true,
+		},
+		{
+			name:               "Already processed",
+			message:            replyMessage,
+			exists:             true,
+			duplicate:          true,
+			expected:           reply.Result{Outcome: reply.OutcomeDuplicate, MessageID: 3, EnrollmentID: 2},
+			expectedMessageIDs: []string{"second@example.com", "first@example.com"},
+			expectedRecorded:   true,
+		},
+		{
+			name:               "Unknown message",
+			message:            replyMessage,
+			expected:           reply.Result{Outcome: reply.OutcomeUnmatched},
+			expectedMessageIDs: []string{"second@example.com", "first@example.com"},
+		},
+		{
+			name:     "Not a reply",
+			message:  "From: jane@example.com\r\n\r\nHi\r\n",
+			expected: reply.Result{Outcome: reply.OutcomeUnmatched},
+		},
+		{
+			name:     "Out of office",
+			message:  "From: jane@example.com\r\nAuto-Submitted: auto-replied\r\nIn-Reply-To: <second@example.com>\r\n\r\nAway\r\n",
+			expected: reply.Result{Outcome: reply.OutcomeIgnored},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				messageIDs []string
+				recorded   bool
+			)
+
+			repo := testdata.MockRepo{
+				FindMessageFn: func(ctx context.Context, ids []string) (reply.Message, bool, error) {
+					messageIDs = ids
+					return reply.Message{ID: 3, EnrollmentID: 2}, tc.exists, nil
+				},
+				RecordReplyFn: func(ctx context.Context, messageID int) (bool, error) {
+					recorded = true
+					return !tc.duplicate, nil
+				},
+			}
+
+			svc := reply.NewService(repo)
+
+			result, err := svc.ProcessMessage(context.Background(), strings.NewReader(tc.message))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if result != tc.expected {
+				t.Errorf("Expected result: %+v, got: %+v", tc.expected, result)
+			}
+
+			if !reflect.DeepEqual(messageIDs, tc.expectedMessageIDs) {
+				t.Errorf("Expected message IDs: %v, got: %v", tc.expectedMessageIDs, messageIDs)
+			}
+
+			if recorded != tc.expectedRecorded {
+				t.Errorf("Expected reply to be recorded: %t, got: %t", tc.expectedRecorded, recorded)
+			}
+		})
+	}
+}
+
+func TestService_ProcessMessage_Invalid(t *testing.T) {
+	svc := reply.NewService(testdata.MockRepo{})
+
+	if _, err := svc.ProcessMessage(context.Background(), strings.NewReader("hello")); !errors.Is(err, reply.ErrInvalidMessage) {
+		t.Errorf("Expected ErrInvalidMessage, got: %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/reply"
+)
+
+type MockRepo struct {
+	FindMessageFn func(ctx context.Context, messageIDs []string) (reply.Message, bool, error)
+	RecordReplyFn func(ctx context.Context, messageID int) (bool, error)
+}
+
+func (m MockRepo) FindMessage(ctx context.Context, messageIDs []string) (reply.Message, bool, error) {
+	return m.FindMessageFn(ctx, messageIDs)
+}
+
+func (m MockRepo) RecordReply(ctx context.Context, messageID int) (bool, error) {
+	return m.RecordReplyFn(ctx, messageID)
+}
@@ -0,0 +1,235 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresRepository is a repository for the scheduler using Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository creates a new Postgres repository.
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+// claimDueQuery locks due enrollments, skipping those already locked by another worker, and
+// moves their next send time to the end of the lease so no other worker claims them meanwhile.
+const claimDueQuery = `
+UPDATE enrollment SET next_send_at = $2
+WHERE id IN (
+	SELECT enrollment.id FROM enrollment
+	JOIN sequence ON sequence.id = enrollment.sequence_id
+	WHERE enrollment.state = 'active' AND enrollment.next_send_at <= $1 AND sequence.archived_at IS NULL
+	ORDER BY enrollment.next_send_at
+	LIMIT $3
+	FOR UPDATE OF enrollment SKIP LOCKED
+)
+RETURNING id, sequence_id, contact_id, current_step, mailbox_id, version;
+`
+
+// ClaimDue claims up to limit active enrollments due at the given time until the lease ends.
+func (r PostgresRepository) ClaimDue(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]Claim, error) {
+	rows := []ClaimRow{}
+	if err := r.db.SelectContext(ctx, &rows, claimDueQuery, now, leaseUntil, limit); err != nil {
+		return nil, err
+	}
+
+	claims := make([]Claim, len(rows))
+	for i, row := range rows {
+		claims[i] = row.ToClaim()
+	}
+
+	return claims, nil
+}
+
+const createMessageQuery = `
+INSERT INTO message (enrollment_id, sequence_id, step_id, contact_id, message_id, subject, status, mailbox_id, variant_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id;
+`
+
+// CreateMessage creates a pending message and returns its ID.
+func (r PostgresRepository) CreateMessage(ctx context.Context, msg Message) (int, error) {
+	var id int
+	if err := r.db.QueryRowxContext(ctx, createMessageQuery, msg.EnrollmentID, msg.SequenceID, msg.StepID, msg.ContactID, msg.MessageID, msg.Subject, MessagePending, msg.MailboxID, msg.VariantID).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+const completeMessageQuery = `
+UPDATE message SET status = $1, error = $2, sent_at = $3 WHERE id = $4;
+`
+
+const recordSentEventQuery = `
+INSERT INTO event (message_id, sequence_id, step_id, type, created_at) SELECT id, sequence_id, step_id, 'sent', sent_at FROM message WHERE id = $1;
+`
+
+const countFailedMessagesQuery = `
+SELECT COUNT(*) FROM message WHERE enrollment_id = $1 AND step_id = $2 AND status = 'failed';
+`
+
+// CountFailedMessages counts the messages of a step of an enrollment that failed to send.
+func (r PostgresRepository) CountFailedMessages(ctx context.Context, enrollmentID int, stepID int) (int, error) {
+	var count int
+	if err := r.db.GetContext(ctx, &count, countFailedMessagesQuery, enrollmentID, stepID); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// advanceEnrollmentQuery only updates active enrollments, so an enrollment paused or
+// otherwise stopped while its step was being sent keeps its state.
+const advanceEnrollmentQuery = `
+UPDATE enrollment SET state = $1, current_step = $2, next_send_at = $3, mailbox_id = COALESCE($5, mailbox_id), updated_at = NOW() WHERE id = $4 AND state = 'active';
+`
+
+// CompleteMessage sets the final status of a message, records sent messages for the sequence
+// stats and advances the enrollment in a single transaction.
+func (r PostgresRepository) CompleteMessage(ctx context.Context, messageID int, status MessageStatus, sendErr string, advance Advance) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	var sentAt *time.Time
+	if status == MessageSent {
+		now := time.Now()
+		sentAt = &now
+	}
+
+	if err := func() error {
+		if _, err := tx.ExecContext(ctx, completeMessageQuery, status, sendErr, sentAt, messageID); err != nil {
+			return err
+		}
+
+		if status == MessageSent {
+			if _, err := tx.ExecContext(ctx, recordSentEventQuery, messageID); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, advanceEnrollmentQuery, advance.State, advance.CurrentStep, advance.NextSendAt, advance.EnrollmentID, advance.MailboxID)
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AdvanceEnrollment advances an enrollment without sending a message.
+func (r PostgresRepository) AdvanceEnrollment(ctx context.Context, advance Advance) error {
+	_, err := r.db.ExecContext(ctx, advanceEnrollmentQuery, advance.State, advance.CurrentStep, advance.NextSendAt, advance.EnrollmentID, advance.MailboxID)
+	return err
+}
+
+const engagedQuery = `
+SELECT EXISTS (
+SELECT 1 FROM event
+WHERE message_id = (SELECT id FROM message WHERE enrollment_id = $1 AND status = 'sent' ORDER BY sent_at DESC, id DESC LIMIT 1)
+AND type = $2 AND NOT bot
+);
+`
+
+// Engaged reports whether the last email sent for the enrollment has an event of the type,
+// leaving out bot events. It reports false when no email was sent yet.
+func (r PostgresRepository) Engaged(ctx context.Context, enrollmentID int, eventType tracking.EventType) (bool, error) {
+	var engaged bool
+	if err := r.db.GetContext(ctx, &engaged, engagedQuery, enrollmentID, eventType); err != nil {
+		return false, err
+	}
+
+	return engaged, nil
+}
+
+const createTaskQuery = `
+INSERT INTO task (enrollment_id, sequence_id, step_id, contact_id, kind, assignee, instructions, due_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (enrollment_id, step_id) DO NOTHING;
+`
+
+// lockTaskQuery locks the task, so closing it waits until the enrollment is left waiting for it.
+const lockTaskQuery = `
+SELECT closed_at FROM task WHERE enrollment_id = $1 AND step_id = $2 FOR UPDATE;
+`
+
+// AwaitTask creates the task unless the enrollment already has one for the step, and advances
+// the enrollment while the task is open in a single transaction. A task closed meanwhile makes
+// the enrollment due again once the transaction commits, so it is never left waiting.
+func (r PostgresRepository) AwaitTask(ctx context.Context, task Task, wait Advance) (*time.Time, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	var closedAt sql.NullTime
+	if err := func() error {
+		if _, err := tx.ExecContext(ctx, createTaskQuery, task.EnrollmentID, task.SequenceID, task.StepID, task.ContactID, task.Kind, task.Assignee, task.Instructions, task.DueAt); err != nil {
+			return err
+		}
+
+		if err := tx.GetContext(ctx, &closedAt, lockTaskQuery, task.EnrollmentID, task.StepID); err != nil {
+			return err
+		}
+
+		if closedAt.Valid {
+			return nil
+		}
+
+		_, err := tx.ExecContext(ctx, advanceEnrollmentQuery, wait.State, wait.CurrentStep, wait.NextSendAt, wait.EnrollmentID, wait.MailboxID)
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if !closedAt.Valid {
+		return nil, nil
+	}
+
+	return &closedAt.Time, nil
+}
+
+// ClaimRow represents an enrollment claimed by the scheduler.
+type ClaimRow struct {
+	ID          int  `db:"id"`
+	SequenceID  int  `db:"sequence_id"`
+	ContactID   int  `db:"contact_id"`
+	CurrentStep int  `db:"current_step"`
+	MailboxID   *int `db:"mailbox_id"`
+	Version     *int `db:"version"`
+}
+
+// ToClaim converts the row to a claim.
+func (r ClaimRow) ToClaim() Claim {
+	c := Claim{
+		EnrollmentID: r.ID,
+		SequenceID:   r.SequenceID,
+		ContactID:    r.ContactID,
+		CurrentStep:  r.CurrentStep,
+	}
+
+	if r.MailboxID != nil {
+		c.MailboxID = *r.MailboxID
+	}
+
+	if r.Version != nil {
+		c.Version = *r.Version
+	}
+
+	return c
+}
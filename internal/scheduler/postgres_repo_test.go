@@ -0,0 +1,32 @@
+package scheduler_test
+
+import (
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+)
+
+func TestClaimRow_ToClaim(t *testing.T) {
+	row := scheduler.ClaimRow{ID: 1, SequenceID: 2, ContactID: 3, CurrentStep: 4}
+
+	expected := scheduler.Claim{EnrollmentID: 1, SequenceID: 2, ContactID: 3, CurrentStep: 4}
+	if c := row.ToClaim(); c != expected {
+		t.Errorf("Expected claim: %+v, got: %+v", expected, c)
+	}
+
+	mailboxID := 5
+	row.MailboxID = &mailboxID
+
+	expected.MailboxID = 5
+	if c := row.ToClaim(); c != expected {
+		t.Errorf("Expected claim: %+v, got: %+v", expected, c)
+	}
+
+	version := 2
+	row.Version = &version
+
+	expected.Version = 2
+	if c := row.ToClaim(); c != expected {
+		t.Errorf("Expected claim: %+v, got: %+v", expected, c)
+	}
+}
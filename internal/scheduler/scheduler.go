@@ -0,0 +1,659 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/compose"
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	"github.com/cybre/salesforge-assignment/internal/templating"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+	"github.com/cybre/salesforge-assignment/pkg/logging"
+)
+
+const (
+	DefaultInterval        = 10 * time.Second
+	DefaultBatchSize       = 50
+	DefaultLease           = 5 * time.Minute
+	DefaultRetryDelay      = 15 * time.Minute
+	DefaultMaxSendAttempts = 5
+)
+
+// Config contains the settings of the scheduler.
+type Config struct {
+	// From is the sender address of emails of sequences without mailboxes.
+	From string
+	// Interval is how long to wait between polls when no more enrollments are due.
+	Interval time.Duration
+	// BatchSize is the largest number of enrollments claimed per poll.
+	BatchSize int
+	// Lease is how long a claimed enrollment is hidden from other workers. Enrollments whose
+	// send is not completed within the lease, e.g. because the worker crashed, are claimed again.
+	Lease time.Duration
+	// RetryDelay is how long to wait before retrying a failed send.
+	RetryDelay time.Duration
+	// MaxSendAttempts is how many times sending a step to a contact is attempted before the
+	// enrollment is paused.
+	MaxSendAttempts int
+}
+
+// WithDefaults returns a copy of the config with unset options replaced by their defaults.
+func (c Config) WithDefaults() Config {
+	if c.Interval == 0 {
+		c.Interval = DefaultInterval
+	}
+
+	if c.BatchSize == 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+
+	if c.Lease == 0 {
+		c.Lease = DefaultLease
+	}
+
+	if c.RetryDelay == 0 {
+		c.RetryDelay = DefaultRetryDelay
+	}
+
+	if c.MaxSendAttempts == 0 {
+		c.MaxSendAttempts = DefaultMaxSendAttempts
+	}
+
+	return c
+}
+
+// Claim is a due enrollment claimed by a worker.
+type Claim struct {
+	EnrollmentID int
+	SequenceID   int
+	ContactID    int
+	CurrentStep  int
+	// MailboxID is the mailbox the contact was last emailed from, or zero if none.
+	MailboxID int
+	// Version is the version of the sequence the enrollment is pinned to, or zero when it is
+	// sent the draft steps.
+	Version int
+}
+
+// MessageStatus is the delivery status of a message.
+type MessageStatus string
+
+const (
+	MessagePending MessageStatus = "pending"
+	MessageSent    MessageStatus = "sent"
+	MessageFailed  MessageStatus = "failed"
+)
+
+// Message is the record of an email sent, or attempted, for a step of an enrollment.
+type Message struct {
+	EnrollmentID int
+	SequenceID   int
+	StepID       int
+	ContactID    int
+	// MessageID is the value of the Message-ID header of the email.
+	MessageID string
+	Subject   string
+	// MailboxID is the mailbox the email is sent from, or nil for the default sender.
+	MailboxID *int
+	// VariantID is the variant of the step that is sent, or nil when the step has no variants.
+	VariantID *int
+}
+
+// Task is the task created for a manual step of an enrollment.
+type Task struct {
+	EnrollmentID int
+	SequenceID   int
+	StepID       int
+	ContactID    int
+	Kind         sequence.StepKind
+	Assignee     string
+	// Instructions are the content of the step rendered for the contact.
+	Instructions string
+	DueAt        time.Time
+}
+
+// Advance is the progress of an enrollment after one of its steps was processed.
+type Advance struct {
+	EnrollmentID int
+	State        enrollment.State
+	CurrentStep  int
+	NextSendAt   *time.Time
+	// MailboxID assigns the mailbox the contact was emailed from. The assigned mailbox is kept
+	// when it is nil.
+	MailboxID *int
+}
+
+// Repository represents the data layer for the scheduler.
+type Repository interface {
+	ClaimDue(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]Claim, error)
+	CreateMessage(ctx context.Context, msg Message) (int, error)
+	CompleteMessage(ctx context.Context, messageID int, status MessageStatus, sendErr string, advance Advance) error
+	// CountFailedMessages counts the messages of a step of an enrollment that failed to send.
+	CountFailedMessages(ctx context.Context, enrollmentID int, stepID int) (int, error)
+	AdvanceEnrollment(ctx context.Context, advance Advance) error
+	// Engaged reports whether the last email sent for the enrollment has an event of the type.
+	Engaged(ctx context.Context, enrollmentID int, eventType tracking.EventType) (bool, error)
+	// AwaitTask creates the task unless the enrollment already has one for the step. While the
+	// task is open, the enrollment is advanced with the given advance. It returns when the task
+	// was closed, or nil while it is open.
+	AwaitTask(ctx context.Context, task Task, wait Advance) (*time.Time, error)
+}
+
+// SequenceRepository gets the sequences of claimed enrollments.
+type SequenceRepository interface {
+	GetSequence(ctx context.Context, id int) (sequence.Sequence, bool, error)
+	// GetSequenceVersion gets a sequence with the steps of the version instead of the draft.
+	GetSequenceVersion(ctx context.Context, id int, version int) (sequence.Sequence, bool, error)
+}
+
+// ContactRepository gets the contacts of claimed enrollments.
+type ContactRepository interface {
+	GetContact(ctx context.Context, id int) (contact.Contact, bool, error)
+}
+
+// Tracker adds engagement tracking and unsubscribe links to the HTML of emails.
+type Tracker interface {
+	TrackOpens(html string, messageID int) string
+	TrackClicks(html string, messageID int) string
+	UnsubscribeURL(messageID int) string
+}
+
+// SuppressionList finds why an email address must not be emailed.
+type SuppressionList interface {
+	FindSuppression(ctx context.Context, email string) (suppression.Suppression, bool, error)
+}
+
+// MailboxPool reserves the mailboxes emails of sequences are sent from.
+type MailboxPool interface {
+	Reserve(ctx context.Context, sequenceID int, assignedID int, now time.Time) (mailbox.Mailbox, error)
+	Release(ctx context.Context, mailboxID int, now time.Time) error
+}
+
+// MailboxMailers creates the mailers emails of mailboxes are sent with.
+type MailboxMailers interface {
+	Mailer(m mailbox.Mailbox) (mail.Mailer, error)
+}
+
+// Scheduler sends the steps of enrollments when they are due.
+type Scheduler struct {
+	repo         Repository
+	sequences    SequenceRepository
+	contacts     ContactRepository
+	mailer       mail.Mailer
+	tracker      Tracker
+	suppressions SuppressionList
+	mailboxes    MailboxPool
+	mailers      MailboxMailers
+	config       Config
+}
+
+// Option configures optional dependencies of the scheduler.
+type Option func(*Scheduler)
+
+// WithTracker sets the tracker used for sequences with open or click tracking enabled.
+func WithTracker(tracker Tracker) Option {
+	return func(s *Scheduler) {
+		s.tracker = tracker
+	}
+}
+
+// WithSuppressionList sets the suppression list checked before every send. Enrollments of
+// suppressed contacts are stopped instead of sent their step.
+func WithSuppressionList(suppressions SuppressionList) Option {
+	return func(s *Scheduler) {
+		s.suppressions = suppressions
+	}
+}
+
+// WithMailboxes sets the pool of mailboxes emails of sequences are sent from, within the daily
+// limits of the mailboxes. Sequences without mailboxes are sent from the configured sender.
+func WithMailboxes(mailboxes MailboxPool, mailers MailboxMailers) Option {
+	return func(s *Scheduler) {
+		s.mailboxes = mailboxes
+		s.mailers = mailers
+	}
+}
+
+// NewScheduler creates a new scheduler.
+func NewScheduler(repo Repository, sequences SequenceRepository, contacts ContactRepository, mailer mail.Mailer, config Config, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		repo:      repo,
+		sequences: sequences,
+		contacts:  contacts,
+		mailer:    mailer,
+		config:    config.WithDefaults(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Run processes due enrollments until the context is done. Polling continues right away
+// after a full batch and waits for the configured interval otherwise.
+func (s Scheduler) Run(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+
+	for {
+		wait := s.config.Interval
+
+		processed, err := s.ProcessDue(ctx)
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "SCHEDULER_ERROR", slog.String("err", err.Error()))
+		} else if processed == s.config.BatchSize {
+			wait = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// ProcessDue claims a batch of due enrollments and sends their current step. It returns
+// the number of claimed enrollments. Enrollments that fail to be processed are retried
+// once their lease expires.
+func (s Scheduler) ProcessDue(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	claims, err := s.repo.ClaimDue(ctx, now, now.Add(s.config.Lease), s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due enrollments: %w", err)
+	}
+
+	logger := logging.FromContext(ctx)
+	sequences := map[sequenceKey]sequence.Sequence{}
+	for _, claim := range claims {
+		if err := s.process(ctx, claim, sequences); err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "SCHEDULER_ERROR",
+				slog.Int("enrollment_id", claim.EnrollmentID),
+				slog.String("err", err.Error()),
+			)
+		}
+	}
+
+	return len(claims), nil
+}
+
+// sequenceKey identifies a version of a sequence in the cache of a batch.
+type sequenceKey struct {
+	id      int
+	version int
+}
+
+// process sends the current step of a claimed enrollment and advances the enrollment.
+// Sequences are cached in the given map for the rest of the batch.
+func (s Scheduler) process(ctx context.Context, claim Claim, sequences map[sequenceKey]sequence.Sequence) error {
+	key := sequenceKey{id: claim.SequenceID, version: claim.Version}
+	seq, ok := sequences[key]
+	if !ok {
+		var exists bool
+		var err error
+		if claim.Version > 0 {
+			seq, exists, err = s.sequences.GetSequenceVersion(ctx, claim.SequenceID, claim.Version)
+		} else {
+			seq, exists, err = s.sequences.GetSequence(ctx, claim.SequenceID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get sequence: %w", err)
+		}
+
+		// Deleting the sequence deletes its enrollments and versions as well.
+		if !exists {
+			return nil
+		}
+
+		sequences[key] = seq
+	}
+
+	// Draft steps can be deleted after the contact was enrolled.
+	if claim.CurrentStep >= len(seq.Steps) {
+		return s.repo.AdvanceEnrollment(ctx, Advance{
+			EnrollmentID: claim.EnrollmentID,
+			State:        enrollment.StateFinished,
+			CurrentStep:  claim.CurrentStep,
+		})
+	}
+
+	c, exists, err := s.contacts.GetContact(ctx, claim.ContactID)
+	if err != nil {
+		return fmt.Errorf("failed to get contact: %w", err)
+	}
+
+	// Deleting the contact deletes its enrollments as well.
+	if !exists {
+		return nil
+	}
+
+	if s.suppressions != nil {
+		found, suppressed, err := s.suppressions.FindSuppression(ctx, c.Email)
+		if err != nil {
+			return fmt.Errorf("failed to check suppression list: %w", err)
+		}
+
+		if suppressed {
+			state := enrollment.StateUnsubscribed
+			if found.Reason == suppression.ReasonBounce {
+				state = enrollment.StateBounced
+			}
+
+			return s.repo.AdvanceEnrollment(ctx, Advance{
+				EnrollmentID: claim.EnrollmentID,
+				State:        state,
+				CurrentStep:  claim.CurrentStep,
+			})
+		}
+	}
+
+	if step := seq.Steps[claim.CurrentStep]; step.Kind == sequence.StepKindBranch {
+		return s.branch(ctx, claim, seq, c, *step.Branch)
+	} else if step.IsManual() {
+		return s.manual(ctx, claim, seq, c, step)
+	}
+
+	// The step may have become due outside the sending window of the contact, e.g. after a
+	// retry or a change of the schedule, so it is held back until the window opens.
+	now := time.Now()
+	if sendAt := seq.NextSendTime(now, c.TimeZone); sendAt.After(now) {
+		return s.repo.AdvanceEnrollment(ctx, Advance{
+			EnrollmentID: claim.EnrollmentID,
+			State:        enrollment.StateActive,
+			CurrentStep:  claim.CurrentStep,
+			NextSendAt:   &sendAt,
+		})
+	}
+
+	from, mailer := s.config.From, s.mailer
+	var mailboxID *int
+	if s.mailboxes != nil {
+		m, err := s.mailboxes.Reserve(ctx, seq.ID, claim.MailboxID, now)
+		switch {
+		case errors.Is(err, mailbox.ErrNoMailboxes):
+		case errors.Is(err, mailbox.ErrQuotaExceeded):
+			// Limits are reset at midnight UTC, so the step is held back until the window of the
+			// contact opens on the next day.
+			sendAt := seq.NextSendTime(mailbox.NextDay(now), c.TimeZone)
+			return s.repo.AdvanceEnrollment(ctx, Advance{
+				EnrollmentID: claim.EnrollmentID,
+				State:        enrollment.StateActive,
+				CurrentStep:  claim.CurrentStep,
+				NextSendAt:   &sendAt,
+			})
+		case err != nil:
+			return fmt.Errorf("failed to reserve mailbox: %w", err)
+		default:
+			mailer, err = s.mailers.Mailer(m)
+			if err != nil {
+				s.release(ctx, m.ID, now)
+				return fmt.Errorf("failed to create mailer for mailbox %d: %w", m.ID, err)
+			}
+
+			from, mailboxID = m.From(), &m.ID
+		}
+	}
+
+	step, variantID := seq.Steps[claim.CurrentStep].ForEnrollment(claim.EnrollmentID)
+	rendered, renderErr := renderEmail(step, c.Fields())
+	if renderErr == nil && len(rendered.MissingFields) > 0 {
+		renderErr = fmt.Errorf("contact has no value for merge fields %s", strings.Join(rendered.MissingFields, ", "))
+	}
+
+	// Messages that could not be rendered are stored with the subject of the step.
+	subject := step.Subject
+	if renderErr == nil {
+		subject = rendered.Subject
+	}
+
+	msg := Message{
+		EnrollmentID: claim.EnrollmentID,
+		SequenceID:   seq.ID,
+		StepID:       step.ID,
+		ContactID:    c.ID,
+		MessageID:    compose.NewMessageID(compose.Domain(from)),
+		Subject:      subject,
+		MailboxID:    mailboxID,
+		VariantID:    variantID,
+	}
+
+	messageID, err := s.repo.CreateMessage(ctx, msg)
+	if err != nil {
+		if mailboxID != nil {
+			s.release(ctx, *mailboxID, now)
+		}
+
+		return fmt.Errorf("failed to create message: %w", err)
+	}
+
+	// A step that cannot be rendered for the contact will not render on a retry either, so
+	// the enrollment is paused until the contact or step is fixed.
+	if renderErr != nil {
+		if mailboxID != nil {
+			s.release(ctx, *mailboxID, now)
+		}
+
+		return s.repo.CompleteMessage(ctx, messageID, MessageFailed, renderErr.Error(), Advance{
+			EnrollmentID: claim.EnrollmentID,
+			State:        enrollment.StatePaused,
+			CurrentStep:  claim.CurrentStep,
+		})
+	}
+
+	email := mail.Message{
+		ID:        messageID,
+		MessageID: msg.MessageID,
+		From:      from,
+		To:        c.Email,
+		Subject:   rendered.Subject,
+	}
+
+	if s.tracker != nil {
+		if seq.ClickTracking {
+			rendered.Content = s.tracker.TrackClicks(rendered.Content, messageID)
+		}
+
+		// The unsubscribe link is added after rewriting links, so it is never tracked.
+		unsubscribeURL := s.tracker.UnsubscribeURL(messageID)
+		rendered.Content = compose.AppendToBody(rendered.Content, unsubscribeFooter(unsubscribeURL))
+		email.ListUnsubscribe = []string{unsubscribeURL}
+		email.OneClickUnsubscribe = true
+
+		if seq.OpenTracking {
+			rendered.Content = s.tracker.TrackOpens(rendered.Content, messageID)
+		}
+	}
+
+	email.HTML = rendered.Content
+	err = mailer.Send(ctx, email)
+	if err != nil {
+		if mailboxID != nil {
+			s.release(ctx, *mailboxID, now)
+		}
+
+		// A send that would keep failing pauses the enrollment, like a step that cannot be
+		// rendered, instead of retrying it for good.
+		if s.giveUp(ctx, claim, step.ID, err) {
+			return s.repo.CompleteMessage(ctx, messageID, MessageFailed, err.Error(), Advance{
+				EnrollmentID: claim.EnrollmentID,
+				State:        enrollment.StatePaused,
+				CurrentStep:  claim.CurrentStep,
+			})
+		}
+
+		retryAt := time.Now().Add(s.config.RetryDelay)
+		return s.repo.CompleteMessage(ctx, messageID, MessageFailed, err.Error(), Advance{
+			EnrollmentID: claim.EnrollmentID,
+			State:        enrollment.StateActive,
+			CurrentStep:  claim.CurrentStep,
+			NextSendAt:   &retryAt,
+		})
+	}
+
+	advance := nextAdvance(claim, seq, c, time.Now())
+	advance.MailboxID = mailboxID
+	return s.repo.CompleteMessage(ctx, messageID, MessageSent, "", advance)
+}
+
+// conditionEvents are the events meeting the conditions of branches.
+var conditionEvents = map[sequence.BranchCondition]tracking.EventType{
+	sequence.BranchOpened:  tracking.EventOpen,
+	sequence.BranchClicked: tracking.EventClick,
+}
+
+// branch advances an enrollment at a branch step to the step the branch continues with, which
+// is scheduled counting from now, or finishes the enrollment when the branch ends it.
+func (s Scheduler) branch(ctx context.Context, claim Claim, seq sequence.Sequence, c contact.Contact, branch sequence.Branch) error {
+	engaged, err := s.repo.Engaged(ctx, claim.EnrollmentID, conditionEvents[branch.Condition])
+	if err != nil {
+		return fmt.Errorf("failed to check engagement: %w", err)
+	}
+
+	target := branch.Target(engaged)
+	if target == nil || *target >= len(seq.Steps) {
+		return s.repo.AdvanceEnrollment(ctx, Advance{
+			EnrollmentID: claim.EnrollmentID,
+			State:        enrollment.StateFinished,
+			CurrentStep:  len(seq.Steps),
+		})
+	}
+
+	nextSendAt := seq.DueAt(*target, time.Now(), c.TimeZone)
+	return s.repo.AdvanceEnrollment(ctx, Advance{
+		EnrollmentID: claim.EnrollmentID,
+		State:        enrollment.StateActive,
+		CurrentStep:  *target,
+		NextSendAt:   &nextSendAt,
+	})
+}
+
+// manual creates the task of a manual step and leaves the enrollment without a next send time
+// until the task is closed, which makes the enrollment due again. The enrollment then advances
+// to the next step, scheduled counting from the moment the task was closed.
+func (s Scheduler) manual(ctx context.Context, claim Claim, seq sequence.Sequence, c contact.Contact, step sequence.Step) error {
+	rendered, err := step.Render(c.Fields())
+	if err != nil {
+		return fmt.Errorf("failed to render task instructions: %w", err)
+	}
+
+	closedAt, err := s.repo.AwaitTask(ctx, Task{
+		EnrollmentID: claim.EnrollmentID,
+		SequenceID:   seq.ID,
+		StepID:       step.ID,
+		ContactID:    c.ID,
+		Kind:         step.Kind,
+		Assignee:     step.Assignment.Assignee,
+		Instructions: rendered.Content,
+		DueAt:        step.Assignment.DueIn.After(time.Now()),
+	}, Advance{
+		EnrollmentID: claim.EnrollmentID,
+		State:        enrollment.StateActive,
+		CurrentStep:  claim.CurrentStep,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+
+	if closedAt == nil {
+		return nil
+	}
+
+	return s.repo.AdvanceEnrollment(ctx, nextAdvance(claim, seq, c, *closedAt))
+}
+
+// giveUp reports whether a failed send of a step is not retried, because the recipient was
+// rejected permanently or this was the last of the allowed attempts. Failing to count the
+// attempts is logged and the send is retried.
+func (s Scheduler) giveUp(ctx context.Context, claim Claim, stepID int, sendErr error) bool {
+	if errors.Is(sendErr, mail.ErrRecipientRejected) {
+		return true
+	}
+
+	failed, err := s.repo.CountFailedMessages(ctx, claim.EnrollmentID, stepID)
+	if err != nil {
+		logging.FromContext(ctx).LogAttrs(ctx, slog.LevelError, "SCHEDULER_ERROR",
+			slog.Int("enrollment_id", claim.EnrollmentID),
+			slog.String("err", fmt.Sprintf("failed to count failed messages: %s", err)),
+		)
+		return false
+	}
+
+	// The message of this attempt is not completed yet, so it is not counted.
+	return failed+1 >= s.config.MaxSendAttempts
+}
+
+// release gives back the reservation of a mailbox for an email that was not sent. Failing to
+// release it only lowers the capacity of the mailbox for the day, so the error is logged.
+func (s Scheduler) release(ctx context.Context, mailboxID int, now time.Time) {
+	if err := s.mailboxes.Release(ctx, mailboxID, now); err != nil {
+		logging.FromContext(ctx).LogAttrs(ctx, slog.LevelError, "SCHEDULER_ERROR",
+			slog.Int("mailbox_id", mailboxID),
+			slog.String("err", err.Error()),
+		)
+	}
+}
+
+// renderEmail renders the step for a contact with HTML content. Merge field values are
+// escaped when the step content is HTML, and plain text content is converted to HTML.
+func renderEmail(step sequence.Step, values map[string]string) (sequence.RenderedStep, error) {
+	rendered, err := step.Render(values)
+	if err != nil {
+		return sequence.RenderedStep{}, err
+	}
+
+	if !compose.IsHTML(step.Content) {
+		rendered.Content = compose.HTMLFromText(rendered.Content)
+		return rendered, nil
+	}
+
+	escaped := make(map[string]string, len(values))
+	for key, value := range values {
+		escaped[key] = html.EscapeString(value)
+	}
+
+	rendered.Content, _, err = templating.Render(step.Content, escaped)
+	if err != nil {
+		return sequence.RenderedStep{}, err
+	}
+
+	return rendered, nil
+}
+
+// unsubscribeFooter returns the HTML of a footer with an unsubscribe link.
+func unsubscribeFooter(unsubscribeURL string) string {
+	return `<p style="margin-top:24px;font-size:12px;color:#888888">If you would rather not hear from us, you can <a href="` +
+		html.EscapeString(unsubscribeURL) + `" style="color:#888888">unsubscribe</a>.</p>`
+}
+
+// nextAdvance schedules the step after the current one within the sending window of the
+// contact, or finishes the enrollment when the current step was the last one.
+func nextAdvance(claim Claim, seq sequence.Sequence, c contact.Contact, sentAt time.Time) Advance {
+	next := claim.CurrentStep + 1
+	if next >= len(seq.Steps) {
+		return Advance{
+			EnrollmentID: claim.EnrollmentID,
+			State:        enrollment.StateFinished,
+			CurrentStep:  next,
+		}
+	}
+
+	nextSendAt := seq.DueAt(next, sentAt, c.TimeZone)
+	return Advance{
+		EnrollmentID: claim.EnrollmentID,
+		State:        enrollment.StateActive,
+		CurrentStep:  next,
+		NextSendAt:   &nextSendAt,
+	}
+}
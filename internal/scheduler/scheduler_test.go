@@ -0,0 +1,1207 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+	"github.com/cybre/salesforge-assignment/internal/scheduler/testdata"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+)
+
+type completed struct {
+	messageID int
+	status    scheduler.MessageStatus
+	sendErr   string
+	advance   scheduler.Advance
+}
+
+func TestScheduler_ProcessDue(t *testing.T) {
+	ctx := context.Background()
+
+	seq := sequence.Sequence{
+		ID:   5,
+		Name: "Test Sequence",
+		Steps: []sequence.Step{
+			{ID: 1, Position: 0, Subject: "Hi {{firstName}}", Content: "Hello {{firstName}} from {{company|us}}"},
+			{ID: 2, Position: 1, Subject: "Follow up", Content: "Still there, {{firstName}}?", Delay: sequence.Delay{Amount: 2, Unit: sequence.DelayUnitHours}},
+		},
+	}
+
+	jane := contact.Contact{ID: 3, Email: "jane@example.com", FirstName: "Jane"}
+	nameless := contact.Contact{ID: 4, Email: "nameless@example.com"}
+
+	sendErr := errors.New("connection refused")
+	rejectedErr := fmt.Errorf("%w: 550 User unknown", mail.ErrRecipientRejected)
+
+	testCases := []struct {
+		name    string
+		claim   scheduler.Claim
+		contact contact.Contact
+		sendErr error
+		// failedAttempts is how many earlier sends of the step failed.
+		failedAttempts int
+		expectedSent   *mail.Message
+		expectedState  enrollment.State
+		expectedStep   int
+		// expectedDelay is when the enrollment is due again, or zero if it is not.
+		expectedDelay  time.Duration
+		expectedStatus scheduler.MessageStatus
+	}{
+		{
+			name:    "First step advances to second step",
+			claim:   scheduler.Claim{EnrollmentID: 10, SequenceID: 5, ContactID: 3, CurrentStep: 0},
+			contact: jane,
+			expectedSent: &mail.Message{
+				ID: 100, From: "sales@example.com", To: "jane@example.com", Subject: "Hi Jane", HTML: "<p>Hello Jane from us</p>\n",
+			},
+			expectedState:  enrollment.StateActive,
+			expectedStep:   1,
+			expectedDelay:  2 * time.Hour,
+			expectedStatus: scheduler.MessageSent,
+		},
+		{
+			name:    "Last step finishes enrollment",
+			claim:   scheduler.Claim{EnrollmentID: 10, SequenceID: 5, ContactID: 3, CurrentStep: 1},
+			contact: jane,
+			expectedSent: &mail.Message{
+				ID: 100, From: "sales@example.com", To: "jane@example.com", Subject: "Follow up", HTML: "<p>Still there, Jane?</p>\n",
+			},
+			expectedState:  enrollment.StateFinished,
+			expectedStep:   2,
+			expectedStatus: scheduler.MessageSent,
+		},
+		{
+			name:           "Failed send is retried",
+			claim:          scheduler.Claim{EnrollmentID: 10, SequenceID: 5, ContactID: 3, CurrentStep: 0},
+			contact:        jane,
+			sendErr:        sendErr,
+			failedAttempts: 3,
+			expectedSent: &mail.Message{
+				ID: 100, From: "sales@example.com", To: "jane@example.com", Subject: "Hi Jane", HTML: "<p>Hello Jane from us</p>\n",
+			},
+			expectedState:  enrollment.StateActive,
+			expectedStep:   0,
+			expectedDelay:  time.Hour,
+			expectedStatus: scheduler.MessageFailed,
+		},
+		{
+			name:           "Last failed attempt pauses enrollment",
+			claim:          scheduler.Claim{EnrollmentID: 10, SequenceID: 5, ContactID: 3, CurrentStep: 0},
+			contact:        jane,
+			sendErr:        sendErr,
+			failedAttempts: 4,
+			expectedSent: &mail.Message{
+				ID: 100, From: "sales@example.com", To: "jane@example.com", Subject: "Hi Jane", HTML: "<p>Hello Jane from us</p>\n",
+			},
+			expectedState:  enrollment.StatePaused,
+			expectedStep:   0,
+			expectedStatus: scheduler.MessageFailed,
+		},
+		{
+			name:    "Rejected recipient pauses enrollment",
+			claim:   scheduler.Claim{EnrollmentID: 10, SequenceID: 5, ContactID: 3, CurrentStep: 0},
+			contact: jane,
+			sendErr: rejectedErr,
+			expectedSent: &mail.Message{
+				ID: 100, From: "sales@example.com", To: "jane@example.com", Subject: "Hi Jane", HTML: "<p>Hello Jane from us</p>\n",
+			},
+			expectedState:  enrollment.StatePaused,
+			expectedStep:   0,
+			expectedStatus: scheduler.MessageFailed,
+		},
+		{
+			name:           "Missing merge field pauses enrollment",
+			claim:          scheduler.Claim{EnrollmentID: 10, SequenceID: 5, ContactID: 4, CurrentStep: 0},
+			contact:        nameless,
+			expectedState:  enrollment.StatePaused,
+			expectedStep:   0,
+			expectedStatus: scheduler.MessageFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				result    *completed
+				messageID string
+			)
+			repo := testdata.MockRepo{
+				ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+					if limit != 20 {
+						t.Errorf("Expected limit: %d, got: %d", 20, limit)
+					}
+
+					if leaseUntil.Sub(now) != time.Minute {
+						t.Errorf("Expected lease: %s, got: %s", time.Minute, leaseUntil.Sub(now))
+					}
+
+					return []scheduler.Claim{tc.claim}, nil
+				},
+				CreateMessageFn: func(ctx context.Context, msg scheduler.Message) (int, error) {
+					step := seq.Steps[tc.claim.CurrentStep]
+					if msg.EnrollmentID != 10 || msg.SequenceID != 5 || msg.StepID != step.ID || msg.ContactID != tc.contact.ID {
+						t.Errorf("Unexpected message: %+v", msg)
+					}
+
+					if !strings.HasSuffix(msg.MessageID, "@example.com") {
+						t.Errorf("Expected message ID of example.com, got: %q", msg.MessageID)
+					}
+					messageID = msg.MessageID
+
+					// The rendered subject is stored, unless the step could not be rendered
+					subject := step.Subject
+					if tc.expectedSent != nil {
+						subject = tc.expectedSent.Subject
+					}
+
+					if msg.Subject != subject {
+						t.Errorf("Expected subject: %q, got: %q", subject, msg.Subject)
+					}
+
+					return 100, nil
+				},
+				CompleteMessageFn: func(ctx context.Context, messageID int, status scheduler.MessageStatus, sendErr string, advance scheduler.Advance) error {
+					result = &completed{messageID, status, sendErr, advance}
+					return nil
+				},
+				CountFailedMessagesFn: func(ctx context.Context, enrollmentID int, stepID int) (int, error) {
+					if enrollmentID != 10 || stepID != seq.Steps[tc.claim.CurrentStep].ID {
+						t.Errorf("Unexpected failed messages of enrollment %d step %d", enrollmentID, stepID)
+					}
+
+					return tc.failedAttempts, nil
+				},
+			}
+
+			sequences := testdata.MockSequenceRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					return seq, true, nil
+				},
+			}
+
+			contacts := testdata.MockContactRepo{
+				GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+					return tc.contact, true, nil
+				},
+			}
+
+			var sent *mail.Message
+			mailer := testdata.MockMailer{
+				SendFn: func(ctx context.Context, msg mail.Message) error {
+					sent = &msg
+					return tc.sendErr
+				},
+			}
+
+			s := scheduler.NewScheduler(repo, sequences, contacts, mailer, scheduler.Config{
+				From:       "sales@example.com",
+				BatchSize:  20,
+				Lease:      time.Minute,
+				RetryDelay: time.Hour,
+			})
+
+			before := time.Now()
+			processed, err := s.ProcessDue(ctx)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if processed != 1 {
+				t.Errorf("Expected 1 processed enrollment, got: %d", processed)
+			}
+
+			if tc.expectedSent == nil && sent != nil {
+				t.Errorf("Expected no email to be sent, got: %+v", sent)
+			}
+
+			if tc.expectedSent != nil {
+				expected := *tc.expectedSent
+				expected.MessageID = messageID
+
+				if sent == nil || !reflect.DeepEqual(*sent, expected) {
+					t.Errorf("Expected email: %+v, got: %+v", expected, sent)
+				}
+			}
+
+			if result == nil {
+				t.Fatalf("Expected message to be completed")
+			}
+
+			if result.messageID != 100 || result.status != tc.expectedStatus {
+				t.Errorf("Expected message 100 to be %s, got message %d %s", tc.expectedStatus, result.messageID, result.status)
+			}
+
+			if (result.status == scheduler.MessageFailed) != (result.sendErr != "") {
+				t.Errorf("Unexpected send error for %s message: %q", result.status, result.sendErr)
+			}
+
+			advance := result.advance
+			if advance.EnrollmentID != 10 || advance.State != tc.expectedState || advance.CurrentStep != tc.expectedStep {
+				t.Errorf("Expected enrollment to be %s at step %d, got: %+v", tc.expectedState, tc.expectedStep, advance)
+			}
+
+			if tc.expectedDelay == 0 && advance.NextSendAt != nil {
+				t.Errorf("Expected enrollment not to be due again, got: %v", advance.NextSendAt)
+			}
+
+			if tc.expectedDelay > 0 {
+				if advance.NextSendAt == nil || advance.NextSendAt.Before(before.Add(tc.expectedDelay)) || advance.NextSendAt.After(time.Now().Add(tc.expectedDelay)) {
+					t.Errorf("Expected enrollment to be due in %s, got: %v", tc.expectedDelay, advance.NextSendAt)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_ProcessDue_HTMLContent(t *testing.T) {
+	ctx := context.Background()
+
+	repo := testdata.MockRepo{
+		ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+			return []scheduler.Claim{{EnrollmentID: 10, SequenceID: 5, ContactID: 3}}, nil
+		},
+		CreateMessageFn: func(ctx context.Context, msg scheduler.Message) (int, error) {
+			return 100, nil
+		},
+		CompleteMessageFn: func(ctx context.Context, messageID int, status scheduler.MessageStatus, sendErr string, advance scheduler.Advance) error {
+			return nil
+		},
+	}
+
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return sequence.Sequence{ID: 5, Steps: []sequence.Step{{ID: 1, Subject: "Hi {{company}}", Content: "<p>Hello {{company}}</p>"}}}, true, nil
+		},
+	}
+
+	contacts := testdata.MockContactRepo{
+		GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+			return contact.Contact{ID: id, Email: "jane@example.com", Company: "Fish & <Chips>"}, true, nil
+		},
+	}
+
+	var sent mail.Message
+	mailer := testdata.MockMailer{
+		SendFn: func(ctx context.Context, msg mail.Message) error {
+			sent = msg
+			return nil
+		},
+	}
+
+	s := scheduler.NewScheduler(repo, sequences, contacts, mailer, scheduler.Config{From: "sales@example.com"})
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Merge field values are escaped in HTML content but not in the subject
+	if sent.Subject != "Hi Fish & <Chips>" {
+		t.Errorf("Expected subject: %q, got: %q", "Hi Fish & <Chips>", sent.Subject)
+	}
+
+	if sent.HTML != "<p>Hello Fish &amp; &lt;Chips&gt;</p>" {
+		t.Errorf("Expected HTML: %q, got: %q", "<p>Hello Fish &amp; &lt;Chips&gt;</p>", sent.HTML)
+	}
+}
+
+func TestScheduler_ProcessDue_PinnedVersion(t *testing.T) {
+	ctx := context.Background()
+
+	repo := testdata.MockRepo{
+		ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+			return []scheduler.Claim{
+				{EnrollmentID: 10, SequenceID: 5, ContactID: 3, Version: 1},
+				{EnrollmentID: 11, SequenceID: 5, ContactID: 3, Version: 2},
+				{EnrollmentID: 12, SequenceID: 5, ContactID: 3, Version: 1},
+			}, nil
+		},
+		CreateMessageFn: func(ctx context.Context, msg scheduler.Message) (int, error) {
+			return msg.EnrollmentID, nil
+		},
+		CompleteMessageFn: func(ctx context.Context, messageID int, status scheduler.MessageStatus, sendErr string, advance scheduler.Advance) error {
+			return nil
+		},
+	}
+
+	loaded := 0
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			t.Error("Expected the draft steps not to be loaded for pinned enrollments")
+			return sequence.Sequence{}, false, nil
+		},
+		GetSequenceVersionFn: func(ctx context.Context, id int, version int) (sequence.Sequence, bool, error) {
+			loaded++
+			subject := fmt.Sprintf("Version %d", version)
+			return sequence.Sequence{ID: id, PublishedVersion: version, Steps: []sequence.Step{{ID: 1, Subject: subject, Content: "Content"}}}, true, nil
+		},
+	}
+
+	contacts := testdata.MockContactRepo{
+		GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+			return contact.Contact{ID: id, Email: "jane@example.com"}, true, nil
+		},
+	}
+
+	subjects := map[int]string{}
+	mailer := testdata.MockMailer{
+		SendFn: func(ctx context.Context, msg mail.Message) error {
+			subjects[msg.ID] = msg.Subject
+			return nil
+		},
+	}
+
+	s := scheduler.NewScheduler(repo, sequences, contacts, mailer, scheduler.Config{From: "sales@example.com"})
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := map[int]string{10: "Version 1", 11: "Version 2", 12: "Version 1"}
+	if !reflect.DeepEqual(subjects, expected) {
+		t.Errorf("Expected subjects: %v, got: %v", expected, subjects)
+	}
+
+	// Versions are loaded once per batch.
+	if loaded != 2 {
+		t.Errorf("Expected 2 versions to be loaded, got: %d", loaded)
+	}
+}
+
+func TestScheduler_ProcessDue_Variants(t *testing.T) {
+	ctx := context.Background()
+
+	var created scheduler.Message
+	repo := testdata.MockRepo{
+		ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+			return []scheduler.Claim{{EnrollmentID: 10, SequenceID: 5, ContactID: 3}}, nil
+		},
+		CreateMessageFn: func(ctx context.Context, msg scheduler.Message) (int, error) {
+			created = msg
+			return 100, nil
+		},
+		CompleteMessageFn: func(ctx context.Context, messageID int, status scheduler.MessageStatus, sendErr string, advance scheduler.Advance) error {
+			return nil
+		},
+	}
+
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return sequence.Sequence{ID: 5, Steps: []sequence.Step{{
+				ID: 1,
+				Variants: []sequence.Variant{
+					{ID: 7, Label: "A", Subject: "Subject A", Content: "Content A", Weight: 50, Active: false},
+					{ID: 8, Label: "B", Subject: "Subject B", Content: "Content B", Weight: 50, Active: true},
+				},
+			}}}, true, nil
+		},
+	}
+
+	contacts := testdata.MockContactRepo{
+		GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+			return contact.Contact{ID: id, Email: "jane@example.com"}, true, nil
+		},
+	}
+
+	var sent mail.Message
+	mailer := testdata.MockMailer{
+		SendFn: func(ctx context.Context, msg mail.Message) error {
+			sent = msg
+			return nil
+		},
+	}
+
+	s := scheduler.NewScheduler(repo, sequences, contacts, mailer, scheduler.Config{From: "sales@example.com"})
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Only the active variant is sent
+	if created.VariantID == nil || *created.VariantID != 8 {
+		t.Errorf("Expected variant ID: 8, got: %v", created.VariantID)
+	}
+
+	if created.Subject != "Subject B" || sent.Subject != "Subject B" {
+		t.Errorf("Expected subject: %q, got: %q and %q", "Subject B", created.Subject, sent.Subject)
+	}
+
+	if sent.HTML != "<p>Content B</p>\n" {
+		t.Errorf("Expected HTML: %q, got: %q", "<p>Content B</p>\n", sent.HTML)
+	}
+}
+
+func TestScheduler_ProcessDue_Tracking(t *testing.T) {
+	ctx := context.Background()
+
+	repo := testdata.MockRepo{
+		ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+			return []scheduler.Claim{
+				{EnrollmentID: 10, SequenceID: 5, ContactID: 3},
+				{EnrollmentID: 11, SequenceID: 6, ContactID: 3},
+				{EnrollmentID: 12, SequenceID: 7, ContactID: 3},
+			}, nil
+		},
+		CreateMessageFn: func(ctx context.Context, msg scheduler.Message) (int, error) {
+			return msg.EnrollmentID + 90, nil
+		},
+		CompleteMessageFn: func(ctx context.Context, messageID int, status scheduler.MessageStatus, sendErr string, advance scheduler.Advance) error {
+			return nil
+		},
+	}
+
+	// Sequence 5 tracks opens and clicks, sequence 6 only clicks and sequence 7 nothing
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return sequence.Sequence{
+				ID:            id,
+				OpenTracking:  id == 5,
+				ClickTracking: id == 5 || id == 6,
+				Steps:         []sequence.Step{{ID: id, Subject: "Subject", Content: `<p><a href="https://example.com">Content</a></p>`}},
+			}, true, nil
+		},
+	}
+
+	contacts := testdata.MockContactRepo{
+		GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+			return contact.Contact{ID: id, Email: "jane@example.com"}, true, nil
+		},
+	}
+
+	sent := map[int]mail.Message{}
+	mailer := testdata.MockMailer{
+		SendFn: func(ctx context.Context, msg mail.Message) error {
+			sent[msg.ID] = msg
+			return nil
+		},
+	}
+
+	tracker := testdata.MockTracker{
+		TrackOpensFn: func(html string, messageID int) string {
+			return fmt.Sprintf("%s<img src=\"/t/o/%d.gif\">", html, messageID)
+		},
+		TrackClicksFn: func(html string, messageID int) string {
+			return strings.ReplaceAll(html, "https://example.com", fmt.Sprintf("/t/c/%d", messageID))
+		},
+		UnsubscribeURLFn: func(messageID int) string {
+			return fmt.Sprintf("https://example.com/u/%d", messageID)
+		},
+	}
+
+	s := scheduler.NewScheduler(repo, sequences, contacts, mailer, scheduler.Config{From: "sales@example.com"}, scheduler.WithTracker(tracker))
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Every email gets an untracked unsubscribe link before the open tracking pixel
+	testCases := []struct {
+		messageID      int
+		expectedPrefix string
+		expectedSuffix string
+	}{
+		{
+			messageID:      100,
+			expectedPrefix: `<p><a href="/t/c/100">Content</a></p>`,
+			expectedSuffix: `<a href="https://example.com/u/100" style="color:#888888">unsubscribe</a>.</p><img src="/t/o/100.gif">`,
+		},
+		{
+			messageID:      101,
+			expectedPrefix: `<p><a href="/t/c/101">Content</a></p>`,
+			expectedSuffix: `<a href="https://example.com/u/101" style="color:#888888">unsubscribe</a>.</p>`,
+		},
+		{
+			messageID:      102,
+			expectedPrefix: `<p><a href="https://example.com">Content</a></p>`,
+			expectedSuffix: `<a href="https://example.com/u/102" style="color:#888888">unsubscribe</a>.</p>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		msg, ok := sent[tc.messageID]
+		if !ok {
+			t.Fatalf("Expected message %d to be sent", tc.messageID)
+		}
+
+		if !strings.HasPrefix(msg.HTML, tc.expectedPrefix) || !strings.HasSuffix(msg.HTML, tc.expectedSuffix) {
+			t.Errorf("Unexpected HTML of message %d: %s", tc.messageID, msg.HTML)
+		}
+
+		expectedUnsubscribe := []string{fmt.Sprintf("https://example.com/u/%d", tc.messageID)}
+		if !reflect.DeepEqual(msg.ListUnsubscribe, expectedUnsubscribe) || !msg.OneClickUnsubscribe {
+			t.Errorf("Unexpected unsubscribe URIs of message %d: %v, one-click: %v", tc.messageID, msg.ListUnsubscribe, msg.OneClickUnsubscribe)
+		}
+	}
+}
+
+func TestScheduler_ProcessDue_Suppressed(t *testing.T) {
+	ctx := context.Background()
+
+	var advanced []scheduler.Advance
+	repo := testdata.MockRepo{
+		ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+			return []scheduler.Claim{
+				{EnrollmentID: 10, SequenceID: 5, ContactID: 3},
+				{EnrollmentID: 11, SequenceID: 5, ContactID: 4},
+				{EnrollmentID: 12, SequenceID: 5, ContactID: 5},
+			}, nil
+		},
+		AdvanceEnrollmentFn: func(ctx context.Context, advance scheduler.Advance) error {
+			advanced = append(advanced, advance)
+			return nil
+		},
+	}
+
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return sequence.Sequence{ID: 5, Steps: []sequence.Step{{ID: 1, Subject: "Subject", Content: "Content"}}}, true, nil
+		},
+	}
+
+	contacts := testdata.MockContactRepo{
+		GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+			return contact.Contact{ID: id, Email: fmt.Sprintf("contact%d@example.com", id)}, true, nil
+		},
+	}
+
+	// The first contact unsubscribed, the second one bounced and checking the third one fails
+	suppressions := testdata.MockSuppressionList{
+		FindSuppressionFn: func(ctx context.Context, email string) (suppression.Suppression, bool, error) {
+			switch email {
+			case "contact3@example.com":
+				return suppression.Suppression{Reason: suppression.ReasonUnsubscribe}, true, nil
+			case "contact4@example.com":
+				return suppression.Suppression{Reason: suppression.ReasonBounce}, true, nil
+			}
+
+			return suppression.Suppression{}, false, errors.New("test error")
+		},
+	}
+
+	s := scheduler.NewScheduler(repo, sequences, contacts, testdata.MockMailer{}, scheduler.Config{}, scheduler.WithSuppressionList(suppressions))
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []scheduler.Advance{
+		{EnrollmentID: 10, State: enrollment.StateUnsubscribed},
+		{EnrollmentID: 11, State: enrollment.StateBounced},
+	}
+	if !reflect.DeepEqual(advanced, expected) {
+		t.Errorf("Expected advances: %+v, got: %+v", expected, advanced)
+	}
+}
+
+func TestScheduler_ProcessDue_StepDeleted(t *testing.T) {
+	ctx := context.Background()
+
+	var advanced scheduler.Advance
+	repo := testdata.MockRepo{
+		ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+			return []scheduler.Claim{{EnrollmentID: 10, SequenceID: 5, ContactID: 3, CurrentStep: 1}}, nil
+		},
+		AdvanceEnrollmentFn: func(ctx context.Context, advance scheduler.Advance) error {
+			advanced = advance
+			return nil
+		},
+	}
+
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return sequence.Sequence{ID: 5, Steps: []sequence.Step{{ID: 1, Subject: "Subject", Content: "Content"}}}, true, nil
+		},
+	}
+
+	s := scheduler.NewScheduler(repo, sequences, testdata.MockContactRepo{}, testdata.MockMailer{}, scheduler.Config{})
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := scheduler.Advance{EnrollmentID: 10, State: enrollment.StateFinished, CurrentStep: 1}
+	if advanced != expected {
+		t.Errorf("Expected advance: %+v, got: %+v", expected, advanced)
+	}
+}
+
+func TestScheduler_ProcessDue_Branch(t *testing.T) {
+	ctx := context.Background()
+
+	then, otherwise := 3, 2
+	steps := []sequence.Step{
+		{ID: 1, Subject: "Subject", Content: "Content"},
+		{ID: 2, Kind: sequence.StepKindBranch, Branch: &sequence.Branch{Condition: sequence.BranchOpened, Then: &then, Else: &otherwise}},
+		{ID: 3, Subject: "Subject", Content: "Content"},
+		{ID: 4, Subject: "Subject", Content: "Content", Delay: sequence.Delay{Amount: 1, Unit: sequence.DelayUnitDays}},
+	}
+
+	testCases := []struct {
+		name          string
+		condition     sequence.BranchCondition
+		otherwise     *int
+		engaged       bool
+		engagedErr    error
+		expectedEvent tracking.EventType
+		expectedState enrollment.State
+		expectedStep  int
+		expectedDelay *time.Duration
+	}{
+		{
+			name:          "Opened",
+			condition:     sequence.BranchOpened,
+			otherwise:     &otherwise,
+			engaged:       true,
+			expectedEvent: tracking.EventOpen,
+			expectedState: enrollment.StateActive,
+			expectedStep:  3,
+			expectedDelay: durationPtr(24 * time.Hour),
+		},
+		{
+			name:          "Not opened",
+			condition:     sequence.BranchOpened,
+			otherwise:     &otherwise,
+			expectedEvent: tracking.EventOpen,
+			expectedState: enrollment.StateActive,
+			expectedStep:  2,
+			expectedDelay: durationPtr(0),
+		},
+		{
+			name:          "Not clicked finishes",
+			condition:     sequence.BranchClicked,
+			expectedEvent: tracking.EventClick,
+			expectedState: enrollment.StateFinished,
+			expectedStep:  4,
+		},
+		{
+			name:          "Failed to check engagement",
+			condition:     sequence.BranchOpened,
+			engagedErr:    errors.New("test error"),
+			expectedEvent: tracking.EventOpen,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				advanced  *scheduler.Advance
+				eventType tracking.EventType
+			)
+			repo := testdata.MockRepo{
+				ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+					return []scheduler.Claim{{EnrollmentID: 10, SequenceID: 5, ContactID: 3, CurrentStep: 1}}, nil
+				},
+				EngagedFn: func(ctx context.Context, enrollmentID int, e tracking.EventType) (bool, error) {
+					eventType = e
+					return tc.engaged, tc.engagedErr
+				},
+				AdvanceEnrollmentFn: func(ctx context.Context, advance scheduler.Advance) error {
+					advanced = &advance
+					return nil
+				},
+			}
+
+			sequences := testdata.MockSequenceRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					branchSteps := append([]sequence.Step{}, steps...)
+					branchSteps[1].Branch = &sequence.Branch{Condition: tc.condition, Then: &then, Else: tc.otherwise}
+					return sequence.Sequence{ID: 5, Steps: branchSteps}, true, nil
+				},
+			}
+
+			contacts := testdata.MockContactRepo{
+				GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+					return contact.Contact{ID: id, Email: "jane@example.com"}, true, nil
+				},
+			}
+
+			// No email is sent for a branch
+			s := scheduler.NewScheduler(repo, sequences, contacts, testdata.MockMailer{}, scheduler.Config{})
+			start := time.Now()
+			if _, err := s.ProcessDue(ctx); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if eventType != tc.expectedEvent {
+				t.Errorf("Expected engagement check for: %s, got: %s", tc.expectedEvent, eventType)
+			}
+
+			if tc.engagedErr != nil {
+				if advanced != nil {
+					t.Errorf("Expected enrollment not to advance, got: %+v", *advanced)
+				}
+				return
+			}
+
+			if advanced == nil {
+				t.Fatal("Expected enrollment to advance")
+			}
+
+			if advanced.State != tc.expectedState || advanced.CurrentStep != tc.expectedStep {
+				t.Errorf("Expected state %s at step %d, got: %s at step %d", tc.expectedState, tc.expectedStep, advanced.State, advanced.CurrentStep)
+			}
+
+			if tc.expectedDelay == nil {
+				if advanced.NextSendAt != nil {
+					t.Errorf("Expected no next send time, got: %v", advanced.NextSendAt)
+				}
+				return
+			}
+
+			if advanced.NextSendAt == nil || advanced.NextSendAt.Before(start.Add(*tc.expectedDelay)) || advanced.NextSendAt.After(time.Now().Add(*tc.expectedDelay)) {
+				t.Errorf("Expected next send time %s from now, got: %v", *tc.expectedDelay, advanced.NextSendAt)
+			}
+		})
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestScheduler_ProcessDue_ManualStep(t *testing.T) {
+	ctx := context.Background()
+
+	seq := sequence.Sequence{ID: 5, Steps: []sequence.Step{
+		{ID: 1, Subject: "Subject", Content: "Content"},
+		{ID: 2, Kind: sequence.StepKindCall, Content: "Ask {{firstName}} about {{company}}", Assignment: &sequence.Assignment{
+			Assignee: "jane@example.com",
+			DueIn:    sequence.Delay{Amount: 2, Unit: sequence.DelayUnitHours},
+		}},
+		{ID: 3, Subject: "Subject", Content: "Content", Delay: sequence.Delay{Amount: 1, Unit: sequence.DelayUnitDays}},
+	}}
+
+	closedAt := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name            string
+		closedAt        *time.Time
+		awaitErr        error
+		expectedAdvance *scheduler.Advance
+	}{
+		{
+			name: "Task created",
+		},
+		{
+			name:     "Task closed",
+			closedAt: &closedAt,
+			expectedAdvance: &scheduler.Advance{
+				EnrollmentID: 10,
+				State:        enrollment.StateActive,
+				CurrentStep:  2,
+				NextSendAt:   timePtr(closedAt.AddDate(0, 0, 1)),
+			},
+		},
+		{
+			name:     "Failed to create task",
+			awaitErr: errors.New("test error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				task     scheduler.Task
+				wait     scheduler.Advance
+				advanced *scheduler.Advance
+			)
+			repo := testdata.MockRepo{
+				ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+					return []scheduler.Claim{{EnrollmentID: 10, SequenceID: 5, ContactID: 3, CurrentStep: 1}}, nil
+				},
+				AwaitTaskFn: func(ctx context.Context, t scheduler.Task, w scheduler.Advance) (*time.Time, error) {
+					task, wait = t, w
+					return tc.closedAt, tc.awaitErr
+				},
+				AdvanceEnrollmentFn: func(ctx context.Context, advance scheduler.Advance) error {
+					advanced = &advance
+					return nil
+				},
+			}
+
+			sequences := testdata.MockSequenceRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					return seq, true, nil
+				},
+			}
+
+			contacts := testdata.MockContactRepo{
+				GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+					return contact.Contact{ID: id, Email: "john@example.com", FirstName: "John", Company: "Acme"}, true, nil
+				},
+			}
+
+			// No email is sent for a manual step
+			s := scheduler.NewScheduler(repo, sequences, contacts, testdata.MockMailer{}, scheduler.Config{})
+			start := time.Now()
+			if _, err := s.ProcessDue(ctx); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			expectedTask := scheduler.Task{
+				EnrollmentID: 10,
+				SequenceID:   5,
+				StepID:       2,
+				ContactID:    3,
+				Kind:         sequence.StepKindCall,
+				Assignee:     "jane@example.com",
+				Instructions: "Ask John about Acme",
+				DueAt:        task.DueAt,
+			}
+			if task != expectedTask {
+				t.Errorf("Expected task: %+v, got: %+v", expectedTask, task)
+			}
+
+			if task.DueAt.Before(start.Add(2*time.Hour)) || task.DueAt.After(time.Now().Add(2*time.Hour)) {
+				t.Errorf("Expected task to be due in 2 hours, got: %v", task.DueAt)
+			}
+
+			expectedWait := scheduler.Advance{EnrollmentID: 10, State: enrollment.StateActive, CurrentStep: 1}
+			if wait != expectedWait {
+				t.Errorf("Expected enrollment to wait with: %+v, got: %+v", expectedWait, wait)
+			}
+
+			if tc.expectedAdvance == nil {
+				if advanced != nil {
+					t.Errorf("Expected enrollment not to advance, got: %+v", *advanced)
+				}
+				return
+			}
+
+			if advanced == nil {
+				t.Fatal("Expected enrollment to advance")
+			}
+
+			if advanced.State != tc.expectedAdvance.State || advanced.CurrentStep != tc.expectedAdvance.CurrentStep ||
+				advanced.NextSendAt == nil || !advanced.NextSendAt.Equal(*tc.expectedAdvance.NextSendAt) {
+				t.Errorf("Expected advance: %+v, got: %+v", *tc.expectedAdvance, *advanced)
+			}
+		})
+	}
+}
+
+func TestScheduler_ProcessDue_CachesSequences(t *testing.T) {
+	ctx := context.Background()
+
+	repo := testdata.MockRepo{
+		ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+			return []scheduler.Claim{
+				{EnrollmentID: 10, SequenceID: 5, ContactID: 3},
+				{EnrollmentID: 11, SequenceID: 5, ContactID: 4},
+			}, nil
+		},
+		CreateMessageFn: func(ctx context.Context, msg scheduler.Message) (int, error) {
+			return msg.EnrollmentID, nil
+		},
+		CompleteMessageFn: func(ctx context.Context, messageID int, status scheduler.MessageStatus, sendErr string, advance scheduler.Advance) error {
+			return nil
+		},
+	}
+
+	loads := 0
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			loads++
+			return sequence.Sequence{ID: 5, Steps: []sequence.Step{{ID: 1, Subject: "Subject", Content: "Content"}}}, true, nil
+		},
+	}
+
+	contacts := testdata.MockContactRepo{
+		GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+			return contact.Contact{ID: id, Email: "contact@example.com"}, true, nil
+		},
+	}
+
+	sends := 0
+	mailer := testdata.MockMailer{
+		SendFn: func(ctx context.Context, msg mail.Message) error {
+			sends++
+			return nil
+		},
+	}
+
+	s := scheduler.NewScheduler(repo, sequences, contacts, mailer, scheduler.Config{})
+	processed, err := s.ProcessDue(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if processed != 2 || sends != 2 {
+		t.Errorf("Expected 2 enrollments to be processed and sent, got: %d processed, %d sent", processed, sends)
+	}
+
+	if loads != 1 {
+		t.Errorf("Expected sequence to be loaded once, got: %d", loads)
+	}
+}
+
+func TestScheduler_ProcessDue_ClaimError(t *testing.T) {
+	ctx := context.Background()
+
+	repo := testdata.MockRepo{
+		ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+			return nil, errors.New("database is down")
+		},
+	}
+
+	s := scheduler.NewScheduler(repo, testdata.MockSequenceRepo{}, testdata.MockContactRepo{}, testdata.MockMailer{}, scheduler.Config{})
+	if _, err := s.ProcessDue(ctx); err == nil {
+		t.Errorf("Expected error, got: nil")
+	}
+}
+
+func TestConfig_WithDefaults(t *testing.T) {
+	c := scheduler.Config{BatchSize: 10}.WithDefaults()
+
+	expected := scheduler.Config{
+		Interval:        scheduler.DefaultInterval,
+		BatchSize:       10,
+		Lease:           scheduler.DefaultLease,
+		RetryDelay:      scheduler.DefaultRetryDelay,
+		MaxSendAttempts: scheduler.DefaultMaxSendAttempts,
+	}
+
+	if c != expected {
+		t.Errorf("Expected config: %+v, got: %+v", expected, c)
+	}
+}
+
+func TestScheduler_ProcessDue_OutsideSendingWindow(t *testing.T) {
+	ctx := context.Background()
+
+	// Only tomorrow is a sending day, so the window is closed for the rest of today.
+	tomorrow := sequence.Weekday(strings.ToLower(time.Now().UTC().AddDate(0, 0, 1).Weekday().String()))
+	seq := sequence.Sequence{
+		ID:       5,
+		Schedule: &sequence.Schedule{Days: []sequence.Weekday{tomorrow}, EndHour: 24, TimeZone: "UTC"},
+		Steps:    []sequence.Step{{ID: 1, Subject: "Subject", Content: "Content"}},
+	}
+
+	var advanced []scheduler.Advance
+	repo := testdata.MockRepo{
+		ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+			return []scheduler.Claim{{EnrollmentID: 10, SequenceID: 5, ContactID: 3}}, nil
+		},
+		CreateMessageFn: func(ctx context.Context, msg scheduler.Message) (int, error) {
+			t.Errorf("Expected no message to be created, got: %+v", msg)
+			return 0, nil
+		},
+		AdvanceEnrollmentFn: func(ctx context.Context, advance scheduler.Advance) error {
+			advanced = append(advanced, advance)
+			return nil
+		},
+	}
+
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return seq, true, nil
+		},
+	}
+
+	contacts := testdata.MockContactRepo{
+		GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+			return contact.Contact{ID: id, Email: "jane@example.com"}, true, nil
+		},
+	}
+
+	s := scheduler.NewScheduler(repo, sequences, contacts, testdata.MockMailer{}, scheduler.Config{})
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(advanced) != 1 {
+		t.Fatalf("Expected the enrollment to be rescheduled once, got: %+v", advanced)
+	}
+
+	expectedSendAt := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	advance := advanced[0]
+	if advance.State != enrollment.StateActive || advance.CurrentStep != 0 || advance.NextSendAt == nil || !advance.NextSendAt.Equal(expectedSendAt) {
+		t.Errorf("Expected enrollment to be held back until %s, got: %+v", expectedSendAt, advance)
+	}
+}
+
+func TestScheduler_ProcessDue_Mailboxes(t *testing.T) {
+	ctx := context.Background()
+
+	jane := mailbox.Mailbox{ID: 7, Email: "jane@sales.example.org", DisplayName: "Jane Doe", DailyLimit: 50}
+	sendErr := errors.New("connection refused")
+
+	testCases := []struct {
+		name       string
+		assignedID int
+		reserved   mailbox.Mailbox
+		reserveErr error
+		sendErr    error
+		// expectedFrom is the sender of the email, or empty if nothing is sent.
+		expectedFrom      string
+		expectedDomain    string
+		expectedMailboxID *int
+		expectedReleased  bool
+		expectedHeldBack  bool
+	}{
+		{
+			name:              "Sent from reserved mailbox",
+			reserved:          jane,
+			expectedFrom:      `"Jane Doe" <jane@sales.example.org>`,
+			expectedDomain:    "sales.example.org",
+			expectedMailboxID: &jane.ID,
+		},
+		{
+			name:              "Assigned mailbox is passed on",
+			assignedID:        7,
+			reserved:          jane,
+			expectedFrom:      `"Jane Doe" <jane@sales.example.org>`,
+			expectedDomain:    "sales.example.org",
+			expectedMailboxID: &jane.ID,
+		},
+		{
+			name:           "Sequence without mailboxes uses default sender",
+			reserveErr:     mailbox.ErrNoMailboxes,
+			expectedFrom:   "sales@example.com",
+			expectedDomain: "example.com",
+		},
+		{
+			name:             "Exceeded quota holds back enrollment",
+			reserveErr:       mailbox.ErrQuotaExceeded,
+			expectedHeldBack: true,
+		},
+		{
+			name:              "Failed send releases reservation",
+			reserved:          jane,
+			sendErr:           sendErr,
+			expectedFrom:      `"Jane Doe" <jane@sales.example.org>`,
+			expectedDomain:    "sales.example.org",
+			expectedMailboxID: &jane.ID,
+			expectedReleased:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				created  *scheduler.Message
+				result   *completed
+				advanced *scheduler.Advance
+			)
+			repo := testdata.MockRepo{
+				ClaimDueFn: func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+					return []scheduler.Claim{{EnrollmentID: 10, SequenceID: 5, ContactID: 3, MailboxID: tc.assignedID}}, nil
+				},
+				CreateMessageFn: func(ctx context.Context, msg scheduler.Message) (int, error) {
+					created = &msg
+					return 100, nil
+				},
+				CompleteMessageFn: func(ctx context.Context, messageID int, status scheduler.MessageStatus, sendErr string, advance scheduler.Advance) error {
+					result = &completed{messageID, status, sendErr, advance}
+					return nil
+				},
+				CountFailedMessagesFn: func(ctx context.Context, enrollmentID int, stepID int) (int, error) {
+					return 0, nil
+				},
+				AdvanceEnrollmentFn: func(ctx context.Context, advance scheduler.Advance) error {
+					advanced = &advance
+					return nil
+				},
+			}
+
+			sequences := testdata.MockSequenceRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{ID: 5, Steps: []sequence.Step{{ID: 1, Subject: "Subject", Content: "Content"}}}, true, nil
+				},
+			}
+
+			contacts := testdata.MockContactRepo{
+				GetContactFn: func(ctx context.Context, id int) (contact.Contact, bool, error) {
+					return contact.Contact{ID: id, Email: "john@example.com"}, true, nil
+				},
+			}
+
+			released := false
+			pool := testdata.MockMailboxPool{
+				ReserveFn: func(ctx context.Context, sequenceID int, assignedID int, now time.Time) (mailbox.Mailbox, error) {
+					if sequenceID != 5 || assignedID != tc.assignedID {
+						t.Errorf("Unexpected reservation of sequence %d with mailbox %d", sequenceID, assignedID)
+					}
+
+					return tc.reserved, tc.reserveErr
+				},
+				ReleaseFn: func(ctx context.Context, mailboxID int, now time.Time) error {
+					if mailboxID != jane.ID {
+						t.Errorf("Expected mailbox %d to be released, got: %d", jane.ID, mailboxID)
+					}
+
+					released = true
+					return nil
+				},
+			}
+
+			var sent *mail.Message
+			send := func(ctx context.Context, msg mail.Message) error {
+				sent = &msg
+				return tc.sendErr
+			}
+
+			mailers := testdata.MockMailboxMailers{
+				MailerFn: func(m mailbox.Mailbox) (mail.Mailer, error) {
+					if m.ID != jane.ID {
+						t.Errorf("Expected mailer of mailbox %d, got: %d", jane.ID, m.ID)
+					}
+
+					return testdata.MockMailer{SendFn: send}, nil
+				},
+			}
+
+			s := scheduler.NewScheduler(repo, sequences, contacts, testdata.MockMailer{SendFn: send}, scheduler.Config{From: "sales@example.com"},
+				scheduler.WithMailboxes(pool, mailers),
+			)
+
+			before := time.Now()
+			if _, err := s.ProcessDue(ctx); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if released != tc.expectedReleased {
+				t.Errorf("Expected reservation released: %t, got: %t", tc.expectedReleased, released)
+			}
+
+			if tc.expectedHeldBack {
+				tomorrow := before.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+				if created != nil || advanced == nil || advanced.State != enrollment.StateActive || advanced.NextSendAt == nil || !advanced.NextSendAt.Equal(tomorrow) {
+					t.Errorf("Expected enrollment to be held back until %s, got: %+v", tomorrow, advanced)
+				}
+
+				return
+			}
+
+			if sent == nil || sent.From != tc.expectedFrom {
+				t.Fatalf("Expected email from %q, got: %+v", tc.expectedFrom, sent)
+			}
+
+			if !strings.HasSuffix(sent.MessageID, "@"+tc.expectedDomain) {
+				t.Errorf("Expected message ID of %s, got: %q", tc.expectedDomain, sent.MessageID)
+			}
+
+			if !reflect.DeepEqual(created.MailboxID, tc.expectedMailboxID) {
+				t.Errorf("Expected message from mailbox %v, got: %v", tc.expectedMailboxID, created.MailboxID)
+			}
+
+			expectedAssigned := tc.expectedMailboxID
+			if tc.sendErr != nil {
+				expectedAssigned = nil
+			}
+
+			if result == nil || !reflect.DeepEqual(result.advance.MailboxID, expectedAssigned) {
+				t.Errorf("Expected mailbox %v to be assigned, got: %+v", expectedAssigned, result)
+			}
+		})
+	}
+}
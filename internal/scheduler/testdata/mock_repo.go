@@ -0,0 +1,128 @@
+package testdata
+
+import (
+	"context"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+)
+
+type MockRepo struct {
+	ClaimDueFn            func(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error)
+	CreateMessageFn       func(ctx context.Context, msg scheduler.Message) (int, error)
+	CompleteMessageFn     func(ctx context.Context, messageID int, status scheduler.MessageStatus, sendErr string, advance scheduler.Advance) error
+	CountFailedMessagesFn func(ctx context.Context, enrollmentID int, stepID int) (int, error)
+	AdvanceEnrollmentFn   func(ctx context.Context, advance scheduler.Advance) error
+	EngagedFn             func(ctx context.Context, enrollmentID int, eventType tracking.EventType) (bool, error)
+	AwaitTaskFn           func(ctx context.Context, task scheduler.Task, wait scheduler.Advance) (*time.Time, error)
+}
+
+func (m MockRepo) ClaimDue(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]scheduler.Claim, error) {
+	return m.ClaimDueFn(ctx, now, leaseUntil, limit)
+}
+
+func (m MockRepo) CreateMessage(ctx context.Context, msg scheduler.Message) (int, error) {
+	return m.CreateMessageFn(ctx, msg)
+}
+
+func (m MockRepo) CompleteMessage(ctx context.Context, messageID int, status scheduler.MessageStatus, sendErr string, advance scheduler.Advance) error {
+	return m.CompleteMessageFn(ctx, messageID, status, sendErr, advance)
+}
+
+func (m MockRepo) CountFailedMessages(ctx context.Context, enrollmentID int, stepID int) (int, error) {
+	return m.CountFailedMessagesFn(ctx, enrollmentID, stepID)
+}
+
+func (m MockRepo) AdvanceEnrollment(ctx context.Context, advance scheduler.Advance) error {
+	return m.AdvanceEnrollmentFn(ctx, advance)
+}
+
+func (m MockRepo) Engaged(ctx context.Context, enrollmentID int, eventType tracking.EventType) (bool, error) {
+	return m.EngagedFn(ctx, enrollmentID, eventType)
+}
+
+func (m MockRepo) AwaitTask(ctx context.Context, task scheduler.Task, wait scheduler.Advance) (*time.Time, error) {
+	return m.AwaitTaskFn(ctx, task, wait)
+}
+
+type MockSequenceRepo struct {
+	GetSequenceFn        func(ctx context.Context, id int) (sequence.Sequence, bool, error)
+	GetSequenceVersionFn func(ctx context.Context, id int, version int) (sequence.Sequence, bool, error)
+}
+
+func (m MockSequenceRepo) GetSequence(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+	return m.GetSequenceFn(ctx, id)
+}
+
+func (m MockSequenceRepo) GetSequenceVersion(ctx context.Context, id int, version int) (sequence.Sequence, bool, error) {
+	return m.GetSequenceVersionFn(ctx, id, version)
+}
+
+type MockContactRepo struct {
+	GetContactFn func(ctx context.Context, id int) (contact.Contact, bool, error)
+}
+
+func (m MockContactRepo) GetContact(ctx context.Context, id int) (contact.Contact, bool, error) {
+	return m.GetContactFn(ctx, id)
+}
+
+type MockMailer struct {
+	SendFn func(ctx context.Context, msg mail.Message) error
+}
+
+func (m MockMailer) Send(ctx context.Context, msg mail.Message) error {
+	return m.SendFn(ctx, msg)
+}
+
+type MockTracker struct {
+	TrackOpensFn     func(html string, messageID int) string
+	TrackClicksFn    func(html string, messageID int) string
+	UnsubscribeURLFn func(messageID int) string
+}
+
+func (m MockTracker) TrackOpens(html string, messageID int) string {
+	return m.TrackOpensFn(html, messageID)
+}
+
+func (m MockTracker) TrackClicks(html string, messageID int) string {
+	return m.TrackClicksFn(html, messageID)
+}
+
+func (m MockTracker) UnsubscribeURL(messageID int) string {
+	return m.UnsubscribeURLFn(messageID)
+}
+
+type MockSuppressionList struct {
+	FindSuppressionFn func(ctx context.Context, email string) (suppression.Suppression, bool, error)
+}
+
+func (m MockSuppressionList) FindSuppression(ctx context.Context, email string) (suppression.Suppression, bool, error) {
+	return m.FindSuppressionFn(ctx, email)
+}
+
+type MockMailboxPool struct {
+	ReserveFn func(ctx context.Context, sequenceID int, assignedID int, now time.Time) (mailbox.Mailbox, error)
+	ReleaseFn func(ctx context.Context, mailboxID int, now time.Time) error
+}
+
+func (m MockMailboxPool) Reserve(ctx context.Context, sequenceID int, assignedID int, now time.Time) (mailbox.Mailbox, error) {
+	return m.ReserveFn(ctx, sequenceID, assignedID, now)
+}
+
+func (m MockMailboxPool) Release(ctx context.Context, mailboxID int, now time.Time) error {
+	return m.ReleaseFn(ctx, mailboxID, now)
+}
+
+type MockMailboxMailers struct {
+	MailerFn func(m mailbox.Mailbox) (mail.Mailer, error)
+}
+
+func (m MockMailboxMailers) Mailer(mb mailbox.Mailbox) (mail.Mailer, error) {
+	return m.MailerFn(mb)
+}
@@ -0,0 +1,239 @@
+package sequence
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BranchCondition is the engagement a branch step checks for.
+type BranchCondition string
+
+const (
+	// BranchOpened is met when the contact opened the last email sent to them.
+	BranchOpened BranchCondition = "opened"
+
+	// BranchClicked is met when the contact clicked a link in the last email sent to them.
+	BranchClicked BranchCondition = "clicked"
+)
+
+// Branch picks the step an enrollment continues with based on the engagement of the contact.
+// The condition is checked once the delay of the branch step has elapsed, and the delay of the
+// chosen step is counted from that moment.
+type Branch struct {
+	Condition BranchCondition `json:"condition"`
+	// Then is the position of the step to continue with when the condition is met. The
+	// enrollment finishes when it is nil.
+	Then *int `json:"then"`
+	// Else is the position of the step to continue with when the condition is not met. The
+	// enrollment finishes when it is nil.
+	Else *int `json:"else"`
+}
+
+// Validate validates the branch. Whether the targets are steps of the sequence is validated
+// with the sequence.
+func (b Branch) Validate() error {
+	switch b.Condition {
+	case BranchOpened, BranchClicked:
+	default:
+		return fmt.Errorf("branch condition must be one of %q or %q", BranchOpened, BranchClicked)
+	}
+
+	return nil
+}
+
+// Target returns the position of the step to continue with, or nil to finish the enrollment.
+func (b Branch) Target(met bool) *int {
+	if met {
+		return b.Then
+	}
+
+	return b.Else
+}
+
+// validateBranchStep validates a branch step, which sends no email.
+func validateBranchStep(s Step) error {
+	if s.Branch == nil {
+		return errors.New("branch is required for branch steps")
+	}
+
+	if s.Subject != "" || s.Content != "" || len(s.Variants) > 0 {
+		return errors.New("branch steps cannot have a subject, content or variants")
+	}
+
+	return s.Branch.Validate()
+}
+
+// validateFlow validates how enrollments move through the steps. Branches must target steps
+// of the sequence and must not make enrollments loop.
+func validateFlow(steps []Step) error {
+	for i, step := range steps {
+		if step.Branch == nil {
+			continue
+		}
+
+		if i == 0 {
+			return errors.New("the first step cannot be a branch, as no email was sent before it")
+		}
+
+		for _, target := range []*int{step.Branch.Then, step.Branch.Else} {
+			if target != nil && (*target < 0 || *target >= len(steps)) {
+				return fmt.Errorf("step %d branches to step %d, which is not in the sequence", i, *target)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	states := make([]int, len(steps))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch states[i] {
+		case visiting:
+			return fmt.Errorf("step %d can be reached again after it, which would loop", i)
+		case visited:
+			return nil
+		}
+
+		states[i] = visiting
+		for _, next := range nextSteps(steps, i) {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		states[i] = visited
+
+		return nil
+	}
+
+	for i := range steps {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nextSteps returns the positions of the steps an enrollment can continue with after the
+// step at the given position.
+func nextSteps(steps []Step, i int) []int {
+	if steps[i].Branch == nil {
+		if i+1 < len(steps) {
+			return []int{i + 1}
+		}
+
+		return nil
+	}
+
+	var next []int
+	for _, target := range []*int{steps[i].Branch.Then, steps[i].Branch.Else} {
+		if target != nil {
+			next = append(next, *target)
+		}
+	}
+
+	return next
+}
+
+// remapTargets returns a copy of the steps with the branch targets moved to the new positions
+// of the steps they target.
+func remapTargets(steps []Step, position func(int) int) []Step {
+	remapped := make([]Step, 0, len(steps))
+	for _, step := range steps {
+		if step.Branch != nil {
+			branch := *step.Branch
+			for _, target := range []**int{&branch.Then, &branch.Else} {
+				if *target != nil {
+					p := position(**target)
+					*target = &p
+				}
+			}
+			step.Branch = &branch
+		}
+
+		remapped = append(remapped, step)
+	}
+
+	return remapped
+}
+
+// withStepInserted returns the steps with the step inserted at the position. The targets of the
+// new step are positions after the insertion, while existing branches keep their targets.
+func withStepInserted(steps []Step, step Step, position int) []Step {
+	shifted := remapTargets(steps, func(p int) int {
+		if p >= position {
+			return p + 1
+		}
+
+		return p
+	})
+
+	inserted := make([]Step, 0, len(steps)+1)
+	inserted = append(inserted, shifted[:position]...)
+	inserted = append(inserted, step)
+	return append(inserted, shifted[position:]...)
+}
+
+// withStepReplaced returns the steps with the step of the same ID replaced.
+func withStepReplaced(steps []Step, step Step) []Step {
+	replaced := make([]Step, len(steps))
+	for i, s := range steps {
+		if s.ID == step.ID {
+			s = step
+		}
+
+		replaced[i] = s
+	}
+
+	return replaced
+}
+
+// withStepRemoved returns the steps without the step at the position. It fails when a branch
+// targets the step.
+func withStepRemoved(steps []Step, position int) ([]Step, error) {
+	for i, step := range steps {
+		if step.Branch == nil {
+			continue
+		}
+
+		for _, target := range []*int{step.Branch.Then, step.Branch.Else} {
+			if target != nil && *target == position {
+				return nil, fmt.Errorf("step %d branches to the step, change its branch first", i)
+			}
+		}
+	}
+
+	shifted := remapTargets(steps, func(p int) int {
+		if p > position {
+			return p - 1
+		}
+
+		return p
+	})
+
+	return append(shifted[:position:position], shifted[position+1:]...), nil
+}
+
+// withStepsReordered returns the steps in the order of the step IDs, which must be a
+// permutation of the IDs of the steps. Branches keep their targets.
+func withStepsReordered(steps []Step, stepIDs []int) []Step {
+	positions := make(map[int]int, len(stepIDs))
+	for i, id := range stepIDs {
+		positions[id] = i
+	}
+
+	remapped := remapTargets(steps, func(p int) int {
+		return positions[steps[p].ID]
+	})
+
+	reordered := make([]Step, len(steps))
+	for _, step := range remapped {
+		reordered[positions[step.ID]] = step
+	}
+
+	return reordered
+}
@@ -0,0 +1,237 @@
+package sequence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/sequence/testdata"
+)
+
+func email(id int) sequence.Step {
+	return sequence.Step{ID: id, Subject: "Subject", Content: "Content"}
+}
+
+func branch(id int, then, otherwise *int) sequence.Step {
+	return sequence.Step{
+		ID:     id,
+		Kind:   sequence.StepKindBranch,
+		Branch: &sequence.Branch{Condition: sequence.BranchOpened, Then: then, Else: otherwise},
+	}
+}
+
+func TestStep_Validate_Branch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		step     sequence.Step
+		expected error
+	}{
+		{
+			name:     "Valid branch",
+			step:     branch(1, intPtr(2), nil),
+			expected: nil,
+		},
+		{
+			name:     "Missing branch",
+			step:     sequence.Step{Kind: sequence.StepKindBranch},
+			expected: errors.New("branch is required for branch steps"),
+		},
+		{
+			name:     "Branch with content",
+			step:     sequence.Step{Kind: sequence.StepKindBranch, Content: "Content", Branch: &sequence.Branch{Condition: sequence.BranchClicked}},
+			expected: errors.New("branch steps cannot have a subject, content or variants"),
+		},
+		{
+			name:     "Unknown condition",
+			step:     sequence.Step{Kind: sequence.StepKindBranch, Branch: &sequence.Branch{Condition: "replied"}},
+			expected: errors.New(`branch condition must be one of "opened" or "clicked"`),
+		},
+		{
+			name:     "Email with branch",
+			step:     sequence.Step{Subject: "Subject", Content: "Content", Branch: &sequence.Branch{Condition: sequence.BranchOpened}},
+			expected: errors.New("only branch steps can have a branch"),
+		},
+		{
+			name:     "Unknown kind",
+			step:     sequence.Step{Kind: "sms", Subject: "Subject", Content: "Content"},
+			expected: errors.New(`step kind must be one of "email", "branch", "call", "linkedin" or "task"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.step.Validate()
+			if err == nil && tc.expected != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.expected)
+			} else if err != nil && tc.expected == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.expected != nil && err.Error() != tc.expected.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestSequence_Validate_Branches(t *testing.T) {
+	testCases := []struct {
+		name     string
+		steps    []sequence.Step
+		expected string
+	}{
+		{
+			name:  "Branch to later steps",
+			steps: []sequence.Step{email(1), branch(2, intPtr(3), nil), email(3), email(4)},
+		},
+		{
+			name:  "Branches finishing the enrollment",
+			steps: []sequence.Step{email(1), branch(2, intPtr(4), intPtr(3)), email(3), branch(4, nil, nil), email(5)},
+		},
+		{
+			name:     "First step is a branch",
+			steps:    []sequence.Step{branch(1, intPtr(1), nil), email(2)},
+			expected: "the first step cannot be a branch, as no email was sent before it",
+		},
+		{
+			name:     "Dangling target",
+			steps:    []sequence.Step{email(1), branch(2, intPtr(5), nil)},
+			expected: "step 1 branches to step 5, which is not in the sequence",
+		},
+		{
+			name:     "Negative target",
+			steps:    []sequence.Step{email(1), branch(2, nil, intPtr(-1))},
+			expected: "step 1 branches to step -1, which is not in the sequence",
+		},
+		{
+			name:     "Branch to itself",
+			steps:    []sequence.Step{email(1), branch(2, intPtr(1), nil)},
+			expected: "step 1 can be reached again after it, which would loop",
+		},
+		{
+			name:     "Branch back to an earlier step",
+			steps:    []sequence.Step{email(1), email(2), branch(3, intPtr(3), intPtr(1)), email(4)},
+			expected: "step 1 can be reached again after it, which would loop",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			seq := sequence.Sequence{Name: "Test Sequence", Steps: tc.steps}
+			err := seq.Validate()
+			if tc.expected == "" && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+
+			if tc.expected != "" && (!errors.Is(err, sequence.ErrStepValidation) || err.Error() != sequence.ErrStepValidation.Error()+": "+tc.expected) {
+				t.Errorf("Expected error: %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestService_Steps_Branches(t *testing.T) {
+	ctx := context.Background()
+
+	// The branch continues with the last step when the first email was opened.
+	steps := []sequence.Step{email(1), branch(2, intPtr(3), intPtr(2)), email(3), email(4)}
+	for i := range steps {
+		steps[i].Position = i
+	}
+
+	var (
+		createdStep sequence.Step
+		reordered   []int
+	)
+	repo := testdata.MockRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return sequence.Sequence{ID: 1, Name: "Test Sequence", Steps: steps}, true, nil
+		},
+		GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+			return sequence.Sequence{ID: 1, Name: "Test Sequence", Steps: steps}, true, nil
+		},
+		CreateStepFn: func(ctx context.Context, sequenceID int, step sequence.Step) (int, error) {
+			createdStep = step
+			return 5, nil
+		},
+		UpdateStepFn: func(ctx context.Context, step sequence.Step) (bool, error) {
+			return true, nil
+		},
+		DeleteStepFn: func(ctx context.Context, id int) error {
+			return nil
+		},
+		ReorderStepsFn: func(ctx context.Context, sequenceID int, stepIDs []int) error {
+			reordered = stepIDs
+			return nil
+		},
+	}
+
+	svc := sequence.NewService(repo)
+
+	t.Run("Add step before the branch targets", func(t *testing.T) {
+		_, err := svc.AddStep(ctx, 1, branch(0, intPtr(4), nil), intPtr(3))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if createdStep.Position != 3 {
+			t.Errorf("Expected step at position 3, got: %d", createdStep.Position)
+		}
+	})
+
+	t.Run("Add branch looping back", func(t *testing.T) {
+		_, err := svc.AddStep(ctx, 1, branch(0, intPtr(0), nil), nil)
+		if !errors.Is(err, sequence.ErrStepValidation) {
+			t.Errorf("Expected error: %v, got: %v", sequence.ErrStepValidation, err)
+		}
+	})
+
+	t.Run("Update branch to a dangling target", func(t *testing.T) {
+		err := svc.UpdateStep(ctx, branch(2, intPtr(7), nil))
+		if !errors.Is(err, sequence.ErrStepValidation) {
+			t.Errorf("Expected error: %v, got: %v", sequence.ErrStepValidation, err)
+		}
+	})
+
+	t.Run("Update the target of a branch to an email", func(t *testing.T) {
+		if err := svc.UpdateStep(ctx, email(4)); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("Delete the target of a branch", func(t *testing.T) {
+		err := svc.DeleteStep(ctx, 4)
+		if !errors.Is(err, sequence.ErrStepValidation) {
+			t.Errorf("Expected error: %v, got: %v", sequence.ErrStepValidation, err)
+		}
+	})
+
+	t.Run("Delete the step before the branch", func(t *testing.T) {
+		err := svc.DeleteStep(ctx, 1)
+		if !errors.Is(err, sequence.ErrStepValidation) {
+			t.Errorf("Expected error: %v, got: %v", sequence.ErrStepValidation, err)
+		}
+	})
+
+	t.Run("Delete the branch", func(t *testing.T) {
+		if err := svc.DeleteStep(ctx, 2); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("Reorder keeping the branch targets after it", func(t *testing.T) {
+		if err := svc.ReorderSteps(ctx, 1, []int{1, 2, 4, 3}); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if len(reordered) != 4 {
+			t.Errorf("Expected steps to be reordered, got: %v", reordered)
+		}
+	})
+
+	t.Run("Reorder moving a target before the branch", func(t *testing.T) {
+		err := svc.ReorderSteps(ctx, 1, []int{4, 1, 2, 3})
+		if !errors.Is(err, sequence.ErrStepValidation) {
+			t.Errorf("Expected error: %v, got: %v", sequence.ErrStepValidation, err)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package sequence
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DelayUnit is the unit in which a step delay is expressed.
+type DelayUnit string
+
+const (
+	// DelayUnitHours waits a number of hours.
+	DelayUnitHours DelayUnit = "hours"
+
+	// DelayUnitDays waits a number of calendar days.
+	DelayUnitDays DelayUnit = "days"
+
+	// DelayUnitBusinessDays waits a number of days, not counting Saturdays and Sundays.
+	DelayUnitBusinessDays DelayUnit = "businessDays"
+)
+
+// maxDelayDays is the longest a single step is allowed to wait.
+const maxDelayDays = 365
+
+// Delay is how long to wait after the previous step was sent before sending a step.
+// For the first step of a sequence the delay is relative to the moment of enrollment.
+// The zero value means the step is sent right away.
+type Delay struct {
+	Amount int       `json:"amount"`
+	Unit   DelayUnit `json:"unit"`
+}
+
+// Validate validates the delay.
+func (d Delay) Validate() error {
+	if d.Amount < 0 {
+		return errors.New("delay amount cannot be negative")
+	}
+
+	switch d.unitOrDefault() {
+	case DelayUnitHours:
+		if d.Amount > maxDelayDays*24 {
+			return fmt.Errorf("delay cannot exceed %d days", maxDelayDays)
+		}
+	case DelayUnitDays, DelayUnitBusinessDays:
+		if d.Amount > maxDelayDays {
+			return fmt.Errorf("delay cannot exceed %d days", maxDelayDays)
+		}
+	default:
+		return fmt.Errorf("delay unit must be one of %q, %q or %q", DelayUnitHours, DelayUnitDays, DelayUnitBusinessDays)
+	}
+
+	return nil
+}
+
+// After returns the moment the delay elapses when counting from t.
+func (d Delay) After(t time.Time) time.Time {
+	return d.after(t, func(t time.Time) bool { return !isWeekend(t) })
+}
+
+// after returns the moment the delay elapses when counting from t, counting business days
+// with the given calendar.
+func (d Delay) after(t time.Time, isBusinessDay func(time.Time) bool) time.Time {
+	switch d.unitOrDefault() {
+	case DelayUnitHours:
+		return t.Add(time.Duration(d.Amount) * time.Hour)
+	case DelayUnitBusinessDays:
+		for remaining := d.Amount; remaining > 0; {
+			t = t.AddDate(0, 0, 1)
+			if isBusinessDay(t) {
+				remaining--
+			}
+		}
+
+		return t
+	default:
+		return t.AddDate(0, 0, d.Amount)
+	}
+}
+
+// unitOrDefault returns the unit of the delay, defaulting to calendar days when it is not set.
+func (d Delay) unitOrDefault() DelayUnit {
+	if d.Unit == "" {
+		return DelayUnitDays
+	}
+
+	return d.Unit
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
@@ -0,0 +1,107 @@
+package sequence_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+func TestDelay_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		delay    sequence.Delay
+		expected error
+	}{
+		{
+			name:     "Zero delay",
+			delay:    sequence.Delay{},
+			expected: nil,
+		},
+		{
+			name:     "Valid hours",
+			delay:    sequence.Delay{Amount: 12, Unit: sequence.DelayUnitHours},
+			expected: nil,
+		},
+		{
+			name:     "Valid business days",
+			delay:    sequence.Delay{Amount: 3, Unit: sequence.DelayUnitBusinessDays},
+			expected: nil,
+		},
+		{
+			name:     "Negative amount",
+			delay:    sequence.Delay{Amount: -1, Unit: sequence.DelayUnitDays},
+			expected: errors.New("delay amount cannot be negative"),
+		},
+		{
+			name:     "Unknown unit",
+			delay:    sequence.Delay{Amount: 1, Unit: "weeks"},
+			expected: errors.New(`delay unit must be one of "hours", "days" or "businessDays"`),
+		},
+		{
+			name:     "Too long",
+			delay:    sequence.Delay{Amount: 366, Unit: sequence.DelayUnitDays},
+			expected: errors.New("delay cannot exceed 365 days"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.delay.Validate()
+			if err == nil && tc.expected != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.expected)
+			} else if err != nil && tc.expected == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.expected != nil && err.Error() != tc.expected.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestDelay_After(t *testing.T) {
+	// Thursday
+	start := time.Date(2024, time.May, 2, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		delay    sequence.Delay
+		expected time.Time
+	}{
+		{
+			name:     "Zero delay",
+			delay:    sequence.Delay{},
+			expected: start,
+		},
+		{
+			name:     "Hours",
+			delay:    sequence.Delay{Amount: 20, Unit: sequence.DelayUnitHours},
+			expected: time.Date(2024, time.May, 3, 5, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "Calendar days",
+			delay:    sequence.Delay{Amount: 3, Unit: sequence.DelayUnitDays},
+			expected: time.Date(2024, time.May, 5, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "Unit defaults to calendar days",
+			delay:    sequence.Delay{Amount: 3},
+			expected: time.Date(2024, time.May, 5, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "Business days skip the weekend",
+			delay:    sequence.Delay{Amount: 3, Unit: sequence.DelayUnitBusinessDays},
+			expected: time.Date(2024, time.May, 7, 9, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := tc.delay.After(start)
+			if !result.Equal(tc.expected) {
+				t.Errorf("Expected: %v, got: %v", tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package sequence
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidListQuery is returned when the options for listing sequences are invalid.
+var ErrInvalidListQuery = errors.New("list query is invalid")
+
+const (
+	// DefaultListLimit is the page size used when a list query does not set one.
+	DefaultListLimit = 20
+
+	// MaxListLimit is the largest page size a list query may request.
+	MaxListLimit = 100
+)
+
+// SortField is a field sequences can be sorted by.
+type SortField string
+
+const (
+	SortByID   SortField = "id"
+	SortByName SortField = "name"
+)
+
+// SortOrder is the direction sequences are sorted in.
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// ListQuery represents the options for listing sequences.
+type ListQuery struct {
+	// Name filters sequences whose name contains the given text, ignoring case.
+	Name          string
+	OpenTracking  *bool
+	ClickTracking *bool
+	SortBy        SortField
+	Order         SortOrder
+	Limit         int
+	// Cursor is the opaque cursor returned with the previous page.
+	Cursor string
+	// IncludeSteps loads the steps of every listed sequence.
+	IncludeSteps bool
+	// IncludeArchived lists archived sequences alongside active ones.
+	IncludeArchived bool
+}
+
+// WithDefaults returns a copy of the query with unset options replaced by their defaults.
+func (q ListQuery) WithDefaults() ListQuery {
+	if q.SortBy == "" {
+		q.SortBy = SortByID
+	}
+
+	if q.Order == "" {
+		q.Order = SortAscending
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultListLimit
+	}
+
+	return q
+}
+
+// Validate validates the list query.
+func (q ListQuery) Validate() error {
+	if q.SortBy != SortByID && q.SortBy != SortByName {
+		return fmt.Errorf("sortBy must be one of %q or %q", SortByID, SortByName)
+	}
+
+	if q.Order != SortAscending && q.Order != SortDescending {
+		return fmt.Errorf("order must be one of %q or %q", SortAscending, SortDescending)
+	}
+
+	if q.Limit < 1 || q.Limit > MaxListLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxListLimit)
+	}
+
+	return nil
+}
+
+// ListFilter is the list query as understood by the repository.
+type ListFilter struct {
+	Name          string
+	OpenTracking  *bool
+	ClickTracking *bool
+	SortBy        SortField
+	Order         SortOrder
+	// After is the position of the last sequence of the previous page, if any.
+	After           *Cursor
+	Limit           int
+	IncludeSteps    bool
+	IncludeArchived bool
+}
+
+// Cursor marks the position of a sequence within a sorted listing.
+type Cursor struct {
+	SortBy SortField `json:"s"`
+	Order  SortOrder `json:"o"`
+	ID     int       `json:"i"`
+	Name   string    `json:"n,omitempty"`
+}
+
+// Encode encodes the cursor into an opaque string.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes a cursor created with Cursor.Encode.
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, errors.New("cursor is malformed")
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return Cursor{}, errors.New("cursor is malformed")
+	}
+
+	return c, nil
+}
+
+// newCursor creates a cursor pointing at the given sequence.
+func newCursor(seq Sequence, sortBy SortField, order SortOrder) Cursor {
+	c := Cursor{
+		SortBy: sortBy,
+		Order:  order,
+		ID:     seq.ID,
+	}
+
+	if sortBy == SortByName {
+		c.Name = seq.Name
+	}
+
+	return c
+}
+
+// Page is a page of sequences.
+type Page struct {
+	Sequences []Sequence `json:"sequences"`
+	// NextCursor is the cursor for the next page. It is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListSequences lists sequences matching the query, one page at a time.
+func (s Service) ListSequences(ctx context.Context, query ListQuery) (Page, error) {
+	query = query.WithDefaults()
+	if err := query.Validate(); err != nil {
+		return Page{}, fmt.Errorf("%w: %s", ErrInvalidListQuery, err)
+	}
+
+	filter := ListFilter{
+		Name:          query.Name,
+		OpenTracking:  query.OpenTracking,
+		ClickTracking: query.ClickTracking,
+		SortBy:        query.SortBy,
+		Order:         query.Order,
+		// Fetch one extra sequence to find out whether there is a next page.
+		Limit:           query.Limit + 1,
+		IncludeSteps:    query.IncludeSteps,
+		IncludeArchived: query.IncludeArchived,
+	}
+
+	if query.Cursor != "" {
+		cursor, err := DecodeCursor(query.Cursor)
+		if err != nil {
+			return Page{}, fmt.Errorf("%w: %s", ErrInvalidListQuery, err)
+		}
+
+		if cursor.SortBy != query.SortBy || cursor.Order != query.Order {
+			return Page{}, fmt.Errorf("%w: cursor does not match the requested sort", ErrInvalidListQuery)
+		}
+
+		filter.After = &cursor
+	}
+
+	sequences, err := s.repo.ListSequences(ctx, filter)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to list sequences: %w", err)
+	}
+
+	page := Page{Sequences: sequences}
+	if len(sequences) > query.Limit {
+		page.Sequences = sequences[:query.Limit]
+		page.NextCursor = newCursor(page.Sequences[query.Limit-1], query.SortBy, query.Order).Encode()
+	}
+
+	return page, nil
+}
@@ -0,0 +1,193 @@
+package sequence_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/sequence/testdata"
+)
+
+func TestListQuery_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    sequence.ListQuery
+		expected error
+	}{
+		{
+			name:     "Defaults",
+			query:    sequence.ListQuery{}.WithDefaults(),
+			expected: nil,
+		},
+		{
+			name:     "Sort by name descending",
+			query:    sequence.ListQuery{SortBy: sequence.SortByName, Order: sequence.SortDescending, Limit: 10},
+			expected: nil,
+		},
+		{
+			name:     "Unknown sort field",
+			query:    sequence.ListQuery{SortBy: "subject", Order: sequence.SortAscending, Limit: 10},
+			expected: errors.New(`sortBy must be one of "id" or "name"`),
+		},
+		{
+			name:     "Unknown sort order",
+			query:    sequence.ListQuery{SortBy: sequence.SortByID, Order: "up", Limit: 10},
+			expected: errors.New(`order must be one of "asc" or "desc"`),
+		},
+		{
+			name:     "Limit too large",
+			query:    sequence.ListQuery{SortBy: sequence.SortByID, Order: sequence.SortAscending, Limit: 101},
+			expected: errors.New("limit must be between 1 and 100"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.query.Validate()
+			if err == nil && tc.expected != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.expected)
+			} else if err != nil && tc.expected == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.expected != nil && err.Error() != tc.expected.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	cursor := sequence.Cursor{SortBy: sequence.SortByName, Order: sequence.SortDescending, ID: 42, Name: "Follow-up"}
+
+	decoded, err := sequence.DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if decoded != cursor {
+		t.Errorf("Expected: %v, got: %v", cursor, decoded)
+	}
+
+	if _, err := sequence.DecodeCursor("not a cursor"); err == nil {
+		t.Error("Expected error for malformed cursor, got: nil")
+	}
+}
+
+func TestService_ListSequences(t *testing.T) {
+	ctx := context.Background()
+
+	sequences := []sequence.Sequence{
+		{ID: 1, Name: "Alpha"},
+		{ID: 2, Name: "Beta"},
+		{ID: 3, Name: "Gamma"},
+	}
+
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name               string
+		query              sequence.ListQuery
+		returned           []sequence.Sequence
+		repoErr            error
+		expectedFilter     sequence.ListFilter
+		expectedSequences  []sequence.Sequence
+		expectedNextCursor string
+		expectedErr        error
+	}{
+		{
+			name:     "Last page",
+			query:    sequence.ListQuery{Name: "a", OpenTracking: boolPtr(true)},
+			returned: sequences,
+			expectedFilter: sequence.ListFilter{
+				Name:         "a",
+				OpenTracking: boolPtr(true),
+				SortBy:       sequence.SortByID,
+				Order:        sequence.SortAscending,
+				Limit:        sequence.DefaultListLimit + 1,
+			},
+			expectedSequences: sequences,
+		},
+		{
+			name:     "Page with more results",
+			query:    sequence.ListQuery{SortBy: sequence.SortByName, Limit: 2, IncludeSteps: true},
+			returned: sequences,
+			expectedFilter: sequence.ListFilter{
+				SortBy:       sequence.SortByName,
+				Order:        sequence.SortAscending,
+				Limit:        3,
+				IncludeSteps: true,
+			},
+			expectedSequences:  sequences[:2],
+			expectedNextCursor: sequence.Cursor{SortBy: sequence.SortByName, Order: sequence.SortAscending, ID: 2, Name: "Beta"}.Encode(),
+		},
+		{
+			name:     "Page after cursor",
+			query:    sequence.ListQuery{Limit: 2, Cursor: sequence.Cursor{SortBy: sequence.SortByID, Order: sequence.SortAscending, ID: 2}.Encode()},
+			returned: sequences[2:],
+			expectedFilter: sequence.ListFilter{
+				SortBy: sequence.SortByID,
+				Order:  sequence.SortAscending,
+				After:  &sequence.Cursor{SortBy: sequence.SortByID, Order: sequence.SortAscending, ID: 2},
+				Limit:  3,
+			},
+			expectedSequences: sequences[2:],
+		},
+		{
+			name:        "Cursor for another sort",
+			query:       sequence.ListQuery{SortBy: sequence.SortByName, Cursor: sequence.Cursor{SortBy: sequence.SortByID, Order: sequence.SortAscending, ID: 2}.Encode()},
+			expectedErr: sequence.ErrInvalidListQuery,
+		},
+		{
+			name:        "Malformed cursor",
+			query:       sequence.ListQuery{Cursor: "???"},
+			expectedErr: sequence.ErrInvalidListQuery,
+		},
+		{
+			name:        "Invalid query",
+			query:       sequence.ListQuery{Limit: -1},
+			expectedErr: sequence.ErrInvalidListQuery,
+		},
+		{
+			name:        "Repository error",
+			query:       sequence.ListQuery{},
+			repoErr:     repoErr,
+			expectedErr: repoErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var filter sequence.ListFilter
+			repo := testdata.MockRepo{
+				ListSequencesFn: func(ctx context.Context, f sequence.ListFilter) ([]sequence.Sequence, error) {
+					filter = f
+					return tc.returned, tc.repoErr
+				},
+			}
+
+			svc := sequence.NewService(repo)
+			page, err := svc.ListSequences(ctx, tc.query)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if tc.expectedErr != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(filter, tc.expectedFilter) {
+				t.Errorf("Expected filter: %+v, got: %+v", tc.expectedFilter, filter)
+			}
+
+			if !reflect.DeepEqual(page.Sequences, tc.expectedSequences) {
+				t.Errorf("Expected sequences: %v, got: %v", tc.expectedSequences, page.Sequences)
+			}
+
+			if page.NextCursor != tc.expectedNextCursor {
+				t.Errorf("Expected next cursor: %q, got: %q", tc.expectedNextCursor, page.NextCursor)
+			}
+		})
+	}
+}
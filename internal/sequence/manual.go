@@ -0,0 +1,89 @@
+package sequence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cybre/salesforge-assignment/internal/templating"
+)
+
+const (
+	// MaxAssigneeLength is the longest assignee a manual step can have, which fits an email address.
+	MaxAssigneeLength = 254
+
+	// MaxLinkedInNoteLength is the longest note LinkedIn allows on a connection request.
+	MaxLinkedInNoteLength = 300
+)
+
+// Assignment is who completes the task of a manual step, and by when.
+type Assignment struct {
+	// Assignee identifies the user the task is assigned to, e.g. by their email address.
+	Assignee string `json:"assignee"`
+	// DueIn is how long the assignee has to complete the task once the step is reached. The
+	// zero value makes the task due right away.
+	DueIn Delay `json:"dueIn"`
+}
+
+// Validate validates the assignment.
+func (a Assignment) Validate() error {
+	assignee := strings.TrimSpace(a.Assignee)
+	if assignee == "" {
+		return errors.New("assignee is required for manual steps")
+	}
+
+	if len(assignee) > MaxAssigneeLength {
+		return fmt.Errorf("assignee cannot be longer than %d characters", MaxAssigneeLength)
+	}
+
+	if err := a.DueIn.Validate(); err != nil {
+		return fmt.Errorf("due in: %w", err)
+	}
+
+	return nil
+}
+
+// IsManual reports whether the step is done by a user instead of being sent. Reaching a manual
+// step creates a task, and the enrollment continues once the task is completed or skipped.
+func (s Step) IsManual() bool {
+	switch s.Kind {
+	case StepKindCall, StepKindLinkedIn, StepKindTask:
+		return true
+	}
+
+	return false
+}
+
+// validateManualStep validates a manual step. Its content holds the instructions of the task,
+// which can contain merge fields.
+func validateManualStep(s Step) error {
+	if s.Assignment == nil {
+		return errors.New("assignment is required for manual steps")
+	}
+
+	if s.Subject != "" || len(s.Variants) > 0 || s.Branch != nil {
+		return errors.New("manual steps cannot have a subject, variants or a branch")
+	}
+
+	if err := s.Assignment.Validate(); err != nil {
+		return err
+	}
+
+	switch s.Kind {
+	case StepKindLinkedIn:
+		if utf8.RuneCountInString(s.Content) > MaxLinkedInNoteLength {
+			return fmt.Errorf("content of LinkedIn steps cannot be longer than %d characters", MaxLinkedInNoteLength)
+		}
+	case StepKindTask:
+		if strings.TrimSpace(s.Content) == "" {
+			return errors.New("content is required for task steps, describing what to do")
+		}
+	}
+
+	if _, err := templating.Parse(s.Content); err != nil {
+		return fmt.Errorf("content: %w", err)
+	}
+
+	return s.Delay.Validate()
+}
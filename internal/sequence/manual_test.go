@@ -0,0 +1,93 @@
+package sequence_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+func TestStep_Validate_Manual(t *testing.T) {
+	assignment := &sequence.Assignment{Assignee: "jane@example.com", DueIn: sequence.Delay{Amount: 1, Unit: sequence.DelayUnitBusinessDays}}
+
+	testCases := []struct {
+		name     string
+		step     sequence.Step
+		expected error
+	}{
+		{
+			name:     "Valid call",
+			step:     sequence.Step{Kind: sequence.StepKindCall, Assignment: assignment},
+			expected: nil,
+		},
+		{
+			name:     "Valid LinkedIn note",
+			step:     sequence.Step{Kind: sequence.StepKindLinkedIn, Content: "Hi {{firstName|there}}, let's connect!", Assignment: assignment},
+			expected: nil,
+		},
+		{
+			name:     "Valid task",
+			step:     sequence.Step{Kind: sequence.StepKindTask, Content: "Send a gift card", Assignment: assignment},
+			expected: nil,
+		},
+		{
+			name:     "Missing assignment",
+			step:     sequence.Step{Kind: sequence.StepKindCall},
+			expected: errors.New("assignment is required for manual steps"),
+		},
+		{
+			name:     "Missing assignee",
+			step:     sequence.Step{Kind: sequence.StepKindCall, Assignment: &sequence.Assignment{Assignee: " "}},
+			expected: errors.New("assignee is required for manual steps"),
+		},
+		{
+			name:     "Assignee too long",
+			step:     sequence.Step{Kind: sequence.StepKindCall, Assignment: &sequence.Assignment{Assignee: strings.Repeat("a", 255)}},
+			expected: errors.New("assignee cannot be longer than 254 characters"),
+		},
+		{
+			name:     "Invalid due in",
+			step:     sequence.Step{Kind: sequence.StepKindCall, Assignment: &sequence.Assignment{Assignee: "jane@example.com", DueIn: sequence.Delay{Amount: -1}}},
+			expected: errors.New("due in: delay amount cannot be negative"),
+		},
+		{
+			name:     "Call with subject",
+			step:     sequence.Step{Kind: sequence.StepKindCall, Subject: "Subject", Assignment: assignment},
+			expected: errors.New("manual steps cannot have a subject, variants or a branch"),
+		},
+		{
+			name:     "LinkedIn note too long",
+			step:     sequence.Step{Kind: sequence.StepKindLinkedIn, Content: strings.Repeat("a", 301), Assignment: assignment},
+			expected: errors.New("content of LinkedIn steps cannot be longer than 300 characters"),
+		},
+		{
+			name:     "Task without content",
+			step:     sequence.Step{Kind: sequence.StepKindTask, Assignment: assignment},
+			expected: errors.New("content is required for task steps, describing what to do"),
+		},
+		{
+			name:     "Invalid instructions",
+			step:     sequence.Step{Kind: sequence.StepKindCall, Content: "Ask {{firstName", Assignment: assignment},
+			expected: errors.New("content: invalid merge field at position 4: missing closing }}"),
+		},
+		{
+			name:     "Email with assignment",
+			step:     sequence.Step{Subject: "Subject", Content: "Content", Assignment: assignment},
+			expected: errors.New("only manual steps can have an assignment"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.step.Validate()
+			if err == nil && tc.expected != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.expected)
+			} else if err != nil && tc.expected == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.expected != nil && err.Error() != tc.expected.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -3,8 +3,14 @@ package sequence
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/cybre/salesforge-assignment/pkg/listing"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // PostgresRepository is a repository containing sequences using Postgres.
@@ -20,48 +26,101 @@ func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
 }
 
 const createSequenceQuery = `
-INSERT INTO sequence (name, open_tracking_enabled, click_tracking_enabled) VALUES ($1, $2, $3) RETURNING id;
+INSERT INTO sequence (name, open_tracking_enabled, click_tracking_enabled, schedule) VALUES ($1, $2, $3, $4) RETURNING id;
 `
 const createStepQuery = `
-INSERT INTO step (sequence_id, subject, content) VALUES ($1, $2, $3);
+INSERT INTO step (sequence_id, position, kind, subject, content, delay_amount, delay_unit, branch_condition, assignee, due_in_amount, due_in_unit) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id;
 `
 
-// CreateSequence creates a new sequence.
-func (r PostgresRepository) CreateSequence(ctx context.Context, seq Sequence) error {
+// setBranchTargetsQuery stores the targets of a branch as the IDs of the steps at the given
+// positions, so branches keep their targets when steps are added, removed or reordered.
+const setBranchTargetsQuery = `
+UPDATE step SET
+then_step_id = (SELECT target.id FROM step target WHERE target.sequence_id = step.sequence_id AND target.position = $1),
+else_step_id = (SELECT target.id FROM step target WHERE target.sequence_id = step.sequence_id AND target.position = $2)
+WHERE step.id = $3;
+`
+
+// CreateSequence creates a new sequence and returns it with the IDs and positions assigned to it and its steps.
+func (r PostgresRepository) CreateSequence(ctx context.Context, seq Sequence) (Sequence, error) {
+	schedule, err := encodeSchedule(seq.Schedule)
+	if err != nil {
+		return Sequence{}, err
+	}
+
 	tx, err := r.db.Beginx()
 	if err != nil {
-		return err
+		return Sequence{}, err
 	}
 
+	created := seq
+	created.Steps = make([]Step, len(seq.Steps))
 	if err := func() error {
-		var seqID int64
-		if err := tx.QueryRowxContext(ctx, createSequenceQuery, seq.Name, seq.OpenTracking, seq.ClickTracking).Scan(&seqID); err != nil {
+		if err := tx.QueryRowxContext(ctx, createSequenceQuery, seq.Name, seq.OpenTracking, seq.ClickTracking, schedule).Scan(&created.ID); err != nil {
 			return err
 		}
 
-		for _, step := range seq.Steps {
-			_, err = tx.ExecContext(ctx, createStepQuery, seqID, step.Subject, step.Content)
+		for i, step := range seq.Steps {
+			step.Position = i
+			step.Delay.Unit = step.Delay.unitOrDefault()
+			step.Kind = step.kindOrDefault()
+			if step.Assignment != nil {
+				assignment := *step.Assignment
+				assignment.Assignee = strings.TrimSpace(assignment.Assignee)
+				assignment.DueIn.Unit = assignment.DueIn.unitOrDefault()
+				step.Assignment = &assignment
+			}
+
+			assignee, dueInAmount, dueInUnit := assignmentColumns(step)
+			if err := tx.QueryRowxContext(ctx, createStepQuery, created.ID, i, step.Kind, step.Subject, step.Content, step.Delay.Amount, step.Delay.Unit, branchCondition(step), assignee, dueInAmount, dueInUnit).Scan(&step.ID); err != nil {
+				return err
+			}
+
+			variants, err := createVariants(ctx, tx, step.ID, step.Variants)
 			if err != nil {
 				return err
 			}
+
+			step.Variants = variants
+			created.Steps[i] = step
+		}
+
+		// Branches can target later steps, so targets are set once every step exists.
+		for _, step := range created.Steps {
+			if step.Branch == nil {
+				continue
+			}
+
+			if err := setBranchTargets(ctx, tx, step); err != nil {
+				return err
+			}
 		}
 
 		return nil
 	}(); err != nil {
 		tx.Rollback()
-		return err
+		return Sequence{}, err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return Sequence{}, err
+	}
+
+	return created, nil
 }
 
 const updateSequenceQuery = `
-UPDATE sequence SET name = $1, open_tracking_enabled = $2, click_tracking_enabled = $3 WHERE id = $4;
+UPDATE sequence SET name = $1, open_tracking_enabled = $2, click_tracking_enabled = $3, schedule = $4 WHERE id = $5;
 `
 
 // UpdateSequence updates a sequence.
 func (r PostgresRepository) UpdateSequence(ctx context.Context, seq Sequence) (bool, error) {
-	res, err := r.db.ExecContext(ctx, updateSequenceQuery, seq.Name, seq.OpenTracking, seq.ClickTracking, seq.ID)
+	schedule, err := encodeSchedule(seq.Schedule)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.db.ExecContext(ctx, updateSequenceQuery, seq.Name, seq.OpenTracking, seq.ClickTracking, schedule, seq.ID)
 	if err != nil {
 		return false, err
 	}
@@ -74,17 +133,36 @@ func (r PostgresRepository) UpdateSequence(ctx context.Context, seq Sequence) (b
 	return rows > 0, nil
 }
 
+// publishedVersionColumn selects the latest published version of the sequence, or zero.
+const publishedVersionColumn = `(SELECT COALESCE(MAX(version), 0) FROM sequence_version WHERE sequence_id = sequence.id) AS published_version`
+
 const getSequenceQuery = `
-SELECT sequence.id, sequence.name, sequence.open_tracking_enabled, sequence.click_tracking_enabled, step.id as step_id, step.subject, step.content
+SELECT sequence.id, sequence.name, sequence.open_tracking_enabled, sequence.click_tracking_enabled, sequence.archived_at, sequence.schedule, ` + publishedVersionColumn + `, step.id as step_id, step.position, step.kind, step.subject, step.content, step.delay_amount, step.delay_unit, step.branch_condition, step.then_step_id, step.else_step_id, step.assignee, step.due_in_amount, step.due_in_unit
 FROM sequence
 LEFT JOIN step ON sequence.id = step.sequence_id 
-WHERE sequence.id = $1 ORDER BY step.id;
+WHERE sequence.id = $1 ORDER BY step.position;
 `
 
-// GetSequence gets a sequence by ID.
+// GetSequence gets a sequence by ID, including archived sequences.
 func (r PostgresRepository) GetSequence(ctx context.Context, id int) (Sequence, bool, error) {
+	return r.getSequence(ctx, getSequenceQuery, id)
+}
+
+const getSequenceByStepIDQuery = `
+SELECT sequence.id, sequence.name, sequence.open_tracking_enabled, sequence.click_tracking_enabled, sequence.archived_at, sequence.schedule, ` + publishedVersionColumn + `, step.id as step_id, step.position, step.kind, step.subject, step.content, step.delay_amount, step.delay_unit, step.branch_condition, step.then_step_id, step.else_step_id, step.assignee, step.due_in_amount, step.due_in_unit
+FROM sequence
+LEFT JOIN step ON sequence.id = step.sequence_id 
+WHERE sequence.id = (SELECT sequence_id FROM step WHERE id = $1) ORDER BY step.position;
+`
+
+// GetSequenceByStepID gets the sequence that contains the step with the given ID.
+func (r PostgresRepository) GetSequenceByStepID(ctx context.Context, stepID int) (Sequence, bool, error) {
+	return r.getSequence(ctx, getSequenceByStepIDQuery, stepID)
+}
+
+func (r PostgresRepository) getSequence(ctx context.Context, query string, args ...any) (Sequence, bool, error) {
 	seq := GetSequenceRows{}
-	err := r.db.SelectContext(ctx, &seq, getSequenceQuery, id)
+	err := r.db.SelectContext(ctx, &seq, query, args...)
 	if err != nil {
 		return Sequence{}, false, err
 	}
@@ -93,16 +171,26 @@ func (r PostgresRepository) GetSequence(ctx context.Context, id int) (Sequence,
 		return Sequence{}, false, nil
 	}
 
-	return seq.ToSequence(), true, nil
+	converted, err := seq.ToSequence()
+	if err != nil {
+		return Sequence{}, false, err
+	}
+
+	if err := r.loadVariants(ctx, []Sequence{converted}); err != nil {
+		return Sequence{}, false, err
+	}
+
+	return converted, true, nil
 }
 
-const updateStepQuery = `
-UPDATE step SET subject = $1, content = $2 WHERE id = $3;
+const setSequenceArchivedQuery = `
+UPDATE sequence SET archived_at = CASE WHEN $1 THEN COALESCE(archived_at, NOW()) END WHERE id = $2;
 `
 
-// UpdateStep updates a sequence step.
-func (r PostgresRepository) UpdateStep(ctx context.Context, step Step) (bool, error) {
-	res, err := r.db.ExecContext(ctx, updateStepQuery, step.Subject, step.Content, step.ID)
+// SetSequenceArchived archives or restores a sequence. Archiving an already archived
+// sequence keeps its original archive time.
+func (r PostgresRepository) SetSequenceArchived(ctx context.Context, id int, archived bool) (bool, error) {
+	res, err := r.db.ExecContext(ctx, setSequenceArchivedQuery, archived, id)
 	if err != nil {
 		return false, err
 	}
@@ -115,53 +203,804 @@ func (r PostgresRepository) UpdateStep(ctx context.Context, step Step) (bool, er
 	return rows > 0, nil
 }
 
+const deleteSequenceQuery = `
+DELETE FROM sequence WHERE id = $1;
+`
+
+// DeleteSequence deletes a sequence. Its steps are deleted by the cascading foreign key.
+func (r PostgresRepository) DeleteSequence(ctx context.Context, id int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, deleteSequenceQuery, id)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+const openStepGapQuery = `
+UPDATE step SET position = position + 1 WHERE sequence_id = $1 AND position >= $2;
+`
+
+// CreateStep inserts a step into a sequence at the step's position, shifting the
+// steps at and after that position down by one.
+func (r PostgresRepository) CreateStep(ctx context.Context, sequenceID int, step Step) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	if err := func() error {
+		if _, err := tx.ExecContext(ctx, openStepGapQuery, sequenceID, step.Position); err != nil {
+			return err
+		}
+
+		assignee, dueInAmount, dueInUnit := assignmentColumns(step)
+		if err := tx.QueryRowxContext(ctx, createStepQuery, sequenceID, step.Position, step.kindOrDefault(), step.Subject, step.Content, step.Delay.Amount, step.Delay.unitOrDefault(), branchCondition(step), assignee, dueInAmount, dueInUnit).Scan(&id); err != nil {
+			return err
+		}
+
+		if step.Branch != nil {
+			step.ID = id
+			if err := setBranchTargets(ctx, tx, step); err != nil {
+				return err
+			}
+		}
+
+		_, err := createVariants(ctx, tx, id, step.Variants)
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+const listSequencesQuery = `
+SELECT id, name, open_tracking_enabled, click_tracking_enabled, archived_at, schedule, ` + publishedVersionColumn + ` FROM sequence %s ORDER BY %s LIMIT %d;
+`
+const listStepsQuery = `
+SELECT id, sequence_id, position, kind, subject, content, delay_amount, delay_unit, branch_condition, then_step_id, else_step_id, assignee, due_in_amount, due_in_unit
+FROM step
+WHERE sequence_id = ANY($1) ORDER BY sequence_id, position;
+`
+
+// ListSequences lists the sequences matching the filter. Steps are loaded with a single
+// additional query, and only when the filter asks for them.
+func (r PostgresRepository) ListSequences(ctx context.Context, filter ListFilter) ([]Sequence, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !filter.IncludeArchived {
+		conditions = append(conditions, "archived_at IS NULL")
+	}
+
+	if filter.Name != "" {
+		conditions = append(conditions, "name ILIKE "+arg("%"+listing.EscapeLike(filter.Name)+"%"))
+	}
+
+	if filter.OpenTracking != nil {
+		conditions = append(conditions, "open_tracking_enabled = "+arg(*filter.OpenTracking))
+	}
+
+	if filter.ClickTracking != nil {
+		conditions = append(conditions, "click_tracking_enabled = "+arg(*filter.ClickTracking))
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Order == SortDescending {
+		direction, comparison = "DESC", "<"
+	}
+
+	orderBy := "id " + direction
+	if filter.SortBy == SortByName {
+		orderBy = fmt.Sprintf("name %s, id %s", direction, direction)
+	}
+
+	if filter.After != nil {
+		if filter.SortBy == SortByName {
+			conditions = append(conditions, fmt.Sprintf("(name, id) %s (%s, %s)", comparison, arg(filter.After.Name), arg(filter.After.ID)))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("id %s %s", comparison, arg(filter.After.ID)))
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows := []SequenceRow{}
+	if err := r.db.SelectContext(ctx, &rows, fmt.Sprintf(listSequencesQuery, where, orderBy, filter.Limit), args...); err != nil {
+		return nil, err
+	}
+
+	sequences := make([]Sequence, len(rows))
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		seq, err := row.ToSequence()
+		if err != nil {
+			return nil, err
+		}
+
+		seq.Steps = []Step{}
+		sequences[i] = seq
+		ids[i] = row.ID
+	}
+
+	if !filter.IncludeSteps || len(sequences) == 0 {
+		return sequences, nil
+	}
+
+	stepRows := []StepRow{}
+	if err := r.db.SelectContext(ctx, &stepRows, listStepsQuery, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+
+	steps := make(map[int]StepRows, len(sequences))
+	for _, row := range stepRows {
+		steps[row.SequenceID] = append(steps[row.SequenceID], row)
+	}
+
+	for i := range sequences {
+		sequences[i].Steps = steps[sequences[i].ID].ToSteps()
+	}
+
+	if err := r.loadVariants(ctx, sequences); err != nil {
+		return nil, err
+	}
+
+	return sequences, nil
+}
+
+const updateStepQuery = `
+UPDATE step SET kind = $1, subject = $2, content = $3, delay_amount = $4, delay_unit = $5, branch_condition = $6, assignee = $7, due_in_amount = $8, due_in_unit = $9 WHERE id = $10;
+`
+
+const deleteRemovedVariantsQuery = `
+DELETE FROM step_variant WHERE step_id = $1 AND NOT (id = ANY($2));
+`
+const updateVariantQuery = `
+UPDATE step_variant SET position = $1, label = $2, subject = $3, content = $4, weight = $5, active = $6 WHERE id = $7 AND step_id = $8;
+`
+
+// UpdateStep updates a sequence step and replaces its variants. Variants with an ID are
+// updated, variants without one are created and the variants left out are deleted.
+func (r PostgresRepository) UpdateStep(ctx context.Context, step Step) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	var updated bool
+	if err := func() error {
+		assignee, dueInAmount, dueInUnit := assignmentColumns(step)
+		res, err := tx.ExecContext(ctx, updateStepQuery, step.kindOrDefault(), step.Subject, step.Content, step.Delay.Amount, step.Delay.unitOrDefault(), branchCondition(step), assignee, dueInAmount, dueInUnit, step.ID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if updated = rows > 0; !updated {
+			return nil
+		}
+
+		if err := setBranchTargets(ctx, tx, step); err != nil {
+			return err
+		}
+
+		kept := []int{}
+		for _, v := range step.Variants {
+			if v.ID != 0 {
+				kept = append(kept, v.ID)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteRemovedVariantsQuery, step.ID, pq.Array(kept)); err != nil {
+			return err
+		}
+
+		for i, v := range step.Variants {
+			if v.ID == 0 {
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx, updateVariantQuery, i, v.Label, v.Subject, v.Content, v.Weight, v.Active, v.ID, step.ID); err != nil {
+				return err
+			}
+		}
+
+		_, err = createVariants(ctx, tx, step.ID, step.Variants)
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return updated, tx.Commit()
+}
+
+// setBranchTargets stores the targets of a step, which are cleared for steps other than branches.
+func setBranchTargets(ctx context.Context, tx *sqlx.Tx, step Step) error {
+	var then, otherwise *int
+	if step.Branch != nil {
+		then, otherwise = step.Branch.Then, step.Branch.Else
+	}
+
+	_, err := tx.ExecContext(ctx, setBranchTargetsQuery, then, otherwise, step.ID)
+	return err
+}
+
+// branchCondition returns the condition of a branch step for the branch_condition column,
+// which is null for other steps.
+func branchCondition(step Step) sql.NullString {
+	if step.Branch == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: string(step.Branch.Condition), Valid: true}
+}
+
+// assignmentColumns returns the assignment of a manual step for the assignee, due_in_amount and
+// due_in_unit columns, which are null for other steps.
+func assignmentColumns(step Step) (sql.NullString, sql.NullInt64, sql.NullString) {
+	if step.Assignment == nil {
+		return sql.NullString{}, sql.NullInt64{}, sql.NullString{}
+	}
+
+	a := step.Assignment
+	return sql.NullString{String: strings.TrimSpace(a.Assignee), Valid: true},
+		sql.NullInt64{Int64: int64(a.DueIn.Amount), Valid: true},
+		sql.NullString{String: string(a.DueIn.unitOrDefault()), Valid: true}
+}
+
+const createVariantQuery = `
+INSERT INTO step_variant (step_id, position, label, subject, content, weight, active) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;
+`
+
+// createVariants creates the variants of a step that do not have an ID yet, positioned in
+// the order they are given in, and returns the variants with their IDs.
+func createVariants(ctx context.Context, tx *sqlx.Tx, stepID int, variants []Variant) ([]Variant, error) {
+	created := make([]Variant, len(variants))
+	for i, v := range variants {
+		if v.ID == 0 {
+			if err := tx.QueryRowxContext(ctx, createVariantQuery, stepID, i, v.Label, v.Subject, v.Content, v.Weight, v.Active).Scan(&v.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		created[i] = v
+	}
+
+	return created, nil
+}
+
+const listVariantsQuery = `
+SELECT id, step_id, label, subject, content, weight, active
+FROM step_variant
+WHERE step_id = ANY($1) ORDER BY step_id, position;
+`
+
+// loadVariants loads the variants of the steps of the sequences with a single query.
+func (r PostgresRepository) loadVariants(ctx context.Context, sequences []Sequence) error {
+	stepIDs := []int{}
+	for _, seq := range sequences {
+		for _, step := range seq.Steps {
+			stepIDs = append(stepIDs, step.ID)
+		}
+	}
+
+	if len(stepIDs) == 0 {
+		return nil
+	}
+
+	rows := []VariantRow{}
+	if err := r.db.SelectContext(ctx, &rows, listVariantsQuery, pq.Array(stepIDs)); err != nil {
+		return err
+	}
+
+	variants := make(map[int][]Variant)
+	for _, row := range rows {
+		variants[row.StepID] = append(variants[row.StepID], row.ToVariant())
+	}
+
+	for _, seq := range sequences {
+		for i := range seq.Steps {
+			seq.Steps[i].Variants = variants[seq.Steps[i].ID]
+		}
+	}
+
+	return nil
+}
+
+const setWinningVariantQuery = `
+UPDATE step_variant SET active = (id = $2) WHERE step_id = $1;
+`
+
+const upsertStepWinnerQuery = `
+INSERT INTO step_winner (step_id, sequence_id, variant_id) SELECT id, sequence_id, $2 FROM step WHERE id = $1
+ON CONFLICT (step_id) DO UPDATE SET variant_id = EXCLUDED.variant_id, declared_at = NOW();
+`
+
+// SetWinningVariant activates the winning variant of a step and deactivates the others in the
+// draft, and records the winner for the versions published before it was declared.
+func (r PostgresRepository) SetWinningVariant(ctx context.Context, stepID int, variantID int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if err := func() error {
+		if _, err := tx.ExecContext(ctx, setWinningVariantQuery, stepID, variantID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, upsertStepWinnerQuery, stepID, variantID)
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 const deleteStepQuery = `
-DELETE FROM step WHERE id = $1;
+DELETE FROM step WHERE id = $1 RETURNING sequence_id, position;
+`
+const closeStepGapQuery = `
+UPDATE step SET position = position - 1 WHERE sequence_id = $1 AND position > $2;
 `
 
-// DeleteStep deletes a sequence step.
+// DeleteStep deletes a sequence step and shifts the steps after it up by one position.
 func (r PostgresRepository) DeleteStep(ctx context.Context, id int) error {
-	_, err := r.db.ExecContext(ctx, deleteStepQuery, id)
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if err := func() error {
+		var seqID, position int
+		if err := tx.QueryRowxContext(ctx, deleteStepQuery, id).Scan(&seqID, &position); err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, closeStepGapQuery, seqID, position)
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const reorderStepsQuery = `
+UPDATE step SET position = ordered.position - 1
+FROM unnest($1::integer[]) WITH ORDINALITY AS ordered(id, position)
+WHERE step.id = ordered.id AND step.sequence_id = $2;
+`
+
+// ReorderSteps sets the positions of the steps of a sequence to the order of the given step IDs.
+func (r PostgresRepository) ReorderSteps(ctx context.Context, sequenceID int, stepIDs []int) error {
+	_, err := r.db.ExecContext(ctx, reorderStepsQuery, pq.Array(stepIDs), sequenceID)
 	return err
 }
 
+// lockSequenceQuery locks the sequence, so versions published at the same time are numbered
+// one after the other.
+const lockSequenceQuery = `
+SELECT id FROM sequence WHERE id = $1 FOR UPDATE;
+`
+const createVersionQuery = `
+INSERT INTO sequence_version (sequence_id, version, steps)
+SELECT $1, COALESCE(MAX(version), 0) + 1, $2::jsonb FROM sequence_version WHERE sequence_id = $1
+RETURNING sequence_id, version, steps, published_at;
+`
+
+// CreateVersion snapshots the steps as the next version of the sequence.
+func (r PostgresRepository) CreateVersion(ctx context.Context, sequenceID int, steps []Step) (Version, error) {
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return Version{}, err
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Version{}, err
+	}
+
+	row := VersionRow{}
+	if err := func() error {
+		var id int
+		if err := tx.GetContext(ctx, &id, lockSequenceQuery, sequenceID); err != nil {
+			return err
+		}
+
+		return tx.GetContext(ctx, &row, createVersionQuery, sequenceID, data)
+	}(); err != nil {
+		tx.Rollback()
+		return Version{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Version{}, err
+	}
+
+	return row.ToVersion()
+}
+
+const listVersionsQuery = `
+SELECT sequence_id, version, steps, published_at FROM sequence_version WHERE sequence_id = $1 ORDER BY version;
+`
+
+// ListVersions lists the versions of a sequence in order of their numbers.
+func (r PostgresRepository) ListVersions(ctx context.Context, sequenceID int) ([]Version, error) {
+	rows := []VersionRow{}
+	if err := r.db.SelectContext(ctx, &rows, listVersionsQuery, sequenceID); err != nil {
+		return nil, err
+	}
+
+	versions := make([]Version, len(rows))
+	for i, row := range rows {
+		version, err := row.ToVersion()
+		if err != nil {
+			return nil, err
+		}
+
+		versions[i] = version
+	}
+
+	return versions, nil
+}
+
+const getVersionQuery = `
+SELECT sequence_id, version, steps, published_at FROM sequence_version WHERE sequence_id = $1 AND version = $2;
+`
+
+// GetVersion gets a version of a sequence by its number.
+func (r PostgresRepository) GetVersion(ctx context.Context, sequenceID int, version int) (Version, bool, error) {
+	row := VersionRow{}
+	if err := r.db.GetContext(ctx, &row, getVersionQuery, sequenceID, version); err != nil {
+		if err == sql.ErrNoRows {
+			return Version{}, false, nil
+		}
+
+		return Version{}, false, err
+	}
+
+	v, err := row.ToVersion()
+	if err != nil {
+		return Version{}, false, err
+	}
+
+	return v, true, nil
+}
+
+const listVersionWinnersQuery = `
+SELECT step_winner.step_id, step_winner.variant_id FROM step_winner
+JOIN sequence_version ON sequence_version.sequence_id = step_winner.sequence_id
+WHERE sequence_version.sequence_id = $1 AND sequence_version.version = $2 AND step_winner.declared_at > sequence_version.published_at;
+`
+
+// GetSequenceVersion gets a sequence by ID with the steps of one of its versions instead of
+// the draft steps, including archived sequences. It reports false when either does not exist.
+// The steps carry the winners of A/B tests declared after the version was published.
+func (r PostgresRepository) GetSequenceVersion(ctx context.Context, id int, version int) (Sequence, bool, error) {
+	seq, exists, err := r.GetSequence(ctx, id)
+	if err != nil || !exists {
+		return Sequence{}, false, err
+	}
+
+	v, exists, err := r.GetVersion(ctx, id, version)
+	if err != nil || !exists {
+		return Sequence{}, false, err
+	}
+
+	winners := []StepWinnerRow{}
+	if err := r.db.SelectContext(ctx, &winners, listVersionWinnersQuery, id, version); err != nil {
+		return Sequence{}, false, err
+	}
+
+	for _, winner := range winners {
+		variantID := winner.VariantID
+		for i := range v.Steps {
+			if v.Steps[i].ID == winner.StepID {
+				v.Steps[i].WinnerID = &variantID
+			}
+		}
+	}
+
+	seq.PublishedVersion = v.Version
+	seq.Steps = v.Steps
+	return seq, true, nil
+}
+
+// SequenceRow represents a row of the sequence table.
+type SequenceRow struct {
+	ID                   int          `db:"id"`
+	Name                 string       `db:"name"`
+	OpenTrackingEnabled  bool         `db:"open_tracking_enabled"`
+	ClickTrackingEnabled bool         `db:"click_tracking_enabled"`
+	ArchivedAt           sql.NullTime `db:"archived_at"`
+	Schedule             []byte       `db:"schedule"`
+	PublishedVersion     int          `db:"published_version"`
+}
+
+// ToSequence converts the row to a sequence domain model without steps.
+func (r SequenceRow) ToSequence() (Sequence, error) {
+	schedule, err := decodeSchedule(r.Schedule)
+	if err != nil {
+		return Sequence{}, fmt.Errorf("failed to decode schedule of sequence %d: %w", r.ID, err)
+	}
+
+	return Sequence{
+		ID:               r.ID,
+		Name:             r.Name,
+		OpenTracking:     r.OpenTrackingEnabled,
+		ClickTracking:    r.ClickTrackingEnabled,
+		ArchivedAt:       nullTimePtr(r.ArchivedAt),
+		Schedule:         schedule,
+		PublishedVersion: r.PublishedVersion,
+	}, nil
+}
+
+// StepRow represents a row of the step table.
+type StepRow struct {
+	ID              int            `db:"id"`
+	SequenceID      int            `db:"sequence_id"`
+	Position        int            `db:"position"`
+	Kind            string         `db:"kind"`
+	Subject         string         `db:"subject"`
+	Content         string         `db:"content"`
+	DelayAmount     int            `db:"delay_amount"`
+	DelayUnit       string         `db:"delay_unit"`
+	BranchCondition sql.NullString `db:"branch_condition"`
+	ThenStepID      sql.NullInt64  `db:"then_step_id"`
+	ElseStepID      sql.NullInt64  `db:"else_step_id"`
+	Assignee        sql.NullString `db:"assignee"`
+	DueInAmount     sql.NullInt64  `db:"due_in_amount"`
+	DueInUnit       sql.NullString `db:"due_in_unit"`
+}
+
+// ToStep converts the row to a step domain model. The targets of a branch are stored as step
+// IDs, so they are left out and resolved to positions by StepRows.ToSteps.
+func (r StepRow) ToStep() Step {
+	step := Step{
+		ID:       r.ID,
+		Position: r.Position,
+		Kind:     StepKind(r.Kind),
+		Subject:  r.Subject,
+		Content:  r.Content,
+		Delay: Delay{
+			Amount: r.DelayAmount,
+			Unit:   DelayUnit(r.DelayUnit),
+		},
+	}
+
+	if r.BranchCondition.Valid {
+		step.Branch = &Branch{Condition: BranchCondition(r.BranchCondition.String)}
+	}
+
+	if r.Assignee.Valid {
+		step.Assignment = &Assignment{
+			Assignee: r.Assignee.String,
+			DueIn: Delay{
+				Amount: int(r.DueInAmount.Int64),
+				Unit:   DelayUnit(r.DueInUnit.String),
+			},
+		}
+	}
+
+	return step
+}
+
+// StepRows represents the rows of the steps of a sequence, ordered by position.
+type StepRows []StepRow
+
+// ToSteps converts the rows to step domain models with the targets of branches resolved.
+func (r StepRows) ToSteps() []Step {
+	positions := make(map[int64]int, len(r))
+	for _, row := range r {
+		positions[int64(row.ID)] = row.Position
+	}
+
+	steps := make([]Step, len(r))
+	for i, row := range r {
+		steps[i] = row.ToStep()
+		if steps[i].Branch != nil {
+			steps[i].Branch.Then = targetPosition(row.ThenStepID, positions)
+			steps[i].Branch.Else = targetPosition(row.ElseStepID, positions)
+		}
+	}
+
+	return steps
+}
+
+// targetPosition resolves the ID of the target of a branch to the position of the step.
+func targetPosition(id sql.NullInt64, positions map[int64]int) *int {
+	position, ok := positions[id.Int64]
+	if !id.Valid || !ok {
+		return nil
+	}
+
+	return &position
+}
+
+// VariantRow represents a row of the step_variant table.
+type VariantRow struct {
+	ID      int    `db:"id"`
+	StepID  int    `db:"step_id"`
+	Label   string `db:"label"`
+	Subject string `db:"subject"`
+	Content string `db:"content"`
+	Weight  int    `db:"weight"`
+	Active  bool   `db:"active"`
+}
+
+// ToVariant converts the row to a variant domain model.
+func (r VariantRow) ToVariant() Variant {
+	return Variant{
+		ID:      r.ID,
+		Label:   r.Label,
+		Subject: r.Subject,
+		Content: r.Content,
+		Weight:  r.Weight,
+		Active:  r.Active,
+	}
+}
+
+// VersionRow represents a row of the sequence_version table.
+type VersionRow struct {
+	SequenceID  int       `db:"sequence_id"`
+	Version     int       `db:"version"`
+	Steps       []byte    `db:"steps"`
+	PublishedAt time.Time `db:"published_at"`
+}
+
+// ToVersion converts the row to a version domain model.
+func (r VersionRow) ToVersion() (Version, error) {
+	steps := []Step{}
+	if err := json.Unmarshal(r.Steps, &steps); err != nil {
+		return Version{}, fmt.Errorf("failed to decode steps of version %d of sequence %d: %w", r.Version, r.SequenceID, err)
+	}
+
+	return Version{
+		SequenceID:  r.SequenceID,
+		Version:     r.Version,
+		Steps:       steps,
+		PublishedAt: r.PublishedAt,
+	}, nil
+}
+
+// StepWinnerRow represents a row of the step_winner table.
+type StepWinnerRow struct {
+	StepID    int `db:"step_id"`
+	VariantID int `db:"variant_id"`
+}
+
 // GetSequenceRow represents a row returned from the get sequence query.
 type GetSequenceRow struct {
 	ID                   int            `db:"id"`
 	Name                 string         `db:"name"`
 	OpenTrackingEnabled  bool           `db:"open_tracking_enabled"`
 	ClickTrackingEnabled bool           `db:"click_tracking_enabled"`
+	ArchivedAt           sql.NullTime   `db:"archived_at"`
+	Schedule             []byte         `db:"schedule"`
+	PublishedVersion     int            `db:"published_version"`
 	StepID               sql.NullInt64  `db:"step_id"`
+	Position             sql.NullInt64  `db:"position"`
+	Kind                 sql.NullString `db:"kind"`
 	Subject              sql.NullString `db:"subject"`
 	Content              sql.NullString `db:"content"`
+	DelayAmount          sql.NullInt64  `db:"delay_amount"`
+	DelayUnit            sql.NullString `db:"delay_unit"`
+	BranchCondition      sql.NullString `db:"branch_condition"`
+	ThenStepID           sql.NullInt64  `db:"then_step_id"`
+	ElseStepID           sql.NullInt64  `db:"else_step_id"`
+	Assignee             sql.NullString `db:"assignee"`
+	DueInAmount          sql.NullInt64  `db:"due_in_amount"`
+	DueInUnit            sql.NullString `db:"due_in_unit"`
 }
 
 // GetSequenceRows represents multiple rows returned from the get sequence query.
 type GetSequenceRows []GetSequenceRow
 
 // ToSequence converts the rows to a sequence domain model.
-func (r GetSequenceRows) ToSequence() Sequence {
+func (r GetSequenceRows) ToSequence() (Sequence, error) {
+	schedule, err := decodeSchedule(r[0].Schedule)
+	if err != nil {
+		return Sequence{}, fmt.Errorf("failed to decode schedule of sequence %d: %w", r[0].ID, err)
+	}
+
 	seq := Sequence{
-		ID:            r[0].ID,
-		Name:          r[0].Name,
-		OpenTracking:  r[0].OpenTrackingEnabled,
-		ClickTracking: r[0].ClickTrackingEnabled,
+		ID:               r[0].ID,
+		Name:             r[0].Name,
+		OpenTracking:     r[0].OpenTrackingEnabled,
+		ClickTracking:    r[0].ClickTrackingEnabled,
+		ArchivedAt:       nullTimePtr(r[0].ArchivedAt),
+		Schedule:         schedule,
+		PublishedVersion: r[0].PublishedVersion,
 	}
 
 	// If the first row has a step ID that is null, there are no steps.
 	if !r[0].StepID.Valid {
 		seq.Steps = []Step{}
-		return seq
+		return seq, nil
 	}
 
-	seq.Steps = make([]Step, len(r))
+	steps := make(StepRows, len(r))
 	for i, row := range r {
-		seq.Steps[i] = Step{
-			ID:      int(row.StepID.Int64),
-			Subject: row.Subject.String,
-			Content: row.Content.String,
+		steps[i] = StepRow{
+			ID:              int(row.StepID.Int64),
+			SequenceID:      row.ID,
+			Position:        int(row.Position.Int64),
+			Kind:            row.Kind.String,
+			Subject:         row.Subject.String,
+			Content:         row.Content.String,
+			DelayAmount:     int(row.DelayAmount.Int64),
+			DelayUnit:       row.DelayUnit.String,
+			BranchCondition: row.BranchCondition,
+			ThenStepID:      row.ThenStepID,
+			ElseStepID:      row.ElseStepID,
+			Assignee:        row.Assignee,
+			DueInAmount:     row.DueInAmount,
+			DueInUnit:       row.DueInUnit,
 		}
 	}
 
-	return seq
+	seq.Steps = steps.ToSteps()
+	return seq, nil
+}
+
+// encodeSchedule encodes a schedule for the schedule column, which is null when there is none.
+func encodeSchedule(schedule *Schedule) ([]byte, error) {
+	if schedule == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(schedule)
+}
+
+// decodeSchedule decodes the schedule column.
+func decodeSchedule(data []byte) (*Schedule, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var schedule Schedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// nullTimePtr converts a nullable time to a pointer that is nil when the time is null.
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+
+	return &t.Time
 }
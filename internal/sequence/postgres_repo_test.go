@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cybre/salesforge-assignment/internal/sequence"
 )
@@ -17,8 +18,11 @@ func TestGetSequenceRows_ToSequence(t *testing.T) {
 			OpenTrackingEnabled:  true,
 			ClickTrackingEnabled: false,
 			StepID:               sql.NullInt64{Int64: 1, Valid: true},
+			Position:             sql.NullInt64{Int64: 0, Valid: true},
 			Subject:              sql.NullString{String: "Step 1 Subject", Valid: true},
 			Content:              sql.NullString{String: "Step 1 Content", Valid: true},
+			DelayAmount:          sql.NullInt64{Int64: 0, Valid: true},
+			DelayUnit:            sql.NullString{String: "days", Valid: true},
 		},
 		{
 			ID:                   1,
@@ -26,8 +30,11 @@ func TestGetSequenceRows_ToSequence(t *testing.T) {
 			OpenTrackingEnabled:  true,
 			ClickTrackingEnabled: false,
 			StepID:               sql.NullInt64{Int64: 2, Valid: true},
+			Position:             sql.NullInt64{Int64: 1, Valid: true},
 			Subject:              sql.NullString{String: "Step 2 Subject", Valid: true},
 			Content:              sql.NullString{String: "Step 2 Content", Valid: true},
+			DelayAmount:          sql.NullInt64{Int64: 2, Valid: true},
+			DelayUnit:            sql.NullString{String: "businessDays", Valid: true},
 		},
 	}
 
@@ -38,20 +45,27 @@ func TestGetSequenceRows_ToSequence(t *testing.T) {
 		ClickTracking: false,
 		Steps: []sequence.Step{
 			{
-				ID:      1,
-				Subject: "Step 1 Subject",
-				Content: "Step 1 Content",
+				ID:       1,
+				Position: 0,
+				Subject:  "Step 1 Subject",
+				Content:  "Step 1 Content",
+				Delay:    sequence.Delay{Amount: 0, Unit: sequence.DelayUnitDays},
 			},
 			{
-				ID:      2,
-				Subject: "Step 2 Subject",
-				Content: "Step 2 Content",
+				ID:       2,
+				Position: 1,
+				Subject:  "Step 2 Subject",
+				Content:  "Step 2 Content",
+				Delay:    sequence.Delay{Amount: 2, Unit: sequence.DelayUnitBusinessDays},
 			},
 		},
 	}
 
 	// Call the ToSequence method
-	seq := rows.ToSequence()
+	seq, err := rows.ToSequence()
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
 
 	// Compare the result with the expected value
 	if !reflect.DeepEqual(seq, expectedResult) {
@@ -68,13 +82,17 @@ func TestGetSequenceRows_ToSequence_NoSteps(t *testing.T) {
 			OpenTrackingEnabled:  true,
 			ClickTrackingEnabled: false,
 			StepID:               sql.NullInt64{Valid: false},
+			Position:             sql.NullInt64{Valid: false},
 			Subject:              sql.NullString{Valid: false},
 			Content:              sql.NullString{Valid: false},
 		},
 	}
 
 	// Call the ToSequence method
-	seq := rows.ToSequence()
+	seq, err := rows.ToSequence()
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
 
 	// Assert the sequence properties
 	if seq.ID != 1 {
@@ -95,3 +113,191 @@ func TestGetSequenceRows_ToSequence_NoSteps(t *testing.T) {
 		t.Errorf("Expected 0 steps, but got %d", len(seq.Steps))
 	}
 }
+
+func TestStepRow_ToStep(t *testing.T) {
+	row := sequence.StepRow{
+		ID:          3,
+		SequenceID:  1,
+		Position:    2,
+		Subject:     "Step 3 Subject",
+		Content:     "Step 3 Content",
+		DelayAmount: 4,
+		DelayUnit:   "hours",
+	}
+
+	expected := sequence.Step{
+		ID:       3,
+		Position: 2,
+		Subject:  "Step 3 Subject",
+		Content:  "Step 3 Content",
+		Delay:    sequence.Delay{Amount: 4, Unit: sequence.DelayUnitHours},
+	}
+
+	if step := row.ToStep(); !reflect.DeepEqual(step, expected) {
+		t.Errorf("Expected %v, but got %v", expected, step)
+	}
+}
+
+func TestStepRow_ToStep_Manual(t *testing.T) {
+	row := sequence.StepRow{
+		ID:          3,
+		SequenceID:  1,
+		Position:    2,
+		Kind:        "call",
+		Content:     "Ask about {{company}}",
+		DelayUnit:   "days",
+		Assignee:    sql.NullString{String: "jane@example.com", Valid: true},
+		DueInAmount: sql.NullInt64{Int64: 2, Valid: true},
+		DueInUnit:   sql.NullString{String: "businessDays", Valid: true},
+	}
+
+	expected := sequence.Step{
+		ID:       3,
+		Position: 2,
+		Kind:     sequence.StepKindCall,
+		Content:  "Ask about {{company}}",
+		Delay:    sequence.Delay{Unit: sequence.DelayUnitDays},
+		Assignment: &sequence.Assignment{
+			Assignee: "jane@example.com",
+			DueIn:    sequence.Delay{Amount: 2, Unit: sequence.DelayUnitBusinessDays},
+		},
+	}
+
+	if step := row.ToStep(); !reflect.DeepEqual(step, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, step)
+	}
+}
+
+func TestSequenceRow_ToSequence(t *testing.T) {
+	archivedAt := time.Date(2024, time.May, 2, 9, 30, 0, 0, time.UTC)
+
+	row := sequence.SequenceRow{
+		ID:                   1,
+		Name:                 "Sequence 1",
+		OpenTrackingEnabled:  true,
+		ClickTrackingEnabled: true,
+		ArchivedAt:           sql.NullTime{Time: archivedAt, Valid: true},
+	}
+
+	expected := sequence.Sequence{
+		ID:            1,
+		Name:          "Sequence 1",
+		OpenTracking:  true,
+		ClickTracking: true,
+		ArchivedAt:    &archivedAt,
+	}
+
+	if seq, err := row.ToSequence(); err != nil || !reflect.DeepEqual(seq, expected) {
+		t.Errorf("Expected %v, but got %v, %v", expected, seq, err)
+	}
+
+	row.ArchivedAt = sql.NullTime{}
+	if seq, _ := row.ToSequence(); seq.Archived() {
+		t.Errorf("Expected sequence not to be archived")
+	}
+}
+
+func TestSequenceRow_ToSequence_Schedule(t *testing.T) {
+	row := sequence.SequenceRow{
+		ID:       1,
+		Name:     "Sequence 1",
+		Schedule: []byte(`{"days":["monday","friday"],"startHour":9,"endHour":17,"timeZone":"Europe/Zagreb","holidays":["2024-12-25"]}`),
+	}
+
+	expected := &sequence.Schedule{
+		Days:      []sequence.Weekday{sequence.Monday, sequence.Friday},
+		StartHour: 9,
+		EndHour:   17,
+		TimeZone:  "Europe/Zagreb",
+		Holidays:  []string{"2024-12-25"},
+	}
+
+	seq, err := row.ToSequence()
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	if !reflect.DeepEqual(seq.Schedule, expected) {
+		t.Errorf("Expected schedule %+v, but got %+v", expected, seq.Schedule)
+	}
+
+	row.Schedule = []byte(`{"days":`)
+	if _, err := row.ToSequence(); err == nil {
+		t.Errorf("Expected an error for a malformed schedule")
+	}
+}
+
+func TestVariantRow_ToVariant(t *testing.T) {
+	row := sequence.VariantRow{ID: 7, StepID: 3, Label: "A", Subject: "Subject A", Content: "Content A", Weight: 40, Active: true}
+
+	expected := sequence.Variant{ID: 7, Label: "A", Subject: "Subject A", Content: "Content A", Weight: 40, Active: true}
+	if v := row.ToVariant(); v != expected {
+		t.Errorf("Expected %v, but got %v", expected, v)
+	}
+}
+
+func TestStepRows_ToSteps(t *testing.T) {
+	rows := sequence.StepRows{
+		{ID: 4, Position: 0, Kind: "email", Subject: "Subject", Content: "Content", DelayUnit: "days"},
+		{
+			ID:              7,
+			Position:        1,
+			Kind:            "branch",
+			DelayAmount:     2,
+			DelayUnit:       "days",
+			BranchCondition: sql.NullString{String: "opened", Valid: true},
+			ThenStepID:      sql.NullInt64{Int64: 5, Valid: true},
+		},
+		{ID: 5, Position: 2, Kind: "email", Subject: "Subject", Content: "Content", DelayUnit: "days"},
+	}
+
+	then := 2
+	expected := []sequence.Step{
+		{ID: 4, Position: 0, Kind: sequence.StepKindEmail, Subject: "Subject", Content: "Content", Delay: sequence.Delay{Unit: sequence.DelayUnitDays}},
+		{
+			ID:       7,
+			Position: 1,
+			Kind:     sequence.StepKindBranch,
+			Delay:    sequence.Delay{Amount: 2, Unit: sequence.DelayUnitDays},
+			Branch:   &sequence.Branch{Condition: sequence.BranchOpened, Then: &then},
+		},
+		{ID: 5, Position: 2, Kind: sequence.StepKindEmail, Subject: "Subject", Content: "Content", Delay: sequence.Delay{Unit: sequence.DelayUnitDays}},
+	}
+
+	if steps := rows.ToSteps(); !reflect.DeepEqual(steps, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, steps)
+	}
+}
+
+func TestVersionRow_ToVersion(t *testing.T) {
+	publishedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	row := sequence.VersionRow{
+		SequenceID:  1,
+		Version:     2,
+		Steps:       []byte(`[{"id":4,"position":0,"kind":"email","subject":"Subject","content":"Content","delay":{"amount":1,"unit":"days"},"variants":[{"id":7,"label":"A","subject":"Subject A","content":"Content A","weight":40,"active":true}]}]`),
+		PublishedAt: publishedAt,
+	}
+
+	expected := sequence.Version{
+		SequenceID: 1,
+		Version:    2,
+		Steps: []sequence.Step{{
+			ID:       4,
+			Kind:     sequence.StepKindEmail,
+			Subject:  "Subject",
+			Content:  "Content",
+			Delay:    sequence.Delay{Amount: 1, Unit: sequence.DelayUnitDays},
+			Variants: []sequence.Variant{{ID: 7, Label: "A", Subject: "Subject A", Content: "Content A", Weight: 40, Active: true}},
+		}},
+		PublishedAt: publishedAt,
+	}
+
+	version, err := row.ToVersion()
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	if !reflect.DeepEqual(version, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, version)
+	}
+}
@@ -0,0 +1,208 @@
+package sequence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// holidayLayout is the layout of the dates in a holiday calendar.
+const holidayLayout = "2006-01-02"
+
+// maxHolidays is the largest number of dates a holiday calendar may contain.
+const maxHolidays = 1000
+
+// Weekday is a day of the week emails can be sent on.
+type Weekday string
+
+const (
+	Monday    Weekday = "monday"
+	Tuesday   Weekday = "tuesday"
+	Wednesday Weekday = "wednesday"
+	Thursday  Weekday = "thursday"
+	Friday    Weekday = "friday"
+	Saturday  Weekday = "saturday"
+	Sunday    Weekday = "sunday"
+)
+
+var weekdays = map[Weekday]time.Weekday{
+	Monday:    time.Monday,
+	Tuesday:   time.Tuesday,
+	Wednesday: time.Wednesday,
+	Thursday:  time.Thursday,
+	Friday:    time.Friday,
+	Saturday:  time.Saturday,
+	Sunday:    time.Sunday,
+}
+
+// Schedule restricts the steps of a sequence to be sent within a window of hours on certain
+// days, in the time zone of the recipient.
+type Schedule struct {
+	// Days are the days of the week emails are sent on. Monday to Friday when empty.
+	Days []Weekday `json:"days"`
+	// StartHour is the hour of the day sending starts at, from 0 to 23.
+	StartHour int `json:"startHour"`
+	// EndHour is the hour of the day sending stops at, from 1 to 24. It defaults to 24.
+	EndHour int `json:"endHour"`
+	// TimeZone is the IANA time zone used for contacts without a time zone of their own.
+	// It defaults to UTC.
+	TimeZone string `json:"timeZone"`
+	// Holidays are the dates nothing is sent on, as YYYY-MM-DD. They are not counted as
+	// business days either.
+	Holidays []string `json:"holidays,omitempty"`
+}
+
+// WithDefaults returns a copy of the schedule with unset options replaced by their defaults.
+func (s Schedule) WithDefaults() Schedule {
+	if len(s.Days) == 0 {
+		s.Days = []Weekday{Monday, Tuesday, Wednesday, Thursday, Friday}
+	}
+
+	if s.EndHour == 0 {
+		s.EndHour = 24
+	}
+
+	if s.TimeZone == "" {
+		s.TimeZone = "UTC"
+	}
+
+	return s
+}
+
+// Validate validates the schedule.
+func (s Schedule) Validate() error {
+	for _, day := range s.Days {
+		if _, ok := weekdays[day]; !ok {
+			return fmt.Errorf("day %q is not a day of the week", day)
+		}
+	}
+
+	if s.StartHour < 0 || s.StartHour > 23 {
+		return errors.New("startHour must be between 0 and 23")
+	}
+
+	if s.EndHour <= s.StartHour || s.EndHour > 24 {
+		return errors.New("endHour must be after startHour and at most 24")
+	}
+
+	if err := ValidateTimeZone(s.TimeZone); err != nil {
+		return err
+	}
+
+	if len(s.Holidays) > maxHolidays {
+		return fmt.Errorf("at most %d holidays are allowed", maxHolidays)
+	}
+
+	for _, holiday := range s.Holidays {
+		if _, err := time.Parse(holidayLayout, holiday); err != nil {
+			return fmt.Errorf("holiday %q must be a date formatted as YYYY-MM-DD", holiday)
+		}
+	}
+
+	return nil
+}
+
+// ValidateTimeZone checks that the name is a known IANA time zone, such as Europe/Zagreb.
+func ValidateTimeZone(name string) error {
+	// LoadLocation also accepts file paths and the zone of the server, which are not time zones.
+	if name == "" || name == "Local" || strings.HasPrefix(name, "/") {
+		return fmt.Errorf("time zone %q is invalid", name)
+	}
+
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("time zone %q is invalid", name)
+	}
+
+	return nil
+}
+
+// Location returns the time zone a contact is sent emails in: their own when it is valid,
+// and the time zone of the schedule otherwise.
+func (s Schedule) Location(contactTimeZone string) *time.Location {
+	for _, name := range []string{contactTimeZone, s.TimeZone} {
+		if ValidateTimeZone(name) != nil {
+			continue
+		}
+
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+
+	return time.UTC
+}
+
+// Next returns the earliest moment at or after t that is within the sending window in the
+// given time zone. Days without the start hour, e.g. because of a daylight saving time
+// change, start at the first moment after it.
+func (s Schedule) Next(t time.Time, loc *time.Location) time.Time {
+	s = s.WithDefaults()
+	holidays := s.holidays()
+
+	days := map[time.Weekday]bool{}
+	for _, day := range s.Days {
+		days[weekdays[day]] = true
+	}
+
+	// Every holiday rules out at most one sending day, so a window opens within a week of
+	// the last one ruled out.
+	local := t.In(loc)
+	for i := 0; i < 7*(len(holidays)+1); i++ {
+		year, month, day := local.Date()
+		if days[local.Weekday()] && !holidays[local.Format(holidayLayout)] {
+			start := time.Date(year, month, day, s.StartHour, 0, 0, 0, loc)
+			end := time.Date(year, month, day, s.EndHour, 0, 0, 0, loc)
+
+			if local.Before(start) {
+				return start
+			}
+
+			if local.Before(end) {
+				return local
+			}
+		}
+
+		local = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+	}
+
+	return t
+}
+
+// isBusinessDay reports whether the date of t, in the time zone of t, is a weekday that is
+// not a holiday.
+func (s Schedule) isBusinessDay(t time.Time) bool {
+	return !isWeekend(t) && !s.holidays()[t.Format(holidayLayout)]
+}
+
+func (s Schedule) holidays() map[string]bool {
+	holidays := make(map[string]bool, len(s.Holidays))
+	for _, holiday := range s.Holidays {
+		holidays[holiday] = true
+	}
+
+	return holidays
+}
+
+// NextSendTime returns the earliest moment at or after t the sequence may send an email to a
+// contact in the given time zone. It is t itself for sequences without a schedule.
+func (s Sequence) NextSendTime(t time.Time, contactTimeZone string) time.Time {
+	if s.Schedule == nil {
+		return t
+	}
+
+	return s.Schedule.Next(t, s.Schedule.Location(contactTimeZone))
+}
+
+// DueAt returns when the step at the given position is due for a contact in the given time
+// zone, counting its delay from t. With a schedule, business days are counted in the time
+// zone of the contact and skip holidays, and the result is moved into the sending window.
+func (s Sequence) DueAt(position int, t time.Time, contactTimeZone string) time.Time {
+	delay := s.Steps[position].Delay
+	if s.Schedule == nil {
+		return delay.After(t)
+	}
+
+	loc := s.Schedule.Location(contactTimeZone)
+	return s.Schedule.Next(delay.after(t.In(loc), s.Schedule.isBusinessDay), loc)
+}
@@ -0,0 +1,250 @@
+package sequence_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+func TestSchedule_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule sequence.Schedule
+		expected error
+	}{
+		{
+			name:     "Defaults",
+			schedule: sequence.Schedule{}.WithDefaults(),
+		},
+		{
+			name:     "Office hours",
+			schedule: sequence.Schedule{Days: []sequence.Weekday{sequence.Monday}, StartHour: 9, EndHour: 17, TimeZone: "America/New_York", Holidays: []string{"2024-12-25"}},
+		},
+		{
+			name:     "Unknown day",
+			schedule: sequence.Schedule{Days: []sequence.Weekday{"funday"}, EndHour: 24, TimeZone: "UTC"},
+			expected: errors.New(`day "funday" is not a day of the week`),
+		},
+		{
+			name:     "Start hour out of range",
+			schedule: sequence.Schedule{StartHour: 24, EndHour: 24, TimeZone: "UTC"},
+			expected: errors.New("startHour must be between 0 and 23"),
+		},
+		{
+			name:     "End hour before start hour",
+			schedule: sequence.Schedule{StartHour: 17, EndHour: 9, TimeZone: "UTC"},
+			expected: errors.New("endHour must be after startHour and at most 24"),
+		},
+		{
+			name:     "Unknown time zone",
+			schedule: sequence.Schedule{EndHour: 24, TimeZone: "Mars/Olympus_Mons"},
+			expected: errors.New(`time zone "Mars/Olympus_Mons" is invalid`),
+		},
+		{
+			name:     "Server time zone",
+			schedule: sequence.Schedule{EndHour: 24, TimeZone: "Local"},
+			expected: errors.New(`time zone "Local" is invalid`),
+		},
+		{
+			name:     "Malformed holiday",
+			schedule: sequence.Schedule{EndHour: 24, TimeZone: "UTC", Holidays: []string{"25/12/2024"}},
+			expected: errors.New(`holiday "25/12/2024" must be a date formatted as YYYY-MM-DD`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.schedule.Validate()
+			if err == nil && tc.expected != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.expected)
+			} else if err != nil && tc.expected == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.expected != nil && err.Error() != tc.expected.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	zagreb, err := time.LoadLocation("Europe/Zagreb")
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+
+	officeHours := sequence.Schedule{
+		Days:      []sequence.Weekday{sequence.Monday, sequence.Tuesday, sequence.Wednesday, sequence.Thursday, sequence.Friday},
+		StartHour: 9,
+		EndHour:   17,
+		TimeZone:  "Europe/Zagreb",
+		Holidays:  []string{"2024-12-25", "2024-12-26"},
+	}
+
+	testCases := []struct {
+		name     string
+		schedule sequence.Schedule
+		t        time.Time
+		loc      *time.Location
+		expected time.Time
+	}{
+		{
+			name:     "Within the window",
+			schedule: officeHours,
+			t:        time.Date(2024, 5, 15, 10, 30, 0, 0, zagreb),
+			loc:      zagreb,
+			expected: time.Date(2024, 5, 15, 10, 30, 0, 0, zagreb),
+		},
+		{
+			name:     "Before the window",
+			schedule: officeHours,
+			t:        time.Date(2024, 5, 15, 3, 0, 0, 0, zagreb),
+			loc:      zagreb,
+			expected: time.Date(2024, 5, 15, 9, 0, 0, 0, zagreb),
+		},
+		{
+			name:     "After the window",
+			schedule: officeHours,
+			t:        time.Date(2024, 5, 15, 17, 0, 0, 0, zagreb),
+			loc:      zagreb,
+			expected: time.Date(2024, 5, 16, 9, 0, 0, 0, zagreb),
+		},
+		{
+			name:     "Friday evening",
+			schedule: officeHours,
+			t:        time.Date(2024, 5, 17, 18, 0, 0, 0, zagreb),
+			loc:      zagreb,
+			expected: time.Date(2024, 5, 20, 9, 0, 0, 0, zagreb),
+		},
+		{
+			name:     "Holidays",
+			schedule: officeHours,
+			t:        time.Date(2024, 12, 24, 17, 30, 0, 0, zagreb),
+			loc:      zagreb,
+			expected: time.Date(2024, 12, 27, 9, 0, 0, 0, zagreb),
+		},
+		{
+			name:     "Evaluated in the contact's time zone",
+			schedule: officeHours,
+			// 10:00 in Zagreb is 04:00 in New York.
+			t:        time.Date(2024, 5, 15, 10, 0, 0, 0, zagreb),
+			loc:      mustLoadLocation(t, "America/New_York"),
+			expected: time.Date(2024, 5, 15, 9, 0, 0, 0, mustLoadLocation(t, "America/New_York")),
+		},
+		{
+			name:     "Daylight saving time starts",
+			schedule: sequence.Schedule{Days: []sequence.Weekday{sequence.Sunday}, StartHour: 2, EndHour: 4, TimeZone: "Europe/Zagreb"},
+			t:        time.Date(2024, 3, 31, 0, 0, 0, 0, zagreb),
+			loc:      zagreb,
+			expected: time.Date(2024, 3, 31, 3, 0, 0, 0, zagreb),
+		},
+		{
+			name:     "Defaults",
+			schedule: sequence.Schedule{},
+			t:        time.Date(2024, 5, 18, 12, 0, 0, 0, time.UTC),
+			loc:      time.UTC,
+			expected: time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if next := tc.schedule.Next(tc.t, tc.loc); !next.Equal(tc.expected) {
+				t.Errorf("Expected %s, got: %s", tc.expected, next)
+			}
+		})
+	}
+}
+
+func TestSchedule_Location(t *testing.T) {
+	schedule := sequence.Schedule{TimeZone: "Europe/Zagreb"}
+
+	testCases := []struct {
+		contactTimeZone string
+		expected        string
+	}{
+		{"America/New_York", "America/New_York"},
+		{"", "Europe/Zagreb"},
+		{"Not/A_Zone", "Europe/Zagreb"},
+	}
+
+	for _, tc := range testCases {
+		if loc := schedule.Location(tc.contactTimeZone); loc.String() != tc.expected {
+			t.Errorf("Expected contact time zone %q to resolve to %s, got: %s", tc.contactTimeZone, tc.expected, loc)
+		}
+	}
+}
+
+func TestSequence_DueAt(t *testing.T) {
+	zagreb := mustLoadLocation(t, "Europe/Zagreb")
+
+	seq := sequence.Sequence{
+		Steps: []sequence.Step{
+			{Delay: sequence.Delay{}},
+			{Delay: sequence.Delay{Amount: 2, Unit: sequence.DelayUnitBusinessDays}},
+			{Delay: sequence.Delay{Amount: 3, Unit: sequence.DelayUnitHours}},
+		},
+	}
+
+	// Monday 23 December 2024, 15:00 in Zagreb.
+	sentAt := time.Date(2024, 12, 23, 15, 0, 0, 0, zagreb)
+
+	if due := seq.DueAt(1, sentAt, ""); !due.Equal(time.Date(2024, 12, 25, 15, 0, 0, 0, zagreb)) {
+		t.Errorf("Expected sequences without a schedule to only skip weekends, got: %s", due)
+	}
+
+	seq.Schedule = &sequence.Schedule{
+		StartHour: 9,
+		EndHour:   17,
+		TimeZone:  "Europe/Zagreb",
+		Holidays:  []string{"2024-12-25", "2024-12-26"},
+	}
+
+	testCases := []struct {
+		name            string
+		position        int
+		contactTimeZone string
+		expected        time.Time
+	}{
+		{
+			name:     "Right away",
+			position: 0,
+			expected: sentAt,
+		},
+		{
+			name:     "Business days skip holidays",
+			position: 1,
+			expected: time.Date(2024, 12, 27, 15, 0, 0, 0, zagreb),
+		},
+		{
+			name:     "Moved into the window",
+			position: 2,
+			expected: time.Date(2024, 12, 24, 9, 0, 0, 0, zagreb),
+		},
+		{
+			name:            "Contact time zone",
+			position:        2,
+			contactTimeZone: "America/New_York",
+			// 18:00 in Zagreb is 12:00 in New York.
+			expected: time.Date(2024, 12, 23, 18, 0, 0, 0, zagreb),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if due := seq.DueAt(tc.position, sentAt, tc.contactTimeZone); !due.Equal(tc.expected) {
+				t.Errorf("Expected %s, got: %s", tc.expected, due)
+			}
+		})
+	}
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+
+	return loc
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/templating"
 )
 
 var (
@@ -18,15 +22,34 @@ var (
 
 	// ErrStepValidation is returned when a step model fails validation.
 	ErrStepValidation = errors.New("step model is invalid")
+
+	// ErrSequenceArchived is returned when trying to modify an archived sequence.
+	ErrSequenceArchived = errors.New("sequence is archived")
+
+	// ErrVariantNotFound is returned when a variant with the given ID is not found.
+	ErrVariantNotFound = errors.New("variant with given ID not found")
 )
 
 // Sequence represents a sequence of emails.
 type Sequence struct {
-	ID            int    `json:"id"`
-	Name          string `json:"name"`
-	OpenTracking  bool   `json:"openTrackingEnabled"`
-	ClickTracking bool   `json:"clickTrackingEnabled"`
-	Steps         []Step `json:"steps"`
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	OpenTracking  bool       `json:"openTrackingEnabled"`
+	ClickTracking bool       `json:"clickTrackingEnabled"`
+	ArchivedAt    *time.Time `json:"archivedAt,omitempty"`
+	// Schedule restricts when steps are sent. Steps are sent as soon as they are due when nil.
+	Schedule *Schedule `json:"schedule,omitempty"`
+	// PublishedVersion is the latest published version, or zero when the sequence was never
+	// published.
+	PublishedVersion int `json:"publishedVersion"`
+	// Steps are the draft steps, which are edited until they are published. Sequences loaded at
+	// a version have the steps of that version, and PublishedVersion is set to it.
+	Steps []Step `json:"steps"`
+}
+
+// Archived reports whether the sequence has been archived.
+func (s Sequence) Archived() bool {
+	return s.ArchivedAt != nil
 }
 
 // Validate validates the sequence model.
@@ -39,24 +62,109 @@ func (s Sequence) Validate() error {
 		return errors.New("steps are required")
 	}
 
+	if s.Schedule != nil {
+		if err := s.Schedule.Validate(); err != nil {
+			return fmt.Errorf("schedule: %w", err)
+		}
+	}
+
 	for _, step := range s.Steps {
 		if err := step.Validate(); err != nil {
 			return fmt.Errorf("%w: %s", ErrStepValidation, err)
 		}
 	}
 
+	if err := validateFlow(s.Steps); err != nil {
+		return fmt.Errorf("%w: %s", ErrStepValidation, err)
+	}
+
 	return nil
 }
 
-// Step represents an email in a sequence.
+// StepKind is the kind of a step.
+type StepKind string
+
+const (
+	// StepKindEmail sends an email.
+	StepKindEmail StepKind = "email"
+
+	// StepKindBranch continues with one of two steps depending on the engagement of the contact.
+	StepKindBranch StepKind = "branch"
+
+	// StepKindCall creates a task to call the contact.
+	StepKindCall StepKind = "call"
+
+	// StepKindLinkedIn creates a task to reach out to the contact on LinkedIn.
+	StepKindLinkedIn StepKind = "linkedin"
+
+	// StepKindTask creates a custom task described by the content of the step.
+	StepKindTask StepKind = "task"
+)
+
+// Step represents a step in a sequence. Steps send an email unless they are of another kind.
 type Step struct {
-	ID      int    `json:"id"`
-	Subject string `json:"subject"`
-	Content string `json:"content"`
+	ID       int      `json:"id"`
+	Position int      `json:"position"`
+	Kind     StepKind `json:"kind,omitempty"`
+	Subject  string   `json:"subject"`
+	Content  string   `json:"content"`
+	Delay    Delay    `json:"delay"`
+	// Variants replace the subject and content of the step when it is A/B tested.
+	Variants []Variant `json:"variants,omitempty"`
+	// Branch is only set for branch steps.
+	Branch *Branch `json:"branch,omitempty"`
+	// Assignment is only set for manual steps.
+	Assignment *Assignment `json:"assignment,omitempty"`
+	// WinnerID is the variant declared the winner of the A/B test of the step after the version
+	// the step belongs to was published. It is only set on the steps of published versions, and
+	// is not part of their snapshots.
+	WinnerID *int `json:"-"`
+}
+
+// kindOrDefault returns the kind of the step, which defaults to email.
+func (s Step) kindOrDefault() StepKind {
+	if s.Kind == "" {
+		return StepKindEmail
+	}
+
+	return s.Kind
 }
 
 // Validate validates the step model.
 func (s Step) Validate() error {
+	switch s.kindOrDefault() {
+	case StepKindEmail:
+		if s.Branch != nil {
+			return errors.New("only branch steps can have a branch")
+		}
+
+		if s.Assignment != nil {
+			return errors.New("only manual steps can have an assignment")
+		}
+	case StepKindBranch:
+		if s.Assignment != nil {
+			return errors.New("only manual steps can have an assignment")
+		}
+
+		if err := validateBranchStep(s); err != nil {
+			return err
+		}
+
+		return s.Delay.Validate()
+	case StepKindCall, StepKindLinkedIn, StepKindTask:
+		return validateManualStep(s)
+	default:
+		return fmt.Errorf("step kind must be one of %q, %q, %q, %q or %q", StepKindEmail, StepKindBranch, StepKindCall, StepKindLinkedIn, StepKindTask)
+	}
+
+	if len(s.Variants) > 0 {
+		if err := validateVariants(s); err != nil {
+			return err
+		}
+
+		return s.Delay.Validate()
+	}
+
 	if s.Subject == "" {
 		return errors.New("subject is required")
 	}
@@ -65,9 +173,67 @@ func (s Step) Validate() error {
 		return errors.New("content is required")
 	}
 
+	if _, err := templating.Parse(s.Subject); err != nil {
+		return fmt.Errorf("subject: %w", err)
+	}
+
+	if _, err := templating.Parse(s.Content); err != nil {
+		return fmt.Errorf("content: %w", err)
+	}
+
+	if err := s.Delay.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// RenderedStep is a step with its merge fields replaced by a recipient's values.
+type RenderedStep struct {
+	Subject string `json:"subject"`
+	Content string `json:"content"`
+	// MissingFields lists the merge fields that had neither a value nor a default.
+	MissingFields []string `json:"missingFields"`
+}
+
+// Render renders the subject and content of the step with the given merge field values.
+func (s Step) Render(values map[string]string) (RenderedStep, error) {
+	subject, missingInSubject, err := templating.Render(s.Subject, values)
+	if err != nil {
+		return RenderedStep{}, fmt.Errorf("subject: %w", err)
+	}
+
+	content, missingInContent, err := templating.Render(s.Content, values)
+	if err != nil {
+		return RenderedStep{}, fmt.Errorf("content: %w", err)
+	}
+
+	return RenderedStep{
+		Subject:       subject,
+		Content:       content,
+		MissingFields: mergeMissing(missingInSubject, missingInContent),
+	}, nil
+}
+
+// mergeMissing merges the missing fields of the subject and content into one sorted list.
+func mergeMissing(subject, content []string) []string {
+	if len(subject) == 0 && len(content) == 0 {
+		return []string{}
+	}
+
+	seen := make(map[string]bool, len(subject)+len(content))
+	merged := make([]string, 0, len(subject)+len(content))
+	for _, name := range append(subject, content...) {
+		if !seen[name] {
+			seen[name] = true
+			merged = append(merged, name)
+		}
+	}
+	sort.Strings(merged)
+
+	return merged
+}
+
 // SequencePatch represents a patch for a sequence.
 type SequencePatch struct {
 	ID            int
@@ -106,11 +272,24 @@ func (s SequencePatch) Validate() error {
 
 // Repository represents a sequence repository.
 type Repository interface {
-	CreateSequence(ctx context.Context, seq Sequence) error
+	CreateSequence(ctx context.Context, seq Sequence) (Sequence, error)
 	UpdateSequence(ctx context.Context, seq Sequence) (bool, error)
 	GetSequence(ctx context.Context, id int) (Sequence, bool, error)
+	GetSequenceByStepID(ctx context.Context, stepID int) (Sequence, bool, error)
+	SetSequenceArchived(ctx context.Context, id int, archived bool) (bool, error)
+	DeleteSequence(ctx context.Context, id int) (bool, error)
+	ListSequences(ctx context.Context, filter ListFilter) ([]Sequence, error)
+	CreateStep(ctx context.Context, sequenceID int, step Step) (int, error)
 	UpdateStep(ctx context.Context, step Step) (bool, error)
 	DeleteStep(ctx context.Context, id int) error
+	ReorderSteps(ctx context.Context, sequenceID int, stepIDs []int) error
+	// SetWinningVariant deactivates the other variants of a step in the draft, and records the
+	// winner for the published versions of the step.
+	SetWinningVariant(ctx context.Context, stepID int, variantID int) error
+	// CreateVersion snapshots the steps as the next version of the sequence.
+	CreateVersion(ctx context.Context, sequenceID int, steps []Step) (Version, error)
+	ListVersions(ctx context.Context, sequenceID int) ([]Version, error)
+	GetVersion(ctx context.Context, sequenceID int, version int) (Version, bool, error)
 }
 
 // Service contains the business logic for handling sequences.
@@ -125,17 +304,23 @@ func NewService(repo Repository) *Service {
 	}
 }
 
-// CreateSequence creates a new sequence.
-func (s Service) CreateSequence(ctx context.Context, seq Sequence) error {
+// CreateSequence creates a new sequence and returns it as it was persisted.
+func (s Service) CreateSequence(ctx context.Context, seq Sequence) (Sequence, error) {
+	if seq.Schedule != nil {
+		schedule := seq.Schedule.WithDefaults()
+		seq.Schedule = &schedule
+	}
+
 	if err := seq.Validate(); err != nil {
-		return fmt.Errorf("%w: %s", ErrSequenceValidation, err)
+		return Sequence{}, fmt.Errorf("%w: %s", ErrSequenceValidation, err)
 	}
 
-	if err := s.repo.CreateSequence(ctx, seq); err != nil {
-		return fmt.Errorf("failed to create sequence: %w", err)
+	created, err := s.repo.CreateSequence(ctx, seq)
+	if err != nil {
+		return Sequence{}, fmt.Errorf("failed to create sequence: %w", err)
 	}
 
-	return nil
+	return created, nil
 }
 
 // PatchSequence patches a sequence using the given patch.
@@ -144,20 +329,47 @@ func (s Service) PatchSequence(ctx context.Context, patch SequencePatch) error {
 		return fmt.Errorf("%w: %s", ErrSequenceValidation, err)
 	}
 
-	seq, exists, err := s.repo.GetSequence(ctx, patch.ID)
+	seq, err := s.getMutableSequence(ctx, patch.ID)
 	if err != nil {
 		return err
 	}
 
-	if !exists {
+	patch.Patch(&seq)
+
+	updated, err := s.repo.UpdateSequence(ctx, seq)
+	if err != nil {
+		return fmt.Errorf("failed to patch sequence: %w", err)
+	}
+
+	if !updated {
 		return ErrSequenceNotFound
 	}
 
-	patch.Patch(&seq)
+	return nil
+}
+
+// SetSchedule sets the schedule of a sequence, or removes it when nil. Steps that are already
+// due at a time outside the new sending window are held back until the window opens.
+func (s Service) SetSchedule(ctx context.Context, id int, schedule *Schedule) error {
+	if schedule != nil {
+		withDefaults := schedule.WithDefaults()
+		if err := withDefaults.Validate(); err != nil {
+			return fmt.Errorf("%w: schedule: %s", ErrSequenceValidation, err)
+		}
+
+		schedule = &withDefaults
+	}
+
+	seq, err := s.getMutableSequence(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	seq.Schedule = schedule
 
 	updated, err := s.repo.UpdateSequence(ctx, seq)
 	if err != nil {
-		return fmt.Errorf("failed to patch sequence: %w", err)
+		return fmt.Errorf("failed to set schedule: %w", err)
 	}
 
 	if !updated {
@@ -167,30 +379,190 @@ func (s Service) PatchSequence(ctx context.Context, patch SequencePatch) error {
 	return nil
 }
 
-// GetSequence gets a sequence by ID.
+// GetSequence gets a sequence by ID. Archived sequences are treated as not found.
 func (s Service) GetSequence(ctx context.Context, id int) (Sequence, error) {
 	seq, exists, err := s.repo.GetSequence(ctx, id)
 	if err != nil {
 		return Sequence{}, err
 	}
 
+	if !exists || seq.Archived() {
+		return Sequence{}, ErrSequenceNotFound
+	}
+
+	return seq, nil
+}
+
+// GetEnrollableSequence gets a sequence that contacts are about to be enrolled into, with
+// the steps of its latest published version. Sequences that were never published are
+// published first. It fails for archived sequences and for sequences that do not pass
+// validation, such as sequences without steps.
+func (s Service) GetEnrollableSequence(ctx context.Context, id int) (Sequence, error) {
+	seq, err := s.getMutableSequence(ctx, id)
+	if err != nil {
+		return Sequence{}, err
+	}
+
+	var version Version
+	if seq.PublishedVersion == 0 {
+		version, err = s.publish(ctx, seq)
+	} else {
+		version, err = s.getVersion(ctx, id, seq.PublishedVersion)
+	}
+	if err != nil {
+		return Sequence{}, err
+	}
+
+	seq.PublishedVersion = version.Version
+	seq.Steps = version.Steps
+	if err := seq.Validate(); err != nil {
+		return Sequence{}, fmt.Errorf("%w: %s", ErrSequenceValidation, err)
+	}
+
+	return seq, nil
+}
+
+// DeleteSequence permanently deletes a sequence and its steps.
+func (s Service) DeleteSequence(ctx context.Context, id int) error {
+	deleted, err := s.repo.DeleteSequence(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete sequence: %w", err)
+	}
+
+	if !deleted {
+		return ErrSequenceNotFound
+	}
+
+	return nil
+}
+
+// ArchiveSequence archives a sequence, hiding it from reads and preventing further changes.
+func (s Service) ArchiveSequence(ctx context.Context, id int) error {
+	return s.setSequenceArchived(ctx, id, true)
+}
+
+// UnarchiveSequence restores an archived sequence.
+func (s Service) UnarchiveSequence(ctx context.Context, id int) error {
+	return s.setSequenceArchived(ctx, id, false)
+}
+
+func (s Service) setSequenceArchived(ctx context.Context, id int, archived bool) error {
+	updated, err := s.repo.SetSequenceArchived(ctx, id, archived)
+	if err != nil {
+		return fmt.Errorf("failed to update sequence: %w", err)
+	}
+
+	if !updated {
+		return ErrSequenceNotFound
+	}
+
+	return nil
+}
+
+// getMutableSequence gets a sequence that is about to be modified, failing if it is archived.
+func (s Service) getMutableSequence(ctx context.Context, id int) (Sequence, error) {
+	seq, exists, err := s.repo.GetSequence(ctx, id)
+	if err != nil {
+		return Sequence{}, err
+	}
+
 	if !exists {
 		return Sequence{}, ErrSequenceNotFound
 	}
 
+	if seq.Archived() {
+		return Sequence{}, ErrSequenceArchived
+	}
+
 	return seq, nil
 }
 
-// UpdateStep updates a sequence step.
+// getMutableStepSequence gets the sequence owning a step that is about to be modified, failing
+// if it is archived. It reports whether the step exists.
+func (s Service) getMutableStepSequence(ctx context.Context, stepID int) (Sequence, bool, error) {
+	seq, exists, err := s.repo.GetSequenceByStepID(ctx, stepID)
+	if err != nil {
+		return Sequence{}, false, err
+	}
+
+	if !exists {
+		return Sequence{}, false, nil
+	}
+
+	if seq.Archived() {
+		return Sequence{}, true, ErrSequenceArchived
+	}
+
+	return seq, true, nil
+}
+
+// AddStep adds a step to an existing sequence and returns the ID of the new step.
+// If position is nil, the step is appended after the last step. Otherwise it is
+// inserted at the given zero-based position and the following steps are shifted down.
+// The targets of a branch step are positions after the step was inserted.
+func (s Service) AddStep(ctx context.Context, sequenceID int, step Step, position *int) (int, error) {
+	if err := step.Validate(); err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrStepValidation, err)
+	}
+
+	seq, err := s.getMutableSequence(ctx, sequenceID)
+	if err != nil {
+		return 0, err
+	}
+
+	step.Position = len(seq.Steps)
+	if position != nil {
+		if *position < 0 || *position > len(seq.Steps) {
+			return 0, fmt.Errorf("%w: position must be between 0 and %d", ErrStepValidation, len(seq.Steps))
+		}
+
+		step.Position = *position
+	}
+
+	if err := validateFlow(withStepInserted(seq.Steps, step, step.Position)); err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrStepValidation, err)
+	}
+
+	id, err := s.repo.CreateStep(ctx, sequenceID, step)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add step: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateStep updates a draft step. Contacts already enrolled keep being sent the version
+// they were enrolled into.
 func (s Service) UpdateStep(ctx context.Context, step Step) error {
 	if step.ID == 0 {
 		return fmt.Errorf("%w: id is required", ErrStepValidation)
 	}
 
+	seq, exists, err := s.getMutableStepSequence(ctx, step.ID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrStepNotFound
+	}
+
+	current := findStep(seq, step.ID)
+	step.Position = current.Position
+
+	step, err = keepVariantStates(current, step)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrStepValidation, err)
+	}
+
 	if err := step.Validate(); err != nil {
 		return fmt.Errorf("%w: %s", ErrStepValidation, err)
 	}
 
+	if err := validateFlow(withStepReplaced(seq.Steps, step)); err != nil {
+		return fmt.Errorf("%w: %s", ErrStepValidation, err)
+	}
+
 	updated, err := s.repo.UpdateStep(ctx, step)
 	if err != nil {
 		return fmt.Errorf("failed to update step: %w", err)
@@ -203,11 +575,187 @@ func (s Service) UpdateStep(ctx context.Context, step Step) error {
 	return nil
 }
 
-// DeleteStep deletes a sequence step.
+// DeleteStep deletes a sequence step. Deleting a step that does not exist is not an error.
+// Steps that branches continue with cannot be deleted.
 func (s Service) DeleteStep(ctx context.Context, id int) error {
+	seq, exists, err := s.getMutableStepSequence(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return nil
+	}
+
+	if step := findStep(seq, id); step.ID != 0 {
+		remaining, err := withStepRemoved(seq.Steps, step.Position)
+		if err == nil {
+			err = validateFlow(remaining)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrStepValidation, err)
+		}
+	}
+
 	if err := s.repo.DeleteStep(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete step: %w", err)
 	}
 
 	return nil
 }
+
+// PreviewStep renders a step with sample merge field values. The given values are
+// used in place of, or in addition to, the samples.
+func (s Service) PreviewStep(ctx context.Context, stepID int, values map[string]string) (RenderedStep, error) {
+	seq, exists, err := s.repo.GetSequenceByStepID(ctx, stepID)
+	if err != nil {
+		return RenderedStep{}, err
+	}
+
+	if !exists {
+		return RenderedStep{}, ErrStepNotFound
+	}
+
+	merged := make(map[string]string, len(templating.SampleValues)+len(values))
+	for k, v := range templating.SampleValues {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+
+	step := findStep(seq, stepID)
+	if step.ID == 0 {
+		return RenderedStep{}, ErrStepNotFound
+	}
+
+	if step.Kind == StepKindBranch {
+		return RenderedStep{}, fmt.Errorf("%w: branch steps send no email", ErrStepValidation)
+	}
+
+	// Steps with variants are previewed with their first active variant.
+	if active := step.activeVariants(); len(active) > 0 {
+		step = step.withVariant(active[0])
+	}
+
+	return step.Render(merged)
+}
+
+// DeclareWinner declares a variant of a step the winner of its A/B test. The other variants
+// are deactivated in the draft. Published versions are left as they are, but contacts pinned
+// to versions that contain the winner are sent it as well, so the winner is sent to every
+// contact reaching the step from now on.
+func (s Service) DeclareWinner(ctx context.Context, stepID int, variantID int) error {
+	seq, exists, err := s.getMutableStepSequence(ctx, stepID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrStepNotFound
+	}
+
+	found := false
+	for _, v := range findStep(seq, stepID).Variants {
+		found = found || v.ID == variantID
+	}
+
+	if !found {
+		return ErrVariantNotFound
+	}
+
+	if err := s.repo.SetWinningVariant(ctx, stepID, variantID); err != nil {
+		return fmt.Errorf("failed to declare winner: %w", err)
+	}
+
+	return nil
+}
+
+// findStep finds a step of a sequence by ID. It returns the zero step when it is not found.
+func findStep(seq Sequence, stepID int) Step {
+	for _, step := range seq.Steps {
+		if step.ID == stepID {
+			return step
+		}
+	}
+
+	return Step{}
+}
+
+// keepVariantStates carries over whether the variants of the current step are active to the
+// updated step, as that is only changed by declaring a winner. New variants are active.
+func keepVariantStates(current Step, updated Step) (Step, error) {
+	active := make(map[int]bool, len(current.Variants))
+	for _, v := range current.Variants {
+		active[v.ID] = v.Active
+	}
+
+	variants := make([]Variant, len(updated.Variants))
+	for i, v := range updated.Variants {
+		v.Active = true
+		if v.ID != 0 {
+			state, ok := active[v.ID]
+			if !ok {
+				return Step{}, fmt.Errorf("variant %d does not belong to the step", v.ID)
+			}
+
+			v.Active = state
+		}
+
+		variants[i] = v
+	}
+
+	updated.Variants = variants
+	return updated, nil
+}
+
+// ReorderSteps reorders the steps of a sequence. The given step IDs must contain
+// every step of the sequence exactly once, in the desired order.
+func (s Service) ReorderSteps(ctx context.Context, sequenceID int, stepIDs []int) error {
+	seq, err := s.getMutableSequence(ctx, sequenceID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateStepOrder(seq.Steps, stepIDs); err != nil {
+		return fmt.Errorf("%w: %s", ErrStepValidation, err)
+	}
+
+	if err := validateFlow(withStepsReordered(seq.Steps, stepIDs)); err != nil {
+		return fmt.Errorf("%w: %s", ErrStepValidation, err)
+	}
+
+	if err := s.repo.ReorderSteps(ctx, sequenceID, stepIDs); err != nil {
+		return fmt.Errorf("failed to reorder steps: %w", err)
+	}
+
+	return nil
+}
+
+// validateStepOrder checks that the step IDs are a permutation of the IDs of the given steps.
+func validateStepOrder(steps []Step, stepIDs []int) error {
+	if len(stepIDs) != len(steps) {
+		return fmt.Errorf("expected %d step IDs, got %d", len(steps), len(stepIDs))
+	}
+
+	current := make(map[int]bool, len(steps))
+	for _, step := range steps {
+		current[step.ID] = true
+	}
+
+	seen := make(map[int]bool, len(stepIDs))
+	for _, id := range stepIDs {
+		if !current[id] {
+			return fmt.Errorf("step %d does not belong to the sequence", id)
+		}
+
+		if seen[id] {
+			return fmt.Errorf("step %d is listed more than once", id)
+		}
+
+		seen[id] = true
+	}
+
+	return nil
+}
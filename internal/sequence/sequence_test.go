@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cybre/salesforge-assignment/internal/sequence"
 	"github.com/cybre/salesforge-assignment/internal/sequence/testdata"
@@ -43,6 +44,17 @@ func TestSequence_Validate(t *testing.T) {
 			},
 			expected: fmt.Errorf("%w: %s", sequence.ErrStepValidation, "subject is required"),
 		},
+		{
+			name: "Invalid schedule",
+			sequence: sequence.Sequence{
+				Name:     "Sequence 5",
+				Schedule: &sequence.Schedule{StartHour: 9, EndHour: 17, TimeZone: "Nowhere/Special"},
+				Steps: []sequence.Step{
+					{Subject: "Subject 1", Content: "Content 1"},
+				},
+			},
+			expected: errors.New(`schedule: time zone "Nowhere/Special" is invalid`),
+		},
 		{
 			name: "Valid sequence",
 			sequence: sequence.Sequence{
@@ -91,6 +103,31 @@ func TestStep_Validate(t *testing.T) {
 			step:     sequence.Step{Subject: "Subject 2", Content: ""},
 			expected: errors.New("content is required"),
 		},
+		{
+			name:     "Valid step with delay",
+			step:     sequence.Step{Subject: "Subject 3", Content: "Content 3", Delay: sequence.Delay{Amount: 2, Unit: sequence.DelayUnitBusinessDays}},
+			expected: nil,
+		},
+		{
+			name:     "Invalid step with negative delay",
+			step:     sequence.Step{Subject: "Subject 4", Content: "Content 4", Delay: sequence.Delay{Amount: -1, Unit: sequence.DelayUnitDays}},
+			expected: errors.New("delay amount cannot be negative"),
+		},
+		{
+			name:     "Valid step with merge fields",
+			step:     sequence.Step{Subject: "Hi {{firstName|there}}", Content: "How is {{company}}?"},
+			expected: nil,
+		},
+		{
+			name:     "Invalid step with unclosed merge field in subject",
+			step:     sequence.Step{Subject: "Hi {{firstName", Content: "Content 5"},
+			expected: errors.New("subject: invalid merge field at position 3: missing closing }}"),
+		},
+		{
+			name:     "Invalid step with empty merge field in content",
+			step:     sequence.Step{Subject: "Subject 6", Content: "Hello {{ }}"},
+			expected: errors.New("content: invalid merge field at position 6: field name is required"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -219,8 +256,9 @@ func TestService_CreateSequence(t *testing.T) {
 	ctx := context.Background()
 
 	repo := testdata.MockRepo{
-		CreateSequenceFn: func(ctx context.Context, seq sequence.Sequence) error {
-			return nil
+		CreateSequenceFn: func(ctx context.Context, seq sequence.Sequence) (sequence.Sequence, error) {
+			seq.ID = 1
+			return seq, nil
 		},
 	}
 
@@ -229,6 +267,7 @@ func TestService_CreateSequence(t *testing.T) {
 	testCases := []struct {
 		name        string
 		sequence    sequence.Sequence
+		expectedID  int
 		expectedErr error
 		repository  sequence.Repository
 	}{
@@ -241,6 +280,7 @@ func TestService_CreateSequence(t *testing.T) {
 					{Subject: "Subject 2", Content: "Content 2"},
 				},
 			},
+			expectedID:  1,
 			expectedErr: nil,
 			repository:  repo,
 		},
@@ -264,8 +304,8 @@ func TestService_CreateSequence(t *testing.T) {
 			},
 			expectedErr: repoErr,
 			repository: testdata.MockRepo{
-				CreateSequenceFn: func(ctx context.Context, seq sequence.Sequence) error {
-					return repoErr
+				CreateSequenceFn: func(ctx context.Context, seq sequence.Sequence) (sequence.Sequence, error) {
+					return sequence.Sequence{}, repoErr
 				},
 			},
 		},
@@ -274,11 +314,15 @@ func TestService_CreateSequence(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			svc := sequence.NewService(tc.repository)
-			err := svc.CreateSequence(ctx, tc.sequence)
+			seq, err := svc.CreateSequence(ctx, tc.sequence)
 
 			if !errors.Is(err, tc.expectedErr) {
 				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
 			}
+
+			if seq.ID != tc.expectedID {
+				t.Errorf("Expected ID: %d, got: %d", tc.expectedID, seq.ID)
+			}
 		})
 	}
 }
@@ -376,6 +420,23 @@ func TestService_PatchSequence(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Archived sequence",
+			patch: sequence.SequencePatch{
+				ID:   3,
+				Name: stringPtr("New Name"),
+			},
+			expectedErr: sequence.ErrSequenceArchived,
+			repository: testdata.MockRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{
+						ID:         3,
+						Name:       "Old Name",
+						ArchivedAt: timePtr(time.Now()),
+					}, true, nil
+				},
+			},
+		},
 		{
 			name: "Failed to update sequence (not found)",
 			patch: sequence.SequencePatch{
@@ -409,6 +470,90 @@ func TestService_PatchSequence(t *testing.T) {
 	}
 }
 
+func TestService_SetSchedule(t *testing.T) {
+	ctx := context.Background()
+
+	existing := sequence.Sequence{
+		ID:       1,
+		Name:     "Sequence",
+		Schedule: &sequence.Schedule{StartHour: 8, EndHour: 16, TimeZone: "UTC"},
+	}
+
+	testCases := []struct {
+		name             string
+		schedule         *sequence.Schedule
+		archived         bool
+		expectedSchedule *sequence.Schedule
+		expectedErr      error
+	}{
+		{
+			name:     "Set schedule",
+			schedule: &sequence.Schedule{StartHour: 9, EndHour: 17, TimeZone: "Europe/Zagreb"},
+			expectedSchedule: &sequence.Schedule{
+				Days:      []sequence.Weekday{sequence.Monday, sequence.Tuesday, sequence.Wednesday, sequence.Thursday, sequence.Friday},
+				StartHour: 9,
+				EndHour:   17,
+				TimeZone:  "Europe/Zagreb",
+			},
+		},
+		{
+			name:             "Remove schedule",
+			schedule:         nil,
+			expectedSchedule: nil,
+		},
+		{
+			name:        "Invalid schedule",
+			schedule:    &sequence.Schedule{StartHour: 17, EndHour: 9},
+			expectedErr: sequence.ErrSequenceValidation,
+		},
+		{
+			name:        "Archived sequence",
+			schedule:    &sequence.Schedule{},
+			archived:    true,
+			expectedErr: sequence.ErrSequenceArchived,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var updated *sequence.Sequence
+			repo := testdata.MockRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					seq := existing
+					if tc.archived {
+						seq.ArchivedAt = timePtr(time.Now())
+					}
+
+					return seq, true, nil
+				},
+				UpdateSequenceFn: func(ctx context.Context, seq sequence.Sequence) (bool, error) {
+					updated = &seq
+					return true, nil
+				},
+			}
+
+			svc := sequence.NewService(repo)
+			err := svc.SetSchedule(ctx, 1, tc.schedule)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if tc.expectedErr != nil {
+				return
+			}
+
+			if updated == nil {
+				t.Fatalf("Expected the sequence to be updated")
+			}
+
+			if !reflect.DeepEqual(updated.Schedule, tc.expectedSchedule) {
+				t.Errorf("Expected schedule: %+v, got: %+v", tc.expectedSchedule, updated.Schedule)
+			}
+		})
+	}
+}
+
 func TestService_GetSequence(t *testing.T) {
 	ctx := context.Background()
 
@@ -451,6 +596,17 @@ func TestService_GetSequence(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "Archived sequence",
+			id:          4,
+			expected:    sequence.Sequence{},
+			expectedErr: sequence.ErrSequenceNotFound,
+			repository: testdata.MockRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{ID: id, Name: "Test Sequence", ArchivedAt: timePtr(time.Now())}, true, nil
+				},
+			},
+		},
 		{
 			name:        "Repository error",
 			id:          3,
@@ -480,10 +636,204 @@ func TestService_GetSequence(t *testing.T) {
 	}
 }
 
+func TestService_GetEnrollableSequence(t *testing.T) {
+	ctx := context.Background()
+
+	draft := []sequence.Step{{ID: 1, Subject: "Draft subject", Content: "Content"}}
+	published := []sequence.Step{{ID: 1, Subject: "Published subject", Content: "Content"}}
+
+	repository := func(seq sequence.Sequence) testdata.MockRepo {
+		return testdata.MockRepo{
+			GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+				return seq, seq.ID != 0, nil
+			},
+			GetVersionFn: func(ctx context.Context, sequenceID int, version int) (sequence.Version, bool, error) {
+				return sequence.Version{SequenceID: sequenceID, Version: version, Steps: published}, true, nil
+			},
+			CreateVersionFn: func(ctx context.Context, sequenceID int, steps []sequence.Step) (sequence.Version, error) {
+				return sequence.Version{SequenceID: sequenceID, Version: 1, Steps: steps}, nil
+			},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		expected    sequence.Sequence
+		expectedErr error
+		repository  sequence.Repository
+	}{
+		{
+			name:       "Published sequence",
+			expected:   sequence.Sequence{ID: 1, Name: "Test Sequence", PublishedVersion: 2, Steps: published},
+			repository: repository(sequence.Sequence{ID: 1, Name: "Test Sequence", PublishedVersion: 2, Steps: draft}),
+		},
+		{
+			name:       "Sequence never published",
+			expected:   sequence.Sequence{ID: 1, Name: "Test Sequence", PublishedVersion: 1, Steps: draft},
+			repository: repository(sequence.Sequence{ID: 1, Name: "Test Sequence", Steps: draft}),
+		},
+		{
+			name:        "Sequence not found",
+			expectedErr: sequence.ErrSequenceNotFound,
+			repository:  repository(sequence.Sequence{}),
+		},
+		{
+			name:        "Archived sequence",
+			expectedErr: sequence.ErrSequenceArchived,
+			repository:  repository(sequence.Sequence{ID: 1, Name: "Test Sequence", ArchivedAt: timePtr(time.Now()), Steps: draft}),
+		},
+		{
+			name:        "Sequence without steps",
+			expectedErr: sequence.ErrSequenceValidation,
+			repository:  repository(sequence.Sequence{ID: 1, Name: "Test Sequence", Steps: []sequence.Step{}}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := sequence.NewService(tc.repository)
+			seq, err := svc.GetEnrollableSequence(ctx, 1)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if err == nil && !reflect.DeepEqual(seq, tc.expected) {
+				t.Errorf("Expected: %v, got: %v", tc.expected, seq)
+			}
+		})
+	}
+}
+
+func TestService_AddStep(t *testing.T) {
+	ctx := context.Background()
+
+	getSequenceFn := func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+		return sequence.Sequence{
+			ID:   id,
+			Name: "Test Sequence",
+			Steps: []sequence.Step{
+				{ID: 1, Position: 0, Subject: "Subject 1", Content: "Content 1"},
+				{ID: 2, Position: 1, Subject: "Subject 2", Content: "Content 2"},
+			},
+		}, true, nil
+	}
+
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name             string
+		step             sequence.Step
+		position         *int
+		expectedID       int
+		expectedPosition int
+		expectedErr      error
+		repository       sequence.Repository
+	}{
+		{
+			name:             "Append step",
+			step:             sequence.Step{Subject: "Subject 3", Content: "Content 3"},
+			expectedID:       3,
+			expectedPosition: 2,
+		},
+		{
+			name:             "Insert step",
+			step:             sequence.Step{Subject: "Subject 3", Content: "Content 3"},
+			position:         intPtr(0),
+			expectedID:       3,
+			expectedPosition: 0,
+		},
+		{
+			name:        "Position out of range",
+			step:        sequence.Step{Subject: "Subject 3", Content: "Content 3"},
+			position:    intPtr(3),
+			expectedErr: sequence.ErrStepValidation,
+		},
+		{
+			name:        "Negative position",
+			step:        sequence.Step{Subject: "Subject 3", Content: "Content 3"},
+			position:    intPtr(-1),
+			expectedErr: sequence.ErrStepValidation,
+		},
+		{
+			name:        "Invalid step",
+			step:        sequence.Step{Subject: "", Content: "Content 3"},
+			expectedErr: sequence.ErrStepValidation,
+		},
+		{
+			name:        "Sequence not found",
+			step:        sequence.Step{Subject: "Subject 3", Content: "Content 3"},
+			expectedErr: sequence.ErrSequenceNotFound,
+			repository: testdata.MockRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{}, false, nil
+				},
+			},
+		},
+		{
+			name:        "Archived sequence",
+			step:        sequence.Step{Subject: "Subject 3", Content: "Content 3"},
+			expectedErr: sequence.ErrSequenceArchived,
+			repository: testdata.MockRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{ID: id, Name: "Test Sequence", ArchivedAt: timePtr(time.Now())}, true, nil
+				},
+			},
+		},
+		{
+			name:        "Failed to create step",
+			step:        sequence.Step{Subject: "Subject 3", Content: "Content 3"},
+			expectedErr: repoErr,
+			repository: testdata.MockRepo{
+				GetSequenceFn: getSequenceFn,
+				CreateStepFn: func(ctx context.Context, sequenceID int, step sequence.Step) (int, error) {
+					return 0, repoErr
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var created sequence.Step
+			repo := tc.repository
+			if repo == nil {
+				repo = testdata.MockRepo{
+					GetSequenceFn: getSequenceFn,
+					CreateStepFn: func(ctx context.Context, sequenceID int, step sequence.Step) (int, error) {
+						created = step
+						return 3, nil
+					},
+				}
+			}
+
+			svc := sequence.NewService(repo)
+			id, err := svc.AddStep(ctx, 1, tc.step, tc.position)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if id != tc.expectedID {
+				t.Errorf("Expected ID: %d, got: %d", tc.expectedID, id)
+			}
+
+			if tc.expectedErr == nil && created.Position != tc.expectedPosition {
+				t.Errorf("Expected position: %d, got: %d", tc.expectedPosition, created.Position)
+			}
+		})
+	}
+}
+
 func TestService_UpdateStep(t *testing.T) {
 	ctx := context.Background()
 
+	getSequenceByStepIDFn := func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+		return sequence.Sequence{ID: 1, Name: "Test Sequence"}, true, nil
+	}
+
 	repo := testdata.MockRepo{
+		GetSequenceByStepIDFn: getSequenceByStepIDFn,
 		UpdateStepFn: func(ctx context.Context, step sequence.Step) (bool, error) {
 			return true, nil
 		},
@@ -535,11 +885,40 @@ func TestService_UpdateStep(t *testing.T) {
 			},
 			expectedErr: sequence.ErrStepNotFound,
 			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: getSequenceByStepIDFn,
 				UpdateStepFn: func(ctx context.Context, step sequence.Step) (bool, error) {
 					return false, nil
 				},
 			},
 		},
+		{
+			name: "Step not found by lookup",
+			step: sequence.Step{
+				ID:      1,
+				Subject: "Subject 3",
+				Content: "Content 3",
+			},
+			expectedErr: sequence.ErrStepNotFound,
+			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{}, false, nil
+				},
+			},
+		},
+		{
+			name: "Archived sequence",
+			step: sequence.Step{
+				ID:      1,
+				Subject: "Subject 3",
+				Content: "Content 3",
+			},
+			expectedErr: sequence.ErrSequenceArchived,
+			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{ID: 1, Name: "Test Sequence", ArchivedAt: timePtr(time.Now())}, true, nil
+				},
+			},
+		},
 		{
 			name: "Failed to update step",
 			step: sequence.Step{
@@ -549,6 +928,7 @@ func TestService_UpdateStep(t *testing.T) {
 			},
 			expectedErr: repoErr,
 			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: getSequenceByStepIDFn,
 				UpdateStepFn: func(ctx context.Context, step sequence.Step) (bool, error) {
 					return false, repoErr
 				},
@@ -571,7 +951,12 @@ func TestService_UpdateStep(t *testing.T) {
 func TestService_DeleteStep(t *testing.T) {
 	ctx := context.Background()
 
+	getSequenceByStepIDFn := func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+		return sequence.Sequence{ID: 1, Name: "Test Sequence"}, true, nil
+	}
+
 	repo := testdata.MockRepo{
+		GetSequenceByStepIDFn: getSequenceByStepIDFn,
 		DeleteStepFn: func(ctx context.Context, id int) error {
 			return nil
 		},
@@ -591,11 +976,32 @@ func TestService_DeleteStep(t *testing.T) {
 			expectedErr: nil,
 			repository:  repo,
 		},
+		{
+			name:        "Step does not exist",
+			id:          3,
+			expectedErr: nil,
+			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{}, false, nil
+				},
+			},
+		},
+		{
+			name:        "Archived sequence",
+			id:          4,
+			expectedErr: sequence.ErrSequenceArchived,
+			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{ID: 1, Name: "Test Sequence", ArchivedAt: timePtr(time.Now())}, true, nil
+				},
+			},
+		},
 		{
 			name:        "Failed to delete step",
 			id:          2,
 			expectedErr: repoErr,
 			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: getSequenceByStepIDFn,
 				DeleteStepFn: func(ctx context.Context, id int) error {
 					return repoErr
 				},
@@ -615,6 +1021,337 @@ func TestService_DeleteStep(t *testing.T) {
 	}
 }
 
+func TestStep_Render(t *testing.T) {
+	step := sequence.Step{
+		Subject: "Hi {{firstName|there}}, quick question for {{company}}",
+		Content: "{{firstName}} at {{company}}, are you still at {{email}}?",
+	}
+
+	rendered, err := step.Render(map[string]string{"company": "Acme"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := sequence.RenderedStep{
+		Subject:       "Hi there, quick question for Acme",
+		Content:       " at Acme, are you still at ?",
+		MissingFields: []string{"email", "firstName"},
+	}
+	if !reflect.DeepEqual(rendered, expected) {
+		t.Errorf("Expected rendered step: %+v, got: %+v", expected, rendered)
+	}
+}
+
+func TestService_PreviewStep(t *testing.T) {
+	ctx := context.Background()
+
+	repo := testdata.MockRepo{
+		GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+			return sequence.Sequence{
+				ID:   1,
+				Name: "Test Sequence",
+				Steps: []sequence.Step{
+					{ID: 1, Subject: "Welcome", Content: "Hello"},
+					{ID: 2, Subject: "Hi {{firstName}}", Content: "Is {{custom.plan|your plan}} working for {{company}}?"},
+				},
+			}, true, nil
+		},
+	}
+
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name        string
+		id          int
+		values      map[string]string
+		expected    sequence.RenderedStep
+		expectedErr error
+		repository  sequence.Repository
+	}{
+		{
+			name: "Sample values",
+			id:   2,
+			expected: sequence.RenderedStep{
+				Subject:       "Hi Jane",
+				Content:       "Is your plan working for Acme Inc.?",
+				MissingFields: []string{},
+			},
+			repository: repo,
+		},
+		{
+			name:   "Overridden values",
+			id:     2,
+			values: map[string]string{"firstName": "John", "custom.plan": "Pro"},
+			expected: sequence.RenderedStep{
+				Subject:       "Hi John",
+				Content:       "Is Pro working for Acme Inc.?",
+				MissingFields: []string{},
+			},
+			repository: repo,
+		},
+		{
+			name:        "Step does not exist",
+			id:          3,
+			expectedErr: sequence.ErrStepNotFound,
+			repository:  repo,
+		},
+		{
+			name:        "Sequence does not exist",
+			id:          4,
+			expectedErr: sequence.ErrStepNotFound,
+			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{}, false, nil
+				},
+			},
+		},
+		{
+			name:        "Failed to get sequence",
+			id:          2,
+			expectedErr: repoErr,
+			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{}, false, repoErr
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := sequence.NewService(tc.repository)
+			rendered, err := svc.PreviewStep(ctx, tc.id, tc.values)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if err == nil && !reflect.DeepEqual(rendered, tc.expected) {
+				t.Errorf("Expected rendered step: %+v, got: %+v", tc.expected, rendered)
+			}
+		})
+	}
+}
+
+func TestService_ReorderSteps(t *testing.T) {
+	ctx := context.Background()
+
+	getSequenceFn := func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+		return sequence.Sequence{
+			ID:   id,
+			Name: "Test Sequence",
+			Steps: []sequence.Step{
+				{ID: 1, Position: 0, Subject: "Subject 1", Content: "Content 1"},
+				{ID: 2, Position: 1, Subject: "Subject 2", Content: "Content 2"},
+				{ID: 3, Position: 2, Subject: "Subject 3", Content: "Content 3"},
+			},
+		}, true, nil
+	}
+
+	repo := testdata.MockRepo{
+		GetSequenceFn: getSequenceFn,
+		ReorderStepsFn: func(ctx context.Context, sequenceID int, stepIDs []int) error {
+			return nil
+		},
+	}
+
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name        string
+		stepIDs     []int
+		expectedErr error
+		repository  sequence.Repository
+	}{
+		{
+			name:        "Valid order",
+			stepIDs:     []int{3, 1, 2},
+			expectedErr: nil,
+			repository:  repo,
+		},
+		{
+			name:        "Missing step",
+			stepIDs:     []int{3, 1},
+			expectedErr: sequence.ErrStepValidation,
+			repository:  repo,
+		},
+		{
+			name:        "Foreign step",
+			stepIDs:     []int{3, 1, 4},
+			expectedErr: sequence.ErrStepValidation,
+			repository:  repo,
+		},
+		{
+			name:        "Duplicate step",
+			stepIDs:     []int{3, 1, 1},
+			expectedErr: sequence.ErrStepValidation,
+			repository:  repo,
+		},
+		{
+			name:        "Sequence not found",
+			stepIDs:     []int{1, 2, 3},
+			expectedErr: sequence.ErrSequenceNotFound,
+			repository: testdata.MockRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{}, false, nil
+				},
+			},
+		},
+		{
+			name:        "Archived sequence",
+			stepIDs:     []int{1, 2, 3},
+			expectedErr: sequence.ErrSequenceArchived,
+			repository: testdata.MockRepo{
+				GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{ID: id, Name: "Test Sequence", ArchivedAt: timePtr(time.Now())}, true, nil
+				},
+			},
+		},
+		{
+			name:        "Failed to reorder steps",
+			stepIDs:     []int{1, 2, 3},
+			expectedErr: repoErr,
+			repository: testdata.MockRepo{
+				GetSequenceFn: getSequenceFn,
+				ReorderStepsFn: func(ctx context.Context, sequenceID int, stepIDs []int) error {
+					return repoErr
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := sequence.NewService(tc.repository)
+			err := svc.ReorderSteps(ctx, 1, tc.stepIDs)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestService_DeleteSequence(t *testing.T) {
+	ctx := context.Background()
+
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name        string
+		expectedErr error
+		repository  sequence.Repository
+	}{
+		{
+			name:        "Valid sequence deletion",
+			expectedErr: nil,
+			repository: testdata.MockRepo{
+				DeleteSequenceFn: func(ctx context.Context, id int) (bool, error) {
+					return true, nil
+				},
+			},
+		},
+		{
+			name:        "Sequence not found",
+			expectedErr: sequence.ErrSequenceNotFound,
+			repository: testdata.MockRepo{
+				DeleteSequenceFn: func(ctx context.Context, id int) (bool, error) {
+					return false, nil
+				},
+			},
+		},
+		{
+			name:        "Failed to delete sequence",
+			expectedErr: repoErr,
+			repository: testdata.MockRepo{
+				DeleteSequenceFn: func(ctx context.Context, id int) (bool, error) {
+					return false, repoErr
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := sequence.NewService(tc.repository)
+			err := svc.DeleteSequence(ctx, 1)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestService_ArchiveSequence(t *testing.T) {
+	ctx := context.Background()
+
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name             string
+		archive          bool
+		updated          bool
+		repoErr          error
+		expectedErr      error
+		expectedArchived bool
+	}{
+		{
+			name:             "Archive",
+			archive:          true,
+			updated:          true,
+			expectedArchived: true,
+		},
+		{
+			name:             "Unarchive",
+			archive:          false,
+			updated:          true,
+			expectedArchived: false,
+		},
+		{
+			name:             "Sequence not found",
+			archive:          true,
+			updated:          false,
+			expectedErr:      sequence.ErrSequenceNotFound,
+			expectedArchived: true,
+		},
+		{
+			name:             "Repository error",
+			archive:          false,
+			repoErr:          repoErr,
+			expectedErr:      repoErr,
+			expectedArchived: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var archived bool
+			svc := sequence.NewService(testdata.MockRepo{
+				SetSequenceArchivedFn: func(ctx context.Context, id int, a bool) (bool, error) {
+					archived = a
+					return tc.updated, tc.repoErr
+				},
+			})
+
+			var err error
+			if tc.archive {
+				err = svc.ArchiveSequence(ctx, 1)
+			} else {
+				err = svc.UnarchiveSequence(ctx, 1)
+			}
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if archived != tc.expectedArchived {
+				t.Errorf("Expected archived: %v, got: %v", tc.expectedArchived, archived)
+			}
+		})
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
@@ -622,3 +1359,11 @@ func stringPtr(s string) *string {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
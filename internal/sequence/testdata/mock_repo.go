@@ -7,18 +7,44 @@ import (
 )
 
 type MockRepo struct {
-	GetSequenceFn    func(ctx context.Context, id int) (sequence.Sequence, bool, error)
-	CreateSequenceFn func(ctx context.Context, seq sequence.Sequence) error
-	UpdateSequenceFn func(ctx context.Context, seq sequence.Sequence) (bool, error)
-	UpdateStepFn     func(ctx context.Context, step sequence.Step) (bool, error)
-	DeleteStepFn     func(ctx context.Context, id int) error
+	GetSequenceFn         func(ctx context.Context, id int) (sequence.Sequence, bool, error)
+	GetSequenceByStepIDFn func(ctx context.Context, stepID int) (sequence.Sequence, bool, error)
+	SetSequenceArchivedFn func(ctx context.Context, id int, archived bool) (bool, error)
+	DeleteSequenceFn      func(ctx context.Context, id int) (bool, error)
+	ListSequencesFn       func(ctx context.Context, filter sequence.ListFilter) ([]sequence.Sequence, error)
+	CreateSequenceFn      func(ctx context.Context, seq sequence.Sequence) (sequence.Sequence, error)
+	UpdateSequenceFn      func(ctx context.Context, seq sequence.Sequence) (bool, error)
+	CreateStepFn          func(ctx context.Context, sequenceID int, step sequence.Step) (int, error)
+	UpdateStepFn          func(ctx context.Context, step sequence.Step) (bool, error)
+	DeleteStepFn          func(ctx context.Context, id int) error
+	ReorderStepsFn        func(ctx context.Context, sequenceID int, stepIDs []int) error
+	SetWinningVariantFn   func(ctx context.Context, stepID int, variantID int) error
+	CreateVersionFn       func(ctx context.Context, sequenceID int, steps []sequence.Step) (sequence.Version, error)
+	ListVersionsFn        func(ctx context.Context, sequenceID int) ([]sequence.Version, error)
+	GetVersionFn          func(ctx context.Context, sequenceID int, version int) (sequence.Version, bool, error)
 }
 
 func (m MockRepo) GetSequence(ctx context.Context, id int) (sequence.Sequence, bool, error) {
 	return m.GetSequenceFn(ctx, id)
 }
 
-func (m MockRepo) CreateSequence(ctx context.Context, seq sequence.Sequence) error {
+func (m MockRepo) GetSequenceByStepID(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+	return m.GetSequenceByStepIDFn(ctx, stepID)
+}
+
+func (m MockRepo) SetSequenceArchived(ctx context.Context, id int, archived bool) (bool, error) {
+	return m.SetSequenceArchivedFn(ctx, id, archived)
+}
+
+func (m MockRepo) DeleteSequence(ctx context.Context, id int) (bool, error) {
+	return m.DeleteSequenceFn(ctx, id)
+}
+
+func (m MockRepo) ListSequences(ctx context.Context, filter sequence.ListFilter) ([]sequence.Sequence, error) {
+	return m.ListSequencesFn(ctx, filter)
+}
+
+func (m MockRepo) CreateSequence(ctx context.Context, seq sequence.Sequence) (sequence.Sequence, error) {
 	return m.CreateSequenceFn(ctx, seq)
 }
 
@@ -26,6 +52,10 @@ func (m MockRepo) UpdateSequence(ctx context.Context, seq sequence.Sequence) (bo
 	return m.UpdateSequenceFn(ctx, seq)
 }
 
+func (m MockRepo) CreateStep(ctx context.Context, sequenceID int, step sequence.Step) (int, error) {
+	return m.CreateStepFn(ctx, sequenceID, step)
+}
+
 func (m MockRepo) UpdateStep(ctx context.Context, step sequence.Step) (bool, error) {
 	return m.UpdateStepFn(ctx, step)
 }
@@ -33,3 +63,23 @@ func (m MockRepo) UpdateStep(ctx context.Context, step sequence.Step) (bool, err
 func (m MockRepo) DeleteStep(ctx context.Context, id int) error {
 	return m.DeleteStepFn(ctx, id)
 }
+
+func (m MockRepo) ReorderSteps(ctx context.Context, sequenceID int, stepIDs []int) error {
+	return m.ReorderStepsFn(ctx, sequenceID, stepIDs)
+}
+
+func (m MockRepo) SetWinningVariant(ctx context.Context, stepID int, variantID int) error {
+	return m.SetWinningVariantFn(ctx, stepID, variantID)
+}
+
+func (m MockRepo) CreateVersion(ctx context.Context, sequenceID int, steps []sequence.Step) (sequence.Version, error) {
+	return m.CreateVersionFn(ctx, sequenceID, steps)
+}
+
+func (m MockRepo) ListVersions(ctx context.Context, sequenceID int) ([]sequence.Version, error) {
+	return m.ListVersionsFn(ctx, sequenceID)
+}
+
+func (m MockRepo) GetVersion(ctx context.Context, sequenceID int, version int) (sequence.Version, bool, error) {
+	return m.GetVersionFn(ctx, sequenceID, version)
+}
@@ -0,0 +1,176 @@
+package sequence
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/cybre/salesforge-assignment/internal/templating"
+)
+
+const (
+	// MaxStepVariants is the largest number of variants a step can have.
+	MaxStepVariants = 10
+
+	// MaxVariantWeight is the largest weight of a variant.
+	MaxVariantWeight = 100
+
+	// maxVariantLabelLength is the maximum length of the label of a variant.
+	maxVariantLabelLength = 100
+)
+
+// Variant is an alternative subject and content of a step for A/B testing. Each contact is
+// sent one of the active variants of the step, picked in proportion to their weights.
+type Variant struct {
+	ID      int    `json:"id"`
+	Label   string `json:"label"`
+	Subject string `json:"subject"`
+	Content string `json:"content"`
+	Weight  int    `json:"weight"`
+	// Active variants are sent. Declaring a winner deactivates the other variants of the step.
+	Active bool `json:"active"`
+}
+
+// Validate validates the variant.
+func (v Variant) Validate() error {
+	if v.Label == "" {
+		return errors.New("label is required")
+	}
+
+	if len(v.Label) > maxVariantLabelLength {
+		return fmt.Errorf("label cannot be longer than %d characters", maxVariantLabelLength)
+	}
+
+	if v.Subject == "" {
+		return errors.New("subject is required")
+	}
+
+	if v.Content == "" {
+		return errors.New("content is required")
+	}
+
+	if _, err := templating.Parse(v.Subject); err != nil {
+		return fmt.Errorf("subject: %w", err)
+	}
+
+	if _, err := templating.Parse(v.Content); err != nil {
+		return fmt.Errorf("content: %w", err)
+	}
+
+	if v.Weight < 1 || v.Weight > MaxVariantWeight {
+		return fmt.Errorf("weight must be between 1 and %d", MaxVariantWeight)
+	}
+
+	return nil
+}
+
+// validateVariants validates the variants of a step. The subject and content of a step with
+// variants are set on its variants instead.
+func validateVariants(s Step) error {
+	if s.Subject != "" || s.Content != "" {
+		return errors.New("subject and content must be set on the variants of a step with variants")
+	}
+
+	if len(s.Variants) > MaxStepVariants {
+		return fmt.Errorf("a step can have at most %d variants", MaxStepVariants)
+	}
+
+	labels := make(map[string]bool, len(s.Variants))
+	active := false
+	for _, v := range s.Variants {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("variant %q: %w", v.Label, err)
+		}
+
+		key := strings.ToLower(v.Label)
+		if labels[key] {
+			return fmt.Errorf("variant label %q is used more than once", v.Label)
+		}
+		labels[key] = true
+
+		active = active || v.Active
+	}
+
+	if !active {
+		return errors.New("at least one variant must be active")
+	}
+
+	return nil
+}
+
+// ForEnrollment returns the step as it is sent to an enrollment, with the subject and content
+// of the variant assigned to the enrollment, and the ID of that variant. Steps without
+// variants are returned unchanged with a nil variant ID.
+//
+// The winner of the A/B test is sent when the step has one. Otherwise the variant is picked
+// by hashing the enrollment and step IDs, so retries send the same variant for as long as the
+// active variants and their weights do not change.
+func (s Step) ForEnrollment(enrollmentID int) (Step, *int) {
+	if winner, ok := s.winner(); ok {
+		return s.withVariant(winner), &winner.ID
+	}
+
+	active := s.activeVariants()
+	if len(active) == 0 {
+		return s, nil
+	}
+
+	total := 0
+	for _, v := range active {
+		total += v.Weight
+	}
+
+	h := fnv.New64a()
+	binary.Write(h, binary.BigEndian, [2]int64{int64(enrollmentID), int64(s.ID)})
+	bucket := int(h.Sum64() % uint64(total))
+
+	picked := active[len(active)-1]
+	for _, v := range active {
+		if bucket < v.Weight {
+			picked = v
+			break
+		}
+
+		bucket -= v.Weight
+	}
+
+	return s.withVariant(picked), &picked.ID
+}
+
+// winner returns the variant declared the winner after the version of the step was
+// published. It reports false when there is none, or when the version was published before
+// the winner was added to the step.
+func (s Step) winner() (Variant, bool) {
+	if s.WinnerID == nil {
+		return Variant{}, false
+	}
+
+	for _, v := range s.Variants {
+		if v.ID == *s.WinnerID {
+			return v, true
+		}
+	}
+
+	return Variant{}, false
+}
+
+// activeVariants returns the variants of the step that are sent.
+func (s Step) activeVariants() []Variant {
+	active := make([]Variant, 0, len(s.Variants))
+	for _, v := range s.Variants {
+		if v.Active {
+			active = append(active, v)
+		}
+	}
+
+	return active
+}
+
+// withVariant returns the step with the subject and content of the variant.
+func (s Step) withVariant(v Variant) Step {
+	s.Subject = v.Subject
+	s.Content = v.Content
+	return s
+}
@@ -0,0 +1,344 @@
+package sequence_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/sequence/testdata"
+)
+
+func TestStep_Validate_Variants(t *testing.T) {
+	variant := func(label string, weight int, active bool) sequence.Variant {
+		return sequence.Variant{Label: label, Subject: "Subject " + label, Content: "Content " + label, Weight: weight, Active: active}
+	}
+
+	testCases := []struct {
+		name     string
+		step     sequence.Step
+		expected error
+	}{
+		{
+			name:     "Valid variants",
+			step:     sequence.Step{Variants: []sequence.Variant{variant("A", 50, true), variant("B", 50, false)}},
+			expected: nil,
+		},
+		{
+			name:     "Subject set on the step",
+			step:     sequence.Step{Subject: "Subject", Variants: []sequence.Variant{variant("A", 50, true)}},
+			expected: errors.New("subject and content must be set on the variants of a step with variants"),
+		},
+		{
+			name:     "Missing label",
+			step:     sequence.Step{Variants: []sequence.Variant{variant("", 50, true)}},
+			expected: errors.New(`variant "": label is required`),
+		},
+		{
+			name:     "Label too long",
+			step:     sequence.Step{Variants: []sequence.Variant{variant(strings.Repeat("a", 101), 50, true)}},
+			expected: errors.New(`variant "` + strings.Repeat("a", 101) + `": label cannot be longer than 100 characters`),
+		},
+		{
+			name:     "Missing content",
+			step:     sequence.Step{Variants: []sequence.Variant{{Label: "A", Subject: "Subject", Weight: 50, Active: true}}},
+			expected: errors.New(`variant "A": content is required`),
+		},
+		{
+			name:     "Invalid template",
+			step:     sequence.Step{Variants: []sequence.Variant{{Label: "A", Subject: "Hi {{firstName", Content: "Content", Weight: 50, Active: true}}},
+			expected: errors.New(`variant "A": subject: invalid merge field at position 3: missing closing }}`),
+		},
+		{
+			name:     "Zero weight",
+			step:     sequence.Step{Variants: []sequence.Variant{variant("A", 0, true)}},
+			expected: errors.New(`variant "A": weight must be between 1 and 100`),
+		},
+		{
+			name:     "Duplicate label",
+			step:     sequence.Step{Variants: []sequence.Variant{variant("A", 50, true), variant("a", 50, true)}},
+			expected: errors.New(`variant label "a" is used more than once`),
+		},
+		{
+			name:     "No active variant",
+			step:     sequence.Step{Variants: []sequence.Variant{variant("A", 50, false)}},
+			expected: errors.New("at least one variant must be active"),
+		},
+		{
+			name: "Too many variants",
+			step: sequence.Step{Variants: []sequence.Variant{
+				variant("A", 1, true), variant("B", 1, true), variant("C", 1, true), variant("D", 1, true),
+				variant("E", 1, true), variant("F", 1, true), variant("G", 1, true), variant("H", 1, true),
+				variant("I", 1, true), variant("J", 1, true), variant("K", 1, true),
+			}},
+			expected: errors.New("a step can have at most 10 variants"),
+		},
+		{
+			name:     "Invalid delay",
+			step:     sequence.Step{Delay: sequence.Delay{Amount: -1}, Variants: []sequence.Variant{variant("A", 50, true)}},
+			expected: errors.New("delay amount cannot be negative"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.step.Validate()
+			if err == nil && tc.expected != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.expected)
+			} else if err != nil && tc.expected == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.expected != nil && err.Error() != tc.expected.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestStep_ForEnrollment(t *testing.T) {
+	step := sequence.Step{
+		ID: 3,
+		Variants: []sequence.Variant{
+			{ID: 1, Subject: "Subject A", Content: "Content A", Weight: 75, Active: true},
+			{ID: 2, Subject: "Subject B", Content: "Content B", Weight: 25, Active: true},
+			{ID: 3, Subject: "Subject C", Content: "Content C", Weight: 100, Active: false},
+		},
+	}
+
+	const enrollments = 10000
+	assigned := map[int]int{}
+	for id := 1; id <= enrollments; id++ {
+		sent, variantID := step.ForEnrollment(id)
+		if variantID == nil {
+			t.Fatalf("Expected a variant for enrollment %d", id)
+		}
+
+		// The same variant is picked every time
+		again, againID := step.ForEnrollment(id)
+		if *againID != *variantID || again.Subject != sent.Subject {
+			t.Fatalf("Expected the same variant for enrollment %d, got: %d and %d", id, *variantID, *againID)
+		}
+
+		if want := "Subject " + string(rune('A'+*variantID-1)); sent.Subject != want {
+			t.Fatalf("Expected subject of variant %d: %q, got: %q", *variantID, want, sent.Subject)
+		}
+
+		assigned[*variantID]++
+	}
+
+	if assigned[3] != 0 {
+		t.Errorf("Expected inactive variant to never be picked, got: %d", assigned[3])
+	}
+
+	// Variants are picked in proportion to their weights
+	if share := float64(assigned[1]) / enrollments; math.Abs(share-0.75) > 0.03 {
+		t.Errorf("Expected variant 1 to be picked for about 75%% of enrollments, got: %.2f%%", share*100)
+	}
+
+	plain := sequence.Step{ID: 4, Subject: "Subject", Content: "Content"}
+	if sent, variantID := plain.ForEnrollment(1); variantID != nil || sent.Subject != "Subject" {
+		t.Errorf("Expected step without variants to be unchanged, got: %+v and %v", sent, variantID)
+	}
+}
+
+func TestStep_ForEnrollment_Winner(t *testing.T) {
+	winnerID, unknownID := 2, 5
+	step := sequence.Step{
+		ID: 3,
+		Variants: []sequence.Variant{
+			{ID: 1, Subject: "Subject A", Content: "Content A", Weight: 50, Active: true},
+			{ID: 2, Subject: "Subject B", Content: "Content B", Weight: 50, Active: false},
+		},
+		WinnerID: &winnerID,
+	}
+
+	// The winner is sent to everyone even though it is inactive in the snapshot
+	for id := 1; id <= 100; id++ {
+		if sent, variantID := step.ForEnrollment(id); variantID == nil || *variantID != winnerID || sent.Subject != "Subject B" {
+			t.Fatalf("Expected the winner for enrollment %d, got: %+v and %v", id, sent, variantID)
+		}
+	}
+
+	// Versions published before the winner was added keep testing their active variants
+	step.WinnerID = &unknownID
+	if _, variantID := step.ForEnrollment(1); variantID == nil || *variantID != 1 {
+		t.Errorf("Expected the only active variant, got: %v", variantID)
+	}
+}
+
+func TestService_UpdateStep_Variants(t *testing.T) {
+	ctx := context.Background()
+
+	repo := func(updated *sequence.Step) testdata.MockRepo {
+		return testdata.MockRepo{
+			GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+				return sequence.Sequence{ID: 1, Name: "Test Sequence", Steps: []sequence.Step{{
+					ID: 1,
+					Variants: []sequence.Variant{
+						{ID: 7, Label: "A", Subject: "Subject A", Content: "Content A", Weight: 50, Active: false},
+						{ID: 8, Label: "B", Subject: "Subject B", Content: "Content B", Weight: 50, Active: true},
+					},
+				}}}, true, nil
+			},
+			UpdateStepFn: func(ctx context.Context, step sequence.Step) (bool, error) {
+				*updated = step
+				return true, nil
+			},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		variants       []sequence.Variant
+		expectedActive []bool
+		expectedErr    error
+	}{
+		{
+			name: "Existing variants keep their state",
+			variants: []sequence.Variant{
+				{ID: 7, Label: "A", Subject: "New subject A", Content: "Content A", Weight: 50, Active: true},
+				{ID: 8, Label: "B", Subject: "Subject B", Content: "Content B", Weight: 50},
+			},
+			expectedActive: []bool{false, true},
+		},
+		{
+			name: "New variants are active",
+			variants: []sequence.Variant{
+				{ID: 7, Label: "A", Subject: "Subject A", Content: "Content A", Weight: 50},
+				{Label: "C", Subject: "Subject C", Content: "Content C", Weight: 50},
+			},
+			expectedActive: []bool{false, true},
+		},
+		{
+			name: "Only inactive variants",
+			variants: []sequence.Variant{
+				{ID: 7, Label: "A", Subject: "Subject A", Content: "Content A", Weight: 50},
+			},
+			expectedErr: sequence.ErrStepValidation,
+		},
+		{
+			name: "Variant of another step",
+			variants: []sequence.Variant{
+				{ID: 9, Label: "C", Subject: "Subject C", Content: "Content C", Weight: 50},
+			},
+			expectedErr: sequence.ErrStepValidation,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var updated sequence.Step
+			svc := sequence.NewService(repo(&updated))
+			err := svc.UpdateStep(ctx, sequence.Step{ID: 1, Variants: tc.variants})
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			for i, v := range updated.Variants {
+				if v.Active != tc.expectedActive[i] {
+					t.Errorf("Expected variant %q active: %v, got: %v", v.Label, tc.expectedActive[i], v.Active)
+				}
+			}
+		})
+	}
+}
+
+func TestService_DeclareWinner(t *testing.T) {
+	ctx := context.Background()
+
+	getSequenceByStepIDFn := func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+		return sequence.Sequence{ID: 1, Name: "Test Sequence", Steps: []sequence.Step{{
+			ID: 1,
+			Variants: []sequence.Variant{
+				{ID: 7, Label: "A", Active: true},
+				{ID: 8, Label: "B", Active: true},
+			},
+		}}}, true, nil
+	}
+
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name        string
+		stepID      int
+		variantID   int
+		expectedErr error
+		repository  sequence.Repository
+	}{
+		{
+			name:      "Valid winner",
+			stepID:    1,
+			variantID: 8,
+			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: getSequenceByStepIDFn,
+				SetWinningVariantFn: func(ctx context.Context, stepID int, variantID int) error {
+					if stepID != 1 || variantID != 8 {
+						t.Errorf("Expected winner 8 of step 1, got: %d of step %d", variantID, stepID)
+					}
+					return nil
+				},
+			},
+		},
+		{
+			name:        "Unknown variant",
+			stepID:      1,
+			variantID:   9,
+			expectedErr: sequence.ErrVariantNotFound,
+			repository:  testdata.MockRepo{GetSequenceByStepIDFn: getSequenceByStepIDFn},
+		},
+		{
+			name:        "Step not found",
+			stepID:      2,
+			variantID:   8,
+			expectedErr: sequence.ErrStepNotFound,
+			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+					return sequence.Sequence{}, false, nil
+				},
+			},
+		},
+		{
+			name:        "Archived sequence",
+			stepID:      1,
+			variantID:   8,
+			expectedErr: sequence.ErrSequenceArchived,
+			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: func(ctx context.Context, stepID int) (sequence.Sequence, bool, error) {
+					seq, _, _ := getSequenceByStepIDFn(ctx, stepID)
+					seq.ArchivedAt = timePtr(time.Now())
+					return seq, true, nil
+				},
+			},
+		},
+		{
+			name:        "Failed to declare winner",
+			stepID:      1,
+			variantID:   8,
+			expectedErr: repoErr,
+			repository: testdata.MockRepo{
+				GetSequenceByStepIDFn: getSequenceByStepIDFn,
+				SetWinningVariantFn: func(ctx context.Context, stepID int, variantID int) error {
+					return repoErr
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := sequence.NewService(tc.repository)
+			err := svc.DeclareWinner(ctx, tc.stepID, tc.variantID)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package sequence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrVersionNotFound is returned when a sequence has no published version with the given number.
+var ErrVersionNotFound = errors.New("version with given number not found")
+
+// Version is an immutable snapshot of the steps of a sequence, taken when the sequence is
+// published. Contacts stay on the version they were enrolled into, so editing the steps only
+// changes what is sent to contacts enrolled after the next publish. The name, tracking and
+// schedule of a sequence are not versioned.
+type Version struct {
+	SequenceID  int       `json:"sequenceId"`
+	Version     int       `json:"version"`
+	Steps       []Step    `json:"steps"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// ChangeType is how a step differs between two versions.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// StepChange is a step that differs between two versions. Steps are matched by ID.
+type StepChange struct {
+	StepID int        `json:"stepId"`
+	Change ChangeType `json:"change"`
+	// Fields lists the fields of a modified step that differ.
+	Fields []string `json:"fields,omitempty"`
+	// From is the step in the older version. It is nil for added steps.
+	From *Step `json:"from,omitempty"`
+	// To is the step in the newer version. It is nil for removed steps.
+	To *Step `json:"to,omitempty"`
+}
+
+// VersionDiff lists the steps that differ between two versions of a sequence. Added and
+// modified steps are listed in the order of the newer version, followed by removed steps.
+type VersionDiff struct {
+	SequenceID int          `json:"sequenceId"`
+	From       int          `json:"from"`
+	To         int          `json:"to"`
+	Steps      []StepChange `json:"steps"`
+}
+
+// PublishSequence publishes the current steps of a sequence as its next version, which
+// contacts enrolled from now on are sent.
+func (s Service) PublishSequence(ctx context.Context, id int) (Version, error) {
+	seq, err := s.getMutableSequence(ctx, id)
+	if err != nil {
+		return Version{}, err
+	}
+
+	return s.publish(ctx, seq)
+}
+
+func (s Service) publish(ctx context.Context, seq Sequence) (Version, error) {
+	if err := seq.Validate(); err != nil {
+		return Version{}, fmt.Errorf("%w: %s", ErrSequenceValidation, err)
+	}
+
+	version, err := s.repo.CreateVersion(ctx, seq.ID, seq.Steps)
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to publish sequence: %w", err)
+	}
+
+	return version, nil
+}
+
+// ListVersions lists the published versions of a sequence, oldest first.
+func (s Service) ListVersions(ctx context.Context, id int) ([]Version, error) {
+	if _, err := s.GetSequence(ctx, id); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.repo.ListVersions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// DiffVersions lists the steps that were added, removed or modified between two published
+// versions of a sequence.
+func (s Service) DiffVersions(ctx context.Context, id int, from int, to int) (VersionDiff, error) {
+	if _, err := s.GetSequence(ctx, id); err != nil {
+		return VersionDiff{}, err
+	}
+
+	older, err := s.getVersion(ctx, id, from)
+	if err != nil {
+		return VersionDiff{}, err
+	}
+
+	newer, err := s.getVersion(ctx, id, to)
+	if err != nil {
+		return VersionDiff{}, err
+	}
+
+	return VersionDiff{
+		SequenceID: id,
+		From:       from,
+		To:         to,
+		Steps:      diffSteps(older.Steps, newer.Steps),
+	}, nil
+}
+
+func (s Service) getVersion(ctx context.Context, id int, version int) (Version, error) {
+	v, exists, err := s.repo.GetVersion(ctx, id, version)
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to get version: %w", err)
+	}
+
+	if !exists {
+		return Version{}, ErrVersionNotFound
+	}
+
+	return v, nil
+}
+
+// diffSteps compares the steps of two versions by ID.
+func diffSteps(from []Step, to []Step) []StepChange {
+	previous := make(map[int]Step, len(from))
+	for _, step := range from {
+		previous[step.ID] = step
+	}
+
+	changes := []StepChange{}
+	kept := make(map[int]bool, len(to))
+	for _, step := range to {
+		step := step
+		old, ok := previous[step.ID]
+		if !ok {
+			changes = append(changes, StepChange{StepID: step.ID, Change: ChangeAdded, To: &step})
+			continue
+		}
+
+		kept[step.ID] = true
+		if fields := changedFields(old, step); len(fields) > 0 {
+			changes = append(changes, StepChange{StepID: step.ID, Change: ChangeModified, Fields: fields, From: &old, To: &step})
+		}
+	}
+
+	for _, step := range from {
+		step := step
+		if !kept[step.ID] {
+			changes = append(changes, StepChange{StepID: step.ID, Change: ChangeRemoved, From: &step})
+		}
+	}
+
+	return changes
+}
+
+// changedFields lists the JSON names of the fields that differ between two versions of a step.
+func changedFields(from Step, to Step) []string {
+	var fields []string
+	add := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+
+	add("position", from.Position != to.Position)
+	add("kind", from.kindOrDefault() != to.kindOrDefault())
+	add("subject", from.Subject != to.Subject)
+	add("content", from.Content != to.Content)
+	add("delay", from.Delay != to.Delay)
+	add("variants", !reflect.DeepEqual(from.Variants, to.Variants))
+	add("branch", !reflect.DeepEqual(from.Branch, to.Branch))
+	add("assignment", !reflect.DeepEqual(from.Assignment, to.Assignment))
+
+	return fields
+}
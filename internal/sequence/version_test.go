@@ -0,0 +1,237 @@
+package sequence_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/sequence/testdata"
+)
+
+func TestService_PublishSequence(t *testing.T) {
+	ctx := context.Background()
+
+	steps := []sequence.Step{{ID: 1, Subject: "Subject", Content: "Content"}}
+
+	repository := func(seq sequence.Sequence) testdata.MockRepo {
+		return testdata.MockRepo{
+			GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+				return seq, seq.ID != 0, nil
+			},
+			CreateVersionFn: func(ctx context.Context, sequenceID int, steps []sequence.Step) (sequence.Version, error) {
+				return sequence.Version{SequenceID: sequenceID, Version: seq.PublishedVersion + 1, Steps: steps}, nil
+			},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		expected    sequence.Version
+		expectedErr error
+		repository  sequence.Repository
+	}{
+		{
+			name:       "First version",
+			expected:   sequence.Version{SequenceID: 1, Version: 1, Steps: steps},
+			repository: repository(sequence.Sequence{ID: 1, Name: "Test Sequence", Steps: steps}),
+		},
+		{
+			name:       "Next version",
+			expected:   sequence.Version{SequenceID: 1, Version: 3, Steps: steps},
+			repository: repository(sequence.Sequence{ID: 1, Name: "Test Sequence", PublishedVersion: 2, Steps: steps}),
+		},
+		{
+			name:        "Sequence not found",
+			expectedErr: sequence.ErrSequenceNotFound,
+			repository:  repository(sequence.Sequence{}),
+		},
+		{
+			name:        "Archived sequence",
+			expectedErr: sequence.ErrSequenceArchived,
+			repository:  repository(sequence.Sequence{ID: 1, Name: "Test Sequence", ArchivedAt: timePtr(time.Now()), Steps: steps}),
+		},
+		{
+			name:        "Sequence without steps",
+			expectedErr: sequence.ErrSequenceValidation,
+			repository:  repository(sequence.Sequence{ID: 1, Name: "Test Sequence", Steps: []sequence.Step{}}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := sequence.NewService(tc.repository)
+			version, err := svc.PublishSequence(ctx, 1)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if err == nil && !reflect.DeepEqual(version, tc.expected) {
+				t.Errorf("Expected: %v, got: %v", tc.expected, version)
+			}
+		})
+	}
+}
+
+func TestService_ListVersions(t *testing.T) {
+	ctx := context.Background()
+
+	versions := []sequence.Version{{SequenceID: 1, Version: 1}, {SequenceID: 1, Version: 2}}
+
+	repository := func(seq sequence.Sequence) testdata.MockRepo {
+		return testdata.MockRepo{
+			GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+				return seq, seq.ID != 0, nil
+			},
+			ListVersionsFn: func(ctx context.Context, sequenceID int) ([]sequence.Version, error) {
+				return versions, nil
+			},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		expected    []sequence.Version
+		expectedErr error
+		repository  sequence.Repository
+	}{
+		{
+			name:       "Versions listed",
+			expected:   versions,
+			repository: repository(sequence.Sequence{ID: 1, Name: "Test Sequence"}),
+		},
+		{
+			name:        "Sequence not found",
+			expectedErr: sequence.ErrSequenceNotFound,
+			repository:  repository(sequence.Sequence{}),
+		},
+		{
+			name:        "Archived sequence",
+			expectedErr: sequence.ErrSequenceNotFound,
+			repository:  repository(sequence.Sequence{ID: 1, Name: "Test Sequence", ArchivedAt: timePtr(time.Now())}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := sequence.NewService(tc.repository)
+			listed, err := svc.ListVersions(ctx, 1)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if err == nil && !reflect.DeepEqual(listed, tc.expected) {
+				t.Errorf("Expected: %v, got: %v", tc.expected, listed)
+			}
+		})
+	}
+}
+
+func TestService_DiffVersions(t *testing.T) {
+	ctx := context.Background()
+
+	intro := sequence.Step{ID: 1, Position: 0, Subject: "Hi", Content: "Content"}
+	followUp := sequence.Step{ID: 2, Position: 1, Subject: "Following up", Content: "Content", Delay: sequence.Delay{Amount: 2, Unit: sequence.DelayUnitDays}}
+	call := sequence.Step{ID: 3, Position: 1, Kind: sequence.StepKindCall, Assignment: &sequence.Assignment{Assignee: "jane@example.com"}}
+
+	editedIntro := intro
+	editedIntro.Subject = "Hello"
+
+	movedFollowUp := followUp
+	movedFollowUp.Position = 2
+	movedFollowUp.Delay.Amount = 3
+
+	versions := map[int][]sequence.Step{
+		1: {intro, followUp},
+		2: {editedIntro, call, movedFollowUp},
+		3: {editedIntro, call},
+	}
+
+	repo := testdata.MockRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return sequence.Sequence{ID: id, Name: "Test Sequence"}, id == 1, nil
+		},
+		GetVersionFn: func(ctx context.Context, sequenceID int, version int) (sequence.Version, bool, error) {
+			steps, ok := versions[version]
+			return sequence.Version{SequenceID: sequenceID, Version: version, Steps: steps}, ok, nil
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		sequenceID  int
+		from        int
+		to          int
+		expected    []sequence.StepChange
+		expectedErr error
+	}{
+		{
+			name:       "Steps added and modified",
+			sequenceID: 1,
+			from:       1,
+			to:         2,
+			expected: []sequence.StepChange{
+				{StepID: 1, Change: sequence.ChangeModified, Fields: []string{"subject"}, From: &intro, To: &editedIntro},
+				{StepID: 3, Change: sequence.ChangeAdded, To: &call},
+				{StepID: 2, Change: sequence.ChangeModified, Fields: []string{"position", "delay"}, From: &followUp, To: &movedFollowUp},
+			},
+		},
+		{
+			name:       "Step removed",
+			sequenceID: 1,
+			from:       2,
+			to:         3,
+			expected: []sequence.StepChange{
+				{StepID: 2, Change: sequence.ChangeRemoved, From: &movedFollowUp},
+			},
+		},
+		{
+			name:       "Same version",
+			sequenceID: 1,
+			from:       2,
+			to:         2,
+			expected:   []sequence.StepChange{},
+		},
+		{
+			name:        "Version not found",
+			sequenceID:  1,
+			from:        1,
+			to:          4,
+			expectedErr: sequence.ErrVersionNotFound,
+		},
+		{
+			name:        "Sequence not found",
+			sequenceID:  2,
+			from:        1,
+			to:          2,
+			expectedErr: sequence.ErrSequenceNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := sequence.NewService(repo)
+			diff, err := svc.DiffVersions(ctx, tc.sequenceID, tc.from, tc.to)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if diff.From != tc.from || diff.To != tc.to {
+				t.Errorf("Expected versions %d and %d, got: %d and %d", tc.from, tc.to, diff.From, diff.To)
+			}
+
+			if !reflect.DeepEqual(diff.Steps, tc.expected) {
+				t.Errorf("Expected: %+v, got: %+v", tc.expected, diff.Steps)
+			}
+		})
+	}
+}
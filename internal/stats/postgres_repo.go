@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresRepository is a repository for stats using Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository creates a new Postgres repository.
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+// countEventsQuery is served by the event_sequence_stats_idx index. Messages are joined for
+// the variants they sent.
+const countEventsQuery = `
+SELECT event.step_id, message.variant_id, event.type, COUNT(*) AS total, COUNT(DISTINCT event.message_id) AS unique_messages
+FROM event
+JOIN message ON message.id = event.message_id
+%s GROUP BY event.step_id, message.variant_id, event.type;
+`
+
+// CountEvents counts the events of a sequence per step, variant and type, leaving out bot events.
+func (r PostgresRepository) CountEvents(ctx context.Context, filter Filter) ([]EventCount, error) {
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"event.sequence_id = " + arg(filter.SequenceID), "NOT event.bot"}
+
+	if filter.From != nil {
+		conditions = append(conditions, "event.created_at >= "+arg(*filter.From))
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, "event.created_at < "+arg(*filter.To))
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	rows := []EventCountRow{}
+	if err := r.db.SelectContext(ctx, &rows, fmt.Sprintf(countEventsQuery, where), args...); err != nil {
+		return nil, err
+	}
+
+	counts := make([]EventCount, len(rows))
+	for i, row := range rows {
+		counts[i] = row.ToEventCount()
+	}
+
+	return counts, nil
+}
+
+// EventCountRow represents a row of the event counts.
+type EventCountRow struct {
+	StepID    sql.NullInt64 `db:"step_id"`
+	VariantID sql.NullInt64 `db:"variant_id"`
+	Type      string        `db:"type"`
+	Total     int           `db:"total"`
+	Unique    int           `db:"unique_messages"`
+}
+
+// ToEventCount converts the row to an event count.
+func (r EventCountRow) ToEventCount() EventCount {
+	count := EventCount{
+		Type:   tracking.EventType(r.Type),
+		Total:  r.Total,
+		Unique: r.Unique,
+	}
+
+	if r.StepID.Valid {
+		stepID := int(r.StepID.Int64)
+		count.StepID = &stepID
+	}
+
+	if r.VariantID.Valid {
+		variantID := int(r.VariantID.Int64)
+		count.VariantID = &variantID
+	}
+
+	return count
+}
@@ -0,0 +1,44 @@
+package stats_test
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/stats"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+)
+
+func TestEventCountRow_ToEventCount(t *testing.T) {
+	stepID, variantID := 4, 6
+
+	testCases := []struct {
+		name     string
+		row      stats.EventCountRow
+		expected stats.EventCount
+	}{
+		{
+			name:     "Step",
+			row:      stats.EventCountRow{StepID: sql.NullInt64{Int64: 4, Valid: true}, Type: "open", Total: 5, Unique: 2},
+			expected: stats.EventCount{StepID: &stepID, Type: tracking.EventOpen, Total: 5, Unique: 2},
+		},
+		{
+			name:     "Variant",
+			row:      stats.EventCountRow{StepID: sql.NullInt64{Int64: 4, Valid: true}, VariantID: sql.NullInt64{Int64: 6, Valid: true}, Type: "click", Total: 3, Unique: 1},
+			expected: stats.EventCount{StepID: &stepID, VariantID: &variantID, Type: tracking.EventClick, Total: 3, Unique: 1},
+		},
+		{
+			name:     "Deleted step",
+			row:      stats.EventCountRow{Type: "sent", Total: 1, Unique: 1},
+			expected: stats.EventCount{Type: tracking.EventSent, Total: 1, Unique: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if c := tc.row.ToEventCount(); !reflect.DeepEqual(c, tc.expected) {
+				t.Errorf("Expected event count: %+v, got: %+v", tc.expected, c)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+// Package stats reports the engagement with the emails sent for sequences.
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+)
+
+// ErrInvalidStatsQuery is returned when the options for getting stats are invalid.
+var ErrInvalidStatsQuery = errors.New("stats query is invalid")
+
+// dateLayout is the layout of dates accepted in place of timestamps.
+const dateLayout = "2006-01-02"
+
+// Query represents the options for getting the stats of a sequence.
+type Query struct {
+	SequenceID int
+	// From limits the stats to events at or after it, given as an RFC 3339 timestamp or a date.
+	From string
+	// To limits the stats to events before it, given as an RFC 3339 timestamp or a date. A
+	// date includes the whole day.
+	To string
+}
+
+// Filter is the stats query as understood by the repository.
+type Filter struct {
+	SequenceID int
+	From       *time.Time
+	To         *time.Time
+}
+
+// BuildFilter parses the query into a filter.
+func (q Query) BuildFilter() (Filter, error) {
+	filter := Filter{SequenceID: q.SequenceID}
+
+	if q.From != "" {
+		from, err := parseBound(q.From, false)
+		if err != nil {
+			return Filter{}, fmt.Errorf("from %s", err)
+		}
+		filter.From = &from
+	}
+
+	if q.To != "" {
+		to, err := parseBound(q.To, true)
+		if err != nil {
+			return Filter{}, fmt.Errorf("to %s", err)
+		}
+		filter.To = &to
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return Filter{}, errors.New("from must be before to")
+	}
+
+	return filter, nil
+}
+
+// parseBound parses a timestamp or date. Dates are the start of the day in UTC, or the start
+// of the next day for the end of a range.
+func parseBound(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp or a date like %s", dateLayout)
+	}
+
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t, nil
+}
+
+// Counts are the numbers of messages and engagement events.
+type Counts struct {
+	Sent int `json:"sent"`
+	// Delivered are the sent messages that did not bounce.
+	Delivered    int `json:"delivered"`
+	UniqueOpens  int `json:"uniqueOpens"`
+	TotalOpens   int `json:"totalOpens"`
+	UniqueClicks int `json:"uniqueClicks"`
+	TotalClicks  int `json:"totalClicks"`
+	Replied      int `json:"replied"`
+	Bounced      int `json:"bounced"`
+	Unsubscribed int `json:"unsubscribed"`
+}
+
+// add adds the counts of an event type.
+func (c *Counts) add(count EventCount) {
+	switch count.Type {
+	case tracking.EventSent:
+		c.Sent += count.Unique
+	case tracking.EventOpen:
+		c.UniqueOpens += count.Unique
+		c.TotalOpens += count.Total
+	case tracking.EventClick:
+		c.UniqueClicks += count.Unique
+		c.TotalClicks += count.Total
+	case tracking.EventReply:
+		c.Replied += count.Unique
+	case tracking.EventBounce:
+		c.Bounced += count.Unique
+	case tracking.EventUnsubscribe:
+		c.Unsubscribed += count.Unique
+	}
+
+	c.Delivered = max(c.Sent-c.Bounced, 0)
+}
+
+// Rates are the shares of delivered messages with engagement, and of sent messages that
+// bounced, rounded to four decimals.
+type Rates struct {
+	// OpenRate is only set for sequences with open tracking enabled.
+	OpenRate *float64 `json:"openRate,omitempty"`
+	// ClickRate is only set for sequences with click tracking enabled.
+	ClickRate       *float64 `json:"clickRate,omitempty"`
+	ReplyRate       float64  `json:"replyRate"`
+	BounceRate      float64  `json:"bounceRate"`
+	UnsubscribeRate float64  `json:"unsubscribeRate"`
+}
+
+// newRates calculates the rates of the counts.
+func newRates(c Counts, seq sequence.Sequence) Rates {
+	rates := Rates{
+		ReplyRate:       rate(c.Replied, c.Delivered),
+		BounceRate:      rate(c.Bounced, c.Sent),
+		UnsubscribeRate: rate(c.Unsubscribed, c.Delivered),
+	}
+
+	if seq.OpenTracking {
+		openRate := rate(c.UniqueOpens, c.Delivered)
+		rates.OpenRate = &openRate
+	}
+
+	if seq.ClickTracking {
+		clickRate := rate(c.UniqueClicks, c.Delivered)
+		rates.ClickRate = &clickRate
+	}
+
+	return rates
+}
+
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return math.Round(float64(n)/float64(total)*10000) / 10000
+}
+
+// StepStats are the stats of a step of a sequence.
+type StepStats struct {
+	StepID int `json:"stepId"`
+	// Removed is set for steps removed from the draft that are still in published versions.
+	Removed bool `json:"removed,omitempty"`
+	Counts
+	Rates
+	// Variants are only set for steps with variants, in the order of the step followed by the
+	// variants removed from it. Step totals include the messages of variants deleted before
+	// sequences were versioned.
+	Variants []VariantStats `json:"variants,omitempty"`
+}
+
+// VariantStats are the stats of a variant of a step.
+type VariantStats struct {
+	VariantID int    `json:"variantId"`
+	Label     string `json:"label"`
+	Active    bool   `json:"active"`
+	// Removed is set for variants removed from the draft that are still in published versions.
+	Removed bool `json:"removed,omitempty"`
+	Counts
+	Rates
+}
+
+// SequenceStats are the stats of a sequence, in total and per step.
+type SequenceStats struct {
+	SequenceID int        `json:"sequenceId"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+	Counts
+	Rates
+	// Steps are in the order of the sequence, followed by the steps removed from it that are
+	// still in published versions. Totals include the messages of steps deleted before
+	// sequences were versioned.
+	Steps []StepStats `json:"steps"`
+}
+
+// EventCount is the number of events of a type for a step.
+type EventCount struct {
+	// StepID may be the ID of a deleted step, or nil for events of steps deleted before
+	// sequences were versioned.
+	StepID *int
+	// VariantID is nil for events of steps without variants. It may be the ID of a deleted
+	// variant as well.
+	VariantID *int
+	Type      tracking.EventType
+	Total     int
+	// Unique is the number of messages with at least one event.
+	Unique int
+}
+
+// Repository represents the data layer for stats.
+type Repository interface {
+	CountEvents(ctx context.Context, filter Filter) ([]EventCount, error)
+}
+
+// SequenceRepository gets the sequences stats are reported for, including archived ones.
+type SequenceRepository interface {
+	GetSequence(ctx context.Context, id int) (sequence.Sequence, bool, error)
+	ListVersions(ctx context.Context, sequenceID int) ([]sequence.Version, error)
+}
+
+// Service contains the business logic for stats.
+type Service struct {
+	repo      Repository
+	sequences SequenceRepository
+}
+
+// NewService creates a new stats service.
+func NewService(repo Repository, sequences SequenceRepository) *Service {
+	return &Service{
+		repo:      repo,
+		sequences: sequences,
+	}
+}
+
+// SequenceStats gets the stats of a sequence. Events of bots are not counted.
+func (s Service) SequenceStats(ctx context.Context, query Query) (SequenceStats, error) {
+	filter, err := query.BuildFilter()
+	if err != nil {
+		return SequenceStats{}, fmt.Errorf("%w: %s", ErrInvalidStatsQuery, err)
+	}
+
+	seq, exists, err := s.sequences.GetSequence(ctx, query.SequenceID)
+	if err != nil {
+		return SequenceStats{}, fmt.Errorf("failed to get sequence: %w", err)
+	}
+
+	if !exists {
+		return SequenceStats{}, sequence.ErrSequenceNotFound
+	}
+
+	versions, err := s.sequences.ListVersions(ctx, seq.ID)
+	if err != nil {
+		return SequenceStats{}, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	counts, err := s.repo.CountEvents(ctx, filter)
+	if err != nil {
+		return SequenceStats{}, fmt.Errorf("failed to count events: %w", err)
+	}
+
+	stats := SequenceStats{
+		SequenceID: seq.ID,
+		From:       filter.From,
+		To:         filter.To,
+		Steps:      reportedSteps(seq.Steps, versions),
+	}
+
+	stepIndex := map[int]int{}
+	variantIndex := map[int][2]int{}
+	for i, step := range stats.Steps {
+		stepIndex[step.StepID] = i
+		for j, v := range step.Variants {
+			variantIndex[v.VariantID] = [2]int{i, j}
+		}
+	}
+
+	for _, count := range counts {
+		stats.Counts.add(count)
+
+		if count.StepID != nil {
+			if i, ok := stepIndex[*count.StepID]; ok {
+				stats.Steps[i].Counts.add(count)
+			}
+		}
+
+		if count.VariantID != nil {
+			if at, ok := variantIndex[*count.VariantID]; ok {
+				stats.Steps[at[0]].Variants[at[1]].Counts.add(count)
+			}
+		}
+	}
+
+	stats.Rates = newRates(stats.Counts, seq)
+	for i := range stats.Steps {
+		step := &stats.Steps[i]
+		step.Rates = newRates(step.Counts, seq)
+
+		for j := range step.Variants {
+			v := &step.Variants[j]
+			v.Rates = newRates(v.Counts, seq)
+		}
+	}
+
+	return stats, nil
+}
+
+// reportedSteps returns the steps and variants stats are reported for without counts. These
+// are the steps of the draft in order, followed by the steps removed from the draft that are
+// still in published versions, newest version first, as contacts pinned to a version may have
+// been sent them. Variants removed from the draft are added to their steps the same way.
+func reportedSteps(draft []sequence.Step, versions []sequence.Version) []StepStats {
+	steps := []StepStats{}
+	index := map[int]int{}
+
+	add := func(step sequence.Step, removed bool) {
+		i, ok := index[step.ID]
+		if !ok {
+			i = len(steps)
+			index[step.ID] = i
+			steps = append(steps, StepStats{StepID: step.ID, Removed: removed})
+		}
+
+		for _, v := range step.Variants {
+			if !hasVariant(steps[i].Variants, v.ID) {
+				steps[i].Variants = append(steps[i].Variants, VariantStats{
+					VariantID: v.ID,
+					Label:     v.Label,
+					Active:    v.Active,
+					Removed:   removed,
+				})
+			}
+		}
+	}
+
+	for _, step := range draft {
+		add(step, false)
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		for _, step := range versions[i].Steps {
+			add(step, true)
+		}
+	}
+
+	return steps
+}
+
+func hasVariant(variants []VariantStats, id int) bool {
+	for _, v := range variants {
+		if v.VariantID == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,303 @@
+package stats_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/stats"
+	"github.com/cybre/salesforge-assignment/internal/stats/testdata"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+)
+
+func TestQuery_BuildFilter(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	timestamp := time.Date(2024, 1, 15, 12, 30, 0, 0, time.FixedZone("", 2*60*60))
+
+	testCases := []struct {
+		name          string
+		query         stats.Query
+		expected      stats.Filter
+		expectedError bool
+	}{
+		{
+			name:     "No range",
+			query:    stats.Query{SequenceID: 1},
+			expected: stats.Filter{SequenceID: 1},
+		},
+		{
+			name:     "Dates include the last day",
+			query:    stats.Query{SequenceID: 1, From: "2024-01-01", To: "2024-01-31"},
+			expected: stats.Filter{SequenceID: 1, From: &from, To: &to},
+		},
+		{
+			name:     "Timestamp",
+			query:    stats.Query{SequenceID: 1, From: "2024-01-15T12:30:00+02:00"},
+			expected: stats.Filter{SequenceID: 1, From: &timestamp},
+		},
+		{
+			name:          "Invalid from",
+			query:         stats.Query{SequenceID: 1, From: "yesterday"},
+			expectedError: true,
+		},
+		{
+			name:          "From after to",
+			query:         stats.Query{SequenceID: 1, From: "2024-02-01", To: "2024-01-01"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := tc.query.BuildFilter()
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("Expected an error, got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(filter, tc.expected) {
+				t.Errorf("Expected filter: %+v, got: %+v", tc.expected, filter)
+			}
+		})
+	}
+}
+
+func TestService_SequenceStats(t *testing.T) {
+	stepOne, stepTwo := 1, 2
+	seq := sequence.Sequence{
+		ID:           1,
+		OpenTracking: true,
+		Steps:        []sequence.Step{{ID: stepTwo}, {ID: stepOne}},
+	}
+
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return seq, id == seq.ID, nil
+		},
+		ListVersionsFn: func(ctx context.Context, sequenceID int) ([]sequence.Version, error) {
+			return nil, nil
+		},
+	}
+
+	repo := testdata.MockRepo{
+		CountEventsFn: func(ctx context.Context, filter stats.Filter) ([]stats.EventCount, error) {
+			return []stats.EventCount{
+				{StepID: &stepOne, Type: tracking.EventSent, Total: 4, Unique: 4},
+				{StepID: &stepOne, Type: tracking.EventBounce, Total: 1, Unique: 1},
+				{StepID: &stepOne, Type: tracking.EventOpen, Total: 5, Unique: 2},
+				{StepID: &stepOne, Type: tracking.EventClick, Total: 2, Unique: 1},
+				{StepID: &stepOne, Type: tracking.EventReply, Total: 1, Unique: 1},
+				{Type: tracking.EventSent, Total: 2, Unique: 2},
+				{Type: tracking.EventOpen, Total: 1, Unique: 1},
+			}, nil
+		},
+	}
+
+	svc := stats.NewService(repo, sequences)
+
+	result, err := svc.SequenceStats(context.Background(), stats.Query{SequenceID: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expectedTotal := stats.Counts{Sent: 6, Delivered: 5, UniqueOpens: 3, TotalOpens: 6, UniqueClicks: 1, TotalClicks: 2, Replied: 1, Bounced: 1}
+	if result.Counts != expectedTotal {
+		t.Errorf("Expected total counts: %+v, got: %+v", expectedTotal, result.Counts)
+	}
+
+	if result.OpenRate == nil || *result.OpenRate != 0.6 {
+		t.Errorf("Expected open rate 0.6, got: %v", result.OpenRate)
+	}
+
+	if result.ClickRate != nil {
+		t.Errorf("Expected no click rate without click tracking, got: %v", *result.ClickRate)
+	}
+
+	if result.BounceRate != 0.1667 {
+		t.Errorf("Expected bounce rate 0.1667, got: %v", result.BounceRate)
+	}
+
+	if len(result.Steps) != 2 || result.Steps[0].StepID != stepTwo || result.Steps[1].StepID != stepOne {
+		t.Fatalf("Expected stats for both steps in sequence order, got: %+v", result.Steps)
+	}
+
+	if result.Steps[0].Counts != (stats.Counts{}) || result.Steps[0].OpenRate == nil || *result.Steps[0].OpenRate != 0 {
+		t.Errorf("Expected empty stats for the second step, got: %+v", result.Steps[0])
+	}
+
+	expectedStep := stats.Counts{Sent: 4, Delivered: 3, UniqueOpens: 2, TotalOpens: 5, UniqueClicks: 1, TotalClicks: 2, Replied: 1, Bounced: 1}
+	if result.Steps[1].Counts != expectedStep {
+		t.Errorf("Expected step counts: %+v, got: %+v", expectedStep, result.Steps[1].Counts)
+	}
+
+	if result.Steps[1].ReplyRate != 0.3333 {
+		t.Errorf("Expected reply rate 0.3333, got: %v", result.Steps[1].ReplyRate)
+	}
+}
+
+func TestService_SequenceStats_Variants(t *testing.T) {
+	stepID, variantA, variantB, deletedVariant := 1, 7, 8, 9
+	seq := sequence.Sequence{
+		ID: 1,
+		Steps: []sequence.Step{{
+			ID: stepID,
+			Variants: []sequence.Variant{
+				{ID: variantA, Label: "A", Active: true},
+				{ID: variantB, Label: "B", Active: false},
+			},
+		}},
+	}
+
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return seq, true, nil
+		},
+		ListVersionsFn: func(ctx context.Context, sequenceID int) ([]sequence.Version, error) {
+			return nil, nil
+		},
+	}
+
+	repo := testdata.MockRepo{
+		CountEventsFn: func(ctx context.Context, filter stats.Filter) ([]stats.EventCount, error) {
+			return []stats.EventCount{
+				{StepID: &stepID, VariantID: &variantA, Type: tracking.EventSent, Total: 4, Unique: 4},
+				{StepID: &stepID, VariantID: &variantA, Type: tracking.EventReply, Total: 2, Unique: 2},
+				{StepID: &stepID, VariantID: &variantB, Type: tracking.EventSent, Total: 5, Unique: 5},
+				{StepID: &stepID, VariantID: &variantB, Type: tracking.EventReply, Total: 1, Unique: 1},
+				{StepID: &stepID, VariantID: &deletedVariant, Type: tracking.EventSent, Total: 1, Unique: 1},
+			}, nil
+		},
+	}
+
+	svc := stats.NewService(repo, sequences)
+
+	result, err := svc.SequenceStats(context.Background(), stats.Query{SequenceID: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	step := result.Steps[0]
+	if step.Sent != 10 || step.Replied != 3 {
+		t.Errorf("Expected step totals to include every variant, got: %+v", step.Counts)
+	}
+
+	if len(step.Variants) != 2 {
+		t.Fatalf("Expected stats for both variants, got: %+v", step.Variants)
+	}
+
+	expected := []stats.VariantStats{
+		{VariantID: variantA, Label: "A", Active: true, Counts: stats.Counts{Sent: 4, Delivered: 4, Replied: 2}, Rates: stats.Rates{ReplyRate: 0.5}},
+		{VariantID: variantB, Label: "B", Active: false, Counts: stats.Counts{Sent: 5, Delivered: 5, Replied: 1}, Rates: stats.Rates{ReplyRate: 0.2}},
+	}
+	if !reflect.DeepEqual(step.Variants, expected) {
+		t.Errorf("Expected variant stats: %+v, got: %+v", expected, step.Variants)
+	}
+}
+
+func TestService_SequenceStats_PublishedVersions(t *testing.T) {
+	stepOne, stepTwo, stepThree, variantA, variantB := 1, 2, 3, 7, 8
+	seq := sequence.Sequence{
+		ID: 1,
+		Steps: []sequence.Step{{
+			ID:       stepOne,
+			Variants: []sequence.Variant{{ID: variantA, Label: "A", Active: true}},
+		}},
+	}
+
+	// Step two was removed from the draft after version 1, and step three and variant B after
+	// version 2
+	versions := []sequence.Version{
+		{SequenceID: 1, Version: 1, Steps: []sequence.Step{{ID: stepOne}, {ID: stepTwo}}},
+		{SequenceID: 1, Version: 2, Steps: []sequence.Step{
+			{
+				ID: stepOne,
+				Variants: []sequence.Variant{
+					{ID: variantA, Label: "A", Active: true},
+					{ID: variantB, Label: "B", Active: true},
+				},
+			},
+			{ID: stepThree},
+		}},
+	}
+
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return seq, true, nil
+		},
+		ListVersionsFn: func(ctx context.Context, sequenceID int) ([]sequence.Version, error) {
+			return versions, nil
+		},
+	}
+
+	repo := testdata.MockRepo{
+		CountEventsFn: func(ctx context.Context, filter stats.Filter) ([]stats.EventCount, error) {
+			return []stats.EventCount{
+				{StepID: &stepOne, VariantID: &variantA, Type: tracking.EventSent, Total: 2, Unique: 2},
+				{StepID: &stepOne, VariantID: &variantB, Type: tracking.EventSent, Total: 3, Unique: 3},
+				{StepID: &stepTwo, Type: tracking.EventSent, Total: 4, Unique: 4},
+				{StepID: &stepThree, Type: tracking.EventSent, Total: 5, Unique: 5},
+			}, nil
+		},
+	}
+
+	svc := stats.NewService(repo, sequences)
+
+	result, err := svc.SequenceStats(context.Background(), stats.Query{SequenceID: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []stats.StepStats{
+		{
+			StepID: stepOne,
+			Counts: stats.Counts{Sent: 5, Delivered: 5},
+			Variants: []stats.VariantStats{
+				{VariantID: variantA, Label: "A", Active: true, Counts: stats.Counts{Sent: 2, Delivered: 2}},
+				{VariantID: variantB, Label: "B", Active: true, Removed: true, Counts: stats.Counts{Sent: 3, Delivered: 3}},
+			},
+		},
+		{StepID: stepThree, Removed: true, Counts: stats.Counts{Sent: 5, Delivered: 5}},
+		{StepID: stepTwo, Removed: true, Counts: stats.Counts{Sent: 4, Delivered: 4}},
+	}
+	if !reflect.DeepEqual(result.Steps, expected) {
+		t.Errorf("Expected step stats: %+v, got: %+v", expected, result.Steps)
+	}
+
+	if result.Sent != 14 {
+		t.Errorf("Expected 14 sent in total, got: %d", result.Sent)
+	}
+}
+
+func TestService_SequenceStats_Errors(t *testing.T) {
+	repo := testdata.MockRepo{
+		CountEventsFn: func(ctx context.Context, filter stats.Filter) ([]stats.EventCount, error) {
+			return nil, nil
+		},
+	}
+
+	sequences := testdata.MockSequenceRepo{
+		GetSequenceFn: func(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+			return sequence.Sequence{}, false, nil
+		},
+	}
+
+	svc := stats.NewService(repo, sequences)
+
+	if _, err := svc.SequenceStats(context.Background(), stats.Query{SequenceID: 1}); !errors.Is(err, sequence.ErrSequenceNotFound) {
+		t.Errorf("Expected ErrSequenceNotFound, got: %v", err)
+	}
+
+	if _, err := svc.SequenceStats(context.Background(), stats.Query{SequenceID: 1, To: "soon"}); !errors.Is(err, stats.ErrInvalidStatsQuery) {
+		t.Errorf("Expected ErrInvalidStatsQuery, got: %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/stats"
+)
+
+type MockRepo struct {
+	CountEventsFn func(ctx context.Context, filter stats.Filter) ([]stats.EventCount, error)
+}
+
+func (m MockRepo) CountEvents(ctx context.Context, filter stats.Filter) ([]stats.EventCount, error) {
+	return m.CountEventsFn(ctx, filter)
+}
+
+type MockSequenceRepo struct {
+	GetSequenceFn  func(ctx context.Context, id int) (sequence.Sequence, bool, error)
+	ListVersionsFn func(ctx context.Context, sequenceID int) ([]sequence.Version, error)
+}
+
+func (m MockSequenceRepo) GetSequence(ctx context.Context, id int) (sequence.Sequence, bool, error) {
+	return m.GetSequenceFn(ctx, id)
+}
+
+func (m MockSequenceRepo) ListVersions(ctx context.Context, sequenceID int) ([]sequence.Version, error) {
+	return m.ListVersionsFn(ctx, sequenceID)
+}
@@ -0,0 +1,75 @@
+package suppression
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+)
+
+// ErrInvalidListQuery is returned when the options for listing suppressions are invalid.
+var ErrInvalidListQuery = errors.New("list query is invalid")
+
+// ListQuery represents the options for listing suppressions. Suppressions are listed in
+// order of ID.
+type ListQuery struct {
+	// Type filters suppressions by type. All types are listed when it is empty.
+	Type Type
+	// Search filters suppressions whose value contains the given text, ignoring case.
+	Search string
+	Limit  int
+	// Cursor is the opaque cursor returned with the previous page.
+	Cursor string
+}
+
+// Validate validates the list query.
+func (q ListQuery) Validate() error {
+	if q.Type != "" && q.Type != TypeEmail && q.Type != TypeDomain {
+		return fmt.Errorf("type must be one of %q or %q", TypeEmail, TypeDomain)
+	}
+
+	return nil
+}
+
+// ListFilter is the list query as understood by the repository.
+type ListFilter struct {
+	Type   Type
+	Search string
+	listing.Filter
+}
+
+// Page is a page of suppressions.
+type Page struct {
+	Suppressions []Suppression `json:"suppressions"`
+	// NextCursor is the cursor for the next page. It is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListSuppressions lists suppressions matching the query, one page at a time.
+func (s Service) ListSuppressions(ctx context.Context, query ListQuery) (Page, error) {
+	if err := query.Validate(); err != nil {
+		return Page{}, fmt.Errorf("%w: %s", ErrInvalidListQuery, err)
+	}
+
+	window, err := listing.NewFilter(query.Limit, query.Cursor)
+	if err != nil {
+		return Page{}, fmt.Errorf("%w: %s", ErrInvalidListQuery, err)
+	}
+
+	filter := ListFilter{
+		Type:   query.Type,
+		Search: query.Search,
+		Filter: window,
+	}
+
+	suppressions, err := s.repo.ListSuppressions(ctx, filter)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to list suppressions: %w", err)
+	}
+
+	page := Page{}
+	page.Suppressions, page.NextCursor = listing.Trim(suppressions, filter.Filter, func(sp Suppression) int { return sp.ID })
+
+	return page, nil
+}
@@ -0,0 +1,221 @@
+package suppression
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres error code for unique constraint violations.
+const uniqueViolation = "23505"
+
+// PostgresRepository is a repository containing suppressions using Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository creates a new Postgres repository.
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+const addSuppressionQuery = `
+INSERT INTO suppression (type, value, reason) VALUES ($1, $2, $3) RETURNING id, type, value, reason, created_at;
+`
+
+// AddSuppression adds a suppression and returns it as stored.
+func (r PostgresRepository) AddSuppression(ctx context.Context, s Suppression) (Suppression, error) {
+	row := SuppressionRow{}
+	if err := r.db.QueryRowxContext(ctx, addSuppressionQuery, s.Type, s.Value, s.Reason).StructScan(&row); err != nil {
+		return Suppression{}, translateError(err)
+	}
+
+	return row.ToSuppression(), nil
+}
+
+const deleteSuppressionQuery = `
+DELETE FROM suppression WHERE id = $1;
+`
+
+// DeleteSuppression deletes a suppression by ID and reports whether it existed.
+func (r PostgresRepository) DeleteSuppression(ctx context.Context, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, deleteSuppressionQuery, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+const listSuppressionsQuery = `
+SELECT id, type, value, reason, created_at FROM suppression %s ORDER BY id LIMIT %d;
+`
+
+// ListSuppressions lists the suppressions matching the filter in order of ID.
+func (r PostgresRepository) ListSuppressions(ctx context.Context, filter ListFilter) ([]Suppression, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Type != "" {
+		conditions = append(conditions, "type = "+arg(filter.Type))
+	}
+
+	if filter.Search != "" {
+		conditions = append(conditions, "value ILIKE "+arg("%"+listing.EscapeLike(filter.Search)+"%"))
+	}
+
+	if filter.AfterID > 0 {
+		conditions = append(conditions, "id > "+arg(filter.AfterID))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows := []SuppressionRow{}
+	if err := r.db.SelectContext(ctx, &rows, fmt.Sprintf(listSuppressionsQuery, where, filter.Limit), args...); err != nil {
+		return nil, err
+	}
+
+	suppressions := make([]Suppression, len(rows))
+	for i, row := range rows {
+		suppressions[i] = row.ToSuppression()
+	}
+
+	return suppressions, nil
+}
+
+const findSuppressionQuery = `
+SELECT id, type, value, reason, created_at FROM suppression
+WHERE (type = 'email' AND value = $1) OR (type = 'domain' AND value = $2)
+ORDER BY type = 'email' DESC LIMIT 1;
+`
+
+// FindSuppression finds the suppression of the email address, or else of the domain.
+func (r PostgresRepository) FindSuppression(ctx context.Context, email string, domain string) (Suppression, bool, error) {
+	row := SuppressionRow{}
+	if err := r.db.GetContext(ctx, &row, findSuppressionQuery, email, domain); err != nil {
+		if err == sql.ErrNoRows {
+			return Suppression{}, false, nil
+		}
+
+		return Suppression{}, false, err
+	}
+
+	return row.ToSuppression(), true, nil
+}
+
+const getRecipientQuery = `
+SELECT contact.id, contact.email FROM message JOIN contact ON contact.id = message.contact_id WHERE message.id = $1;
+`
+
+const suppressRecipientQuery = `
+INSERT INTO suppression (type, value, reason) VALUES ('email', $1, 'unsubscribe') ON CONFLICT (type, value) DO NOTHING;
+`
+
+const stopEnrollmentsQuery = `
+UPDATE enrollment SET state = 'unsubscribed', next_send_at = NULL, updated_at = NOW() WHERE contact_id = $1 AND state IN ('active', 'paused');
+`
+
+const recordUnsubscribeEventQuery = `
+INSERT INTO event (message_id, sequence_id, step_id, type)
+SELECT id, sequence_id, step_id, 'unsubscribe' FROM message
+WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM event WHERE message_id = $1 AND type = 'unsubscribe');
+`
+
+// Unsubscribe suppresses the email address of the recipient of a message, stops the
+// recipient's active and paused enrollments and records the unsubscribe for the sequence stats
+// in a single transaction. It reports whether the message exists.
+func (r PostgresRepository) Unsubscribe(ctx context.Context, messageID int) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	if err := func() error {
+		var recipient struct {
+			ID    int    `db:"id"`
+			Email string `db:"email"`
+		}
+		if err := tx.GetContext(ctx, &recipient, getRecipientQuery, messageID); err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+
+			return err
+		}
+		exists = true
+
+		if _, err := tx.ExecContext(ctx, suppressRecipientQuery, recipient.Email); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, stopEnrollmentsQuery, recipient.ID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, recordUnsubscribeEventQuery, messageID)
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// translateError converts constraint violations into domain errors.
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "suppression_type_value_key" {
+		return ErrSuppressionExists
+	}
+
+	return err
+}
+
+// SuppressionRow represents a row of the suppression table.
+type SuppressionRow struct {
+	ID        int       `db:"id"`
+	Type      string    `db:"type"`
+	Value     string    `db:"value"`
+	Reason    string    `db:"reason"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// ToSuppression converts the row to a suppression domain model.
+func (r SuppressionRow) ToSuppression() Suppression {
+	return Suppression{
+		ID:        r.ID,
+		Type:      Type(r.Type),
+		Value:     r.Value,
+		Reason:    Reason(r.Reason),
+		CreatedAt: r.CreatedAt,
+	}
+}
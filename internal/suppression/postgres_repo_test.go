@@ -0,0 +1,19 @@
+package suppression_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+)
+
+func TestSuppressionRow_ToSuppression(t *testing.T) {
+	now := time.Now()
+
+	row := suppression.SuppressionRow{ID: 1, Type: "domain", Value: "example.com", Reason: "manual", CreatedAt: now}
+	expected := suppression.Suppression{ID: 1, Type: suppression.TypeDomain, Value: "example.com", Reason: suppression.ReasonManual, CreatedAt: now}
+
+	if s := row.ToSuppression(); s != expected {
+		t.Errorf("Expected suppression: %+v, got: %+v", expected, s)
+	}
+}
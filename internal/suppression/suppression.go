@@ -0,0 +1,211 @@
+// Package suppression keeps the list of email addresses and domains that must not be emailed,
+// and unsubscribes recipients through the unsubscribe URLs of sent emails.
+package suppression
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+)
+
+var (
+	// ErrSuppressionNotFound is returned when a suppression with the given ID is not found.
+	ErrSuppressionNotFound = errors.New("suppression with given ID not found")
+
+	// ErrSuppressionValidation is returned when a suppression model fails validation.
+	ErrSuppressionValidation = errors.New("suppression model is invalid")
+
+	// ErrSuppressionExists is returned when the address or domain is already suppressed.
+	ErrSuppressionExists = errors.New("suppression with given type and value already exists")
+
+	// ErrInvalidUnsubscribeLink is returned when an unsubscribe link is not valid, or its
+	// message no longer exists.
+	ErrInvalidUnsubscribeLink = errors.New("unsubscribe link is invalid")
+)
+
+// domainPattern matches lower case domain names with at least two labels.
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z0-9-]{2,63}$`)
+
+// Type is what a suppression matches.
+type Type string
+
+const (
+	// TypeEmail suppressions match a single email address.
+	TypeEmail Type = "email"
+	// TypeDomain suppressions match every email address of a domain.
+	TypeDomain Type = "domain"
+)
+
+// Reason is why an email address or domain was suppressed.
+type Reason string
+
+const (
+	// ReasonManual suppressions were added through the API.
+	ReasonManual Reason = "manual"
+	// ReasonUnsubscribe suppressions were added by the recipient unsubscribing.
+	ReasonUnsubscribe Reason = "unsubscribe"
+	// ReasonBounce suppressions were added because an email to the address hard bounced.
+	ReasonBounce Reason = "bounce"
+)
+
+// Suppression is an email address or domain that must not be emailed.
+type Suppression struct {
+	ID        int       `json:"id"`
+	Type      Type      `json:"type"`
+	Value     string    `json:"value"`
+	Reason    Reason    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Normalize returns a copy of the suppression with its value in lower case and without
+// surrounding whitespace. The reason defaults to manual.
+func (s Suppression) Normalize() Suppression {
+	s.Value = strings.ToLower(strings.TrimSpace(s.Value))
+	if s.Type == TypeDomain {
+		s.Value = strings.TrimPrefix(s.Value, "@")
+	}
+
+	if s.Reason == "" {
+		s.Reason = ReasonManual
+	}
+
+	return s
+}
+
+// Validate validates the suppression model.
+func (s Suppression) Validate() error {
+	if s.Value == "" {
+		return errors.New("value is required")
+	}
+
+	switch s.Type {
+	case TypeEmail:
+		if _, err := contact.NormalizeEmail(s.Value); err != nil {
+			return err
+		}
+	case TypeDomain:
+		if len(s.Value) > 253 || !domainPattern.MatchString(s.Value) {
+			return fmt.Errorf("domain %q is invalid", s.Value)
+		}
+	default:
+		return fmt.Errorf("type must be one of %q or %q", TypeEmail, TypeDomain)
+	}
+
+	if s.Reason != ReasonManual && s.Reason != ReasonUnsubscribe && s.Reason != ReasonBounce {
+		return fmt.Errorf("reason must be one of %q, %q or %q", ReasonManual, ReasonUnsubscribe, ReasonBounce)
+	}
+
+	return nil
+}
+
+// Repository represents the data layer for suppressions.
+type Repository interface {
+	AddSuppression(ctx context.Context, s Suppression) (Suppression, error)
+	DeleteSuppression(ctx context.Context, id int) (bool, error)
+	ListSuppressions(ctx context.Context, filter ListFilter) ([]Suppression, error)
+	FindSuppression(ctx context.Context, email string, domain string) (Suppression, bool, error)
+	Unsubscribe(ctx context.Context, messageID int) (bool, error)
+}
+
+// TokenVerifier verifies the tokens of unsubscribe URLs.
+type TokenVerifier interface {
+	VerifyUnsubscribe(token string) (int, error)
+}
+
+// Service contains the business logic for suppressions.
+type Service struct {
+	repo   Repository
+	tokens TokenVerifier
+}
+
+// NewService creates a new suppression service.
+func NewService(repo Repository, tokens TokenVerifier) *Service {
+	return &Service{
+		repo:   repo,
+		tokens: tokens,
+	}
+}
+
+// AddSuppression suppresses an email address or domain and returns the suppression with its
+// assigned ID.
+func (s Service) AddSuppression(ctx context.Context, suppression Suppression) (Suppression, error) {
+	suppression = suppression.Normalize()
+	if err := suppression.Validate(); err != nil {
+		return Suppression{}, fmt.Errorf("%w: %s", ErrSuppressionValidation, err)
+	}
+
+	created, err := s.repo.AddSuppression(ctx, suppression)
+	if err != nil {
+		if errors.Is(err, ErrSuppressionExists) {
+			return Suppression{}, err
+		}
+
+		return Suppression{}, fmt.Errorf("failed to add suppression: %w", err)
+	}
+
+	return created, nil
+}
+
+// DeleteSuppression deletes a suppression by ID, allowing its address or domain to be emailed
+// again. Enrollments stopped because of it are not resumed.
+func (s Service) DeleteSuppression(ctx context.Context, id int) error {
+	exists, err := s.repo.DeleteSuppression(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete suppression: %w", err)
+	}
+
+	if !exists {
+		return ErrSuppressionNotFound
+	}
+
+	return nil
+}
+
+// FindSuppression finds the suppression of an email address, or else of its domain, and
+// reports whether either is suppressed.
+func (s Service) FindSuppression(ctx context.Context, email string) (Suppression, bool, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	_, domain, _ := strings.Cut(email, "@")
+
+	suppression, suppressed, err := s.repo.FindSuppression(ctx, email, domain)
+	if err != nil {
+		return Suppression{}, false, fmt.Errorf("failed to check suppressions: %w", err)
+	}
+
+	return suppression, suppressed, nil
+}
+
+// VerifyUnsubscribe checks that the token of an unsubscribe URL is valid without
+// unsubscribing the recipient.
+func (s Service) VerifyUnsubscribe(token string) error {
+	if _, err := s.tokens.VerifyUnsubscribe(token); err != nil {
+		return ErrInvalidUnsubscribeLink
+	}
+
+	return nil
+}
+
+// Unsubscribe suppresses the recipient of the message the token was created for and stops
+// their active and paused enrollments. Unsubscribing more than once has no further effect.
+func (s Service) Unsubscribe(ctx context.Context, token string) error {
+	messageID, err := s.tokens.VerifyUnsubscribe(token)
+	if err != nil {
+		return ErrInvalidUnsubscribeLink
+	}
+
+	exists, err := s.repo.Unsubscribe(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to unsubscribe recipient of message %d: %w", messageID, err)
+	}
+
+	if !exists {
+		return ErrInvalidUnsubscribeLink
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package suppression_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	"github.com/cybre/salesforge-assignment/internal/suppression/testdata"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+)
+
+func TestSuppression_Validate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		suppression suppression.Suppression
+		expected    suppression.Suppression
+		err         error
+	}{
+		{
+			name:        "Email",
+			suppression: suppression.Suppression{Type: suppression.TypeEmail, Value: " Jane@Example.com "},
+			expected:    suppression.Suppression{Type: suppression.TypeEmail, Value: "jane@example.com", Reason: suppression.ReasonManual},
+		},
+		{
+			name:        "Domain",
+			suppression: suppression.Suppression{Type: suppression.TypeDomain, Value: "@Example.co.uk", Reason: suppression.ReasonUnsubscribe},
+			expected:    suppression.Suppression{Type: suppression.TypeDomain, Value: "example.co.uk", Reason: suppression.ReasonUnsubscribe},
+		},
+		{
+			name:        "Missing value",
+			suppression: suppression.Suppression{Type: suppression.TypeEmail},
+			expected:    suppression.Suppression{Type: suppression.TypeEmail, Reason: suppression.ReasonManual},
+			err:         errors.New("value is required"),
+		},
+		{
+			name:        "Invalid email",
+			suppression: suppression.Suppression{Type: suppression.TypeEmail, Value: "example.com"},
+			expected:    suppression.Suppression{Type: suppression.TypeEmail, Value: "example.com", Reason: suppression.ReasonManual},
+			err:         errors.New(`email "example.com" is invalid`),
+		},
+		{
+			name:        "Invalid domain",
+			suppression: suppression.Suppression{Type: suppression.TypeDomain, Value: "jane@example.com"},
+			expected:    suppression.Suppression{Type: suppression.TypeDomain, Value: "jane@example.com", Reason: suppression.ReasonManual},
+			err:         errors.New(`domain "jane@example.com" is invalid`),
+		},
+		{
+			name:        "Unknown type",
+			suppression: suppression.Suppression{Type: "phone", Value: "123"},
+			expected:    suppression.Suppression{Type: "phone", Value: "123", Reason: suppression.ReasonManual},
+			err:         errors.New(`type must be one of "email" or "domain"`),
+		},
+		{
+			name:        "Unknown reason",
+			suppression: suppression.Suppression{Type: suppression.TypeEmail, Value: "jane@example.com", Reason: "spam"},
+			expected:    suppression.Suppression{Type: suppression.TypeEmail, Value: "jane@example.com", Reason: "spam"},
+			err:         errors.New(`reason must be one of "manual", "unsubscribe" or "bounce"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := tc.suppression.Normalize()
+			if s != tc.expected {
+				t.Errorf("Expected suppression: %+v, got: %+v", tc.expected, s)
+			}
+
+			err := s.Validate()
+			if err == nil && tc.err != nil {
+				t.Errorf("Expected error: %v, got: nil", tc.err)
+			} else if err != nil && tc.err == nil {
+				t.Errorf("Expected no error, got: %v", err)
+			} else if err != nil && tc.err != nil && err.Error() != tc.err.Error() {
+				t.Errorf("Expected error: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestService_AddSuppression(t *testing.T) {
+	testCases := []struct {
+		name     string
+		repoErr  error
+		value    string
+		expected error
+	}{
+		{
+			name:  "Success",
+			value: "jane@example.com",
+		},
+		{
+			name:     "Validation error",
+			value:    "jane",
+			expected: suppression.ErrSuppressionValidation,
+		},
+		{
+			name:     "Already suppressed",
+			value:    "jane@example.com",
+			repoErr:  suppression.ErrSuppressionExists,
+			expected: suppression.ErrSuppressionExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testdata.MockRepo{
+				AddSuppressionFn: func(ctx context.Context, s suppression.Suppression) (suppression.Suppression, error) {
+					s.ID = 1
+					return s, tc.repoErr
+				},
+			}
+
+			svc := suppression.NewService(repo, testdata.MockTokenVerifier{})
+
+			created, err := svc.AddSuppression(context.Background(), suppression.Suppression{Type: suppression.TypeEmail, Value: tc.value})
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expected, err)
+			}
+
+			if tc.expected == nil && (created.ID != 1 || created.Reason != suppression.ReasonManual) {
+				t.Errorf("Unexpected suppression: %+v", created)
+			}
+		})
+	}
+}
+
+func TestService_DeleteSuppression(t *testing.T) {
+	repo := testdata.MockRepo{
+		DeleteSuppressionFn: func(ctx context.Context, id int) (bool, error) {
+			return id == 1, nil
+		},
+	}
+
+	svc := suppression.NewService(repo, testdata.MockTokenVerifier{})
+
+	if err := svc.DeleteSuppression(context.Background(), 1); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	if err := svc.DeleteSuppression(context.Background(), 2); !errors.Is(err, suppression.ErrSuppressionNotFound) {
+		t.Errorf("Expected ErrSuppressionNotFound, got: %v", err)
+	}
+}
+
+func TestService_FindSuppression(t *testing.T) {
+	var receivedEmail, receivedDomain string
+	repo := testdata.MockRepo{
+		FindSuppressionFn: func(ctx context.Context, email string, domain string) (suppression.Suppression, bool, error) {
+			receivedEmail, receivedDomain = email, domain
+			return suppression.Suppression{ID: 1, Reason: suppression.ReasonBounce}, true, nil
+		},
+	}
+
+	svc := suppression.NewService(repo, testdata.MockTokenVerifier{})
+
+	s, suppressed, err := svc.FindSuppression(context.Background(), "Jane@Example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if !suppressed || s.ID != 1 || receivedEmail != "jane@example.com" || receivedDomain != "example.com" {
+		t.Errorf("Unexpected check of %q and %q: %+v, %v", receivedEmail, receivedDomain, s, suppressed)
+	}
+}
+
+func TestService_Unsubscribe(t *testing.T) {
+	tokens := testdata.MockTokenVerifier{
+		VerifyUnsubscribeFn: func(token string) (int, error) {
+			switch token {
+			case "valid":
+				return 7, nil
+			case "deleted":
+				return 8, nil
+			}
+
+			return 0, tracking.ErrInvalidToken
+		},
+	}
+
+	var unsubscribed []int
+	repo := testdata.MockRepo{
+		UnsubscribeFn: func(ctx context.Context, messageID int) (bool, error) {
+			unsubscribed = append(unsubscribed, messageID)
+			return messageID == 7, nil
+		},
+	}
+
+	svc := suppression.NewService(repo, tokens)
+
+	if err := svc.VerifyUnsubscribe("valid"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	if err := svc.VerifyUnsubscribe("forged"); !errors.Is(err, suppression.ErrInvalidUnsubscribeLink) {
+		t.Errorf("Expected ErrInvalidUnsubscribeLink, got: %v", err)
+	}
+
+	if len(unsubscribed) != 0 {
+		t.Fatalf("Expected verifying not to unsubscribe, got: %v", unsubscribed)
+	}
+
+	if err := svc.Unsubscribe(context.Background(), "valid"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	if err := svc.Unsubscribe(context.Background(), "deleted"); !errors.Is(err, suppression.ErrInvalidUnsubscribeLink) {
+		t.Errorf("Expected ErrInvalidUnsubscribeLink for a deleted message, got: %v", err)
+	}
+
+	if err := svc.Unsubscribe(context.Background(), "forged"); !errors.Is(err, suppression.ErrInvalidUnsubscribeLink) {
+		t.Errorf("Expected ErrInvalidUnsubscribeLink, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(unsubscribed, []int{7, 8}) {
+		t.Errorf("Expected messages 7 and 8 to be unsubscribed, got: %v", unsubscribed)
+	}
+}
+
+func TestService_ListSuppressions(t *testing.T) {
+	var received suppression.ListFilter
+	repo := testdata.MockRepo{
+		ListSuppressionsFn: func(ctx context.Context, filter suppression.ListFilter) ([]suppression.Suppression, error) {
+			received = filter
+			return []suppression.Suppression{{ID: 1}, {ID: 2}, {ID: 3}}, nil
+		},
+	}
+
+	svc := suppression.NewService(repo, testdata.MockTokenVerifier{})
+
+	page, err := svc.ListSuppressions(context.Background(), suppression.ListQuery{Type: suppression.TypeDomain, Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if received != (suppression.ListFilter{Type: suppression.TypeDomain, Filter: listing.Filter{Limit: 3}}) {
+		t.Errorf("Unexpected filter: %+v", received)
+	}
+
+	if len(page.Suppressions) != 2 || page.NextCursor == "" {
+		t.Fatalf("Expected a full page with a cursor, got: %+v", page)
+	}
+
+	if _, err := svc.ListSuppressions(context.Background(), suppression.ListQuery{Cursor: page.NextCursor}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if received.AfterID != 2 {
+		t.Errorf("Expected the next page after ID 2, got: %d", received.AfterID)
+	}
+
+	if _, err := svc.ListSuppressions(context.Background(), suppression.ListQuery{Type: "phone"}); !errors.Is(err, suppression.ErrInvalidListQuery) {
+		t.Errorf("Expected ErrInvalidListQuery, got: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+)
+
+type MockRepo struct {
+	AddSuppressionFn    func(ctx context.Context, s suppression.Suppression) (suppression.Suppression, error)
+	DeleteSuppressionFn func(ctx context.Context, id int) (bool, error)
+	ListSuppressionsFn  func(ctx context.Context, filter suppression.ListFilter) ([]suppression.Suppression, error)
+	FindSuppressionFn   func(ctx context.Context, email string, domain string) (suppression.Suppression, bool, error)
+	UnsubscribeFn       func(ctx context.Context, messageID int) (bool, error)
+}
+
+func (m MockRepo) AddSuppression(ctx context.Context, s suppression.Suppression) (suppression.Suppression, error) {
+	return m.AddSuppressionFn(ctx, s)
+}
+
+func (m MockRepo) DeleteSuppression(ctx context.Context, id int) (bool, error) {
+	return m.DeleteSuppressionFn(ctx, id)
+}
+
+func (m MockRepo) ListSuppressions(ctx context.Context, filter suppression.ListFilter) ([]suppression.Suppression, error) {
+	return m.ListSuppressionsFn(ctx, filter)
+}
+
+func (m MockRepo) FindSuppression(ctx context.Context, email string, domain string) (suppression.Suppression, bool, error) {
+	return m.FindSuppressionFn(ctx, email, domain)
+}
+
+func (m MockRepo) Unsubscribe(ctx context.Context, messageID int) (bool, error) {
+	return m.UnsubscribeFn(ctx, messageID)
+}
+
+type MockTokenVerifier struct {
+	VerifyUnsubscribeFn func(token string) (int, error)
+}
+
+func (m MockTokenVerifier) VerifyUnsubscribe(token string) (int, error) {
+	return m.VerifyUnsubscribeFn(token)
+}
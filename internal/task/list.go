@@ -0,0 +1,84 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+)
+
+// ErrInvalidListQuery is returned when the options for listing tasks are invalid.
+var ErrInvalidListQuery = errors.New("list query is invalid")
+
+// ListQuery represents the options for listing tasks. Tasks are listed in order of ID, which
+// is the order they were created in.
+type ListQuery struct {
+	// Assignee filters tasks by assignee. Tasks of every assignee are listed when it is empty.
+	Assignee string
+	// Status filters tasks by status. All statuses are listed when it is empty.
+	Status Status
+	// SequenceID filters tasks by sequence. Tasks of every sequence are listed when it is zero.
+	SequenceID int
+	Limit      int
+	// Cursor is the opaque cursor returned with the previous page.
+	Cursor string
+}
+
+// Validate validates the list query.
+func (q ListQuery) Validate() error {
+	if q.Status != "" && !q.Status.Valid() {
+		return fmt.Errorf("status %q is unknown", q.Status)
+	}
+
+	if q.SequenceID < 0 {
+		return errors.New("sequenceId is invalid")
+	}
+
+	return nil
+}
+
+// ListFilter is the list query as understood by the repository.
+type ListFilter struct {
+	Assignee   string
+	Status     Status
+	SequenceID int
+	listing.Filter
+}
+
+// Page is a page of tasks.
+type Page struct {
+	Tasks []Task `json:"tasks"`
+	// NextCursor is the cursor for the next page. It is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListTasks lists tasks matching the query, one page at a time.
+func (s Service) ListTasks(ctx context.Context, query ListQuery) (Page, error) {
+	if err := query.Validate(); err != nil {
+		return Page{}, fmt.Errorf("%w: %s", ErrInvalidListQuery, err)
+	}
+
+	window, err := listing.NewFilter(query.Limit, query.Cursor)
+	if err != nil {
+		return Page{}, fmt.Errorf("%w: %s", ErrInvalidListQuery, err)
+	}
+
+	filter := ListFilter{
+		Assignee:   strings.TrimSpace(query.Assignee),
+		Status:     query.Status,
+		SequenceID: query.SequenceID,
+		Filter:     window,
+	}
+
+	tasks, err := s.repo.ListTasks(ctx, filter)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	page := Page{}
+	page.Tasks, page.NextCursor = listing.Trim(tasks, filter.Filter, func(t Task) int { return t.ID })
+
+	return page, nil
+}
@@ -0,0 +1,173 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresRepository is a repository containing tasks using Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository creates a new Postgres repository.
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+const taskColumns = `id, enrollment_id, sequence_id, step_id, contact_id, kind, assignee, instructions, status, due_at, created_at, closed_at`
+
+const listTasksQuery = `
+SELECT ` + taskColumns + ` FROM task %s ORDER BY id LIMIT %d;
+`
+
+// ListTasks lists the tasks matching the filter in order of ID.
+func (r PostgresRepository) ListTasks(ctx context.Context, filter ListFilter) ([]Task, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Assignee != "" {
+		conditions = append(conditions, "assignee = "+arg(filter.Assignee))
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = "+arg(filter.Status))
+	}
+
+	if filter.SequenceID > 0 {
+		conditions = append(conditions, "sequence_id = "+arg(filter.SequenceID))
+	}
+
+	if filter.AfterID > 0 {
+		conditions = append(conditions, "id > "+arg(filter.AfterID))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows := []TaskRow{}
+	if err := r.db.SelectContext(ctx, &rows, fmt.Sprintf(listTasksQuery, where, filter.Limit), args...); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, len(rows))
+	for i, row := range rows {
+		tasks[i] = row.ToTask()
+	}
+
+	return tasks, nil
+}
+
+const closeTaskQuery = `
+UPDATE task SET status = $1, closed_at = NOW() WHERE id = $2 AND status = 'open' RETURNING ` + taskColumns + `;
+`
+
+const taskExistsQuery = `
+SELECT EXISTS (SELECT 1 FROM task WHERE id = $1);
+`
+
+// resumeEnrollmentQuery makes the enrollment due when it is waiting for a task, which only
+// active enrollments without a next send time are. Stopped enrollments are left alone.
+const resumeEnrollmentQuery = `
+UPDATE enrollment SET next_send_at = NOW(), updated_at = NOW() WHERE id = $1 AND state = 'active' AND next_send_at IS NULL;
+`
+
+// CloseTask closes an open task with the status and makes its enrollment due in a single
+// transaction.
+func (r PostgresRepository) CloseTask(ctx context.Context, id int, status Status) (Task, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Task{}, err
+	}
+
+	row := TaskRow{}
+	if err := func() error {
+		if err := tx.GetContext(ctx, &row, closeTaskQuery, status, id); err != nil {
+			if err != sql.ErrNoRows {
+				return err
+			}
+
+			var exists bool
+			if err := tx.GetContext(ctx, &exists, taskExistsQuery, id); err != nil {
+				return err
+			}
+
+			if !exists {
+				return ErrTaskNotFound
+			}
+
+			return ErrTaskClosed
+		}
+
+		_, err := tx.ExecContext(ctx, resumeEnrollmentQuery, row.EnrollmentID)
+		return err
+	}(); err != nil {
+		tx.Rollback()
+		return Task{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Task{}, err
+	}
+
+	return row.ToTask(), nil
+}
+
+// TaskRow represents a row of the task table.
+type TaskRow struct {
+	ID           int           `db:"id"`
+	EnrollmentID int           `db:"enrollment_id"`
+	SequenceID   int           `db:"sequence_id"`
+	StepID       sql.NullInt64 `db:"step_id"`
+	ContactID    int           `db:"contact_id"`
+	Kind         string        `db:"kind"`
+	Assignee     string        `db:"assignee"`
+	Instructions string        `db:"instructions"`
+	Status       string        `db:"status"`
+	DueAt        time.Time     `db:"due_at"`
+	CreatedAt    time.Time     `db:"created_at"`
+	ClosedAt     sql.NullTime  `db:"closed_at"`
+}
+
+// ToTask converts the row to a task domain model.
+func (r TaskRow) ToTask() Task {
+	t := Task{
+		ID:           r.ID,
+		EnrollmentID: r.EnrollmentID,
+		SequenceID:   r.SequenceID,
+		ContactID:    r.ContactID,
+		Kind:         sequence.StepKind(r.Kind),
+		Assignee:     r.Assignee,
+		Instructions: r.Instructions,
+		Status:       Status(r.Status),
+		DueAt:        r.DueAt,
+		CreatedAt:    r.CreatedAt,
+	}
+
+	if r.StepID.Valid {
+		stepID := int(r.StepID.Int64)
+		t.StepID = &stepID
+	}
+
+	if r.ClosedAt.Valid {
+		t.ClosedAt = &r.ClosedAt.Time
+	}
+
+	return t
+}
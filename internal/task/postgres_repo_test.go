@@ -0,0 +1,53 @@
+package task_test
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/task"
+)
+
+func TestTaskRow_ToTask(t *testing.T) {
+	now := time.Now()
+	stepID := 3
+
+	testCases := []struct {
+		name     string
+		row      task.TaskRow
+		expected task.Task
+	}{
+		{
+			name: "Open task",
+			row: task.TaskRow{
+				ID: 1, EnrollmentID: 2, SequenceID: 5, StepID: sql.NullInt64{Int64: 3, Valid: true}, ContactID: 7,
+				Kind: "call", Assignee: "jane@example.com", Instructions: "Call John", Status: "open", DueAt: now, CreatedAt: now,
+			},
+			expected: task.Task{
+				ID: 1, EnrollmentID: 2, SequenceID: 5, StepID: &stepID, ContactID: 7,
+				Kind: sequence.StepKindCall, Assignee: "jane@example.com", Instructions: "Call John", Status: task.StatusOpen, DueAt: now, CreatedAt: now,
+			},
+		},
+		{
+			name: "Closed task of a deleted step",
+			row: task.TaskRow{
+				ID: 1, EnrollmentID: 2, SequenceID: 5, ContactID: 7,
+				Kind: "task", Assignee: "jane@example.com", Status: "skipped", DueAt: now, CreatedAt: now, ClosedAt: sql.NullTime{Time: now, Valid: true},
+			},
+			expected: task.Task{
+				ID: 1, EnrollmentID: 2, SequenceID: 5, ContactID: 7,
+				Kind: sequence.StepKindTask, Assignee: "jane@example.com", Status: task.StatusSkipped, DueAt: now, CreatedAt: now, ClosedAt: &now,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.row.ToTask(); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected task: %+v, got: %+v", tc.expected, got)
+			}
+		})
+	}
+}
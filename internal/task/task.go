@@ -0,0 +1,110 @@
+// Package task keeps the tasks created for the manual steps of sequences, such as calling a
+// contact, and lets users complete or skip them so the enrollments continue.
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+)
+
+var (
+	// ErrTaskNotFound is returned when a task with the given ID is not found.
+	ErrTaskNotFound = errors.New("task with given ID not found")
+
+	// ErrTaskClosed is returned when completing or skipping a task that was already completed
+	// or skipped.
+	ErrTaskClosed = errors.New("task is already closed")
+)
+
+// Status is the progress of a task.
+type Status string
+
+const (
+	// StatusOpen tasks are waiting for their assignee.
+	StatusOpen Status = "open"
+	// StatusCompleted tasks were done by their assignee.
+	StatusCompleted Status = "completed"
+	// StatusSkipped tasks were closed without being done.
+	StatusSkipped Status = "skipped"
+)
+
+// Valid reports whether the status is one of the known statuses.
+func (s Status) Valid() bool {
+	switch s {
+	case StatusOpen, StatusCompleted, StatusSkipped:
+		return true
+	}
+
+	return false
+}
+
+// Task is the work a manual step asks of its assignee for an enrolled contact. The enrollment
+// continues with the next step once the task is completed or skipped.
+type Task struct {
+	ID           int               `json:"id"`
+	EnrollmentID int               `json:"enrollmentId"`
+	SequenceID   int               `json:"sequenceId"`
+	ContactID    int               `json:"contactId"`
+	Kind         sequence.StepKind `json:"kind"`
+	Assignee     string            `json:"assignee"`
+	// StepID is the step the task was created for, which may have been deleted from the
+	// draft of the sequence since. It is nil for tasks of steps deleted before sequences had
+	// published versions.
+	StepID *int `json:"stepId"`
+	// Instructions are the content of the step, rendered for the contact.
+	Instructions string     `json:"instructions"`
+	Status       Status     `json:"status"`
+	DueAt        time.Time  `json:"dueAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	ClosedAt     *time.Time `json:"closedAt,omitempty"`
+}
+
+// Repository represents the data layer for tasks.
+type Repository interface {
+	ListTasks(ctx context.Context, filter ListFilter) ([]Task, error)
+	// CloseTask closes an open task with the status and makes its enrollment due, so it
+	// continues with the next step. It returns ErrTaskNotFound or ErrTaskClosed when the task
+	// does not exist or is not open.
+	CloseTask(ctx context.Context, id int, status Status) (Task, error)
+}
+
+// Service contains the business logic for tasks.
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new task service.
+func NewService(repo Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// CompleteTask marks a task as done by its assignee, and the enrollment continues with the
+// next step.
+func (s Service) CompleteTask(ctx context.Context, id int) (Task, error) {
+	return s.closeTask(ctx, id, StatusCompleted)
+}
+
+// SkipTask closes a task without it being done, and the enrollment continues with the next
+// step all the same.
+func (s Service) SkipTask(ctx context.Context, id int) (Task, error) {
+	return s.closeTask(ctx, id, StatusSkipped)
+}
+
+func (s Service) closeTask(ctx context.Context, id int, status Status) (Task, error) {
+	task, err := s.repo.CloseTask(ctx, id, status)
+	if err != nil {
+		if errors.Is(err, ErrTaskNotFound) || errors.Is(err, ErrTaskClosed) {
+			return Task{}, err
+		}
+
+		return Task{}, fmt.Errorf("failed to close task: %w", err)
+	}
+
+	return task, nil
+}
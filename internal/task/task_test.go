@@ -0,0 +1,124 @@
+package task_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/task"
+	"github.com/cybre/salesforge-assignment/internal/task/testdata"
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+)
+
+func TestService_CloseTask(t *testing.T) {
+	ctx := context.Background()
+	repoErr := errors.New("repository error")
+
+	testCases := []struct {
+		name           string
+		skip           bool
+		repoErr        error
+		expectedStatus task.Status
+		expectedErr    error
+	}{
+		{
+			name:           "Complete",
+			expectedStatus: task.StatusCompleted,
+		},
+		{
+			name:           "Skip",
+			skip:           true,
+			expectedStatus: task.StatusSkipped,
+		},
+		{
+			name:           "Not found",
+			repoErr:        task.ErrTaskNotFound,
+			expectedStatus: task.StatusCompleted,
+			expectedErr:    task.ErrTaskNotFound,
+		},
+		{
+			name:           "Already closed",
+			skip:           true,
+			repoErr:        task.ErrTaskClosed,
+			expectedStatus: task.StatusSkipped,
+			expectedErr:    task.ErrTaskClosed,
+		},
+		{
+			name:           "Failed to close",
+			repoErr:        repoErr,
+			expectedStatus: task.StatusCompleted,
+			expectedErr:    repoErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received task.Status
+			svc := task.NewService(testdata.MockRepo{
+				CloseTaskFn: func(ctx context.Context, id int, status task.Status) (task.Task, error) {
+					received = status
+					if tc.repoErr != nil {
+						return task.Task{}, tc.repoErr
+					}
+
+					return task.Task{ID: id, Status: status}, nil
+				},
+			})
+
+			closeTask := svc.CompleteTask
+			if tc.skip {
+				closeTask = svc.SkipTask
+			}
+
+			closed, err := closeTask(ctx, 4)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if received != tc.expectedStatus {
+				t.Errorf("Expected task to be closed as: %s, got: %s", tc.expectedStatus, received)
+			}
+
+			if err == nil && (closed.ID != 4 || closed.Status != tc.expectedStatus) {
+				t.Errorf("Expected closed task 4, got: %+v", closed)
+			}
+		})
+	}
+}
+
+func TestService_ListTasks(t *testing.T) {
+	var received task.ListFilter
+	repo := testdata.MockRepo{
+		ListTasksFn: func(ctx context.Context, filter task.ListFilter) ([]task.Task, error) {
+			received = filter
+			return []task.Task{{ID: 1}, {ID: 2}, {ID: 3}}, nil
+		},
+	}
+
+	svc := task.NewService(repo)
+
+	page, err := svc.ListTasks(context.Background(), task.ListQuery{Assignee: " jane@example.com ", Status: task.StatusOpen, Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if received != (task.ListFilter{Assignee: "jane@example.com", Status: task.StatusOpen, Filter: listing.Filter{Limit: 3}}) {
+		t.Errorf("Unexpected filter: %+v", received)
+	}
+
+	if len(page.Tasks) != 2 || page.NextCursor == "" {
+		t.Fatalf("Expected a full page with a cursor, got: %+v", page)
+	}
+
+	if _, err := svc.ListTasks(context.Background(), task.ListQuery{Cursor: page.NextCursor}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if received.AfterID != 2 {
+		t.Errorf("Expected the next page after ID 2, got: %d", received.AfterID)
+	}
+
+	if _, err := svc.ListTasks(context.Background(), task.ListQuery{Status: "done"}); !errors.Is(err, task.ErrInvalidListQuery) {
+		t.Errorf("Expected ErrInvalidListQuery, got: %v", err)
+	}
+}
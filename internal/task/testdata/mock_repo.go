@@ -0,0 +1,20 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/task"
+)
+
+type MockRepo struct {
+	ListTasksFn func(ctx context.Context, filter task.ListFilter) ([]task.Task, error)
+	CloseTaskFn func(ctx context.Context, id int, status task.Status) (task.Task, error)
+}
+
+func (m MockRepo) ListTasks(ctx context.Context, filter task.ListFilter) ([]task.Task, error) {
+	return m.ListTasksFn(ctx, filter)
+}
+
+func (m MockRepo) CloseTask(ctx context.Context, id int, status task.Status) (task.Task, error) {
+	return m.CloseTaskFn(ctx, id, status)
+}
@@ -0,0 +1,187 @@
+// Package templating parses and renders text containing merge fields.
+//
+// A merge field is written as {{name}} and is replaced by the recipient's value for
+// that name. A fallback can be given after a pipe, as in {{firstName|there}}, which is
+// used when the recipient has no value for the field.
+package templating
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	openDelim  = "{{"
+	closeDelim = "}}"
+)
+
+// fieldNamePattern matches valid merge field names, e.g. firstName or custom.plan.
+var fieldNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z0-9_]+)*$`)
+
+// SampleValues are the values used to render templates when previewing them.
+var SampleValues = map[string]string{
+	"email":     "jane.doe@example.com",
+	"firstName": "Jane",
+	"lastName":  "Doe",
+	"company":   "Acme Inc.",
+}
+
+// SyntaxError describes a malformed merge field.
+type SyntaxError struct {
+	// Offset is the byte offset of the start of the malformed merge field.
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid merge field at position %d: %s", e.Offset, e.Msg)
+}
+
+// Field is a merge field within a template.
+type Field struct {
+	Name       string
+	Default    string
+	HasDefault bool
+}
+
+// node is either a piece of literal text or a merge field.
+type node struct {
+	text  string
+	field *Field
+}
+
+// Template is a parsed piece of text.
+type Template struct {
+	nodes []node
+}
+
+// Parse parses text containing merge fields. Closing braces without a matching opening
+// pair are treated as literal text, so content such as CSS does not need escaping.
+func Parse(text string) (Template, error) {
+	var (
+		t      Template
+		offset int
+	)
+
+	for {
+		start := strings.Index(text[offset:], openDelim)
+		if start < 0 {
+			break
+		}
+		start += offset
+
+		end := strings.Index(text[start+len(openDelim):], closeDelim)
+		if end < 0 {
+			return Template{}, &SyntaxError{Offset: start, Msg: "missing closing }}"}
+		}
+		end += start + len(openDelim)
+
+		body := text[start+len(openDelim) : end]
+		if strings.Contains(body, openDelim) {
+			return Template{}, &SyntaxError{Offset: start, Msg: "missing closing }}"}
+		}
+
+		field, err := parseField(body)
+		if err != nil {
+			return Template{}, &SyntaxError{Offset: start, Msg: err.Error()}
+		}
+
+		if start > offset {
+			t.nodes = append(t.nodes, node{text: text[offset:start]})
+		}
+		t.nodes = append(t.nodes, node{field: &field})
+
+		offset = end + len(closeDelim)
+	}
+
+	if offset < len(text) {
+		t.nodes = append(t.nodes, node{text: text[offset:]})
+	}
+
+	return t, nil
+}
+
+func parseField(body string) (Field, error) {
+	name, fallback, hasDefault := strings.Cut(body, "|")
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Field{}, fmt.Errorf("field name is required")
+	}
+
+	if !fieldNamePattern.MatchString(name) {
+		return Field{}, fmt.Errorf("field name %q is invalid", name)
+	}
+
+	return Field{
+		Name:       name,
+		Default:    strings.TrimSpace(fallback),
+		HasDefault: hasDefault,
+	}, nil
+}
+
+// Fields returns the merge fields of the template in order of appearance.
+func (t Template) Fields() []Field {
+	var fields []Field
+	for _, n := range t.nodes {
+		if n.field != nil {
+			fields = append(fields, *n.field)
+		}
+	}
+
+	return fields
+}
+
+// Render replaces the merge fields of the template with the given values. Fields without
+// a value fall back to their default. Fields with neither are rendered as empty text and
+// their names are returned, sorted and without duplicates.
+func (t Template) Render(values map[string]string) (string, []string) {
+	var (
+		sb      strings.Builder
+		missing = map[string]bool{}
+	)
+
+	for _, n := range t.nodes {
+		if n.field == nil {
+			sb.WriteString(n.text)
+			continue
+		}
+
+		if value := values[n.field.Name]; value != "" {
+			sb.WriteString(value)
+		} else if n.field.HasDefault {
+			sb.WriteString(n.field.Default)
+		} else {
+			missing[n.field.Name] = true
+		}
+	}
+
+	return sb.String(), sortedKeys(missing)
+}
+
+// Render parses and renders text in one go.
+func Render(text string, values map[string]string) (string, []string, error) {
+	t, err := Parse(text)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rendered, missing := t.Render(values)
+	return rendered, missing, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
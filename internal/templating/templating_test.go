@@ -0,0 +1,126 @@
+package templating_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/templating"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected []templating.Field
+		err      string
+	}{
+		{
+			name:     "Plain text",
+			text:     "Hello there",
+			expected: nil,
+		},
+		{
+			name: "Fields",
+			text: "Hi {{ firstName }}, how is {{company|your company}}? {{custom.plan|}}",
+			expected: []templating.Field{
+				{Name: "firstName"},
+				{Name: "company", Default: "your company", HasDefault: true},
+				{Name: "custom.plan", HasDefault: true},
+			},
+		},
+		{
+			name:     "Stray closing braces",
+			text:     "a { color: red }} {{email}}",
+			expected: []templating.Field{{Name: "email"}},
+		},
+		{
+			name: "Missing closing braces",
+			text: "Hi {{firstName",
+			err:  "invalid merge field at position 3: missing closing }}",
+		},
+		{
+			name: "Nested opening braces",
+			text: "Hi {{first {{name}}",
+			err:  "invalid merge field at position 3: missing closing }}",
+		},
+		{
+			name: "Empty field name",
+			text: "Hi {{|there}}",
+			err:  "invalid merge field at position 3: field name is required",
+		},
+		{
+			name: "Invalid field name",
+			text: "Hi {{first name}}",
+			err:  `invalid merge field at position 3: field name "first name" is invalid`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := templating.Parse(tc.text)
+			if tc.err != "" {
+				var syntaxErr *templating.SyntaxError
+				if !errors.As(err, &syntaxErr) || err.Error() != tc.err {
+					t.Fatalf("Expected error: %s, got: %v", tc.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if fields := tmpl.Fields(); !reflect.DeepEqual(fields, tc.expected) {
+				t.Errorf("Expected fields: %+v, got: %+v", tc.expected, fields)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name            string
+		text            string
+		values          map[string]string
+		expected        string
+		expectedMissing []string
+	}{
+		{
+			name:     "All values present",
+			text:     "Hi {{firstName}} from {{company}}",
+			values:   map[string]string{"firstName": "Jane", "company": "Acme"},
+			expected: "Hi Jane from Acme",
+		},
+		{
+			name:     "Default used for missing and empty values",
+			text:     "Hi {{firstName|there}}, {{lastName|friend}}",
+			values:   map[string]string{"lastName": ""},
+			expected: "Hi there, friend",
+		},
+		{
+			name:            "Missing values without defaults",
+			text:            "{{lastName}} {{firstName}} {{lastName}}",
+			values:          nil,
+			expected:        "  ",
+			expectedMissing: []string{"firstName", "lastName"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rendered, missing, err := templating.Render(tc.text, tc.values)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if rendered != tc.expected {
+				t.Errorf("Expected: %q, got: %q", tc.expected, rendered)
+			}
+
+			if !reflect.DeepEqual(missing, tc.expectedMissing) {
+				t.Errorf("Expected missing fields: %v, got: %v", tc.expectedMissing, missing)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package tracking
+
+import "strings"
+
+// botUserAgents are fragments of the user agents of crawlers, HTTP libraries and the link
+// scanners of email security gateways. Image proxies such as GoogleImageProxy are missing on
+// purpose, as they fetch images when the recipient opens the email.
+var botUserAgents = []string{
+	"bot",
+	"crawler",
+	"spider",
+	"preview",
+	"curl",
+	"wget",
+	"python-requests",
+	"python-urllib",
+	"go-http-client",
+	"java/",
+	"okhttp",
+	"headlesschrome",
+	"barracuda",
+	"mimecast",
+	"proofpoint",
+	"symantec",
+	"trendmicro",
+	"forcepoint",
+}
+
+// IsBot reports whether the user agent belongs to a bot rather than a person's email client.
+func IsBot(userAgent string) bool {
+	userAgent = strings.ToLower(strings.TrimSpace(userAgent))
+	if userAgent == "" {
+		return true
+	}
+
+	for _, fragment := range botUserAgents {
+		if strings.Contains(userAgent, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
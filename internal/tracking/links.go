@@ -0,0 +1,39 @@
+package tracking
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// hrefPattern matches the quoted href attribute of a link.
+var hrefPattern = regexp.MustCompile(`(?i)(<a\b[^>]*?\bhref\s*=\s*)(?:"([^"]*)"|'([^']*)')`)
+
+// rewriteLinks replaces the href of every link in the HTML with the result of rewrite,
+// unless rewrite declines the link.
+func rewriteLinks(content string, rewrite func(target string) (string, bool)) string {
+	return hrefPattern.ReplaceAllStringFunc(content, func(link string) string {
+		m := hrefPattern.FindStringSubmatch(link)
+
+		target := html.UnescapeString(strings.TrimSpace(m[2] + m[3]))
+		rewritten, ok := rewrite(target)
+		if !ok {
+			return link
+		}
+
+		return m[1] + `"` + html.EscapeString(rewritten) + `"`
+	})
+}
+
+// isTrackable reports whether clicks of a link to target can be tracked. Only absolute http
+// and https links are tracked, except for links starting with unsubscribePrefix, which must
+// keep working without a detour through the tracking endpoint.
+func isTrackable(target string, unsubscribePrefix string) bool {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+
+	return !strings.HasPrefix(target, unsubscribePrefix)
+}
@@ -0,0 +1,52 @@
+package tracking
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresRepository is a repository containing tracking events using Postgres.
+type PostgresRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresRepository creates a new Postgres repository.
+func NewPostgresRepository(db *sqlx.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+// recordEventQuery only records events of sent messages. Bot and human events are
+// deduplicated separately, so a scanner fetching a tracking URL does not hide the recipient's
+// own open or click.
+const recordEventQuery = `
+WITH tracked AS (
+	SELECT id, sequence_id, step_id, $4 OR sent_at > $7 AS bot FROM message WHERE id = $1 AND status = 'sent'
+)
+INSERT INTO event (message_id, sequence_id, step_id, type, url, bot, user_agent, ip)
+SELECT tracked.id, tracked.sequence_id, tracked.step_id, $2, $3, tracked.bot, $5, $6 FROM tracked
+WHERE NOT EXISTS (
+	SELECT 1 FROM event
+	WHERE event.message_id = tracked.id AND event.type = $2 AND event.url = $3 AND event.bot = tracked.bot AND event.created_at > $8
+)
+RETURNING id;
+`
+
+// RecordEvent records an event and reports whether it was recorded.
+func (r PostgresRepository) RecordEvent(ctx context.Context, event Event) (bool, error) {
+	var id int
+	err := r.db.QueryRowxContext(ctx, recordEventQuery,
+		event.MessageID, event.Type, event.URL, event.Bot, event.UserAgent, event.IP, event.PrefetchedAfter, event.DedupeAfter,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,15 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+)
+
+type MockRepo struct {
+	RecordEventFn func(ctx context.Context, event tracking.Event) (bool, error)
+}
+
+func (m MockRepo) RecordEvent(ctx context.Context, event tracking.Event) (bool, error) {
+	return m.RecordEventFn(ctx, event)
+}
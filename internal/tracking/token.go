@@ -0,0 +1,82 @@
+package tracking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidToken is returned when a tracking token is malformed or not signed by us.
+var ErrInvalidToken = errors.New("tracking token is invalid")
+
+// MinSecretSize is the shortest secret accepted for signing tokens.
+const MinSecretSize = 16
+
+// macSize is the length of the truncated HMAC-SHA256 signature of a token.
+const macSize = 16
+
+// Kind is what a tracking token is used for.
+type Kind byte
+
+const (
+	KindOpen        Kind = 'o'
+	KindClick       Kind = 'c'
+	KindUnsubscribe Kind = 'u'
+)
+
+// Signer creates and verifies tracking tokens.
+//
+// A token is the URL-safe base64 encoding of its kind, the message ID as a uvarint, optional
+// data such as a link target and an HMAC of all of those.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner creates a new signer with the given secret.
+func NewSigner(secret []byte) (*Signer, error) {
+	if len(secret) < MinSecretSize {
+		return nil, errors.New("tracking secret must be at least 16 bytes")
+	}
+
+	return &Signer{
+		key: secret,
+	}, nil
+}
+
+// Sign creates a token of the given kind for a message.
+func (s Signer) Sign(kind Kind, messageID int, data string) string {
+	payload := make([]byte, 0, 1+binary.MaxVarintLen64+len(data)+macSize)
+	payload = append(payload, byte(kind))
+	payload = binary.AppendUvarint(payload, uint64(messageID))
+	payload = append(payload, data...)
+
+	return base64.RawURLEncoding.EncodeToString(append(payload, s.mac(payload)...))
+}
+
+// Verify verifies a token of the given kind and returns its message ID and data.
+func (s Signer) Verify(kind Kind, token string) (int, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) < 2+macSize {
+		return 0, "", ErrInvalidToken
+	}
+
+	payload, mac := raw[:len(raw)-macSize], raw[len(raw)-macSize:]
+	if !hmac.Equal(mac, s.mac(payload)) || Kind(payload[0]) != kind {
+		return 0, "", ErrInvalidToken
+	}
+
+	messageID, n := binary.Uvarint(payload[1:])
+	if n <= 0 || messageID == 0 || messageID > 1<<31-1 {
+		return 0, "", ErrInvalidToken
+	}
+
+	return int(messageID), string(payload[1+n:]), nil
+}
+
+func (s Signer) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write(payload)
+	return h.Sum(nil)[:macSize]
+}
@@ -0,0 +1,60 @@
+package tracking_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+)
+
+func TestSigner(t *testing.T) {
+	signer, err := tracking.NewSigner([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	token := signer.Sign(tracking.KindClick, 42, "https://example.com")
+
+	messageID, data, err := signer.Verify(tracking.KindClick, token)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if messageID != 42 || data != "https://example.com" {
+		t.Errorf("Expected message 42 and https://example.com, got: %d and %s", messageID, data)
+	}
+
+	other, _ := tracking.NewSigner([]byte("fedcba9876543210"))
+
+	raw, _ := base64.RawURLEncoding.DecodeString(token)
+	raw[1]++
+	tampered := base64.RawURLEncoding.EncodeToString(raw)
+
+	testCases := []struct {
+		name   string
+		signer *tracking.Signer
+		kind   tracking.Kind
+		token  string
+	}{
+		{name: "Wrong kind", signer: signer, kind: tracking.KindOpen, token: token},
+		{name: "Wrong secret", signer: other, kind: tracking.KindClick, token: token},
+		{name: "Tampered token", signer: signer, kind: tracking.KindClick, token: tampered},
+		{name: "Truncated token", signer: signer, kind: tracking.KindClick, token: token[:10]},
+		{name: "Malformed token", signer: signer, kind: tracking.KindClick, token: "not a token!"},
+		{name: "Empty token", signer: signer, kind: tracking.KindClick, token: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := tc.signer.Verify(tc.kind, tc.token); err != tracking.ErrInvalidToken {
+				t.Errorf("Expected error: %v, got: %v", tracking.ErrInvalidToken, err)
+			}
+		})
+	}
+}
+
+func TestNewSigner_ShortSecret(t *testing.T) {
+	if _, err := tracking.NewSigner([]byte("short")); err == nil {
+		t.Errorf("Expected error, got: nil")
+	}
+}
@@ -0,0 +1,196 @@
+// Package tracking records opens and clicks of sent emails through signed tracking URLs, and
+// signs the unsubscribe URLs of sent emails.
+package tracking
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/compose"
+)
+
+const (
+	DefaultDedupeWindow   = time.Minute
+	DefaultPrefetchWindow = 30 * time.Second
+)
+
+// Config contains the settings for tracking emails.
+type Config struct {
+	// BaseURL is the public URL of the server that tracking URLs point to.
+	BaseURL string
+	// Secret signs the tracking tokens.
+	Secret string
+	// DedupeWindow is how long repeated opens of a message are ignored after an open.
+	DedupeWindow time.Duration
+	// PrefetchWindow is how soon after sending an open is attributed to a mail server or
+	// security scanner fetching the email rather than the recipient.
+	PrefetchWindow time.Duration
+}
+
+// WithDefaults returns a copy of the config with unset options replaced by their defaults.
+func (c Config) WithDefaults() Config {
+	if c.DedupeWindow == 0 {
+		c.DedupeWindow = DefaultDedupeWindow
+	}
+
+	if c.PrefetchWindow == 0 {
+		c.PrefetchWindow = DefaultPrefetchWindow
+	}
+
+	return c
+}
+
+// EventType is the type of an engagement event.
+type EventType string
+
+const (
+	EventSent        EventType = "sent"
+	EventOpen        EventType = "open"
+	EventClick       EventType = "click"
+	EventReply       EventType = "reply"
+	EventBounce      EventType = "bounce"
+	EventUnsubscribe EventType = "unsubscribe"
+)
+
+// Event is an open or click of a message reported through a tracking URL.
+type Event struct {
+	Type      EventType
+	MessageID int
+	// URL is the target of a clicked link.
+	URL       string
+	UserAgent string
+	IP        string
+	// Bot is set when the user agent belongs to a bot rather than a person.
+	Bot bool
+	// PrefetchedAfter marks events of messages sent after it as bot events.
+	PrefetchedAfter time.Time
+	// DedupeAfter skips the event when the same one was recorded after it.
+	DedupeAfter time.Time
+}
+
+// Repository represents the data layer for tracking events.
+type Repository interface {
+	RecordEvent(ctx context.Context, event Event) (bool, error)
+}
+
+// Service contains the business logic for tracking emails.
+type Service struct {
+	repo   Repository
+	signer *Signer
+	config Config
+}
+
+// NewService creates a new tracking service.
+func NewService(repo Repository, config Config) (*Service, error) {
+	signer, err := NewSigner([]byte(config.Secret))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		repo:   repo,
+		signer: signer,
+		config: config.WithDefaults(),
+	}, nil
+}
+
+// OpenURL returns the URL of the tracking pixel of a message.
+func (s Service) OpenURL(messageID int) string {
+	return strings.TrimSuffix(s.config.BaseURL, "/") + "/t/o/" + s.signer.Sign(KindOpen, messageID, "") + ".gif"
+}
+
+// TrackOpens adds the tracking pixel of a message to its HTML.
+func (s Service) TrackOpens(html string, messageID int) string {
+	pixel := `<img src="` + s.OpenURL(messageID) + `" width="1" height="1" alt="" style="display:none;border:0;width:1px;height:1px">`
+
+	return compose.AppendToBody(html, pixel)
+}
+
+// RecordOpen records an open of the message the token was created for. Opens by bots are
+// recorded as such, and opens repeated within the dedupe window are skipped.
+func (s Service) RecordOpen(ctx context.Context, token string, userAgent string, ip string) error {
+	messageID, _, err := s.signer.Verify(KindOpen, token)
+	if err != nil {
+		return err
+	}
+
+	if err := s.recordEvent(ctx, EventOpen, messageID, "", userAgent, ip); err != nil {
+		return fmt.Errorf("failed to record open of message %d: %w", messageID, err)
+	}
+
+	return nil
+}
+
+// UnsubscribeURL returns the URL the recipient of a message can unsubscribe with.
+func (s Service) UnsubscribeURL(messageID int) string {
+	return s.unsubscribePrefix() + s.signer.Sign(KindUnsubscribe, messageID, "")
+}
+
+// unsubscribePrefix is the part of unsubscribe URLs that comes before the token.
+func (s Service) unsubscribePrefix() string {
+	return strings.TrimSuffix(s.config.BaseURL, "/") + "/u/"
+}
+
+// VerifyUnsubscribe verifies the token of an unsubscribe URL and returns its message ID.
+func (s Service) VerifyUnsubscribe(token string) (int, error) {
+	messageID, _, err := s.signer.Verify(KindUnsubscribe, token)
+	return messageID, err
+}
+
+// ClickURL returns the tracking URL of a link in a message.
+func (s Service) ClickURL(messageID int, target string) string {
+	return strings.TrimSuffix(s.config.BaseURL, "/") + "/t/c/" + s.signer.Sign(KindClick, messageID, target)
+}
+
+// TrackClicks rewrites the links of an HTML message to their tracking URLs. Links that are not
+// http or https, such as mailto links, and unsubscribe URLs of the server are left untouched.
+func (s Service) TrackClicks(html string, messageID int) string {
+	return rewriteLinks(html, func(target string) (string, bool) {
+		if !isTrackable(target, s.unsubscribePrefix()) {
+			return "", false
+		}
+
+		return s.ClickURL(messageID, target), true
+	})
+}
+
+// RecordClick records a click of the link the token was created for and returns the target
+// of the link. The target is returned even when recording the click fails, so the recipient
+// still gets to the link.
+func (s Service) RecordClick(ctx context.Context, token string, userAgent string, ip string) (string, error) {
+	messageID, target, err := s.signer.Verify(KindClick, token)
+	if err != nil {
+		return "", err
+	}
+
+	// Only links that could have been rewritten are followed, so a leaked secret cannot be
+	// used to redirect to arbitrary schemes.
+	if !isTrackable(target, s.unsubscribePrefix()) {
+		return "", ErrInvalidToken
+	}
+
+	if err := s.recordEvent(ctx, EventClick, messageID, target, userAgent, ip); err != nil {
+		return target, fmt.Errorf("failed to record click of message %d: %w", messageID, err)
+	}
+
+	return target, nil
+}
+
+func (s Service) recordEvent(ctx context.Context, eventType EventType, messageID int, url string, userAgent string, ip string) error {
+	now := time.Now()
+
+	_, err := s.repo.RecordEvent(ctx, Event{
+		Type:            eventType,
+		MessageID:       messageID,
+		URL:             url,
+		UserAgent:       userAgent,
+		IP:              ip,
+		Bot:             IsBot(userAgent),
+		PrefetchedAfter: now.Add(-s.config.PrefetchWindow),
+		DedupeAfter:     now.Add(-s.config.DedupeWindow),
+	})
+
+	return err
+}
@@ -0,0 +1,260 @@
+package tracking_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/gif"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+	"github.com/cybre/salesforge-assignment/internal/tracking/testdata"
+)
+
+var testConfig = tracking.Config{
+	BaseURL: "https://track.example.com/",
+	Secret:  "0123456789abcdef",
+}
+
+func TestService_TrackOpens(t *testing.T) {
+	svc, err := tracking.NewService(testdata.MockRepo{}, testConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	url := svc.OpenURL(7)
+	if !strings.HasPrefix(url, "https://track.example.com/t/o/") || !strings.HasSuffix(url, ".gif") {
+		t.Errorf("Unexpected open URL: %s", url)
+	}
+
+	if svc.OpenURL(8) == url {
+		t.Errorf("Expected open URLs to differ per message")
+	}
+
+	testCases := []struct {
+		name           string
+		html           string
+		expectedPrefix string
+		expectedSuffix string
+	}{
+		{
+			name:           "Fragment",
+			html:           "<p>Hi</p>",
+			expectedPrefix: "<p>Hi</p><img src=\"" + url + "\"",
+			expectedSuffix: ">",
+		},
+		{
+			name:           "Document",
+			html:           "<html><BODY><p>Hi</p></BODY></html>",
+			expectedPrefix: "<html><BODY><p>Hi</p><img src=\"" + url + "\"",
+			expectedSuffix: "></BODY></html>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			html := svc.TrackOpens(tc.html, 7)
+			if !strings.HasPrefix(html, tc.expectedPrefix) || !strings.HasSuffix(html, tc.expectedSuffix) {
+				t.Errorf("Unexpected HTML: %s", html)
+			}
+		})
+	}
+}
+
+func TestService_RecordOpen(t *testing.T) {
+	ctx := context.Background()
+
+	var recorded *tracking.Event
+	repo := testdata.MockRepo{
+		RecordEventFn: func(ctx context.Context, event tracking.Event) (bool, error) {
+			recorded = &event
+			return true, nil
+		},
+	}
+
+	svc, err := tracking.NewService(repo, testConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(svc.OpenURL(7), "https://track.example.com/t/o/"), ".gif")
+
+	before := time.Now()
+	if err := svc.RecordOpen(ctx, token, "Mozilla/5.0 (Windows NT 10.0) Thunderbird/115.0", "203.0.113.5"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if recorded == nil || recorded.Type != tracking.EventOpen || recorded.MessageID != 7 || recorded.Bot || recorded.IP != "203.0.113.5" {
+		t.Fatalf("Unexpected open: %+v", recorded)
+	}
+
+	if recorded.DedupeAfter.Before(before.Add(-tracking.DefaultDedupeWindow)) || recorded.DedupeAfter.After(time.Now().Add(-tracking.DefaultDedupeWindow)) {
+		t.Errorf("Expected dedupe window of %s, got: %v", tracking.DefaultDedupeWindow, recorded.DedupeAfter)
+	}
+
+	if recorded.PrefetchedAfter.Before(before.Add(-tracking.DefaultPrefetchWindow)) || recorded.PrefetchedAfter.After(time.Now().Add(-tracking.DefaultPrefetchWindow)) {
+		t.Errorf("Expected prefetch window of %s, got: %v", tracking.DefaultPrefetchWindow, recorded.PrefetchedAfter)
+	}
+
+	// Bots are recorded as such
+	if err := svc.RecordOpen(ctx, token, "curl/8.0", "203.0.113.5"); err != nil || !recorded.Bot {
+		t.Errorf("Expected bot open to be recorded, got: %+v (%v)", recorded, err)
+	}
+
+	// Invalid tokens are not recorded
+	recorded = nil
+	if err := svc.RecordOpen(ctx, token+"x", "Thunderbird", ""); err != tracking.ErrInvalidToken || recorded != nil {
+		t.Errorf("Expected error: %v, got: %v", tracking.ErrInvalidToken, err)
+	}
+
+	// Repository errors are returned
+	repo.RecordEventFn = func(ctx context.Context, event tracking.Event) (bool, error) {
+		return false, errors.New("database is down")
+	}
+	svc, _ = tracking.NewService(repo, testConfig)
+	if err := svc.RecordOpen(ctx, token, "Thunderbird", ""); err == nil {
+		t.Errorf("Expected error, got: nil")
+	}
+}
+
+func TestService_TrackClicks(t *testing.T) {
+	svc, err := tracking.NewService(testdata.MockRepo{}, testConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	html := `<p><a href="https://example.com/pricing?a=1&amp;b=2">Pricing</a>, ` +
+		`<A class="cta" HREF='http://example.com'>Site</A>, ` +
+		`<a href="mailto:sales@example.com">Mail</a>, ` +
+		`<a href="https://example.com/blog/how-to-unsubscribe">Blog</a>, ` +
+		`<a href="` + svc.UnsubscribeURL(7) + `">Unsubscribe</a>, ` +
+		`<a href="#top">Top</a>, ` +
+		`<a href="javascript:alert(1)">Script</a></p>`
+
+	expected := `<p><a href="` + svc.ClickURL(7, "https://example.com/pricing?a=1&b=2") + `">Pricing</a>, ` +
+		`<A class="cta" HREF="` + svc.ClickURL(7, "http://example.com") + `">Site</A>, ` +
+		`<a href="mailto:sales@example.com">Mail</a>, ` +
+		`<a href="` + svc.ClickURL(7, "https://example.com/blog/how-to-unsubscribe") + `">Blog</a>, ` +
+		`<a href="` + svc.UnsubscribeURL(7) + `">Unsubscribe</a>, ` +
+		`<a href="#top">Top</a>, ` +
+		`<a href="javascript:alert(1)">Script</a></p>`
+
+	if tracked := svc.TrackClicks(html, 7); tracked != expected {
+		t.Errorf("Expected HTML: %s, got: %s", expected, tracked)
+	}
+}
+
+func TestService_RecordClick(t *testing.T) {
+	ctx := context.Background()
+
+	var recorded *tracking.Event
+	repo := testdata.MockRepo{
+		RecordEventFn: func(ctx context.Context, event tracking.Event) (bool, error) {
+			recorded = &event
+			return true, nil
+		},
+	}
+
+	svc, err := tracking.NewService(repo, testConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	token := func(url string) string {
+		return strings.TrimPrefix(url, "https://track.example.com/t/c/")
+	}
+
+	target, err := svc.RecordClick(ctx, token(svc.ClickURL(7, "https://example.com/pricing")), "Thunderbird", "203.0.113.5")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if target != "https://example.com/pricing" {
+		t.Errorf("Expected target: %s, got: %s", "https://example.com/pricing", target)
+	}
+
+	if recorded == nil || recorded.Type != tracking.EventClick || recorded.MessageID != 7 || recorded.URL != target {
+		t.Errorf("Unexpected click: %+v", recorded)
+	}
+
+	// Signed links to targets that are never tracked are refused
+	recorded = nil
+	if _, err := svc.RecordClick(ctx, token(svc.ClickURL(7, "javascript:alert(1)")), "Thunderbird", ""); err != tracking.ErrInvalidToken || recorded != nil {
+		t.Errorf("Expected error: %v, got: %v", tracking.ErrInvalidToken, err)
+	}
+
+	// Open tokens cannot be used as click tokens
+	openToken := strings.TrimSuffix(strings.TrimPrefix(svc.OpenURL(7), "https://track.example.com/t/o/"), ".gif")
+	if _, err := svc.RecordClick(ctx, openToken, "Thunderbird", ""); err != tracking.ErrInvalidToken {
+		t.Errorf("Expected error: %v, got: %v", tracking.ErrInvalidToken, err)
+	}
+
+	// The target is returned even when the click cannot be recorded
+	repo.RecordEventFn = func(ctx context.Context, event tracking.Event) (bool, error) {
+		return false, errors.New("database is down")
+	}
+	svc, _ = tracking.NewService(repo, testConfig)
+
+	target, err = svc.RecordClick(ctx, token(svc.ClickURL(7, "https://example.com/pricing")), "Thunderbird", "")
+	if err == nil || target != "https://example.com/pricing" {
+		t.Errorf("Expected target and error, got: %q and %v", target, err)
+	}
+}
+
+func TestService_UnsubscribeURL(t *testing.T) {
+	svc, err := tracking.NewService(testdata.MockRepo{}, testConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	url := svc.UnsubscribeURL(7)
+	token, ok := strings.CutPrefix(url, "https://track.example.com/u/")
+	if !ok {
+		t.Fatalf("Unexpected unsubscribe URL: %s", url)
+	}
+
+	messageID, err := svc.VerifyUnsubscribe(token)
+	if err != nil || messageID != 7 {
+		t.Errorf("Expected message 7, got: %d, %v", messageID, err)
+	}
+
+	// Tokens of other kinds cannot be used to unsubscribe
+	openToken := strings.TrimSuffix(strings.TrimPrefix(svc.OpenURL(7), "https://track.example.com/t/o/"), ".gif")
+	if _, err := svc.VerifyUnsubscribe(openToken); !errors.Is(err, tracking.ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken, got: %v", err)
+	}
+}
+
+func TestIsBot(t *testing.T) {
+	testCases := []struct {
+		userAgent string
+		expected  bool
+	}{
+		{"", true},
+		{"curl/8.4.0", true},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", true},
+		{"Go-http-client/1.1", true},
+		{"Mozilla/5.0 (Windows NT 5.1; rv:11.0) Gecko Firefox/11.0 (via ggpht.com GoogleImageProxy)", false},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko)", false},
+		{"Microsoft Office/16.0 (Windows NT 10.0; Microsoft Outlook 16.0.17029; Pro)", false},
+	}
+
+	for _, tc := range testCases {
+		if isBot := tracking.IsBot(tc.userAgent); isBot != tc.expected {
+			t.Errorf("Expected IsBot(%q) to be %t, got: %t", tc.userAgent, tc.expected, isBot)
+		}
+	}
+}
+
+func TestPixel(t *testing.T) {
+	img, err := gif.Decode(bytes.NewReader(tracking.Pixel))
+	if err != nil {
+		t.Fatalf("Expected a valid GIF, got: %v", err)
+	}
+
+	if bounds := img.Bounds(); bounds.Dx() != 1 || bounds.Dy() != 1 {
+		t.Errorf("Expected a 1x1 image, got: %dx%d", bounds.Dx(), bounds.Dy())
+	}
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cybre/salesforge-assignment/internal/bounce"
+	"github.com/labstack/echo/v4"
+)
+
+// maxDSNSize is the largest delivery status notification that is read. Notifications often
+// include the bounced message, so this leaves room for attachments.
+const maxDSNSize = 10 << 20
+
+// ProcessDSN is an echo handler for processing a delivery status notification posted as a raw
+// MIME message.
+func (s Server) ProcessDSN(e echo.Context) error {
+	body := io.LimitReader(e.Request().Body, maxDSNSize)
+
+	result, err := s.bounceService.ProcessDSN(e.Request().Context(), body)
+	if err != nil {
+		if errors.Is(err, bounce.ErrInvalidDSN) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,99 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/bounce"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+	"github.com/cybre/salesforge-assignment/internal/transport/http/testdata"
+	"github.com/labstack/echo/v4"
+)
+
+func TestProcessDSN(t *testing.T) {
+	tests := []struct {
+		name           string
+		expectedStatus int
+		expectedBody   string
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{\"recipients\":[{\"recipient\":\"jane@example.com\",\"action\":\"failed\",\"status\":\"5.1.1\",\"type\":\"hard\",\"outcome\":\"hardBounce\",\"messageId\":3}]}\n",
+		},
+		{
+			name:           "Invalid DSN Error",
+			expectedStatus: http.StatusBadRequest,
+			serviceError:   fmt.Errorf("%w: message is not a multipart/report", bounce.ErrInvalidDSN),
+		},
+		{
+			name:           "Unknown Error",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodPost, "/inbound/dsn", strings.NewReader("raw message"))
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Create a mock bounce service
+			var received string
+			mockBounceService := &testdata.MockBounceService{
+				ProcessDSNFn: func(ctx context.Context, r io.Reader) (bounce.Result, error) {
+					data, _ := io.ReadAll(r)
+					received = string(data)
+					if tt.serviceError != nil {
+						return bounce.Result{}, tt.serviceError
+					}
+
+					return bounce.Result{Recipients: []bounce.RecipientResult{{
+						Recipient: "jane@example.com",
+						Action:    "failed",
+						Status:    "5.1.1",
+						Type:      bounce.TypeHard,
+						Outcome:   bounce.OutcomeHardBounce,
+						MessageID: 3,
+					}}}, nil
+				},
+			}
+
+			// Create a new server instance with the mock bounce service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithBounceService(mockBounceService))
+
+			// Call the ProcessDSN method
+			err := server.ProcessDSN(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+
+			if received != "raw message" {
+				t.Errorf("expected the raw message to be passed on, got %q", received)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateContact is an echo handler for creating a contact.
+func (s Server) CreateContact(e echo.Context) error {
+	request := CreateContactRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	c, err := s.contactService.CreateContact(e.Request().Context(), request.BuildContactModel())
+	if err != nil {
+		if errors.Is(err, contact.ErrContactValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, contact.ErrContactExists) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	e.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/contact/%d", c.ID))
+	return e.JSON(http.StatusCreated, c)
+}
+
+// GetContact is an echo handler for getting a contact.
+func (s Server) GetContact(e echo.Context) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	c, err := s.contactService.GetContact(e.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, contact.ErrContactNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, c)
+}
+
+// ListContacts is an echo handler for listing contacts.
+func (s Server) ListContacts(e echo.Context) error {
+	request := ListContactsRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	page, err := s.contactService.ListContacts(e.Request().Context(), request.BuildListQuery())
+	if err != nil {
+		if errors.Is(err, contact.ErrInvalidListQuery) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, page)
+}
+
+// UpdateContact is an echo handler for replacing the fields of a contact.
+func (s Server) UpdateContact(e echo.Context) error {
+	request := UpdateContactRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	c, err := s.contactService.UpdateContact(e.Request().Context(), request.BuildContactModel())
+	if err != nil {
+		if errors.Is(err, contact.ErrContactValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, contact.ErrContactNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		if errors.Is(err, contact.ErrContactExists) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, c)
+}
+
+// DeleteContact is an echo handler for deleting a contact.
+func (s Server) DeleteContact(e echo.Context) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	if err := s.contactService.DeleteContact(e.Request().Context(), id); err != nil {
+		if errors.Is(err, contact.ErrContactNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// ImportContacts is an echo handler for importing contacts from a CSV file. The file is
+// expected in the multipart form field "file" and the optional column mapping as JSON in
+// the form field "mapping".
+func (s Server) ImportContacts(e echo.Context) error {
+	fileHeader, err := e.FormFile("file")
+	if err != nil {
+		return e.String(http.StatusBadRequest, "file is required")
+	}
+
+	var mapping contact.Mapping
+	if raw := e.FormValue("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			return e.String(http.StatusBadRequest, "mapping must be a JSON object of column names to fields")
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+	defer file.Close()
+
+	report, err := s.contactService.ImportContacts(e.Request().Context(), file, mapping)
+	if err != nil {
+		if errors.Is(err, contact.ErrInvalidImport) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, report)
+}
+
+// CreateContactRequest represents the request body for creating a contact.
+type CreateContactRequest struct {
+	Email        string         `json:"email"`
+	FirstName    string         `json:"firstName"`
+	LastName     string         `json:"lastName"`
+	Company      string         `json:"company"`
+	TimeZone     string         `json:"timeZone"`
+	CustomFields map[string]any `json:"customFields"`
+}
+
+// BuildContactModel builds a contact domain model from the request.
+func (r CreateContactRequest) BuildContactModel() contact.Contact {
+	return contact.Contact{
+		Email:        r.Email,
+		FirstName:    r.FirstName,
+		LastName:     r.LastName,
+		Company:      r.Company,
+		TimeZone:     r.TimeZone,
+		CustomFields: r.CustomFields,
+	}
+}
+
+// UpdateContactRequest represents the request body for replacing the fields of a contact.
+type UpdateContactRequest struct {
+	ID           int            `param:"id"`
+	Email        string         `json:"email"`
+	FirstName    string         `json:"firstName"`
+	LastName     string         `json:"lastName"`
+	Company      string         `json:"company"`
+	TimeZone     string         `json:"timeZone"`
+	CustomFields map[string]any `json:"customFields"`
+}
+
+// BuildContactModel builds a contact domain model from the request.
+func (r UpdateContactRequest) BuildContactModel() contact.Contact {
+	return contact.Contact{
+		ID:           r.ID,
+		Email:        r.Email,
+		FirstName:    r.FirstName,
+		LastName:     r.LastName,
+		Company:      r.Company,
+		TimeZone:     r.TimeZone,
+		CustomFields: r.CustomFields,
+	}
+}
+
+// ListContactsRequest represents the query parameters for listing contacts.
+type ListContactsRequest struct {
+	Search string `query:"search"`
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+}
+
+// BuildListQuery builds a contact list query from the request.
+func (r ListContactsRequest) BuildListQuery() contact.ListQuery {
+	return contact.ListQuery{
+		Search: strings.TrimSpace(r.Search),
+		Limit:  r.Limit,
+		Cursor: r.Cursor,
+	}
+}
@@ -0,0 +1,555 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+	"github.com/cybre/salesforge-assignment/internal/transport/http/testdata"
+	"github.com/labstack/echo/v4"
+)
+
+func TestCreateContact(t *testing.T) {
+	tests := []struct {
+		name             string
+		requestBody      string
+		expectedStatus   int
+		expectedLocation string
+		expectedContact  contact.Contact
+		serviceError     error
+	}{
+		{
+			name:             "Success",
+			requestBody:      `{"email": "jane@example.com", "firstName": "Jane", "customFields": {"plan": "Pro"}}`,
+			expectedStatus:   http.StatusCreated,
+			expectedLocation: "/contact/7",
+			expectedContact:  contact.Contact{Email: "jane@example.com", FirstName: "Jane", CustomFields: map[string]any{"plan": "Pro"}},
+		},
+		{
+			name:           "Invalid Request Body",
+			requestBody:    `{"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "Validation Error",
+			requestBody:     `{"email": "jane"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedContact: contact.Contact{Email: "jane"},
+			serviceError:    contact.ErrContactValidation,
+		},
+		{
+			name:            "Duplicate Email",
+			requestBody:     `{"email": "jane@example.com"}`,
+			expectedStatus:  http.StatusConflict,
+			expectedContact: contact.Contact{Email: "jane@example.com"},
+			serviceError:    contact.ErrContactExists,
+		},
+		{
+			name:            "Unknown Error",
+			requestBody:     `{"email": "jane@example.com"}`,
+			expectedStatus:  http.StatusInternalServerError,
+			expectedContact: contact.Contact{Email: "jane@example.com"},
+			serviceError:    errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request with a JSON payload
+			req := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Create a mock contact service
+			var received contact.Contact
+			mockContactService := &testdata.MockContactService{
+				CreateContactFn: func(ctx context.Context, c contact.Contact) (contact.Contact, error) {
+					received = c
+					if tt.serviceError != nil {
+						return contact.Contact{}, tt.serviceError
+					}
+
+					c.ID = 7
+					return c, nil
+				},
+			}
+
+			// Create a new server instance with the mock contact service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithContactService(mockContactService))
+
+			// Call the CreateContact method
+			err := server.CreateContact(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if location := rec.Header().Get(echo.HeaderLocation); location != tt.expectedLocation {
+				t.Errorf("expected location %q, got %q", tt.expectedLocation, location)
+			}
+
+			if !reflect.DeepEqual(received, tt.expectedContact) {
+				t.Errorf("expected contact %+v, got %+v", tt.expectedContact, received)
+			}
+		})
+	}
+}
+
+func TestGetContact(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+		expectedBody   string
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			id:             "1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{\"id\":1,\"email\":\"jane@example.com\",\"firstName\":\"Jane\",\"lastName\":\"\",\"company\":\"\",\"timeZone\":\"\",\"customFields\":{},\"createdAt\":\"0001-01-01T00:00:00Z\",\"updatedAt\":\"0001-01-01T00:00:00Z\"}\n",
+		},
+		{
+			name:           "Invalid ID",
+			id:             "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not Found Error",
+			id:             "1",
+			expectedStatus: http.StatusNotFound,
+			serviceError:   contact.ErrContactNotFound,
+		},
+		{
+			name:           "Unknown Error",
+			id:             "1",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodGet, "/contact/"+tt.id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			// Create a mock contact service
+			mockContactService := &testdata.MockContactService{
+				GetContactFn: func(ctx context.Context, id int) (contact.Contact, error) {
+					if tt.serviceError != nil {
+						return contact.Contact{}, tt.serviceError
+					}
+
+					return contact.Contact{ID: id, Email: "jane@example.com", FirstName: "Jane", CustomFields: map[string]any{}}, nil
+				},
+			}
+
+			// Create a new server instance with the mock contact service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithContactService(mockContactService))
+
+			// Call the GetContact method
+			err := server.GetContact(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestListContacts(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedQuery  contact.ListQuery
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			query:          "?search=%20acme%20&limit=10&cursor=abc",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  contact.ListQuery{Search: "acme", Limit: 10, Cursor: "abc"},
+		},
+		{
+			name:           "Invalid limit",
+			query:          "?limit=ten",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Query Error",
+			query:          "?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+			expectedQuery:  contact.ListQuery{Limit: 1000},
+			serviceError:   contact.ErrInvalidListQuery,
+		},
+		{
+			name:           "Unknown Error",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodGet, "/contact"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Create a mock contact service
+			var received contact.ListQuery
+			mockContactService := &testdata.MockContactService{
+				ListContactsFn: func(ctx context.Context, query contact.ListQuery) (contact.Page, error) {
+					received = query
+					return contact.Page{Contacts: []contact.Contact{}}, tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock contact service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithContactService(mockContactService))
+
+			// Call the ListContacts method
+			err := server.ListContacts(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if received != tt.expectedQuery {
+				t.Errorf("expected query %+v, got %+v", tt.expectedQuery, received)
+			}
+		})
+	}
+}
+
+func TestUpdateContact(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		requestBody    string
+		expectedStatus int
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			id:             "1",
+			requestBody:    `{"email": "jane@example.com"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid ID",
+			id:             "abc",
+			requestBody:    `{"email": "jane@example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Validation Error",
+			id:             "1",
+			requestBody:    `{"email": "jane"}`,
+			expectedStatus: http.StatusBadRequest,
+			serviceError:   contact.ErrContactValidation,
+		},
+		{
+			name:           "Not Found Error",
+			id:             "1",
+			requestBody:    `{"email": "jane@example.com"}`,
+			expectedStatus: http.StatusNotFound,
+			serviceError:   contact.ErrContactNotFound,
+		},
+		{
+			name:           "Duplicate Email",
+			id:             "1",
+			requestBody:    `{"email": "jane@example.com"}`,
+			expectedStatus: http.StatusConflict,
+			serviceError:   contact.ErrContactExists,
+		},
+		{
+			name:           "Unknown Error",
+			id:             "1",
+			requestBody:    `{"email": "jane@example.com"}`,
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request with a JSON payload
+			req := httptest.NewRequest(http.MethodPut, "/contact/"+tt.id, strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			// Create a mock contact service
+			mockContactService := &testdata.MockContactService{
+				UpdateContactFn: func(ctx context.Context, c contact.Contact) (contact.Contact, error) {
+					if c.ID != 1 {
+						t.Errorf("expected contact ID 1, got %d", c.ID)
+					}
+
+					return c, tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock contact service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithContactService(mockContactService))
+
+			// Call the UpdateContact method
+			err := server.UpdateContact(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestDeleteContact(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			id:             "1",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Invalid ID",
+			id:             "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not Found Error",
+			id:             "1",
+			expectedStatus: http.StatusNotFound,
+			serviceError:   contact.ErrContactNotFound,
+		},
+		{
+			name:           "Unknown Error",
+			id:             "1",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodDelete, "/contact/"+tt.id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			// Create a mock contact service
+			mockContactService := &testdata.MockContactService{
+				DeleteContactFn: func(ctx context.Context, id int) error {
+					return tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock contact service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithContactService(mockContactService))
+
+			// Call the DeleteContact method
+			err := server.DeleteContact(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestImportContacts(t *testing.T) {
+	tests := []struct {
+		name            string
+		file            string
+		mapping         string
+		expectedStatus  int
+		expectedBody    string
+		expectedMapping contact.Mapping
+		serviceError    error
+	}{
+		{
+			name:            "Success",
+			file:            "E-mail\njane@example.com\n",
+			mapping:         `{"E-mail": "email"}`,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    "{\"created\":1,\"updated\":0,\"skipped\":0,\"errors\":[]}\n",
+			expectedMapping: contact.Mapping{"E-mail": "email"},
+		},
+		{
+			name:           "Success without mapping",
+			file:           "email\njane@example.com\n",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing file",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Malformed mapping",
+			file:           "email\njane@example.com\n",
+			mapping:        `["email"]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Import Error",
+			file:           "name\nJane\n",
+			expectedStatus: http.StatusBadRequest,
+			serviceError:   contact.ErrInvalidImport,
+		},
+		{
+			name:           "Unknown Error",
+			file:           "email\njane@example.com\n",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a multipart form with the CSV file and mapping
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			if tt.file != "" {
+				part, err := writer.CreateFormFile("file", "contacts.csv")
+				if err != nil {
+					t.Fatalf("failed to create form file: %v", err)
+				}
+				part.Write([]byte(tt.file))
+			}
+			if tt.mapping != "" {
+				writer.WriteField("mapping", tt.mapping)
+			}
+			writer.Close()
+
+			// Create a new HTTP request with the multipart payload
+			req := httptest.NewRequest(http.MethodPost, "/contacts/import", body)
+			req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Create a mock contact service
+			var (
+				receivedFile    string
+				receivedMapping contact.Mapping
+			)
+			mockContactService := &testdata.MockContactService{
+				ImportContactsFn: func(ctx context.Context, r io.Reader, mapping contact.Mapping) (contact.ImportReport, error) {
+					data, _ := io.ReadAll(r)
+					receivedFile = string(data)
+					receivedMapping = mapping
+					if tt.serviceError != nil {
+						return contact.ImportReport{}, tt.serviceError
+					}
+
+					return contact.ImportReport{Created: 1, Errors: []contact.RowError{}}, nil
+				},
+			}
+
+			// Create a new server instance with the mock contact service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithContactService(mockContactService))
+
+			// Call the ImportContacts method
+			err := server.ImportContacts(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+
+			if tt.expectedStatus != http.StatusBadRequest || tt.serviceError != nil {
+				if receivedFile != tt.file {
+					t.Errorf("expected file %q, got %q", tt.file, receivedFile)
+				}
+
+				if !reflect.DeepEqual(receivedMapping, tt.expectedMapping) {
+					t.Errorf("expected mapping %v, got %v", tt.expectedMapping, receivedMapping)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/labstack/echo/v4"
+)
+
+// EnrollContacts is an echo handler for enrolling contacts into a sequence.
+func (s Server) EnrollContacts(e echo.Context) error {
+	request := EnrollContactsRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	result, err := s.enrollmentService.Enroll(e.Request().Context(), request.SequenceID, request.ContactIDs)
+	if err != nil {
+		if errors.Is(err, enrollment.ErrEnrollmentValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceArchived) || errors.Is(err, sequence.ErrSequenceValidation) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// ListEnrollments is an echo handler for listing the enrollments of a sequence.
+func (s Server) ListEnrollments(e echo.Context) error {
+	request := ListEnrollmentsRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	page, err := s.enrollmentService.ListEnrollments(e.Request().Context(), request.BuildListQuery())
+	if err != nil {
+		if errors.Is(err, enrollment.ErrInvalidListQuery) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, page)
+}
+
+// EnrollContactsRequest represents the request body for enrolling contacts into a sequence.
+type EnrollContactsRequest struct {
+	SequenceID int   `param:"id"`
+	ContactIDs []int `json:"contactIds"`
+}
+
+// ListEnrollmentsRequest represents the query parameters for listing the enrollments of a sequence.
+type ListEnrollmentsRequest struct {
+	SequenceID int    `param:"id"`
+	State      string `query:"state"`
+	Limit      int    `query:"limit"`
+	Cursor     string `query:"cursor"`
+}
+
+// BuildListQuery builds an enrollment list query from the request.
+func (r ListEnrollmentsRequest) BuildListQuery() enrollment.ListQuery {
+	return enrollment.ListQuery{
+		SequenceID: r.SequenceID,
+		State:      enrollment.State(r.State),
+		Limit:      r.Limit,
+		Cursor:     r.Cursor,
+	}
+}
@@ -0,0 +1,223 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+	"github.com/cybre/salesforge-assignment/internal/transport/http/testdata"
+	"github.com/labstack/echo/v4"
+)
+
+func TestEnrollContacts(t *testing.T) {
+	tests := []struct {
+		name               string
+		requestBody        string
+		idParamValue       string
+		expectedStatus     int
+		expectedBody       string
+		expectedContactIDs []int
+		serviceError       error
+	}{
+		{
+			name:               "Success",
+			requestBody:        `{"contactIds": [1, 2]}`,
+			idParamValue:       "1",
+			expectedStatus:     http.StatusOK,
+			expectedBody:       "{\"enrolled\":[],\"skipped\":[{\"contactId\":2,\"reason\":\"alreadyEnrolled\"}]}\n",
+			expectedContactIDs: []int{1, 2},
+		},
+		{
+			name:           "Invalid Request Body",
+			requestBody:    `{"}`,
+			idParamValue:   "1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid ID param",
+			requestBody:    `{"contactIds": [1]}`,
+			idParamValue:   "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:               "Validation Error",
+			requestBody:        `{"contactIds": []}`,
+			idParamValue:       "1",
+			expectedStatus:     http.StatusBadRequest,
+			expectedContactIDs: []int{},
+			serviceError:       enrollment.ErrEnrollmentValidation,
+		},
+		{
+			name:               "Not Found Error",
+			requestBody:        `{"contactIds": [1]}`,
+			idParamValue:       "1",
+			expectedStatus:     http.StatusNotFound,
+			expectedContactIDs: []int{1},
+			serviceError:       sequence.ErrSequenceNotFound,
+		},
+		{
+			name:               "Archived Error",
+			requestBody:        `{"contactIds": [1]}`,
+			idParamValue:       "1",
+			expectedStatus:     http.StatusConflict,
+			expectedContactIDs: []int{1},
+			serviceError:       sequence.ErrSequenceArchived,
+		},
+		{
+			name:               "Sequence Without Steps Error",
+			requestBody:        `{"contactIds": [1]}`,
+			idParamValue:       "1",
+			expectedStatus:     http.StatusConflict,
+			expectedContactIDs: []int{1},
+			serviceError:       sequence.ErrSequenceValidation,
+		},
+		{
+			name:               "Unknown Error",
+			requestBody:        `{"contactIds": [1]}`,
+			idParamValue:       "1",
+			expectedStatus:     http.StatusInternalServerError,
+			expectedContactIDs: []int{1},
+			serviceError:       errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request with a JSON payload
+			req := httptest.NewRequest(http.MethodPost, "/sequence/1/enrollments", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.idParamValue)
+
+			// Create a mock enrollment service
+			var receivedContactIDs []int
+			mockEnrollmentService := &testdata.MockEnrollmentService{
+				EnrollFn: func(ctx context.Context, sequenceID int, contactIDs []int) (enrollment.EnrollResult, error) {
+					receivedContactIDs = contactIDs
+					if tt.serviceError != nil {
+						return enrollment.EnrollResult{}, tt.serviceError
+					}
+
+					return enrollment.EnrollResult{
+						Enrolled: []enrollment.Enrollment{},
+						Skipped:  []enrollment.SkippedContact{{ContactID: 2, Reason: enrollment.SkipAlreadyEnrolled}},
+					}, nil
+				},
+			}
+
+			// Create a new server instance with the mock enrollment service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithEnrollmentService(mockEnrollmentService))
+
+			// Call the EnrollContacts method
+			err := server.EnrollContacts(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+
+			if !reflect.DeepEqual(receivedContactIDs, tt.expectedContactIDs) {
+				t.Errorf("expected contact IDs %v, got %v", tt.expectedContactIDs, receivedContactIDs)
+			}
+		})
+	}
+}
+
+func TestListEnrollments(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedQuery  enrollment.ListQuery
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			query:          "?state=paused&limit=10&cursor=abc",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  enrollment.ListQuery{SequenceID: 1, State: enrollment.StatePaused, Limit: 10, Cursor: "abc"},
+		},
+		{
+			name:           "Invalid limit",
+			query:          "?limit=ten",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Query Error",
+			query:          "?state=sleeping",
+			expectedStatus: http.StatusBadRequest,
+			expectedQuery:  enrollment.ListQuery{SequenceID: 1, State: "sleeping"},
+			serviceError:   enrollment.ErrInvalidListQuery,
+		},
+		{
+			name:           "Unknown Error",
+			expectedStatus: http.StatusInternalServerError,
+			expectedQuery:  enrollment.ListQuery{SequenceID: 1},
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodGet, "/sequence/1/enrollments"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			// Create a mock enrollment service
+			var received enrollment.ListQuery
+			mockEnrollmentService := &testdata.MockEnrollmentService{
+				ListEnrollmentsFn: func(ctx context.Context, query enrollment.ListQuery) (enrollment.Page, error) {
+					received = query
+					return enrollment.Page{Enrollments: []enrollment.Enrollment{}}, tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock enrollment service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithEnrollmentService(mockEnrollmentService))
+
+			// Call the ListEnrollments method
+			err := server.ListEnrollments(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if received != tt.expectedQuery {
+				t.Errorf("expected query %+v, got %+v", tt.expectedQuery, received)
+			}
+		})
+	}
+}
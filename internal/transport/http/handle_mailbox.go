@@ -0,0 +1,236 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateMailbox is an echo handler for creating a mailbox.
+func (s Server) CreateMailbox(e echo.Context) error {
+	request := CreateMailboxRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	m, err := s.mailboxService.CreateMailbox(e.Request().Context(), request.BuildMailboxModel())
+	if err != nil {
+		if errors.Is(err, mailbox.ErrMailboxValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, mailbox.ErrMailboxExists) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	e.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/mailbox/%d", m.ID))
+	return e.JSON(http.StatusCreated, m)
+}
+
+// GetMailbox is an echo handler for getting a mailbox.
+func (s Server) GetMailbox(e echo.Context) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	m, err := s.mailboxService.GetMailbox(e.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, mailbox.ErrMailboxNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, m)
+}
+
+// ListMailboxes is an echo handler for listing mailboxes.
+func (s Server) ListMailboxes(e echo.Context) error {
+	request := ListMailboxesRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	page, err := s.mailboxService.ListMailboxes(e.Request().Context(), request.BuildListQuery())
+	if err != nil {
+		if errors.Is(err, mailbox.ErrInvalidListQuery) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, page)
+}
+
+// UpdateMailbox is an echo handler for replacing the settings of a mailbox.
+func (s Server) UpdateMailbox(e echo.Context) error {
+	request := UpdateMailboxRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	m, err := s.mailboxService.UpdateMailbox(e.Request().Context(), request.BuildMailboxModel())
+	if err != nil {
+		if errors.Is(err, mailbox.ErrMailboxValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, mailbox.ErrMailboxNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		if errors.Is(err, mailbox.ErrMailboxExists) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, m)
+}
+
+// DeleteMailbox is an echo handler for deleting a mailbox.
+func (s Server) DeleteMailbox(e echo.Context) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	if err := s.mailboxService.DeleteMailbox(e.Request().Context(), id); err != nil {
+		if errors.Is(err, mailbox.ErrMailboxNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// SetSequenceMailboxes is an echo handler for replacing the mailboxes the emails of a sequence
+// are sent from.
+func (s Server) SetSequenceMailboxes(e echo.Context) error {
+	request := SetSequenceMailboxesRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	if request.MailboxIDs == nil {
+		return e.String(http.StatusBadRequest, "mailboxIds is required")
+	}
+
+	if err := s.mailboxService.SetSequenceMailboxes(e.Request().Context(), request.ID, request.MailboxIDs); err != nil {
+		if errors.Is(err, mailbox.ErrMailboxValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceArchived) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.NoContent(http.StatusOK)
+}
+
+// ListSequenceMailboxes is an echo handler for listing the mailboxes the emails of a sequence
+// are sent from.
+func (s Server) ListSequenceMailboxes(e echo.Context) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	mailboxes, err := s.mailboxService.ListSequenceMailboxes(e.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, mailboxes)
+}
+
+// CreateMailboxRequest represents the request body for creating a mailbox.
+type CreateMailboxRequest struct {
+	Email       string          `json:"email"`
+	DisplayName string          `json:"displayName"`
+	SMTP        mailbox.SMTP    `json:"smtp"`
+	DailyLimit  int             `json:"dailyLimit"`
+	RampUp      *mailbox.RampUp `json:"rampUp"`
+}
+
+// BuildMailboxModel builds a mailbox domain model from the request.
+func (r CreateMailboxRequest) BuildMailboxModel() mailbox.Mailbox {
+	return mailbox.Mailbox{
+		Email:       r.Email,
+		DisplayName: r.DisplayName,
+		SMTP:        r.SMTP,
+		DailyLimit:  r.DailyLimit,
+		RampUp:      r.RampUp,
+	}
+}
+
+// UpdateMailboxRequest represents the request body for replacing the settings of a mailbox.
+type UpdateMailboxRequest struct {
+	ID          int             `param:"id"`
+	Email       string          `json:"email"`
+	DisplayName string          `json:"displayName"`
+	SMTP        mailbox.SMTP    `json:"smtp"`
+	DailyLimit  int             `json:"dailyLimit"`
+	RampUp      *mailbox.RampUp `json:"rampUp"`
+}
+
+// BuildMailboxModel builds a mailbox domain model from the request.
+func (r UpdateMailboxRequest) BuildMailboxModel() mailbox.Mailbox {
+	return mailbox.Mailbox{
+		ID:          r.ID,
+		Email:       r.Email,
+		DisplayName: r.DisplayName,
+		SMTP:        r.SMTP,
+		DailyLimit:  r.DailyLimit,
+		RampUp:      r.RampUp,
+	}
+}
+
+// ListMailboxesRequest represents the query parameters for listing mailboxes.
+type ListMailboxesRequest struct {
+	Search string `query:"search"`
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+}
+
+// BuildListQuery builds a mailbox list query from the request.
+func (r ListMailboxesRequest) BuildListQuery() mailbox.ListQuery {
+	return mailbox.ListQuery{
+		Search: strings.TrimSpace(r.Search),
+		Limit:  r.Limit,
+		Cursor: r.Cursor,
+	}
+}
+
+// SetSequenceMailboxesRequest represents the request body for replacing the mailboxes of a
+// sequence.
+type SetSequenceMailboxesRequest struct {
+	ID         int   `param:"id"`
+	MailboxIDs []int `json:"mailboxIds"`
+}
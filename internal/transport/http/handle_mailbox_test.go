@@ -0,0 +1,351 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+	"github.com/cybre/salesforge-assignment/internal/transport/http/testdata"
+	"github.com/labstack/echo/v4"
+)
+
+func TestCreateMailbox(t *testing.T) {
+	expectedModel := mailbox.Mailbox{
+		Email:       "jane@example.com",
+		DisplayName: "Jane Doe",
+		SMTP: mailbox.SMTP{
+			Host:     "smtp.example.com",
+			Port:     "587",
+			Username: "jane",
+			Password: "hunter2",
+			Auth:     mail.SMTPAuthLogin,
+		},
+		DailyLimit: 50,
+		RampUp:     &mailbox.RampUp{StartDate: "2024-05-01", InitialLimit: 5, DailyIncrease: 5},
+	}
+
+	requestBody := `{"email": "jane@example.com", "displayName": "Jane Doe", "smtp": {"host": "smtp.example.com", "port": "587", "username": "jane", "password": "hunter2", "auth": "login"}, "dailyLimit": 50, "rampUp": {"startDate": "2024-05-01", "initialLimit": 5, "dailyIncrease": 5}}`
+
+	tests := []struct {
+		name             string
+		requestBody      string
+		expectedStatus   int
+		expectedLocation string
+		serviceError     error
+	}{
+		{
+			name:             "Success",
+			requestBody:      requestBody,
+			expectedStatus:   http.StatusCreated,
+			expectedLocation: "/mailbox/1",
+		},
+		{
+			name:           "Invalid Request Body",
+			requestBody:    `{"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Validation Error",
+			requestBody:    requestBody,
+			expectedStatus: http.StatusBadRequest,
+			serviceError:   mailbox.ErrMailboxValidation,
+		},
+		{
+			name:           "Exists Error",
+			requestBody:    requestBody,
+			expectedStatus: http.StatusConflict,
+			serviceError:   mailbox.ErrMailboxExists,
+		},
+		{
+			name:           "Unknown Error",
+			requestBody:    requestBody,
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodPost, "/mailbox", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			mockMailboxService := &testdata.MockMailboxService{
+				CreateMailboxFn: func(ctx context.Context, m mailbox.Mailbox) (mailbox.Mailbox, error) {
+					if !reflect.DeepEqual(m, expectedModel) {
+						t.Errorf("expected mailbox %+v, got %+v", expectedModel, m)
+					}
+
+					m.ID = 1
+					return m, tt.serviceError
+				},
+			}
+
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithMailboxService(mockMailboxService))
+
+			if err := server.CreateMailbox(c); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if location := rec.Header().Get(echo.HeaderLocation); location != tt.expectedLocation {
+				t.Errorf("expected location %q, got %q", tt.expectedLocation, location)
+			}
+		})
+	}
+}
+
+func TestUpdateMailbox(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		requestBody    string
+		expectedStatus int
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			id:             "1",
+			requestBody:    `{"email": "jane@example.com", "smtp": {"host": "smtp.example.com", "port": "587"}, "dailyLimit": 50}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid ID",
+			id:             "abc",
+			requestBody:    `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Validation Error",
+			id:             "1",
+			requestBody:    `{}`,
+			expectedStatus: http.StatusBadRequest,
+			serviceError:   mailbox.ErrMailboxValidation,
+		},
+		{
+			name:           "Not Found Error",
+			id:             "1",
+			requestBody:    `{}`,
+			expectedStatus: http.StatusNotFound,
+			serviceError:   mailbox.ErrMailboxNotFound,
+		},
+		{
+			name:           "Exists Error",
+			id:             "1",
+			requestBody:    `{}`,
+			expectedStatus: http.StatusConflict,
+			serviceError:   mailbox.ErrMailboxExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodPut, "/mailbox/"+tt.id, strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			mockMailboxService := &testdata.MockMailboxService{
+				UpdateMailboxFn: func(ctx context.Context, m mailbox.Mailbox) (mailbox.Mailbox, error) {
+					if m.ID != 1 {
+						t.Errorf("expected mailbox 1, got %d", m.ID)
+					}
+
+					return m, tt.serviceError
+				},
+			}
+
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithMailboxService(mockMailboxService))
+
+			if err := server.UpdateMailbox(c); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestSetSequenceMailboxes(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expectedIDs    []int
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			requestBody:    `{"mailboxIds": [3, 2]}`,
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int{3, 2},
+		},
+		{
+			name:           "Remove All",
+			requestBody:    `{"mailboxIds": []}`,
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int{},
+		},
+		{
+			name:           "Missing Mailbox IDs",
+			requestBody:    `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Validation Error",
+			requestBody:    `{"mailboxIds": [9]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedIDs:    []int{9},
+			serviceError:   mailbox.ErrMailboxValidation,
+		},
+		{
+			name:           "Not Found Error",
+			requestBody:    `{"mailboxIds": [2]}`,
+			expectedStatus: http.StatusNotFound,
+			expectedIDs:    []int{2},
+			serviceError:   sequence.ErrSequenceNotFound,
+		},
+		{
+			name:           "Archived Error",
+			requestBody:    `{"mailboxIds": [2]}`,
+			expectedStatus: http.StatusConflict,
+			expectedIDs:    []int{2},
+			serviceError:   sequence.ErrSequenceArchived,
+		},
+		{
+			name:           "Unknown Error",
+			requestBody:    `{"mailboxIds": [2]}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedIDs:    []int{2},
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodPut, "/sequence/1/mailboxes", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			var received []int
+			mockMailboxService := &testdata.MockMailboxService{
+				SetSequenceMailboxesFn: func(ctx context.Context, sequenceID int, ids []int) error {
+					if sequenceID != 1 {
+						t.Errorf("expected sequence 1, got %d", sequenceID)
+					}
+
+					received = ids
+					return tt.serviceError
+				},
+			}
+
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithMailboxService(mockMailboxService))
+
+			if err := server.SetSequenceMailboxes(c); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if !reflect.DeepEqual(received, tt.expectedIDs) {
+				t.Errorf("expected mailboxes %v, got %v", tt.expectedIDs, received)
+			}
+		})
+	}
+}
+
+func TestGetMailbox(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+		expectedBody   string
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			id:             "1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"capacity":{"day":"2024-05-15","limit":50,"sent":12,"remaining":38}`,
+		},
+		{
+			name:           "Invalid ID",
+			id:             "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not Found Error",
+			id:             "1",
+			expectedStatus: http.StatusNotFound,
+			serviceError:   mailbox.ErrMailboxNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodGet, "/mailbox/"+tt.id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			mockMailboxService := &testdata.MockMailboxService{
+				GetMailboxFn: func(ctx context.Context, id int) (mailbox.Mailbox, error) {
+					return mailbox.Mailbox{
+						ID:         id,
+						Email:      "jane@example.com",
+						DailyLimit: 50,
+						Capacity:   &mailbox.Capacity{Day: "2024-05-15", Limit: 50, Sent: 12, Remaining: 38},
+					}, tt.serviceError
+				},
+			}
+
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithMailboxService(mockMailboxService))
+
+			if err := server.GetMailbox(c); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", tt.expectedBody, rec.Body.String())
+			}
+
+			if strings.Contains(rec.Body.String(), "password") {
+				t.Errorf("expected body without password, got %s", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cybre/salesforge-assignment/internal/reply"
+	"github.com/labstack/echo/v4"
+)
+
+// maxInboundMessageSize is the largest inbound message that is read. Only the headers are
+// needed, but replies often quote the whole thread and carry attachments.
+const maxInboundMessageSize = 25 << 20
+
+// ProcessReply is an echo handler for processing an inbound message posted as a raw RFC 5322
+// message.
+func (s Server) ProcessReply(e echo.Context) error {
+	body := io.LimitReader(e.Request().Body, maxInboundMessageSize)
+
+	result, err := s.replyService.ProcessMessage(e.Request().Context(), body)
+	if err != nil {
+		if errors.Is(err, reply.ErrInvalidMessage) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
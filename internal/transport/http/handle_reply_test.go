@@ -0,0 +1,92 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/reply"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+	"github.com/cybre/salesforge-assignment/internal/transport/http/testdata"
+	"github.com/labstack/echo/v4"
+)
+
+func TestProcessReply(t *testing.T) {
+	tests := []struct {
+		name           string
+		expectedStatus int
+		expectedBody   string
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{\"outcome\":\"replied\",\"messageId\":3,\"enrollmentId\":2}\n",
+		},
+		{
+			name:           "Invalid Message Error",
+			expectedStatus: http.StatusBadRequest,
+			serviceError:   fmt.Errorf("%w: malformed header", reply.ErrInvalidMessage),
+		},
+		{
+			name:           "Unknown Error",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodPost, "/inbound/reply", strings.NewReader("raw message"))
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Create a mock reply service
+			var received string
+			mockReplyService := &testdata.MockReplyService{
+				ProcessMessageFn: func(ctx context.Context, r io.Reader) (reply.Result, error) {
+					data, _ := io.ReadAll(r)
+					received = string(data)
+					if tt.serviceError != nil {
+						return reply.Result{}, tt.serviceError
+					}
+
+					return reply.Result{Outcome: reply.OutcomeReplied, MessageID: 3, EnrollmentID: 2}, nil
+				},
+			}
+
+			// Create a new server instance with the mock reply service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithReplyService(mockReplyService))
+
+			// Call the ProcessReply method
+			err := server.ProcessReply(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+
+			if received != "raw message" {
+				t.Errorf("expected the raw message to be passed on, got %q", received)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package http
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -18,7 +20,8 @@ func (s Server) CreateSequence(e echo.Context) error {
 	}
 
 	model := request.BuildSequenceModel()
-	if err := s.sequenceService.CreateSequence(e.Request().Context(), model); err != nil {
+	seq, err := s.sequenceService.CreateSequence(e.Request().Context(), model)
+	if err != nil {
 		if errors.Is(err, sequence.ErrSequenceValidation) {
 			return e.String(http.StatusBadRequest, err.Error())
 		}
@@ -26,7 +29,8 @@ func (s Server) CreateSequence(e echo.Context) error {
 		return e.String(http.StatusInternalServerError, err.Error())
 	}
 
-	return e.NoContent(http.StatusCreated)
+	e.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/sequence/%d", seq.ID))
+	return e.JSON(http.StatusCreated, seq)
 }
 
 // GetSequence is an echo handler for getting a sequence.
@@ -48,6 +52,30 @@ func (s Server) GetSequence(e echo.Context) error {
 	return e.JSON(http.StatusOK, seq)
 }
 
+// ListSequences is an echo handler for listing sequences.
+func (s Server) ListSequences(e echo.Context) error {
+	request := ListSequencesRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	query, err := request.BuildListQuery()
+	if err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	page, err := s.sequenceService.ListSequences(e.Request().Context(), query)
+	if err != nil {
+		if errors.Is(err, sequence.ErrInvalidListQuery) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, page)
+}
+
 // PatchSequence is an echo handler for patching a sequence.
 func (s Server) PatchSequence(e echo.Context) error {
 	request := PatchSequenceRequest{}
@@ -64,6 +92,165 @@ func (s Server) PatchSequence(e echo.Context) error {
 			return e.String(http.StatusBadRequest, err.Error())
 		}
 
+		if errors.Is(err, sequence.ErrSequenceArchived) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.NoContent(http.StatusOK)
+}
+
+// DeleteSequence is an echo handler for permanently deleting a sequence.
+func (s Server) DeleteSequence(e echo.Context) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	if err := s.sequenceService.DeleteSequence(e.Request().Context(), id); err != nil {
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// ArchiveSequence is an echo handler for archiving a sequence.
+func (s Server) ArchiveSequence(e echo.Context) error {
+	return s.setSequenceArchived(e, s.sequenceService.ArchiveSequence)
+}
+
+// UnarchiveSequence is an echo handler for restoring an archived sequence.
+func (s Server) UnarchiveSequence(e echo.Context) error {
+	return s.setSequenceArchived(e, s.sequenceService.UnarchiveSequence)
+}
+
+// PublishSequence is an echo handler for publishing the steps of a sequence as its next version.
+func (s Server) PublishSequence(e echo.Context) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	version, err := s.sequenceService.PublishSequence(e.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sequence.ErrSequenceValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceArchived) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusCreated, version)
+}
+
+// ListVersions is an echo handler for listing the published versions of a sequence.
+func (s Server) ListVersions(e echo.Context) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	versions, err := s.sequenceService.ListVersions(e.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, versions)
+}
+
+// DiffVersions is an echo handler for comparing the steps of two versions of a sequence.
+func (s Server) DiffVersions(e echo.Context) error {
+	request := DiffVersionsRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	if request.From == 0 || request.To == 0 {
+		return e.String(http.StatusBadRequest, "from and to are required")
+	}
+
+	diff, err := s.sequenceService.DiffVersions(e.Request().Context(), request.SequenceID, request.From, request.To)
+	if err != nil {
+		if errors.Is(err, sequence.ErrSequenceNotFound) || errors.Is(err, sequence.ErrVersionNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, diff)
+}
+
+// SetSchedule is an echo handler for setting the sending schedule of a sequence.
+func (s Server) SetSchedule(e echo.Context) error {
+	request := SetScheduleRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	schedule := request.BuildSchedule()
+	return s.setSchedule(e, request.ID, &schedule)
+}
+
+// DeleteSchedule is an echo handler for removing the sending schedule of a sequence.
+func (s Server) DeleteSchedule(e echo.Context) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	return s.setSchedule(e, id, nil)
+}
+
+func (s Server) setSchedule(e echo.Context, id int, schedule *sequence.Schedule) error {
+	if err := s.sequenceService.SetSchedule(e.Request().Context(), id, schedule); err != nil {
+		if errors.Is(err, sequence.ErrSequenceValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceArchived) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.NoContent(http.StatusOK)
+}
+
+func (s Server) setSequenceArchived(e echo.Context, update func(ctx context.Context, id int) error) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	if err := update(e.Request().Context(), id); err != nil {
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
 		return e.String(http.StatusInternalServerError, err.Error())
 	}
 
@@ -93,17 +280,96 @@ func (r PatchSequenceRequest) BuildSequencePatch() sequence.SequencePatch {
 	}
 }
 
+// DiffVersionsRequest represents the query parameters for comparing two versions of a sequence.
+type DiffVersionsRequest struct {
+	SequenceID int `param:"id"`
+	From       int `query:"from"`
+	To         int `query:"to"`
+}
+
+// SetScheduleRequest represents the request body for setting the sending schedule of a sequence.
+type SetScheduleRequest struct {
+	ID int `param:"id"`
+	sequence.Schedule
+}
+
+// BuildSchedule builds a schedule domain object from the request.
+func (r SetScheduleRequest) BuildSchedule() sequence.Schedule {
+	schedule := r.Schedule
+	schedule.TimeZone = strings.TrimSpace(schedule.TimeZone)
+	return schedule
+}
+
+// ListSequencesRequest represents the query parameters for listing sequences.
+type ListSequencesRequest struct {
+	Name            string `query:"name"`
+	OpenTracking    string `query:"openTrackingEnabled"`
+	ClickTracking   string `query:"clickTrackingEnabled"`
+	SortBy          string `query:"sortBy"`
+	Order           string `query:"order"`
+	Limit           int    `query:"limit"`
+	Cursor          string `query:"cursor"`
+	IncludeSteps    bool   `query:"includeSteps"`
+	IncludeArchived bool   `query:"includeArchived"`
+}
+
+// BuildListQuery builds a sequence list query from the request.
+func (r ListSequencesRequest) BuildListQuery() (sequence.ListQuery, error) {
+	openTracking, err := parseOptionalBool("openTrackingEnabled", r.OpenTracking)
+	if err != nil {
+		return sequence.ListQuery{}, err
+	}
+
+	clickTracking, err := parseOptionalBool("clickTrackingEnabled", r.ClickTracking)
+	if err != nil {
+		return sequence.ListQuery{}, err
+	}
+
+	return sequence.ListQuery{
+		Name:            strings.TrimSpace(r.Name),
+		OpenTracking:    openTracking,
+		ClickTracking:   clickTracking,
+		SortBy:          sequence.SortField(r.SortBy),
+		Order:           sequence.SortOrder(r.Order),
+		Limit:           r.Limit,
+		Cursor:          r.Cursor,
+		IncludeSteps:    r.IncludeSteps,
+		IncludeArchived: r.IncludeArchived,
+	}, nil
+}
+
+// parseOptionalBool parses a boolean query parameter, returning nil when it is not set.
+func parseOptionalBool(name, value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a boolean", name)
+	}
+
+	return &b, nil
+}
+
 // CreateSequenceRequest represents the request body for creating a sequence.
 type CreateSequenceRequest struct {
 	Name          string                      `json:"name"`
 	OpenTracking  bool                        `json:"openTrackingEnabled"`
 	ClickTracking bool                        `json:"clickTrackingEnabled"`
+	Schedule      *sequence.Schedule          `json:"schedule,omitempty"`
 	Steps         []CreateSequenceRequestStep `json:"steps"`
 }
 
 type CreateSequenceRequestStep struct {
-	Subject string `json:"subject"`
-	Content string `json:"content"`
+	Kind     sequence.StepKind    `json:"kind"`
+	Subject  string               `json:"subject"`
+	Content  string               `json:"content"`
+	Delay    sequence.Delay       `json:"delay"`
+	Variants []StepVariantRequest `json:"variants"`
+	// Branch targets are positions of the steps of the request.
+	Branch     *sequence.Branch     `json:"branch,omitempty"`
+	Assignment *sequence.Assignment `json:"assignment,omitempty"`
 }
 
 // BuildSequenceModel builds a sequence domain model from the request.
@@ -111,8 +377,13 @@ func (r CreateSequenceRequest) BuildSequenceModel() sequence.Sequence {
 	steps := make([]sequence.Step, len(r.Steps))
 	for i, step := range r.Steps {
 		steps[i] = sequence.Step{
-			Subject: strings.TrimSpace(step.Subject),
-			Content: strings.TrimSpace(step.Content),
+			Kind:       step.Kind,
+			Subject:    strings.TrimSpace(step.Subject),
+			Content:    strings.TrimSpace(step.Content),
+			Delay:      step.Delay,
+			Variants:   buildVariants(step.Variants),
+			Branch:     step.Branch,
+			Assignment: step.Assignment,
 		}
 	}
 
@@ -120,6 +391,7 @@ func (r CreateSequenceRequest) BuildSequenceModel() sequence.Sequence {
 		Name:          strings.TrimSpace(r.Name),
 		OpenTracking:  r.OpenTracking,
 		ClickTracking: r.ClickTracking,
+		Schedule:      r.Schedule,
 		Steps:         steps,
 	}
 }
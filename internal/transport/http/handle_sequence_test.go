@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
@@ -18,15 +19,19 @@ import (
 
 func TestCreateSequence(t *testing.T) {
 	tests := []struct {
-		name         string
-		reqBody      string
-		expected     int
-		serviceError error
+		name             string
+		reqBody          string
+		expected         int
+		expectedBody     string
+		expectedLocation string
+		serviceError     error
 	}{
 		{
-			name:     "Success",
-			reqBody:  `{"name": "Test Sequence", "openTrackingEnabled": true, "clickTrackingEnabled": false, "steps": [{"subject": "Step 1", "content": "Content 1"}]}`,
-			expected: http.StatusCreated,
+			name:             "Success",
+			reqBody:          `{"name": "Test Sequence", "openTrackingEnabled": true, "clickTrackingEnabled": false, "steps": [{"subject": "Step 1", "content": "Content 1"}]}`,
+			expected:         http.StatusCreated,
+			expectedBody:     "{\"id\":7,\"name\":\"Test Sequence\",\"openTrackingEnabled\":true,\"clickTrackingEnabled\":false,\"publishedVersion\":0,\"steps\":[{\"id\":9,\"position\":0,\"subject\":\"Step 1\",\"content\":\"Content 1\",\"delay\":{\"amount\":0,\"unit\":\"\"}}]}\n",
+			expectedLocation: "/sequence/7",
 		},
 		{
 			name:     "Invalid Body",
@@ -60,8 +65,18 @@ func TestCreateSequence(t *testing.T) {
 
 			// Create a mock sequence service
 			mockSequenceService := &testdata.MockSequenceService{
-				CreateSequenceFn: func(ctx context.Context, seq sequence.Sequence) error {
-					return tt.serviceError
+				CreateSequenceFn: func(ctx context.Context, seq sequence.Sequence) (sequence.Sequence, error) {
+					if tt.serviceError != nil {
+						return sequence.Sequence{}, tt.serviceError
+					}
+
+					seq.ID = 7
+					for i := range seq.Steps {
+						seq.Steps[i].ID = 9 + i
+						seq.Steps[i].Position = i
+					}
+
+					return seq, nil
 				},
 			}
 
@@ -80,6 +95,15 @@ func TestCreateSequence(t *testing.T) {
 			if rec.Code != tt.expected {
 				t.Errorf("expected status code %d, got %d", tt.expected, rec.Code)
 			}
+
+			// Check if the created sequence is returned
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+
+			if location := rec.Header().Get(echo.HeaderLocation); location != tt.expectedLocation {
+				t.Errorf("expected location %q, got %q", tt.expectedLocation, location)
+			}
 		})
 	}
 }
@@ -96,14 +120,14 @@ func TestGetSequence(t *testing.T) {
 			name:           "Success",
 			idParamValue:   "1",
 			expectedStatus: http.StatusOK,
-			expectedBody:   "{\"id\":1,\"name\":\"Test Sequence\",\"openTrackingEnabled\":false,\"clickTrackingEnabled\":false,\"steps\":[{\"id\":1,\"subject\":\"Step 1\",\"content\":\"Content 1\"}]}\n",
+			expectedBody:   "{\"id\":1,\"name\":\"Test Sequence\",\"openTrackingEnabled\":false,\"clickTrackingEnabled\":false,\"publishedVersion\":0,\"steps\":[{\"id\":1,\"position\":0,\"subject\":\"Step 1\",\"content\":\"Content 1\",\"delay\":{\"amount\":2,\"unit\":\"days\"}}]}\n",
 			sequence: sequence.Sequence{
 				ID:            1,
 				Name:          "Test Sequence",
 				OpenTracking:  false,
 				ClickTracking: false,
 				Steps: []sequence.Step{
-					{ID: 1, Subject: "Step 1", Content: "Content 1"},
+					{ID: 1, Subject: "Step 1", Content: "Content 1", Delay: sequence.Delay{Amount: 2, Unit: sequence.DelayUnitDays}},
 				},
 			},
 			serviceError: nil,
@@ -177,6 +201,107 @@ func TestGetSequence(t *testing.T) {
 	}
 }
 
+func TestListSequences(t *testing.T) {
+	testCases := []struct {
+		name           string
+		queryString    string
+		expectedStatus int
+		expectedBody   string
+		expectedQuery  sequence.ListQuery
+		page           sequence.Page
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			queryString:    "name=intro&openTrackingEnabled=true&sortBy=name&order=desc&limit=1&includeSteps=false",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{\"sequences\":[{\"id\":1,\"name\":\"Intro\",\"openTrackingEnabled\":true,\"clickTrackingEnabled\":false,\"publishedVersion\":0,\"steps\":null}],\"nextCursor\":\"abc\"}\n",
+			expectedQuery: sequence.ListQuery{
+				Name:         "intro",
+				OpenTracking: boolPtr(true),
+				SortBy:       sequence.SortByName,
+				Order:        sequence.SortDescending,
+				Limit:        1,
+			},
+			page: sequence.Page{
+				Sequences:  []sequence.Sequence{{ID: 1, Name: "Intro", OpenTracking: true}},
+				NextCursor: "abc",
+			},
+		},
+		{
+			name:           "Invalid tracking filter",
+			queryString:    "clickTrackingEnabled=maybe",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "clickTrackingEnabled must be a boolean",
+		},
+		{
+			name:           "Invalid limit",
+			queryString:    "limit=many",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid query",
+			queryString:    "sortBy=subject",
+			expectedStatus: http.StatusBadRequest,
+			expectedQuery:  sequence.ListQuery{SortBy: "subject"},
+			serviceError:   sequence.ErrInvalidListQuery,
+		},
+		{
+			name:           "Unknown Error",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "test error",
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodGet, "/sequence?"+tc.queryString, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Create a mock sequence service
+			var receivedQuery sequence.ListQuery
+			mockSequenceService := &testdata.MockSequenceService{
+				ListSequencesFn: func(ctx context.Context, query sequence.ListQuery) (sequence.Page, error) {
+					receivedQuery = query
+					return tc.page, tc.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock sequence service
+			server := transporthttp.NewServer(mockSequenceService)
+
+			// Call the ListSequences method
+			err := server.ListSequences(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tc.expectedStatus, rec.Code)
+			}
+
+			// Check if the response body matches the expected body
+			if tc.expectedBody != "" && rec.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, rec.Body.String())
+			}
+
+			// Check if the query was passed to the service
+			if !reflect.DeepEqual(receivedQuery, tc.expectedQuery) {
+				t.Errorf("expected query %+v, got %+v", tc.expectedQuery, receivedQuery)
+			}
+		})
+	}
+}
+
 func TestPatchSequence(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -206,6 +331,12 @@ func TestPatchSequence(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			serviceError:   sequence.ErrSequenceNotFound,
 		},
+		{
+			name:           "Archived Error",
+			requestBody:    `{"id": 1, "name": "Test Name", "openTracking": true, "clickTracking": false}`,
+			expectedStatus: http.StatusConflict,
+			serviceError:   sequence.ErrSequenceArchived,
+		},
 		{
 			name:           "Unknown Error",
 			requestBody:    `{"id": 1, "name": "Test Name", "openTracking": true, "clickTracking": false}`,
@@ -253,6 +384,542 @@ func TestPatchSequence(t *testing.T) {
 	}
 }
 
+func TestSetSchedule(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		requestBody      string
+		expectedStatus   int
+		expectedSchedule *sequence.Schedule
+		serviceError     error
+	}{
+		{
+			name:           "Set",
+			method:         http.MethodPut,
+			requestBody:    `{"days": ["monday", "tuesday"], "startHour": 9, "endHour": 17, "timeZone": " Europe/Zagreb ", "holidays": ["2024-12-25"]}`,
+			expectedStatus: http.StatusOK,
+			expectedSchedule: &sequence.Schedule{
+				Days:      []sequence.Weekday{sequence.Monday, sequence.Tuesday},
+				StartHour: 9,
+				EndHour:   17,
+				TimeZone:  "Europe/Zagreb",
+				Holidays:  []string{"2024-12-25"},
+			},
+		},
+		{
+			name:           "Delete",
+			method:         http.MethodDelete,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid Body",
+			method:         http.MethodPut,
+			requestBody:    `{"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Validation Error",
+			method:         http.MethodPut,
+			requestBody:    `{"startHour": 17, "endHour": 9}`,
+			expectedStatus: http.StatusBadRequest,
+			serviceError:   sequence.ErrSequenceValidation,
+		},
+		{
+			name:           "Not Found Error",
+			method:         http.MethodPut,
+			requestBody:    `{}`,
+			expectedStatus: http.StatusNotFound,
+			serviceError:   sequence.ErrSequenceNotFound,
+		},
+		{
+			name:           "Archived Error",
+			method:         http.MethodDelete,
+			expectedStatus: http.StatusConflict,
+			serviceError:   sequence.ErrSequenceArchived,
+		},
+		{
+			name:           "Unknown Error",
+			method:         http.MethodPut,
+			requestBody:    `{}`,
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(tt.method, "/sequence/1/schedule", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			var called bool
+			mockSequenceService := &testdata.MockSequenceService{
+				SetScheduleFn: func(ctx context.Context, id int, schedule *sequence.Schedule) error {
+					called = true
+					if id != 1 {
+						t.Errorf("expected sequence 1, got %d", id)
+					}
+
+					if tt.expectedSchedule != nil && !reflect.DeepEqual(schedule, tt.expectedSchedule) {
+						t.Errorf("expected schedule %+v, got %+v", tt.expectedSchedule, schedule)
+					}
+
+					if tt.method == http.MethodDelete && schedule != nil {
+						t.Errorf("expected schedule to be removed, got %+v", schedule)
+					}
+
+					return tt.serviceError
+				},
+			}
+
+			server := transporthttp.NewServer(mockSequenceService)
+
+			var err error
+			if tt.method == http.MethodDelete {
+				err = server.DeleteSchedule(c)
+			} else {
+				err = server.SetSchedule(c)
+			}
+
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK && !called {
+				t.Errorf("expected the schedule to be set")
+			}
+		})
+	}
+}
+
+func TestDeleteSequence(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			id:             "1",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Invalid ID",
+			id:             "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not Found Error",
+			id:             "1",
+			expectedStatus: http.StatusNotFound,
+			serviceError:   sequence.ErrSequenceNotFound,
+		},
+		{
+			name:           "Unknown Error",
+			id:             "1",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodDelete, "/sequence/"+tt.id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			// Create a mock sequence service
+			mockSequenceService := &testdata.MockSequenceService{
+				DeleteSequenceFn: func(ctx context.Context, id int) error {
+					return tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock sequence service
+			server := transporthttp.NewServer(mockSequenceService)
+
+			// Call the DeleteSequence method
+			err := server.DeleteSequence(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestArchiveSequence(t *testing.T) {
+	tests := []struct {
+		name           string
+		unarchive      bool
+		id             string
+		expectedStatus int
+		serviceError   error
+	}{
+		{
+			name:           "Archive",
+			id:             "1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unarchive",
+			unarchive:      true,
+			id:             "1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid ID",
+			id:             "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not Found Error",
+			id:             "1",
+			expectedStatus: http.StatusNotFound,
+			serviceError:   sequence.ErrSequenceNotFound,
+		},
+		{
+			name:           "Unknown Error",
+			unarchive:      true,
+			id:             "1",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodPost, "/sequence/"+tt.id+"/archive", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			// Create a mock sequence service that records which operation was called
+			var called string
+			mockSequenceService := &testdata.MockSequenceService{
+				ArchiveSequenceFn: func(ctx context.Context, id int) error {
+					called = "archive"
+					return tt.serviceError
+				},
+				UnarchiveSequenceFn: func(ctx context.Context, id int) error {
+					called = "unarchive"
+					return tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock sequence service
+			server := transporthttp.NewServer(mockSequenceService)
+
+			// Call the ArchiveSequence or UnarchiveSequence method
+			var err error
+			expectedCall := "archive"
+			if tt.unarchive {
+				expectedCall = "unarchive"
+				err = server.UnarchiveSequence(c)
+			} else {
+				err = server.ArchiveSequence(c)
+			}
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			// Check if the right service method was called
+			if tt.id != "abc" && called != expectedCall {
+				t.Errorf("expected %s to be called, got %q", expectedCall, called)
+			}
+		})
+	}
+}
+
+func TestPublishSequence(t *testing.T) {
+	publishedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+		expectedBody   string
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			id:             "1",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   "{\"sequenceId\":1,\"version\":2,\"steps\":[{\"id\":3,\"position\":0,\"subject\":\"Step 1\",\"content\":\"Content 1\",\"delay\":{\"amount\":0,\"unit\":\"\"}}],\"publishedAt\":\"2024-05-01T12:00:00Z\"}\n",
+		},
+		{
+			name:           "Invalid ID",
+			id:             "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "id must be an integer",
+		},
+		{
+			name:           "Validation Error",
+			id:             "1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   sequence.ErrSequenceValidation.Error(),
+			serviceError:   sequence.ErrSequenceValidation,
+		},
+		{
+			name:           "Not Found Error",
+			id:             "1",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   sequence.ErrSequenceNotFound.Error(),
+			serviceError:   sequence.ErrSequenceNotFound,
+		},
+		{
+			name:           "Archived Error",
+			id:             "1",
+			expectedStatus: http.StatusConflict,
+			expectedBody:   sequence.ErrSequenceArchived.Error(),
+			serviceError:   sequence.ErrSequenceArchived,
+		},
+		{
+			name:           "Unknown Error",
+			id:             "1",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "test error",
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodPost, "/sequence/"+tt.id+"/publish", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			mockSequenceService := &testdata.MockSequenceService{
+				PublishSequenceFn: func(ctx context.Context, id int) (sequence.Version, error) {
+					if tt.serviceError != nil {
+						return sequence.Version{}, tt.serviceError
+					}
+
+					return sequence.Version{
+						SequenceID:  id,
+						Version:     2,
+						Steps:       []sequence.Step{{ID: 3, Subject: "Step 1", Content: "Content 1"}},
+						PublishedAt: publishedAt,
+					}, nil
+				},
+			}
+
+			server := transporthttp.NewServer(mockSequenceService)
+			if err := server.PublishSequence(c); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestListVersions(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+		expectedBody   string
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			id:             "1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[{\"sequenceId\":1,\"version\":1,\"steps\":[],\"publishedAt\":\"0001-01-01T00:00:00Z\"}]\n",
+		},
+		{
+			name:           "Invalid ID",
+			id:             "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "id must be an integer",
+		},
+		{
+			name:           "Not Found Error",
+			id:             "1",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   sequence.ErrSequenceNotFound.Error(),
+			serviceError:   sequence.ErrSequenceNotFound,
+		},
+		{
+			name:           "Unknown Error",
+			id:             "1",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "test error",
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodGet, "/sequence/"+tt.id+"/versions", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			mockSequenceService := &testdata.MockSequenceService{
+				ListVersionsFn: func(ctx context.Context, id int) ([]sequence.Version, error) {
+					if tt.serviceError != nil {
+						return nil, tt.serviceError
+					}
+
+					return []sequence.Version{{SequenceID: id, Version: 1, Steps: []sequence.Step{}}}, nil
+				},
+			}
+
+			server := transporthttp.NewServer(mockSequenceService)
+			if err := server.ListVersions(c); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestDiffVersions(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   string
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			query:          "from=1&to=2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{\"sequenceId\":1,\"from\":1,\"to\":2,\"steps\":[{\"stepId\":3,\"change\":\"removed\",\"from\":{\"id\":3,\"position\":1,\"subject\":\"Step 2\",\"content\":\"Content 2\",\"delay\":{\"amount\":0,\"unit\":\"\"}}}]}\n",
+		},
+		{
+			name:           "Missing Versions",
+			query:          "from=1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "from and to are required",
+		},
+		{
+			name:           "Invalid Version",
+			query:          "from=1&to=abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Version Not Found Error",
+			query:          "from=1&to=9",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   sequence.ErrVersionNotFound.Error(),
+			serviceError:   sequence.ErrVersionNotFound,
+		},
+		{
+			name:           "Sequence Not Found Error",
+			query:          "from=1&to=2",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   sequence.ErrSequenceNotFound.Error(),
+			serviceError:   sequence.ErrSequenceNotFound,
+		},
+		{
+			name:           "Unknown Error",
+			query:          "from=1&to=2",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "test error",
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodGet, "/sequence/1/versions/diff?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			mockSequenceService := &testdata.MockSequenceService{
+				DiffVersionsFn: func(ctx context.Context, id int, from int, to int) (sequence.VersionDiff, error) {
+					if tt.serviceError != nil {
+						return sequence.VersionDiff{}, tt.serviceError
+					}
+
+					removed := sequence.Step{ID: 3, Position: 1, Subject: "Step 2", Content: "Content 2"}
+					return sequence.VersionDiff{
+						SequenceID: id,
+						From:       from,
+						To:         to,
+						Steps:      []sequence.StepChange{{StepID: 3, Change: sequence.ChangeRemoved, From: &removed}},
+					}, nil
+				},
+			}
+
+			server := transporthttp.NewServer(mockSequenceService)
+			if err := server.DiffVersions(c); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
 func TestBuildSequenceModel(t *testing.T) {
 	// Create a new CreateSequenceRequest instance
 	req := transporthttp.CreateSequenceRequest{
@@ -261,7 +928,7 @@ func TestBuildSequenceModel(t *testing.T) {
 		ClickTracking: false,
 		Steps: []transporthttp.CreateSequenceRequestStep{
 			{Subject: "Step 1", Content: "Content 1"},
-			{Subject: "Step 2", Content: "Content 2"},
+			{Subject: "Step 2", Content: "Content 2", Delay: sequence.Delay{Amount: 3, Unit: sequence.DelayUnitBusinessDays}},
 			{Subject: "Step 3", Content: "Content 3"},
 		},
 	}
@@ -287,7 +954,7 @@ func TestBuildSequenceModel(t *testing.T) {
 
 	expectedSteps := []sequence.Step{
 		{Subject: "Step 1", Content: "Content 1"},
-		{Subject: "Step 2", Content: "Content 2"},
+		{Subject: "Step 2", Content: "Content 2", Delay: sequence.Delay{Amount: 3, Unit: sequence.DelayUnitBusinessDays}},
 		{Subject: "Step 3", Content: "Content 3"},
 	}
 	if !reflect.DeepEqual(sequenceModel.Steps, expectedSteps) {
@@ -336,3 +1003,7 @@ func stringPtr(s string) *string {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/stats"
+	"github.com/labstack/echo/v4"
+)
+
+// GetSequenceStats is an echo handler for getting the stats of a sequence.
+func (s Server) GetSequenceStats(e echo.Context) error {
+	request := GetSequenceStatsRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	result, err := s.statsService.SequenceStats(e.Request().Context(), request.BuildQuery())
+	if err != nil {
+		if errors.Is(err, stats.ErrInvalidStatsQuery) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// GetSequenceStatsRequest represents the query parameters for getting the stats of a sequence.
+type GetSequenceStatsRequest struct {
+	SequenceID int    `param:"id"`
+	From       string `query:"from"`
+	To         string `query:"to"`
+}
+
+// BuildQuery builds a stats query from the request.
+func (r GetSequenceStatsRequest) BuildQuery() stats.Query {
+	return stats.Query{
+		SequenceID: r.SequenceID,
+		From:       r.From,
+		To:         r.To,
+	}
+}
@@ -0,0 +1,120 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/stats"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+	"github.com/cybre/salesforge-assignment/internal/transport/http/testdata"
+	"github.com/labstack/echo/v4"
+)
+
+func TestGetSequenceStats(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		idParamValue   string
+		expectedStatus int
+		expectedBody   string
+		expectedQuery  stats.Query
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			query:          "?from=2024-01-01&to=2024-01-31",
+			idParamValue:   "1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{\"sequenceId\":1,\"sent\":2,\"delivered\":2,\"uniqueOpens\":1,\"totalOpens\":3,\"uniqueClicks\":0,\"totalClicks\":0,\"replied\":0,\"bounced\":0,\"unsubscribed\":0,\"openRate\":0.5,\"replyRate\":0,\"bounceRate\":0,\"unsubscribeRate\":0,\"steps\":[]}\n",
+			expectedQuery:  stats.Query{SequenceID: 1, From: "2024-01-01", To: "2024-01-31"},
+		},
+		{
+			name:           "Invalid ID param",
+			idParamValue:   "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Query Error",
+			query:          "?from=yesterday",
+			idParamValue:   "1",
+			expectedStatus: http.StatusBadRequest,
+			expectedQuery:  stats.Query{SequenceID: 1, From: "yesterday"},
+			serviceError:   stats.ErrInvalidStatsQuery,
+		},
+		{
+			name:           "Not Found Error",
+			idParamValue:   "1",
+			expectedStatus: http.StatusNotFound,
+			expectedQuery:  stats.Query{SequenceID: 1},
+			serviceError:   sequence.ErrSequenceNotFound,
+		},
+		{
+			name:           "Unknown Error",
+			idParamValue:   "1",
+			expectedStatus: http.StatusInternalServerError,
+			expectedQuery:  stats.Query{SequenceID: 1},
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodGet, "/sequence/1/stats"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.idParamValue)
+
+			// Create a mock stats service
+			var received stats.Query
+			mockStatsService := &testdata.MockStatsService{
+				SequenceStatsFn: func(ctx context.Context, query stats.Query) (stats.SequenceStats, error) {
+					received = query
+					if tt.serviceError != nil {
+						return stats.SequenceStats{}, tt.serviceError
+					}
+
+					openRate := 0.5
+					return stats.SequenceStats{
+						SequenceID: 1,
+						Counts:     stats.Counts{Sent: 2, Delivered: 2, UniqueOpens: 1, TotalOpens: 3},
+						Rates:      stats.Rates{OpenRate: &openRate},
+						Steps:      []stats.StepStats{},
+					}, nil
+				},
+			}
+
+			// Create a new server instance with the mock stats service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithStatsService(mockStatsService))
+
+			// Call the GetSequenceStats method
+			err := server.GetSequenceStats(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+
+			if received != tt.expectedQuery {
+				t.Errorf("expected query %+v, got %+v", tt.expectedQuery, received)
+			}
+		})
+	}
+}
@@ -4,11 +4,40 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/cybre/salesforge-assignment/internal/contact"
 	"github.com/cybre/salesforge-assignment/internal/sequence"
 	"github.com/labstack/echo/v4"
 )
 
+// AddStep is an echo handler for adding a step to an existing sequence.
+func (s Server) AddStep(e echo.Context) error {
+	request := AddStepRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	id, err := s.sequenceService.AddStep(e.Request().Context(), request.SequenceID, request.BuildStepModel(), request.Position)
+	if err != nil {
+		if errors.Is(err, sequence.ErrStepValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceArchived) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusCreated, AddStepResponse{ID: id})
+}
+
 // UpdateStep is an echo handler for updating a sequence step.
 func (s Server) UpdateStep(e echo.Context) error {
 	request := UpdateStepRequest{}
@@ -26,6 +55,10 @@ func (s Server) UpdateStep(e echo.Context) error {
 			return e.String(http.StatusBadRequest, err.Error())
 		}
 
+		if errors.Is(err, sequence.ErrSequenceArchived) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
 		return e.String(http.StatusInternalServerError, err.Error())
 	}
 
@@ -40,22 +73,233 @@ func (s Server) DeleteStep(e echo.Context) error {
 	}
 
 	if err := s.sequenceService.DeleteStep(e.Request().Context(), id); err != nil {
+		if errors.Is(err, sequence.ErrStepValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceArchived) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
 		return e.String(http.StatusInternalServerError, err.Error())
 	}
 
 	return e.NoContent(http.StatusNoContent)
 }
 
+// ReorderSteps is an echo handler for reordering the steps of a sequence.
+func (s Server) ReorderSteps(e echo.Context) error {
+	request := ReorderStepsRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	if err := s.sequenceService.ReorderSteps(e.Request().Context(), request.ID, request.StepIDs); err != nil {
+		if errors.Is(err, sequence.ErrStepValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceArchived) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.NoContent(http.StatusOK)
+}
+
+// PreviewStep is an echo handler for rendering a step with the merge field values of a
+// contact, or with sample values when no contact is given.
+func (s Server) PreviewStep(e echo.Context) error {
+	request := PreviewStepRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	values := request.Fields
+	if request.ContactID != nil {
+		if s.contactService == nil {
+			return e.String(http.StatusBadRequest, "contacts are not available")
+		}
+
+		c, err := s.contactService.GetContact(e.Request().Context(), *request.ContactID)
+		if err != nil {
+			if errors.Is(err, contact.ErrContactNotFound) {
+				return e.String(http.StatusNotFound, err.Error())
+			}
+
+			return e.String(http.StatusInternalServerError, err.Error())
+		}
+
+		values = c.Fields()
+		for k, v := range request.Fields {
+			values[k] = v
+		}
+	}
+
+	rendered, err := s.sequenceService.PreviewStep(e.Request().Context(), request.ID, values)
+	if err != nil {
+		if errors.Is(err, sequence.ErrStepValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrStepNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, rendered)
+}
+
+// DeclareWinner is an echo handler for declaring the winning variant of a step.
+func (s Server) DeclareWinner(e echo.Context) error {
+	request := DeclareWinnerRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	if err := s.sequenceService.DeclareWinner(e.Request().Context(), request.ID, request.VariantID); err != nil {
+		if errors.Is(err, sequence.ErrStepNotFound) || errors.Is(err, sequence.ErrVariantNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		if errors.Is(err, sequence.ErrSequenceArchived) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.NoContent(http.StatusOK)
+}
+
 type UpdateStepRequest struct {
-	ID      int    `param:"id"`
+	ID      int               `param:"id"`
+	Kind    sequence.StepKind `json:"kind"`
+	Subject string            `json:"subject"`
+	Content string            `json:"content"`
+	Delay   sequence.Delay    `json:"delay"`
+	Branch  *sequence.Branch  `json:"branch,omitempty"`
+	// Assignment is set for manual steps, whose content holds the instructions of their tasks.
+	Assignment *sequence.Assignment `json:"assignment,omitempty"`
+	// Variants replace the variants of the step. Variants with an ID are updated, the others
+	// are added, and the variants left out are removed.
+	Variants []UpdateStepVariantRequest `json:"variants"`
+}
+
+func (r UpdateStepRequest) BuildStepModel() sequence.Step {
+	var variants []sequence.Variant
+	for _, v := range r.Variants {
+		variant := v.BuildVariantModel()
+		variant.ID = v.ID
+		variants = append(variants, variant)
+	}
+
+	return sequence.Step{
+		ID:         r.ID,
+		Kind:       r.Kind,
+		Subject:    r.Subject,
+		Content:    r.Content,
+		Delay:      r.Delay,
+		Variants:   variants,
+		Branch:     r.Branch,
+		Assignment: r.Assignment,
+	}
+}
+
+// StepVariantRequest represents a variant of a step in a request body.
+type StepVariantRequest struct {
+	Label   string `json:"label"`
 	Subject string `json:"subject"`
 	Content string `json:"content"`
+	Weight  int    `json:"weight"`
 }
 
-func (r UpdateStepRequest) BuildStepModel() sequence.Step {
+// BuildVariantModel builds an active variant domain model from the request.
+func (r StepVariantRequest) BuildVariantModel() sequence.Variant {
+	return sequence.Variant{
+		Label:   strings.TrimSpace(r.Label),
+		Subject: strings.TrimSpace(r.Subject),
+		Content: strings.TrimSpace(r.Content),
+		Weight:  r.Weight,
+		Active:  true,
+	}
+}
+
+// UpdateStepVariantRequest represents a variant of a step in the request body for updating
+// a step. The ID is set for existing variants.
+type UpdateStepVariantRequest struct {
+	ID int `json:"id,omitempty"`
+	StepVariantRequest
+}
+
+// buildVariants builds the variant domain models of a step, which are nil without variants.
+func buildVariants(requests []StepVariantRequest) []sequence.Variant {
+	var variants []sequence.Variant
+	for _, v := range requests {
+		variants = append(variants, v.BuildVariantModel())
+	}
+
+	return variants
+}
+
+// AddStepRequest represents the request body for adding a step to a sequence.
+type AddStepRequest struct {
+	SequenceID int               `param:"id"`
+	Kind       sequence.StepKind `json:"kind"`
+	Subject    string            `json:"subject"`
+	Content    string            `json:"content"`
+	Delay      sequence.Delay    `json:"delay"`
+	Position   *int              `json:"position,omitempty"`
+	// Branch targets are positions after the step was added.
+	Branch     *sequence.Branch     `json:"branch,omitempty"`
+	Assignment *sequence.Assignment `json:"assignment,omitempty"`
+	// Variants A/B test the step in place of its subject and content.
+	Variants []StepVariantRequest `json:"variants"`
+}
+
+// BuildStepModel builds a step domain model from the request.
+func (r AddStepRequest) BuildStepModel() sequence.Step {
 	return sequence.Step{
-		ID:      r.ID,
-		Subject: r.Subject,
-		Content: r.Content,
+		Kind:       r.Kind,
+		Subject:    strings.TrimSpace(r.Subject),
+		Content:    strings.TrimSpace(r.Content),
+		Delay:      r.Delay,
+		Variants:   buildVariants(r.Variants),
+		Branch:     r.Branch,
+		Assignment: r.Assignment,
 	}
 }
+
+// AddStepResponse represents the response body for adding a step to a sequence.
+type AddStepResponse struct {
+	ID int `json:"id"`
+}
+
+// ReorderStepsRequest represents the request body for reordering the steps of a sequence.
+type ReorderStepsRequest struct {
+	ID      int   `param:"id"`
+	StepIDs []int `json:"stepIds"`
+}
+
+// DeclareWinnerRequest represents the request for declaring the winning variant of a step.
+type DeclareWinnerRequest struct {
+	ID        int `param:"id"`
+	VariantID int `param:"variantId"`
+}
+
+// PreviewStepRequest represents the request body for previewing a step.
+type PreviewStepRequest struct {
+	ID int `param:"id"`
+	// ContactID renders the step with the fields of the given contact instead of sample values.
+	ContactID *int `json:"contactId,omitempty"`
+	// Fields overrides or adds to the sample merge field values.
+	Fields map[string]string `json:"fields"`
+}
@@ -9,12 +9,126 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cybre/salesforge-assignment/internal/contact"
 	"github.com/cybre/salesforge-assignment/internal/sequence"
 	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
 	"github.com/cybre/salesforge-assignment/internal/transport/http/testdata"
 	"github.com/labstack/echo/v4"
 )
 
+func TestAddStep(t *testing.T) {
+	tests := []struct {
+		name             string
+		requestBody      string
+		expectedStatus   int
+		expectedBody     string
+		expectedPosition *int
+		serviceError     error
+		idParamValue     string
+	}{
+		{
+			name:           "Success",
+			requestBody:    `{"subject": "Test Subject", "content": "Test Content"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   "{\"id\":5}\n",
+			idParamValue:   "1",
+		},
+		{
+			name:             "Success with position",
+			requestBody:      `{"subject": "Test Subject", "content": "Test Content", "position": 0}`,
+			expectedStatus:   http.StatusCreated,
+			expectedBody:     "{\"id\":5}\n",
+			expectedPosition: intPtr(0),
+			idParamValue:     "1",
+		},
+		{
+			name:           "Invalid Request Body",
+			requestBody:    `{"}`,
+			expectedStatus: http.StatusBadRequest,
+			idParamValue:   "1",
+		},
+		{
+			name:           "Invalid ID param",
+			requestBody:    `{"subject": "Test Subject", "content": "Test Content"}`,
+			expectedStatus: http.StatusBadRequest,
+			idParamValue:   "abc",
+		},
+		{
+			name:           "Validation Error",
+			requestBody:    `{"subject": "Test Subject"}`,
+			expectedStatus: http.StatusBadRequest,
+			serviceError:   sequence.ErrStepValidation,
+			idParamValue:   "1",
+		},
+		{
+			name:           "Not Found Error",
+			requestBody:    `{"subject": "Test Subject", "content": "Test Content"}`,
+			expectedStatus: http.StatusNotFound,
+			serviceError:   sequence.ErrSequenceNotFound,
+			idParamValue:   "1",
+		},
+		{
+			name:           "Unknown Error",
+			requestBody:    `{"subject": "Test Subject", "content": "Test Content"}`,
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+			idParamValue:   "1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request with a JSON payload
+			req := httptest.NewRequest(http.MethodPost, "/sequence/1/steps", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.idParamValue)
+
+			// Create a mock sequence service
+			var receivedPosition *int
+			mockSequenceService := &testdata.MockSequenceService{
+				AddStepFn: func(ctx context.Context, sequenceID int, step sequence.Step, position *int) (int, error) {
+					receivedPosition = position
+					if tt.serviceError != nil {
+						return 0, tt.serviceError
+					}
+
+					return 5, nil
+				},
+			}
+
+			// Create a new server instance with the mock sequence service
+			server := transporthttp.NewServer(mockSequenceService)
+
+			// Call the AddStep method
+			err := server.AddStep(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+
+			if !reflect.DeepEqual(receivedPosition, tt.expectedPosition) {
+				t.Errorf("expected position %v, got %v", tt.expectedPosition, receivedPosition)
+			}
+		})
+	}
+}
+
 func TestUpdateStep(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -55,6 +169,13 @@ func TestUpdateStep(t *testing.T) {
 			idParamValue:   "1",
 			serviceError:   sequence.ErrStepNotFound,
 		},
+		{
+			name:           "Archived Error",
+			requestBody:    `{"subject": "Test Subject", "content": "Test Content"}`,
+			expectedStatus: http.StatusConflict,
+			serviceError:   sequence.ErrSequenceArchived,
+			idParamValue:   "1",
+		},
 		{
 			name:           "Unknown Error",
 			requestBody:    `{"subject": "Test Subject", "content": "Test Content"}`,
@@ -108,12 +229,38 @@ func TestUpdateStepRequest_BuildStepModel(t *testing.T) {
 		ID:      1,
 		Subject: "Test Subject",
 		Content: "Test Content",
+		Delay:   sequence.Delay{Amount: 4, Unit: sequence.DelayUnitHours},
 	}
 
 	expected := sequence.Step{
 		ID:      r.ID,
 		Subject: r.Subject,
 		Content: r.Content,
+		Delay:   r.Delay,
+	}
+
+	result := r.BuildStepModel()
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, but got %v", expected, result)
+	}
+}
+
+func TestUpdateStepRequest_BuildStepModel_Variants(t *testing.T) {
+	r := transporthttp.UpdateStepRequest{
+		ID: 1,
+		Variants: []transporthttp.UpdateStepVariantRequest{
+			{ID: 7, StepVariantRequest: transporthttp.StepVariantRequest{Label: " A ", Subject: "Subject A ", Content: " Content A", Weight: 60}},
+			{StepVariantRequest: transporthttp.StepVariantRequest{Label: "B", Subject: "Subject B", Content: "Content B", Weight: 40}},
+		},
+	}
+
+	expected := sequence.Step{
+		ID: 1,
+		Variants: []sequence.Variant{
+			{ID: 7, Label: "A", Subject: "Subject A", Content: "Content A", Weight: 60, Active: true},
+			{Label: "B", Subject: "Subject B", Content: "Content B", Weight: 40, Active: true},
+		},
 	}
 
 	result := r.BuildStepModel()
@@ -140,6 +287,18 @@ func TestDeleteStep(t *testing.T) {
 			id:             "abc",
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "Archived Error",
+			id:             "1",
+			expectedStatus: http.StatusConflict,
+			serviceError:   sequence.ErrSequenceArchived,
+		},
+		{
+			name:           "Validation Error",
+			id:             "1",
+			expectedStatus: http.StatusBadRequest,
+			serviceError:   sequence.ErrStepValidation,
+		},
 		{
 			name:           "Unknown Error",
 			id:             "1",
@@ -185,3 +344,361 @@ func TestDeleteStep(t *testing.T) {
 		})
 	}
 }
+
+func TestReorderSteps(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		serviceError   error
+		idParamValue   string
+	}{
+		{
+			name:           "Success",
+			requestBody:    `{"stepIds": [3, 1, 2]}`,
+			expectedStatus: http.StatusOK,
+			idParamValue:   "1",
+		},
+		{
+			name:           "Invalid Request Body",
+			requestBody:    `{"}`,
+			expectedStatus: http.StatusBadRequest,
+			idParamValue:   "1",
+		},
+		{
+			name:           "Invalid ID param",
+			requestBody:    `{"stepIds": [3, 1, 2]}`,
+			expectedStatus: http.StatusBadRequest,
+			idParamValue:   "abc",
+		},
+		{
+			name:           "Validation Error",
+			requestBody:    `{"stepIds": [3, 1]}`,
+			expectedStatus: http.StatusBadRequest,
+			serviceError:   sequence.ErrStepValidation,
+			idParamValue:   "1",
+		},
+		{
+			name:           "Not Found Error",
+			requestBody:    `{"stepIds": [3, 1, 2]}`,
+			expectedStatus: http.StatusNotFound,
+			serviceError:   sequence.ErrSequenceNotFound,
+			idParamValue:   "1",
+		},
+		{
+			name:           "Unknown Error",
+			requestBody:    `{"stepIds": [3, 1, 2]}`,
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+			idParamValue:   "1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request with a JSON payload
+			req := httptest.NewRequest(http.MethodPut, "/sequence/1/steps/order", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.idParamValue)
+
+			// Create a mock sequence service
+			var receivedIDs []int
+			mockSequenceService := &testdata.MockSequenceService{
+				ReorderStepsFn: func(ctx context.Context, sequenceID int, stepIDs []int) error {
+					receivedIDs = stepIDs
+					return tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock sequence service
+			server := transporthttp.NewServer(mockSequenceService)
+
+			// Call the ReorderSteps method
+			err := server.ReorderSteps(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			// Check if the step IDs were passed through in order
+			if rec.Code == http.StatusOK && !reflect.DeepEqual(receivedIDs, []int{3, 1, 2}) {
+				t.Errorf("expected step IDs %v, got %v", []int{3, 1, 2}, receivedIDs)
+			}
+		})
+	}
+}
+
+func TestPreviewStep(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expectedBody   string
+		expectedFields map[string]string
+		serviceError   error
+		idParamValue   string
+	}{
+		{
+			name:           "Success",
+			requestBody:    `{"fields": {"firstName": "John"}}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{\"subject\":\"Hi John\",\"content\":\"Hello\",\"missingFields\":[]}\n",
+			expectedFields: map[string]string{"firstName": "John"},
+			idParamValue:   "1",
+		},
+		{
+			name:           "Invalid Request Body",
+			requestBody:    `{"}`,
+			expectedStatus: http.StatusBadRequest,
+			idParamValue:   "1",
+		},
+		{
+			name:           "Invalid ID param",
+			requestBody:    `{}`,
+			expectedStatus: http.StatusBadRequest,
+			idParamValue:   "abc",
+		},
+		{
+			name:           "Not Found Error",
+			requestBody:    `{}`,
+			expectedStatus: http.StatusNotFound,
+			serviceError:   sequence.ErrStepNotFound,
+			idParamValue:   "1",
+		},
+		{
+			name:           "Unknown Error",
+			requestBody:    `{}`,
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+			idParamValue:   "1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request with a JSON payload
+			req := httptest.NewRequest(http.MethodPost, "/step/1/preview", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.idParamValue)
+
+			// Create a mock sequence service
+			var receivedFields map[string]string
+			mockSequenceService := &testdata.MockSequenceService{
+				PreviewStepFn: func(ctx context.Context, stepID int, values map[string]string) (sequence.RenderedStep, error) {
+					receivedFields = values
+					if tt.serviceError != nil {
+						return sequence.RenderedStep{}, tt.serviceError
+					}
+
+					return sequence.RenderedStep{Subject: "Hi John", Content: "Hello", MissingFields: []string{}}, nil
+				},
+			}
+
+			// Create a new server instance with the mock sequence service
+			server := transporthttp.NewServer(mockSequenceService)
+
+			// Call the PreviewStep method
+			err := server.PreviewStep(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+
+			if tt.expectedFields != nil && !reflect.DeepEqual(receivedFields, tt.expectedFields) {
+				t.Errorf("expected fields %v, got %v", tt.expectedFields, receivedFields)
+			}
+		})
+	}
+}
+
+func TestPreviewStep_WithContact(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expectedFields map[string]string
+		contactError   error
+	}{
+		{
+			name:           "Success",
+			requestBody:    `{"contactId": 3, "fields": {"company": "Globex"}}`,
+			expectedStatus: http.StatusOK,
+			expectedFields: map[string]string{
+				"email":       "jane@example.com",
+				"firstName":   "Jane",
+				"lastName":    "",
+				"company":     "Globex",
+				"custom.plan": "Pro",
+			},
+		},
+		{
+			name:           "Contact Not Found Error",
+			requestBody:    `{"contactId": 3}`,
+			expectedStatus: http.StatusNotFound,
+			contactError:   contact.ErrContactNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request with a JSON payload
+			req := httptest.NewRequest(http.MethodPost, "/step/1/preview", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			// Create mock sequence and contact services
+			var receivedFields map[string]string
+			mockSequenceService := &testdata.MockSequenceService{
+				PreviewStepFn: func(ctx context.Context, stepID int, values map[string]string) (sequence.RenderedStep, error) {
+					receivedFields = values
+					return sequence.RenderedStep{MissingFields: []string{}}, nil
+				},
+			}
+			mockContactService := &testdata.MockContactService{
+				GetContactFn: func(ctx context.Context, id int) (contact.Contact, error) {
+					if tt.contactError != nil {
+						return contact.Contact{}, tt.contactError
+					}
+
+					return contact.Contact{ID: id, Email: "jane@example.com", FirstName: "Jane", Company: "Acme", CustomFields: map[string]any{"plan": "Pro"}}, nil
+				},
+			}
+
+			// Create a new server instance with the mock services
+			server := transporthttp.NewServer(mockSequenceService, transporthttp.WithContactService(mockContactService))
+
+			// Call the PreviewStep method
+			err := server.PreviewStep(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if !reflect.DeepEqual(receivedFields, tt.expectedFields) {
+				t.Errorf("expected fields %v, got %v", tt.expectedFields, receivedFields)
+			}
+		})
+	}
+}
+
+func TestDeclareWinner(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		variantID      string
+		expectedStatus int
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			id:             "1",
+			variantID:      "7",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid variant ID",
+			id:             "1",
+			variantID:      "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Step not found",
+			id:             "1",
+			variantID:      "7",
+			expectedStatus: http.StatusNotFound,
+			serviceError:   sequence.ErrStepNotFound,
+		},
+		{
+			name:           "Variant not found",
+			id:             "1",
+			variantID:      "7",
+			expectedStatus: http.StatusNotFound,
+			serviceError:   sequence.ErrVariantNotFound,
+		},
+		{
+			name:           "Archived Error",
+			id:             "1",
+			variantID:      "7",
+			expectedStatus: http.StatusConflict,
+			serviceError:   sequence.ErrSequenceArchived,
+		},
+		{
+			name:           "Unknown Error",
+			id:             "1",
+			variantID:      "7",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodPost, "/step/"+tt.id+"/variants/"+tt.variantID+"/winner", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id", "variantId")
+			c.SetParamValues(tt.id, tt.variantID)
+
+			mockSequenceService := &testdata.MockSequenceService{
+				DeclareWinnerFn: func(ctx context.Context, stepID int, variantID int) error {
+					if stepID != 1 || variantID != 7 {
+						t.Errorf("expected variant 7 of step 1, got variant %d of step %d", variantID, stepID)
+					}
+					return tt.serviceError
+				},
+			}
+
+			server := transporthttp.NewServer(mockSequenceService)
+
+			if err := server.DeclareWinner(c); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
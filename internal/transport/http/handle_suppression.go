@@ -0,0 +1,160 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	"github.com/labstack/echo/v4"
+)
+
+// unsubscribeConfirmPage asks the recipient to confirm unsubscribing. Unsubscribing only
+// happens on POST, so mail scanners following the link do not unsubscribe anyone.
+const unsubscribeConfirmPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Unsubscribe</title></head>
+<body style="font-family:sans-serif;max-width:480px;margin:48px auto;padding:0 16px">
+<h1>Unsubscribe</h1>
+<p>Do you want to stop receiving these emails?</p>
+<form method="post">
+<input type="hidden" name="List-Unsubscribe" value="One-Click">
+<button type="submit">Unsubscribe</button>
+</form>
+</body>
+</html>
+`
+
+const unsubscribedPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Unsubscribed</title></head>
+<body style="font-family:sans-serif;max-width:480px;margin:48px auto;padding:0 16px">
+<h1>You have been unsubscribed</h1>
+<p>You will not receive any more of these emails.</p>
+</body>
+</html>
+`
+
+// AddSuppression is an echo handler for adding an email address or domain to the suppression list.
+func (s Server) AddSuppression(e echo.Context) error {
+	request := AddSuppressionRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	created, err := s.suppressionService.AddSuppression(e.Request().Context(), request.BuildSuppressionModel())
+	if err != nil {
+		if errors.Is(err, suppression.ErrSuppressionValidation) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		if errors.Is(err, suppression.ErrSuppressionExists) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusCreated, created)
+}
+
+// ListSuppressions is an echo handler for listing the suppression list.
+func (s Server) ListSuppressions(e echo.Context) error {
+	request := ListSuppressionsRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	page, err := s.suppressionService.ListSuppressions(e.Request().Context(), request.BuildListQuery())
+	if err != nil {
+		if errors.Is(err, suppression.ErrInvalidListQuery) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, page)
+}
+
+// DeleteSuppression is an echo handler for removing an entry from the suppression list.
+func (s Server) DeleteSuppression(e echo.Context) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	if err := s.suppressionService.DeleteSuppression(e.Request().Context(), id); err != nil {
+		if errors.Is(err, suppression.ErrSuppressionNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// ConfirmUnsubscribe is an echo handler for the unsubscribe links of emails. It shows a page
+// asking the recipient to confirm unsubscribing.
+func (s Server) ConfirmUnsubscribe(e echo.Context) error {
+	if err := s.suppressionService.VerifyUnsubscribe(e.Param("token")); err != nil {
+		return e.String(http.StatusNotFound, "link not found")
+	}
+
+	e.Response().Header().Set("Cache-Control", "no-store")
+	e.Response().Header().Set("Referrer-Policy", "no-referrer")
+
+	return e.HTML(http.StatusOK, unsubscribeConfirmPage)
+}
+
+// Unsubscribe is an echo handler for unsubscribing through the unsubscribe links of emails. It
+// serves both the confirmation form and RFC 8058 one-click requests from mail clients.
+func (s Server) Unsubscribe(e echo.Context) error {
+	if err := s.suppressionService.Unsubscribe(e.Request().Context(), e.Param("token")); err != nil {
+		if errors.Is(err, suppression.ErrInvalidUnsubscribeLink) {
+			return e.String(http.StatusNotFound, "link not found")
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	e.Response().Header().Set("Cache-Control", "no-store")
+	e.Response().Header().Set("Referrer-Policy", "no-referrer")
+
+	return e.HTML(http.StatusOK, unsubscribedPage)
+}
+
+// AddSuppressionRequest represents the request body for adding to the suppression list.
+type AddSuppressionRequest struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// BuildSuppressionModel builds a suppression domain model from the request.
+func (r AddSuppressionRequest) BuildSuppressionModel() suppression.Suppression {
+	return suppression.Suppression{
+		Type:   suppression.Type(r.Type),
+		Value:  r.Value,
+		Reason: suppression.ReasonManual,
+	}
+}
+
+// ListSuppressionsRequest represents the query parameters for listing the suppression list.
+type ListSuppressionsRequest struct {
+	Type   string `query:"type"`
+	Search string `query:"search"`
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+}
+
+// BuildListQuery builds a suppression list query from the request.
+func (r ListSuppressionsRequest) BuildListQuery() suppression.ListQuery {
+	return suppression.ListQuery{
+		Type:   suppression.Type(r.Type),
+		Search: strings.TrimSpace(r.Search),
+		Limit:  r.Limit,
+		Cursor: r.Cursor,
+	}
+}
@@ -0,0 +1,342 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+	"github.com/cybre/salesforge-assignment/internal/transport/http/testdata"
+	"github.com/labstack/echo/v4"
+)
+
+func TestAddSuppression(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expectedModel  suppression.Suppression
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			requestBody:    `{"type": "domain", "value": "example.com"}`,
+			expectedStatus: http.StatusCreated,
+			expectedModel:  suppression.Suppression{Type: suppression.TypeDomain, Value: "example.com", Reason: suppression.ReasonManual},
+		},
+		{
+			name:           "Invalid Request Body",
+			requestBody:    `{"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Validation Error",
+			requestBody:    `{"type": "phone", "value": "123"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedModel:  suppression.Suppression{Type: "phone", Value: "123", Reason: suppression.ReasonManual},
+			serviceError:   suppression.ErrSuppressionValidation,
+		},
+		{
+			name:           "Exists Error",
+			requestBody:    `{"type": "email", "value": "jane@example.com"}`,
+			expectedStatus: http.StatusConflict,
+			expectedModel:  suppression.Suppression{Type: suppression.TypeEmail, Value: "jane@example.com", Reason: suppression.ReasonManual},
+			serviceError:   suppression.ErrSuppressionExists,
+		},
+		{
+			name:           "Unknown Error",
+			requestBody:    `{"type": "email", "value": "jane@example.com"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedModel:  suppression.Suppression{Type: suppression.TypeEmail, Value: "jane@example.com", Reason: suppression.ReasonManual},
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request with a JSON payload
+			req := httptest.NewRequest(http.MethodPost, "/suppression", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Create a mock suppression service
+			var received suppression.Suppression
+			mockSuppressionService := &testdata.MockSuppressionService{
+				AddSuppressionFn: func(ctx context.Context, s suppression.Suppression) (suppression.Suppression, error) {
+					received = s
+					s.ID = 1
+					return s, tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock suppression service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithSuppressionService(mockSuppressionService))
+
+			// Call the AddSuppression method
+			err := server.AddSuppression(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if received != tt.expectedModel {
+				t.Errorf("expected suppression %+v, got %+v", tt.expectedModel, received)
+			}
+		})
+	}
+}
+
+func TestListSuppressions(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedQuery  suppression.ListQuery
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			query:          "?type=email&search=%20example%20&limit=10&cursor=abc",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  suppression.ListQuery{Type: suppression.TypeEmail, Search: "example", Limit: 10, Cursor: "abc"},
+		},
+		{
+			name:           "Invalid limit",
+			query:          "?limit=ten",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Query Error",
+			query:          "?type=phone",
+			expectedStatus: http.StatusBadRequest,
+			expectedQuery:  suppression.ListQuery{Type: "phone"},
+			serviceError:   suppression.ErrInvalidListQuery,
+		},
+		{
+			name:           "Unknown Error",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodGet, "/suppression"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Create a mock suppression service
+			var received suppression.ListQuery
+			mockSuppressionService := &testdata.MockSuppressionService{
+				ListSuppressionsFn: func(ctx context.Context, query suppression.ListQuery) (suppression.Page, error) {
+					received = query
+					return suppression.Page{Suppressions: []suppression.Suppression{}}, tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock suppression service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithSuppressionService(mockSuppressionService))
+
+			// Call the ListSuppressions method
+			err := server.ListSuppressions(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if received != tt.expectedQuery {
+				t.Errorf("expected query %+v, got %+v", tt.expectedQuery, received)
+			}
+		})
+	}
+}
+
+func TestDeleteSuppression(t *testing.T) {
+	tests := []struct {
+		name           string
+		idParamValue   string
+		expectedStatus int
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			idParamValue:   "1",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Invalid ID param",
+			idParamValue:   "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not Found Error",
+			idParamValue:   "1",
+			expectedStatus: http.StatusNotFound,
+			serviceError:   suppression.ErrSuppressionNotFound,
+		},
+		{
+			name:           "Unknown Error",
+			idParamValue:   "1",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodDelete, "/suppression/"+tt.idParamValue, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.idParamValue)
+
+			// Create a mock suppression service
+			mockSuppressionService := &testdata.MockSuppressionService{
+				DeleteSuppressionFn: func(ctx context.Context, id int) error {
+					return tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock suppression service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithSuppressionService(mockSuppressionService))
+
+			// Call the DeleteSuppression method
+			err := server.DeleteSuppression(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		expectedStatus int
+		expectedBody   string
+		serviceError   error
+	}{
+		{
+			name:           "Confirmation page",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `<form method="post">`,
+		},
+		{
+			name:           "Confirmation page with invalid token",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusNotFound,
+			serviceError:   suppression.ErrInvalidUnsubscribeLink,
+		},
+		{
+			name:           "One-click",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "You have been unsubscribed",
+		},
+		{
+			name:           "One-click with invalid token",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusNotFound,
+			serviceError:   suppression.ErrInvalidUnsubscribeLink,
+		},
+		{
+			name:           "Unknown Error",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request, as sent by mail clients for one-click unsubscribing
+			req := httptest.NewRequest(tt.method, "/u/token", strings.NewReader("List-Unsubscribe=One-Click"))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("token")
+			c.SetParamValues("token")
+
+			// Create a mock suppression service
+			var unsubscribed bool
+			mockSuppressionService := &testdata.MockSuppressionService{
+				VerifyUnsubscribeFn: func(token string) error {
+					return tt.serviceError
+				},
+				UnsubscribeFn: func(ctx context.Context, token string) error {
+					unsubscribed = tt.serviceError == nil
+					return tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock suppression service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithSuppressionService(mockSuppressionService))
+
+			// Call the ConfirmUnsubscribe or Unsubscribe method
+			var err error
+			if tt.method == http.MethodGet {
+				err = server.ConfirmUnsubscribe(c)
+			} else {
+				err = server.Unsubscribe(c)
+			}
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+
+			if tt.method == http.MethodGet && unsubscribed {
+				t.Errorf("expected the confirmation page not to unsubscribe")
+			}
+		})
+	}
+}
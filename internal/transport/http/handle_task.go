@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cybre/salesforge-assignment/internal/task"
+	"github.com/labstack/echo/v4"
+)
+
+// ListTasks is an echo handler for listing the tasks of manual steps.
+func (s Server) ListTasks(e echo.Context) error {
+	request := ListTasksRequest{}
+	if err := e.Bind(&request); err != nil {
+		return e.String(http.StatusBadRequest, err.Error())
+	}
+
+	page, err := s.taskService.ListTasks(e.Request().Context(), request.BuildListQuery())
+	if err != nil {
+		if errors.Is(err, task.ErrInvalidListQuery) {
+			return e.String(http.StatusBadRequest, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, page)
+}
+
+// CompleteTask is an echo handler for completing a task, which lets its enrollment continue.
+func (s Server) CompleteTask(e echo.Context) error {
+	return s.closeTask(e, s.taskService.CompleteTask)
+}
+
+// SkipTask is an echo handler for skipping a task, which lets its enrollment continue.
+func (s Server) SkipTask(e echo.Context) error {
+	return s.closeTask(e, s.taskService.SkipTask)
+}
+
+func (s Server) closeTask(e echo.Context, closeTask func(ctx context.Context, id int) (task.Task, error)) error {
+	id, err := strconv.Atoi(e.Param("id"))
+	if err != nil {
+		return e.String(http.StatusBadRequest, "id must be an integer")
+	}
+
+	closed, err := closeTask(e.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, task.ErrTaskNotFound) {
+			return e.String(http.StatusNotFound, err.Error())
+		}
+
+		if errors.Is(err, task.ErrTaskClosed) {
+			return e.String(http.StatusConflict, err.Error())
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, closed)
+}
+
+// ListTasksRequest represents the query parameters for listing tasks.
+type ListTasksRequest struct {
+	Assignee   string `query:"assignee"`
+	Status     string `query:"status"`
+	SequenceID int    `query:"sequenceId"`
+	Limit      int    `query:"limit"`
+	Cursor     string `query:"cursor"`
+}
+
+// BuildListQuery builds a task list query from the request.
+func (r ListTasksRequest) BuildListQuery() task.ListQuery {
+	return task.ListQuery{
+		Assignee:   r.Assignee,
+		Status:     task.Status(r.Status),
+		SequenceID: r.SequenceID,
+		Limit:      r.Limit,
+		Cursor:     r.Cursor,
+	}
+}
@@ -0,0 +1,193 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/task"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+	"github.com/cybre/salesforge-assignment/internal/transport/http/testdata"
+	"github.com/labstack/echo/v4"
+)
+
+func TestListTasks(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedQuery  task.ListQuery
+		serviceError   error
+	}{
+		{
+			name:           "Success",
+			query:          "?assignee=jane%40example.com&status=open&sequenceId=5&limit=10&cursor=abc",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  task.ListQuery{Assignee: "jane@example.com", Status: task.StatusOpen, SequenceID: 5, Limit: 10, Cursor: "abc"},
+		},
+		{
+			name:           "Invalid sequence ID",
+			query:          "?sequenceId=abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Query Error",
+			query:          "?status=done",
+			expectedStatus: http.StatusBadRequest,
+			expectedQuery:  task.ListQuery{Status: "done"},
+			serviceError:   task.ErrInvalidListQuery,
+		},
+		{
+			name:           "Unknown Error",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodGet, "/task"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Create a mock task service
+			var received task.ListQuery
+			mockTaskService := &testdata.MockTaskService{
+				ListTasksFn: func(ctx context.Context, query task.ListQuery) (task.Page, error) {
+					received = query
+					return task.Page{Tasks: []task.Task{}}, tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock task service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithTaskService(mockTaskService))
+
+			// Call the ListTasks method
+			err := server.ListTasks(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if received != tt.expectedQuery {
+				t.Errorf("expected query %+v, got %+v", tt.expectedQuery, received)
+			}
+		})
+	}
+}
+
+func TestCloseTask(t *testing.T) {
+	tests := []struct {
+		name           string
+		skip           bool
+		idParamValue   string
+		expectedStatus int
+		serviceError   error
+	}{
+		{
+			name:           "Complete",
+			idParamValue:   "1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Skip",
+			skip:           true,
+			idParamValue:   "1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid ID",
+			idParamValue:   "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not Found Error",
+			idParamValue:   "1",
+			expectedStatus: http.StatusNotFound,
+			serviceError:   task.ErrTaskNotFound,
+		},
+		{
+			name:           "Closed Error",
+			skip:           true,
+			idParamValue:   "1",
+			expectedStatus: http.StatusConflict,
+			serviceError:   task.ErrTaskClosed,
+		},
+		{
+			name:           "Unknown Error",
+			idParamValue:   "1",
+			expectedStatus: http.StatusInternalServerError,
+			serviceError:   errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			action := "complete"
+			if tt.skip {
+				action = "skip"
+			}
+			req := httptest.NewRequest(http.MethodPost, "/task/"+tt.idParamValue+"/"+action, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.idParamValue)
+
+			// Create a mock task service
+			var closed task.Status
+			closeTask := func(status task.Status) func(ctx context.Context, id int) (task.Task, error) {
+				return func(ctx context.Context, id int) (task.Task, error) {
+					closed = status
+					return task.Task{ID: id, Status: status}, tt.serviceError
+				}
+			}
+			mockTaskService := &testdata.MockTaskService{
+				CompleteTaskFn: closeTask(task.StatusCompleted),
+				SkipTaskFn:     closeTask(task.StatusSkipped),
+			}
+
+			// Create a new server instance with the mock task service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithTaskService(mockTaskService))
+
+			// Call the CompleteTask or SkipTask method
+			var err error
+			expectedClosed := task.StatusCompleted
+			if tt.skip {
+				err = server.SkipTask(c)
+				expectedClosed = task.StatusSkipped
+			} else {
+				err = server.CompleteTask(c)
+			}
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if tt.idParamValue != "abc" && closed != expectedClosed {
+				t.Errorf("expected task to be closed as %s, got %s", expectedClosed, closed)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+	"github.com/cybre/salesforge-assignment/pkg/logging"
+	"github.com/labstack/echo/v4"
+)
+
+// TrackOpen is an echo handler for the open tracking pixel. It responds with the pixel
+// whatever the outcome, so the response does not reveal whether the token was valid.
+func (s Server) TrackOpen(e echo.Context) error {
+	ctx := e.Request().Context()
+	token := strings.TrimSuffix(e.Param("token"), ".gif")
+
+	if err := s.trackingService.RecordOpen(ctx, token, e.Request().UserAgent(), e.RealIP()); err != nil && !errors.Is(err, tracking.ErrInvalidToken) {
+		logging.FromContext(ctx).LogAttrs(ctx, slog.LevelError, "TRACKING_ERROR", slog.String("err", err.Error()))
+	}
+
+	e.Response().Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	e.Response().Header().Set("Pragma", "no-cache")
+	e.Response().Header().Set("Expires", "0")
+
+	return e.Blob(http.StatusOK, "image/gif", tracking.Pixel)
+}
+
+// TrackClick is an echo handler for tracked links. It redirects to the target of the link,
+// even when the click cannot be recorded.
+func (s Server) TrackClick(e echo.Context) error {
+	ctx := e.Request().Context()
+
+	target, err := s.trackingService.RecordClick(ctx, e.Param("token"), e.Request().UserAgent(), e.RealIP())
+	if target == "" {
+		if errors.Is(err, tracking.ErrInvalidToken) {
+			return e.String(http.StatusNotFound, "link not found")
+		}
+
+		return e.String(http.StatusInternalServerError, err.Error())
+	}
+
+	if err != nil {
+		logging.FromContext(ctx).LogAttrs(ctx, slog.LevelError, "TRACKING_ERROR", slog.String("err", err.Error()))
+	}
+
+	e.Response().Header().Set("Cache-Control", "no-store")
+	e.Response().Header().Set("Referrer-Policy", "no-referrer")
+
+	return e.Redirect(http.StatusFound, target)
+}
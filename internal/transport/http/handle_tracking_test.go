@@ -0,0 +1,174 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+	"github.com/cybre/salesforge-assignment/internal/transport/http/testdata"
+	"github.com/labstack/echo/v4"
+)
+
+func TestTrackOpen(t *testing.T) {
+	tests := []struct {
+		name          string
+		tokenParam    string
+		expectedToken string
+		serviceError  error
+	}{
+		{
+			name:          "Success",
+			tokenParam:    "abc.gif",
+			expectedToken: "abc",
+		},
+		{
+			name:          "Invalid Token",
+			tokenParam:    "abc.gif",
+			expectedToken: "abc",
+			serviceError:  tracking.ErrInvalidToken,
+		},
+		{
+			name:          "Unknown Error",
+			tokenParam:    "abc.gif",
+			expectedToken: "abc",
+			serviceError:  errors.New("test error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request from an email client
+			req := httptest.NewRequest(http.MethodGet, "/t/o/"+tt.tokenParam, nil)
+			req.Header.Set("User-Agent", "Thunderbird")
+			req.Header.Set(echo.HeaderXRealIP, "203.0.113.5")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("token")
+			c.SetParamValues(tt.tokenParam)
+
+			// Create a mock tracking service
+			var receivedToken, receivedUserAgent, receivedIP string
+			mockTrackingService := &testdata.MockTrackingService{
+				RecordOpenFn: func(ctx context.Context, token string, userAgent string, ip string) error {
+					receivedToken, receivedUserAgent, receivedIP = token, userAgent, ip
+					return tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock tracking service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithTrackingService(mockTrackingService))
+
+			// Call the TrackOpen method
+			err := server.TrackOpen(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// The response never depends on the outcome
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected status code %d, got %d", http.StatusOK, rec.Code)
+			}
+
+			if rec.Header().Get(echo.HeaderContentType) != "image/gif" || !bytes.Equal(rec.Body.Bytes(), tracking.Pixel) {
+				t.Errorf("expected tracking pixel, got %q (%s)", rec.Body.Bytes(), rec.Header().Get(echo.HeaderContentType))
+			}
+
+			if rec.Header().Get("Cache-Control") == "" {
+				t.Errorf("expected Cache-Control header to be set")
+			}
+
+			if receivedToken != tt.expectedToken || receivedUserAgent != "Thunderbird" || receivedIP != "203.0.113.5" {
+				t.Errorf("unexpected open: token %q, user agent %q, ip %q", receivedToken, receivedUserAgent, receivedIP)
+			}
+		})
+	}
+}
+
+func TestTrackClick(t *testing.T) {
+	tests := []struct {
+		name             string
+		target           string
+		serviceError     error
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{
+			name:             "Success",
+			target:           "https://example.com/pricing",
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://example.com/pricing",
+		},
+		{
+			name:             "Click Not Recorded",
+			target:           "https://example.com/pricing",
+			serviceError:     errors.New("test error"),
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://example.com/pricing",
+		},
+		{
+			name:           "Invalid Token",
+			serviceError:   tracking.ErrInvalidToken,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Unknown Error",
+			serviceError:   errors.New("test error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new Echo instance
+			e := echo.New()
+
+			// Create a new HTTP request
+			req := httptest.NewRequest(http.MethodGet, "/t/c/abc", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("token")
+			c.SetParamValues("abc")
+
+			// Create a mock tracking service
+			mockTrackingService := &testdata.MockTrackingService{
+				RecordClickFn: func(ctx context.Context, token string, userAgent string, ip string) (string, error) {
+					if token != "abc" {
+						t.Errorf("expected token %q, got %q", "abc", token)
+					}
+
+					return tt.target, tt.serviceError
+				},
+			}
+
+			// Create a new server instance with the mock tracking service
+			server := transporthttp.NewServer(&testdata.MockSequenceService{}, transporthttp.WithTrackingService(mockTrackingService))
+
+			// Call the TrackClick method
+			err := server.TrackClick(c)
+
+			// Check if there was an error
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			// Check if the response status code matches the expected status code
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if location := rec.Header().Get(echo.HeaderLocation); location != tt.expectedLocation {
+				t.Errorf("expected location %q, got %q", tt.expectedLocation, location)
+			}
+		})
+	}
+}
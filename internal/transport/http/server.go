@@ -3,11 +3,20 @@ package http
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/cybre/salesforge-assignment/internal/bounce"
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/reply"
 	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/stats"
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	"github.com/cybre/salesforge-assignment/internal/task"
 	"github.com/cybre/salesforge-assignment/pkg/logging"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -15,23 +24,180 @@ import (
 
 // SequenceService represents the service layer for sequences.
 type SequenceService interface {
-	CreateSequence(ctx context.Context, seq sequence.Sequence) error
+	CreateSequence(ctx context.Context, seq sequence.Sequence) (sequence.Sequence, error)
 	PatchSequence(ctx context.Context, patch sequence.SequencePatch) error
 	GetSequence(ctx context.Context, id int) (sequence.Sequence, error)
+	ListSequences(ctx context.Context, query sequence.ListQuery) (sequence.Page, error)
+	DeleteSequence(ctx context.Context, id int) error
+	ArchiveSequence(ctx context.Context, id int) error
+	UnarchiveSequence(ctx context.Context, id int) error
+	SetSchedule(ctx context.Context, id int, schedule *sequence.Schedule) error
+	AddStep(ctx context.Context, sequenceID int, step sequence.Step, position *int) (int, error)
 	UpdateStep(ctx context.Context, step sequence.Step) error
 	DeleteStep(ctx context.Context, id int) error
+	ReorderSteps(ctx context.Context, sequenceID int, stepIDs []int) error
+	PreviewStep(ctx context.Context, stepID int, values map[string]string) (sequence.RenderedStep, error)
+	DeclareWinner(ctx context.Context, stepID int, variantID int) error
+	PublishSequence(ctx context.Context, id int) (sequence.Version, error)
+	ListVersions(ctx context.Context, id int) ([]sequence.Version, error)
+	DiffVersions(ctx context.Context, id int, from int, to int) (sequence.VersionDiff, error)
+}
+
+// ContactService represents the service layer for contacts.
+type ContactService interface {
+	CreateContact(ctx context.Context, c contact.Contact) (contact.Contact, error)
+	GetContact(ctx context.Context, id int) (contact.Contact, error)
+	UpdateContact(ctx context.Context, c contact.Contact) (contact.Contact, error)
+	DeleteContact(ctx context.Context, id int) error
+	ListContacts(ctx context.Context, query contact.ListQuery) (contact.Page, error)
+	ImportContacts(ctx context.Context, r io.Reader, mapping contact.Mapping) (contact.ImportReport, error)
+}
+
+// EnrollmentService represents the service layer for enrollments.
+type EnrollmentService interface {
+	Enroll(ctx context.Context, sequenceID int, contactIDs []int) (enrollment.EnrollResult, error)
+	ListEnrollments(ctx context.Context, query enrollment.ListQuery) (enrollment.Page, error)
+}
+
+// TrackingService represents the service layer for email tracking.
+type TrackingService interface {
+	RecordOpen(ctx context.Context, token string, userAgent string, ip string) error
+	RecordClick(ctx context.Context, token string, userAgent string, ip string) (string, error)
+}
+
+// StatsService represents the service layer for sequence stats.
+type StatsService interface {
+	SequenceStats(ctx context.Context, query stats.Query) (stats.SequenceStats, error)
+}
+
+// SuppressionService represents the service layer for the suppression list and unsubscribing.
+type SuppressionService interface {
+	AddSuppression(ctx context.Context, s suppression.Suppression) (suppression.Suppression, error)
+	DeleteSuppression(ctx context.Context, id int) error
+	ListSuppressions(ctx context.Context, query suppression.ListQuery) (suppression.Page, error)
+	VerifyUnsubscribe(token string) error
+	Unsubscribe(ctx context.Context, token string) error
+}
+
+// BounceService represents the service layer for processing bounces.
+type BounceService interface {
+	ProcessDSN(ctx context.Context, r io.Reader) (bounce.Result, error)
+}
+
+// ReplyService represents the service layer for detecting replies.
+type ReplyService interface {
+	ProcessMessage(ctx context.Context, r io.Reader) (reply.Result, error)
+}
+
+// MailboxService represents the service layer for sender mailboxes.
+type MailboxService interface {
+	CreateMailbox(ctx context.Context, m mailbox.Mailbox) (mailbox.Mailbox, error)
+	GetMailbox(ctx context.Context, id int) (mailbox.Mailbox, error)
+	UpdateMailbox(ctx context.Context, m mailbox.Mailbox) (mailbox.Mailbox, error)
+	DeleteMailbox(ctx context.Context, id int) error
+	ListMailboxes(ctx context.Context, query mailbox.ListQuery) (mailbox.Page, error)
+	SetSequenceMailboxes(ctx context.Context, sequenceID int, ids []int) error
+	ListSequenceMailboxes(ctx context.Context, sequenceID int) ([]mailbox.Mailbox, error)
+}
+
+// TaskService represents the service layer for the tasks of manual steps.
+type TaskService interface {
+	ListTasks(ctx context.Context, query task.ListQuery) (task.Page, error)
+	CompleteTask(ctx context.Context, id int) (task.Task, error)
+	SkipTask(ctx context.Context, id int) (task.Task, error)
 }
 
 // Server contains the REST endpoints.
 type Server struct {
-	sequenceService SequenceService
+	sequenceService    SequenceService
+	contactService     ContactService
+	enrollmentService  EnrollmentService
+	trackingService    TrackingService
+	statsService       StatsService
+	suppressionService SuppressionService
+	bounceService      BounceService
+	replyService       ReplyService
+	mailboxService     MailboxService
+	taskService        TaskService
+}
+
+// Option configures optional dependencies of the server.
+type Option func(*Server)
+
+// WithContactService sets the service used by the contact endpoints.
+func WithContactService(contactService ContactService) Option {
+	return func(s *Server) {
+		s.contactService = contactService
+	}
+}
+
+// WithEnrollmentService sets the service used by the enrollment endpoints.
+func WithEnrollmentService(enrollmentService EnrollmentService) Option {
+	return func(s *Server) {
+		s.enrollmentService = enrollmentService
+	}
+}
+
+// WithTrackingService sets the service used by the tracking endpoints.
+func WithTrackingService(trackingService TrackingService) Option {
+	return func(s *Server) {
+		s.trackingService = trackingService
+	}
+}
+
+// WithStatsService sets the service used by the stats endpoints.
+func WithStatsService(statsService StatsService) Option {
+	return func(s *Server) {
+		s.statsService = statsService
+	}
+}
+
+// WithSuppressionService sets the service used by the suppression list and unsubscribe endpoints.
+func WithSuppressionService(suppressionService SuppressionService) Option {
+	return func(s *Server) {
+		s.suppressionService = suppressionService
+	}
+}
+
+// WithBounceService sets the service used by the inbound delivery status notification endpoint.
+func WithBounceService(bounceService BounceService) Option {
+	return func(s *Server) {
+		s.bounceService = bounceService
+	}
+}
+
+// WithReplyService sets the service used by the inbound reply endpoint.
+func WithReplyService(replyService ReplyService) Option {
+	return func(s *Server) {
+		s.replyService = replyService
+	}
+}
+
+// WithMailboxService sets the service used by the mailbox endpoints.
+func WithMailboxService(mailboxService MailboxService) Option {
+	return func(s *Server) {
+		s.mailboxService = mailboxService
+	}
+}
+
+// WithTaskService sets the service used by the task endpoints.
+func WithTaskService(taskService TaskService) Option {
+	return func(s *Server) {
+		s.taskService = taskService
+	}
 }
 
 // NewServer creates a new server.
-func NewServer(sequenceService SequenceService) *Server {
-	return &Server{
+func NewServer(sequenceService SequenceService, opts ...Option) *Server {
+	s := &Server{
 		sequenceService: sequenceService,
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 // Start starts the HTTP server and closes it when the context is done.
@@ -95,10 +261,79 @@ func (s Server) Start(ctx context.Context, port string) error {
 // RegisterRoutes registers the REST endpoints.
 func (s Server) RegisterRoutes(e *echo.Echo) {
 	e.POST("/sequence", s.CreateSequence)
+	e.GET("/sequence", s.ListSequences)
 	e.PATCH("/sequence/:id", s.PatchSequence)
 	e.GET("/sequence/:id", s.GetSequence)
+	e.DELETE("/sequence/:id", s.DeleteSequence)
+	e.POST("/sequence/:id/archive", s.ArchiveSequence)
+	e.POST("/sequence/:id/unarchive", s.UnarchiveSequence)
+	e.PUT("/sequence/:id/schedule", s.SetSchedule)
+	e.DELETE("/sequence/:id/schedule", s.DeleteSchedule)
+	e.POST("/sequence/:id/publish", s.PublishSequence)
+	e.GET("/sequence/:id/versions", s.ListVersions)
+	e.GET("/sequence/:id/versions/diff", s.DiffVersions)
+	e.POST("/sequence/:id/steps", s.AddStep)
+	e.PUT("/sequence/:id/steps/order", s.ReorderSteps)
 	e.PUT("/step/:id", s.UpdateStep)
 	e.DELETE("/step/:id", s.DeleteStep)
+	e.POST("/step/:id/preview", s.PreviewStep)
+	e.POST("/step/:id/variants/:variantId/winner", s.DeclareWinner)
+
+	if s.contactService != nil {
+		e.POST("/contact", s.CreateContact)
+		e.GET("/contact", s.ListContacts)
+		e.GET("/contact/:id", s.GetContact)
+		e.PUT("/contact/:id", s.UpdateContact)
+		e.DELETE("/contact/:id", s.DeleteContact)
+		e.POST("/contacts/import", s.ImportContacts)
+	}
+
+	if s.enrollmentService != nil {
+		e.POST("/sequence/:id/enrollments", s.EnrollContacts)
+		e.GET("/sequence/:id/enrollments", s.ListEnrollments)
+	}
+
+	if s.statsService != nil {
+		e.GET("/sequence/:id/stats", s.GetSequenceStats)
+	}
+
+	if s.suppressionService != nil {
+		e.POST("/suppression", s.AddSuppression)
+		e.GET("/suppression", s.ListSuppressions)
+		e.DELETE("/suppression/:id", s.DeleteSuppression)
+		e.GET("/u/:token", s.ConfirmUnsubscribe)
+		e.POST("/u/:token", s.Unsubscribe)
+	}
+
+	if s.mailboxService != nil {
+		e.POST("/mailbox", s.CreateMailbox)
+		e.GET("/mailbox", s.ListMailboxes)
+		e.GET("/mailbox/:id", s.GetMailbox)
+		e.PUT("/mailbox/:id", s.UpdateMailbox)
+		e.DELETE("/mailbox/:id", s.DeleteMailbox)
+		e.PUT("/sequence/:id/mailboxes", s.SetSequenceMailboxes)
+		e.GET("/sequence/:id/mailboxes", s.ListSequenceMailboxes)
+	}
+
+	if s.taskService != nil {
+		e.GET("/task", s.ListTasks)
+		e.POST("/task/:id/complete", s.CompleteTask)
+		e.POST("/task/:id/skip", s.SkipTask)
+	}
+
+	if s.bounceService != nil {
+		e.POST("/inbound/dsn", s.ProcessDSN)
+	}
+
+	if s.replyService != nil {
+		e.POST("/inbound/reply", s.ProcessReply)
+	}
+
+	if s.trackingService != nil {
+		e.GET("/t/o/:token", s.TrackOpen)
+		e.GET("/t/c/:token", s.TrackClick)
+	}
+
 	e.GET("/health", func(c echo.Context) error {
 		return c.NoContent(http.StatusOK)
 	})
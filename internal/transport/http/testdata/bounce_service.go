@@ -0,0 +1,16 @@
+package testdata
+
+import (
+	"context"
+	"io"
+
+	"github.com/cybre/salesforge-assignment/internal/bounce"
+)
+
+type MockBounceService struct {
+	ProcessDSNFn func(ctx context.Context, r io.Reader) (bounce.Result, error)
+}
+
+func (m MockBounceService) ProcessDSN(ctx context.Context, r io.Reader) (bounce.Result, error) {
+	return m.ProcessDSNFn(ctx, r)
+}
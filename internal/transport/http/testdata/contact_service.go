@@ -0,0 +1,41 @@
+package testdata
+
+import (
+	"context"
+	"io"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+)
+
+type MockContactService struct {
+	CreateContactFn  func(ctx context.Context, c contact.Contact) (contact.Contact, error)
+	GetContactFn     func(ctx context.Context, id int) (contact.Contact, error)
+	UpdateContactFn  func(ctx context.Context, c contact.Contact) (contact.Contact, error)
+	DeleteContactFn  func(ctx context.Context, id int) error
+	ListContactsFn   func(ctx context.Context, query contact.ListQuery) (contact.Page, error)
+	ImportContactsFn func(ctx context.Context, r io.Reader, mapping contact.Mapping) (contact.ImportReport, error)
+}
+
+func (m MockContactService) CreateContact(ctx context.Context, c contact.Contact) (contact.Contact, error) {
+	return m.CreateContactFn(ctx, c)
+}
+
+func (m MockContactService) GetContact(ctx context.Context, id int) (contact.Contact, error) {
+	return m.GetContactFn(ctx, id)
+}
+
+func (m MockContactService) UpdateContact(ctx context.Context, c contact.Contact) (contact.Contact, error) {
+	return m.UpdateContactFn(ctx, c)
+}
+
+func (m MockContactService) DeleteContact(ctx context.Context, id int) error {
+	return m.DeleteContactFn(ctx, id)
+}
+
+func (m MockContactService) ListContacts(ctx context.Context, query contact.ListQuery) (contact.Page, error) {
+	return m.ListContactsFn(ctx, query)
+}
+
+func (m MockContactService) ImportContacts(ctx context.Context, r io.Reader, mapping contact.Mapping) (contact.ImportReport, error) {
+	return m.ImportContactsFn(ctx, r, mapping)
+}
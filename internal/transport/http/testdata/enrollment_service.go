@@ -0,0 +1,20 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+)
+
+type MockEnrollmentService struct {
+	EnrollFn          func(ctx context.Context, sequenceID int, contactIDs []int) (enrollment.EnrollResult, error)
+	ListEnrollmentsFn func(ctx context.Context, query enrollment.ListQuery) (enrollment.Page, error)
+}
+
+func (m MockEnrollmentService) Enroll(ctx context.Context, sequenceID int, contactIDs []int) (enrollment.EnrollResult, error) {
+	return m.EnrollFn(ctx, sequenceID, contactIDs)
+}
+
+func (m MockEnrollmentService) ListEnrollments(ctx context.Context, query enrollment.ListQuery) (enrollment.Page, error) {
+	return m.ListEnrollmentsFn(ctx, query)
+}
@@ -0,0 +1,45 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+)
+
+type MockMailboxService struct {
+	CreateMailboxFn         func(ctx context.Context, m mailbox.Mailbox) (mailbox.Mailbox, error)
+	GetMailboxFn            func(ctx context.Context, id int) (mailbox.Mailbox, error)
+	UpdateMailboxFn         func(ctx context.Context, m mailbox.Mailbox) (mailbox.Mailbox, error)
+	DeleteMailboxFn         func(ctx context.Context, id int) error
+	ListMailboxesFn         func(ctx context.Context, query mailbox.ListQuery) (mailbox.Page, error)
+	SetSequenceMailboxesFn  func(ctx context.Context, sequenceID int, ids []int) error
+	ListSequenceMailboxesFn func(ctx context.Context, sequenceID int) ([]mailbox.Mailbox, error)
+}
+
+func (m MockMailboxService) CreateMailbox(ctx context.Context, mb mailbox.Mailbox) (mailbox.Mailbox, error) {
+	return m.CreateMailboxFn(ctx, mb)
+}
+
+func (m MockMailboxService) GetMailbox(ctx context.Context, id int) (mailbox.Mailbox, error) {
+	return m.GetMailboxFn(ctx, id)
+}
+
+func (m MockMailboxService) UpdateMailbox(ctx context.Context, mb mailbox.Mailbox) (mailbox.Mailbox, error) {
+	return m.UpdateMailboxFn(ctx, mb)
+}
+
+func (m MockMailboxService) DeleteMailbox(ctx context.Context, id int) error {
+	return m.DeleteMailboxFn(ctx, id)
+}
+
+func (m MockMailboxService) ListMailboxes(ctx context.Context, query mailbox.ListQuery) (mailbox.Page, error) {
+	return m.ListMailboxesFn(ctx, query)
+}
+
+func (m MockMailboxService) SetSequenceMailboxes(ctx context.Context, sequenceID int, ids []int) error {
+	return m.SetSequenceMailboxesFn(ctx, sequenceID, ids)
+}
+
+func (m MockMailboxService) ListSequenceMailboxes(ctx context.Context, sequenceID int) ([]mailbox.Mailbox, error) {
+	return m.ListSequenceMailboxesFn(ctx, sequenceID)
+}
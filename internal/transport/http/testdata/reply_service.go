@@ -0,0 +1,16 @@
+package testdata
+
+import (
+	"context"
+	"io"
+
+	"github.com/cybre/salesforge-assignment/internal/reply"
+)
+
+type MockReplyService struct {
+	ProcessMessageFn func(ctx context.Context, r io.Reader) (reply.Result, error)
+}
+
+func (m MockReplyService) ProcessMessage(ctx context.Context, r io.Reader) (reply.Result, error) {
+	return m.ProcessMessageFn(ctx, r)
+}
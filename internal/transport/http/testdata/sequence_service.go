@@ -7,14 +7,26 @@ import (
 )
 
 type MockSequenceService struct {
-	CreateSequenceFn func(ctx context.Context, seq sequence.Sequence) error
-	PatchSequenceFn  func(ctx context.Context, patch sequence.SequencePatch) error
-	GetSequenceFn    func(ctx context.Context, id int) (sequence.Sequence, error)
-	UpdateStepFn     func(ctx context.Context, step sequence.Step) error
-	DeleteStepFn     func(ctx context.Context, id int) error
+	CreateSequenceFn    func(ctx context.Context, seq sequence.Sequence) (sequence.Sequence, error)
+	PatchSequenceFn     func(ctx context.Context, patch sequence.SequencePatch) error
+	GetSequenceFn       func(ctx context.Context, id int) (sequence.Sequence, error)
+	ListSequencesFn     func(ctx context.Context, query sequence.ListQuery) (sequence.Page, error)
+	DeleteSequenceFn    func(ctx context.Context, id int) error
+	ArchiveSequenceFn   func(ctx context.Context, id int) error
+	UnarchiveSequenceFn func(ctx context.Context, id int) error
+	SetScheduleFn       func(ctx context.Context, id int, schedule *sequence.Schedule) error
+	AddStepFn           func(ctx context.Context, sequenceID int, step sequence.Step, position *int) (int, error)
+	UpdateStepFn        func(ctx context.Context, step sequence.Step) error
+	DeleteStepFn        func(ctx context.Context, id int) error
+	ReorderStepsFn      func(ctx context.Context, sequenceID int, stepIDs []int) error
+	PreviewStepFn       func(ctx context.Context, stepID int, values map[string]string) (sequence.RenderedStep, error)
+	DeclareWinnerFn     func(ctx context.Context, stepID int, variantID int) error
+	PublishSequenceFn   func(ctx context.Context, id int) (sequence.Version, error)
+	ListVersionsFn      func(ctx context.Context, id int) ([]sequence.Version, error)
+	DiffVersionsFn      func(ctx context.Context, id int, from int, to int) (sequence.VersionDiff, error)
 }
 
-func (m MockSequenceService) CreateSequence(ctx context.Context, seq sequence.Sequence) error {
+func (m MockSequenceService) CreateSequence(ctx context.Context, seq sequence.Sequence) (sequence.Sequence, error) {
 	return m.CreateSequenceFn(ctx, seq)
 }
 
@@ -26,6 +38,30 @@ func (m MockSequenceService) GetSequence(ctx context.Context, id int) (sequence.
 	return m.GetSequenceFn(ctx, id)
 }
 
+func (m MockSequenceService) SetSchedule(ctx context.Context, id int, schedule *sequence.Schedule) error {
+	return m.SetScheduleFn(ctx, id, schedule)
+}
+
+func (m MockSequenceService) AddStep(ctx context.Context, sequenceID int, step sequence.Step, position *int) (int, error) {
+	return m.AddStepFn(ctx, sequenceID, step, position)
+}
+
+func (m MockSequenceService) ListSequences(ctx context.Context, query sequence.ListQuery) (sequence.Page, error) {
+	return m.ListSequencesFn(ctx, query)
+}
+
+func (m MockSequenceService) DeleteSequence(ctx context.Context, id int) error {
+	return m.DeleteSequenceFn(ctx, id)
+}
+
+func (m MockSequenceService) ArchiveSequence(ctx context.Context, id int) error {
+	return m.ArchiveSequenceFn(ctx, id)
+}
+
+func (m MockSequenceService) UnarchiveSequence(ctx context.Context, id int) error {
+	return m.UnarchiveSequenceFn(ctx, id)
+}
+
 func (m MockSequenceService) UpdateStep(ctx context.Context, step sequence.Step) error {
 	return m.UpdateStepFn(ctx, step)
 }
@@ -33,3 +69,27 @@ func (m MockSequenceService) UpdateStep(ctx context.Context, step sequence.Step)
 func (m MockSequenceService) DeleteStep(ctx context.Context, id int) error {
 	return m.DeleteStepFn(ctx, id)
 }
+
+func (m MockSequenceService) ReorderSteps(ctx context.Context, sequenceID int, stepIDs []int) error {
+	return m.ReorderStepsFn(ctx, sequenceID, stepIDs)
+}
+
+func (m MockSequenceService) PreviewStep(ctx context.Context, stepID int, values map[string]string) (sequence.RenderedStep, error) {
+	return m.PreviewStepFn(ctx, stepID, values)
+}
+
+func (m MockSequenceService) DeclareWinner(ctx context.Context, stepID int, variantID int) error {
+	return m.DeclareWinnerFn(ctx, stepID, variantID)
+}
+
+func (m MockSequenceService) PublishSequence(ctx context.Context, id int) (sequence.Version, error) {
+	return m.PublishSequenceFn(ctx, id)
+}
+
+func (m MockSequenceService) ListVersions(ctx context.Context, id int) ([]sequence.Version, error) {
+	return m.ListVersionsFn(ctx, id)
+}
+
+func (m MockSequenceService) DiffVersions(ctx context.Context, id int, from int, to int) (sequence.VersionDiff, error) {
+	return m.DiffVersionsFn(ctx, id, from, to)
+}
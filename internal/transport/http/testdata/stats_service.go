@@ -0,0 +1,15 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/stats"
+)
+
+type MockStatsService struct {
+	SequenceStatsFn func(ctx context.Context, query stats.Query) (stats.SequenceStats, error)
+}
+
+func (m MockStatsService) SequenceStats(ctx context.Context, query stats.Query) (stats.SequenceStats, error) {
+	return m.SequenceStatsFn(ctx, query)
+}
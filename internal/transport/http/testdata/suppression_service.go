@@ -0,0 +1,35 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+)
+
+type MockSuppressionService struct {
+	AddSuppressionFn    func(ctx context.Context, s suppression.Suppression) (suppression.Suppression, error)
+	DeleteSuppressionFn func(ctx context.Context, id int) error
+	ListSuppressionsFn  func(ctx context.Context, query suppression.ListQuery) (suppression.Page, error)
+	VerifyUnsubscribeFn func(token string) error
+	UnsubscribeFn       func(ctx context.Context, token string) error
+}
+
+func (m MockSuppressionService) AddSuppression(ctx context.Context, s suppression.Suppression) (suppression.Suppression, error) {
+	return m.AddSuppressionFn(ctx, s)
+}
+
+func (m MockSuppressionService) DeleteSuppression(ctx context.Context, id int) error {
+	return m.DeleteSuppressionFn(ctx, id)
+}
+
+func (m MockSuppressionService) ListSuppressions(ctx context.Context, query suppression.ListQuery) (suppression.Page, error) {
+	return m.ListSuppressionsFn(ctx, query)
+}
+
+func (m MockSuppressionService) VerifyUnsubscribe(token string) error {
+	return m.VerifyUnsubscribeFn(token)
+}
+
+func (m MockSuppressionService) Unsubscribe(ctx context.Context, token string) error {
+	return m.UnsubscribeFn(ctx, token)
+}
@@ -0,0 +1,25 @@
+package testdata
+
+import (
+	"context"
+
+	"github.com/cybre/salesforge-assignment/internal/task"
+)
+
+type MockTaskService struct {
+	ListTasksFn    func(ctx context.Context, query task.ListQuery) (task.Page, error)
+	CompleteTaskFn func(ctx context.Context, id int) (task.Task, error)
+	SkipTaskFn     func(ctx context.Context, id int) (task.Task, error)
+}
+
+func (m MockTaskService) ListTasks(ctx context.Context, query task.ListQuery) (task.Page, error) {
+	return m.ListTasksFn(ctx, query)
+}
+
+func (m MockTaskService) CompleteTask(ctx context.Context, id int) (task.Task, error) {
+	return m.CompleteTaskFn(ctx, id)
+}
+
+func (m MockTaskService) SkipTask(ctx context.Context, id int) (task.Task, error) {
+	return m.SkipTaskFn(ctx, id)
+}
@@ -0,0 +1,18 @@
+package testdata
+
+import (
+	"context"
+)
+
+type MockTrackingService struct {
+	RecordOpenFn  func(ctx context.Context, token string, userAgent string, ip string) error
+	RecordClickFn func(ctx context.Context, token string, userAgent string, ip string) (string, error)
+}
+
+func (m MockTrackingService) RecordOpen(ctx context.Context, token string, userAgent string, ip string) error {
+	return m.RecordOpenFn(ctx, token, userAgent, ip)
+}
+
+func (m MockTrackingService) RecordClick(ctx context.Context, token string, userAgent string, ip string) (string, error) {
+	return m.RecordClickFn(ctx, token, userAgent, ip)
+}
@@ -0,0 +1,2 @@
+ALTER TABLE step DROP CONSTRAINT step_sequence_position_key;
+ALTER TABLE step DROP COLUMN position;
\ No newline at end of file
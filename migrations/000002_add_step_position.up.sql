@@ -0,0 +1,13 @@
+ALTER TABLE step ADD COLUMN position INTEGER;
+
+UPDATE step SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY sequence_id ORDER BY id) - 1 AS position
+    FROM step
+) AS ordered
+WHERE step.id = ordered.id;
+
+ALTER TABLE step ALTER COLUMN position SET NOT NULL;
+
+ALTER TABLE step ADD CONSTRAINT step_sequence_position_key
+    UNIQUE (sequence_id, position) DEFERRABLE INITIALLY DEFERRED;
\ No newline at end of file
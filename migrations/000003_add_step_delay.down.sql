@@ -0,0 +1,3 @@
+ALTER TABLE step
+    DROP COLUMN delay_unit,
+    DROP COLUMN delay_amount;
\ No newline at end of file
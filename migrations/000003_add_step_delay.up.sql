@@ -0,0 +1,3 @@
+ALTER TABLE step
+    ADD COLUMN delay_amount INTEGER NOT NULL DEFAULT 0 CHECK (delay_amount >= 0),
+    ADD COLUMN delay_unit VARCHAR(16) NOT NULL DEFAULT 'days' CHECK (delay_unit IN ('hours', 'days', 'businessDays'));
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE step
+    DROP CONSTRAINT step_sequence_id_fkey,
+    ADD CONSTRAINT step_sequence_id_fkey FOREIGN KEY (sequence_id) REFERENCES sequence (id);
+
+ALTER TABLE sequence DROP COLUMN archived_at;
\ No newline at end of file
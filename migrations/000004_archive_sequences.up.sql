@@ -0,0 +1,5 @@
+ALTER TABLE sequence ADD COLUMN archived_at TIMESTAMPTZ;
+
+ALTER TABLE step
+    DROP CONSTRAINT step_sequence_id_fkey,
+    ADD CONSTRAINT step_sequence_id_fkey FOREIGN KEY (sequence_id) REFERENCES sequence (id) ON DELETE CASCADE;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE contact;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE contact (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(320) NOT NULL,
+    first_name VARCHAR(255) NOT NULL DEFAULT '',
+    last_name VARCHAR(255) NOT NULL DEFAULT '',
+    company VARCHAR(255) NOT NULL DEFAULT '',
+    custom_fields JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT contact_email_key UNIQUE (email)
+);
\ No newline at end of file
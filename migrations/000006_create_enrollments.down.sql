@@ -0,0 +1 @@
+DROP TABLE enrollment;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE enrollment (
+    id SERIAL PRIMARY KEY,
+    sequence_id INTEGER NOT NULL,
+    contact_id INTEGER NOT NULL,
+    state VARCHAR(16) NOT NULL DEFAULT 'active',
+    current_step INTEGER NOT NULL DEFAULT 0,
+    next_send_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT enrollment_sequence_contact_key UNIQUE (sequence_id, contact_id),
+    CONSTRAINT enrollment_state_check CHECK (state IN ('active', 'paused', 'finished', 'replied', 'bounced', 'unsubscribed')),
+    FOREIGN KEY (sequence_id) REFERENCES sequence (id) ON DELETE CASCADE,
+    FOREIGN KEY (contact_id) REFERENCES contact (id) ON DELETE CASCADE
+);
+
+CREATE INDEX enrollment_contact_id_idx ON enrollment (contact_id);
+CREATE INDEX enrollment_due_idx ON enrollment (next_send_at) WHERE state = 'active';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE message;
\ No newline at end of file
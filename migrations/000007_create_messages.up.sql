@@ -0,0 +1,20 @@
+CREATE TABLE message (
+    id SERIAL PRIMARY KEY,
+    enrollment_id INTEGER NOT NULL,
+    sequence_id INTEGER NOT NULL,
+    step_id INTEGER,
+    contact_id INTEGER NOT NULL,
+    subject TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ,
+    CONSTRAINT message_status_check CHECK (status IN ('pending', 'sent', 'failed')),
+    FOREIGN KEY (enrollment_id) REFERENCES enrollment (id) ON DELETE CASCADE,
+    FOREIGN KEY (sequence_id) REFERENCES sequence (id) ON DELETE CASCADE,
+    FOREIGN KEY (step_id) REFERENCES step (id) ON DELETE SET NULL,
+    FOREIGN KEY (contact_id) REFERENCES contact (id) ON DELETE CASCADE
+);
+
+CREATE INDEX message_enrollment_id_idx ON message (enrollment_id);
+CREATE INDEX message_sequence_id_idx ON message (sequence_id);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE message DROP COLUMN message_id;
\ No newline at end of file
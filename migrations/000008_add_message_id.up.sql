@@ -0,0 +1,3 @@
+ALTER TABLE message ADD COLUMN message_id TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX message_message_id_key ON message (message_id) WHERE message_id <> '';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE event;
\ No newline at end of file
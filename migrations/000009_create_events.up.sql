@@ -0,0 +1,17 @@
+CREATE TABLE event (
+    id SERIAL PRIMARY KEY,
+    message_id INTEGER NOT NULL,
+    sequence_id INTEGER NOT NULL,
+    step_id INTEGER,
+    type VARCHAR(16) NOT NULL,
+    bot BOOLEAN NOT NULL DEFAULT FALSE,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT event_type_check CHECK (type IN ('open')),
+    FOREIGN KEY (message_id) REFERENCES message (id) ON DELETE CASCADE,
+    FOREIGN KEY (sequence_id) REFERENCES sequence (id) ON DELETE CASCADE,
+    FOREIGN KEY (step_id) REFERENCES step (id) ON DELETE SET NULL
+);
+
+CREATE INDEX event_message_id_type_idx ON event (message_id, type, created_at);
\ No newline at end of file
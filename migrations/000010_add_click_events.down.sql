@@ -0,0 +1,6 @@
+DELETE FROM event WHERE type = 'click';
+
+ALTER TABLE event DROP CONSTRAINT event_type_check;
+ALTER TABLE event ADD CONSTRAINT event_type_check CHECK (type IN ('open'));
+
+ALTER TABLE event DROP COLUMN url;
\ No newline at end of file
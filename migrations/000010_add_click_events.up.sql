@@ -0,0 +1,4 @@
+ALTER TABLE event ADD COLUMN url TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE event DROP CONSTRAINT event_type_check;
+ALTER TABLE event ADD CONSTRAINT event_type_check CHECK (type IN ('open', 'click'));
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX event_sequence_stats_idx;
+
+DELETE FROM event WHERE type NOT IN ('open', 'click');
+
+ALTER TABLE event DROP CONSTRAINT event_type_check;
+ALTER TABLE event ADD CONSTRAINT event_type_check CHECK (type IN ('open', 'click'));
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE event DROP CONSTRAINT event_type_check;
+ALTER TABLE event ADD CONSTRAINT event_type_check CHECK (type IN ('sent', 'open', 'click', 'reply', 'bounce', 'unsubscribe'));
+
+INSERT INTO event (message_id, sequence_id, step_id, type, created_at)
+SELECT id, sequence_id, step_id, 'sent', sent_at FROM message WHERE status = 'sent';
+
+CREATE INDEX event_sequence_stats_idx ON event (sequence_id, step_id, type, created_at) INCLUDE (message_id) WHERE NOT bot;
\ No newline at end of file
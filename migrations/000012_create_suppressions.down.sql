@@ -0,0 +1 @@
+DROP TABLE suppression;
\ No newline at end of file
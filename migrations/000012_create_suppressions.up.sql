@@ -0,0 +1,10 @@
+CREATE TABLE suppression (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(16) NOT NULL,
+    value TEXT NOT NULL,
+    reason VARCHAR(16) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT suppression_type_check CHECK (type IN ('email', 'domain')),
+    CONSTRAINT suppression_reason_check CHECK (reason IN ('manual', 'unsubscribe')),
+    CONSTRAINT suppression_type_value_key UNIQUE (type, value)
+);
\ No newline at end of file
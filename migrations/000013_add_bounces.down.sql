@@ -0,0 +1,9 @@
+DELETE FROM suppression WHERE reason = 'bounce';
+ALTER TABLE suppression DROP CONSTRAINT suppression_reason_check;
+ALTER TABLE suppression ADD CONSTRAINT suppression_reason_check CHECK (reason IN ('manual', 'unsubscribe'));
+
+DROP INDEX message_enrollment_id_step_id_idx;
+
+ALTER TABLE message DROP CONSTRAINT message_bounce_type_check;
+ALTER TABLE message DROP COLUMN bounced_at;
+ALTER TABLE message DROP COLUMN bounce_type;
\ No newline at end of file
@@ -0,0 +1,8 @@
+ALTER TABLE message ADD COLUMN bounce_type VARCHAR(8);
+ALTER TABLE message ADD COLUMN bounced_at TIMESTAMPTZ;
+ALTER TABLE message ADD CONSTRAINT message_bounce_type_check CHECK (bounce_type IN ('hard', 'soft'));
+
+CREATE INDEX message_enrollment_id_step_id_idx ON message (enrollment_id, step_id) WHERE bounce_type IS NOT NULL;
+
+ALTER TABLE suppression DROP CONSTRAINT suppression_reason_check;
+ALTER TABLE suppression ADD CONSTRAINT suppression_reason_check CHECK (reason IN ('manual', 'unsubscribe', 'bounce'));
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE contact DROP COLUMN time_zone;
+ALTER TABLE sequence DROP COLUMN schedule;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE sequence ADD COLUMN schedule JSONB;
+ALTER TABLE contact ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';
\ No newline at end of file
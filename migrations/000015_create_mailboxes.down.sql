@@ -0,0 +1,6 @@
+ALTER TABLE message DROP COLUMN mailbox_id;
+ALTER TABLE enrollment DROP COLUMN mailbox_id;
+
+DROP TABLE sequence_mailbox;
+DROP TABLE mailbox_usage;
+DROP TABLE mailbox;
\ No newline at end of file
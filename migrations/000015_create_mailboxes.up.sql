@@ -0,0 +1,37 @@
+CREATE TABLE mailbox (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(320) NOT NULL,
+    display_name VARCHAR(255) NOT NULL DEFAULT '',
+    smtp_host TEXT NOT NULL,
+    smtp_port VARCHAR(5) NOT NULL,
+    smtp_username TEXT NOT NULL DEFAULT '',
+    smtp_password BYTEA,
+    smtp_auth VARCHAR(8) NOT NULL,
+    smtp_security VARCHAR(16) NOT NULL,
+    daily_limit INTEGER NOT NULL,
+    ramp_up JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT mailbox_email_key UNIQUE (email)
+);
+
+CREATE TABLE mailbox_usage (
+    mailbox_id INTEGER NOT NULL,
+    day DATE NOT NULL,
+    sent INTEGER NOT NULL,
+    PRIMARY KEY (mailbox_id, day),
+    FOREIGN KEY (mailbox_id) REFERENCES mailbox (id) ON DELETE CASCADE
+);
+
+CREATE TABLE sequence_mailbox (
+    sequence_id INTEGER NOT NULL,
+    mailbox_id INTEGER NOT NULL,
+    PRIMARY KEY (sequence_id, mailbox_id),
+    FOREIGN KEY (sequence_id) REFERENCES sequence (id) ON DELETE CASCADE,
+    FOREIGN KEY (mailbox_id) REFERENCES mailbox (id) ON DELETE CASCADE
+);
+
+CREATE INDEX sequence_mailbox_mailbox_id_idx ON sequence_mailbox (mailbox_id);
+
+ALTER TABLE enrollment ADD COLUMN mailbox_id INTEGER REFERENCES mailbox (id) ON DELETE SET NULL;
+ALTER TABLE message ADD COLUMN mailbox_id INTEGER REFERENCES mailbox (id) ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE message DROP COLUMN variant_id;
+
+DROP TABLE step_variant;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE step_variant (
+    id SERIAL PRIMARY KEY,
+    step_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    subject TEXT NOT NULL,
+    content TEXT NOT NULL,
+    weight INTEGER NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    FOREIGN KEY (step_id) REFERENCES step (id) ON DELETE CASCADE
+);
+
+CREATE INDEX step_variant_step_id_idx ON step_variant (step_id, position);
+
+ALTER TABLE message ADD COLUMN variant_id INTEGER REFERENCES step_variant (id) ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE step DROP COLUMN else_step_id;
+ALTER TABLE step DROP COLUMN then_step_id;
+ALTER TABLE step DROP COLUMN branch_condition;
+ALTER TABLE step DROP COLUMN kind;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE step ADD COLUMN kind VARCHAR(16) NOT NULL DEFAULT 'email';
+ALTER TABLE step ADD COLUMN branch_condition VARCHAR(16);
+ALTER TABLE step ADD COLUMN then_step_id INTEGER REFERENCES step (id) ON DELETE SET NULL;
+ALTER TABLE step ADD COLUMN else_step_id INTEGER REFERENCES step (id) ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TABLE task;
+
+ALTER TABLE step DROP COLUMN due_in_unit;
+ALTER TABLE step DROP COLUMN due_in_amount;
+ALTER TABLE step DROP COLUMN assignee;
\ No newline at end of file
@@ -0,0 +1,27 @@
+ALTER TABLE step ADD COLUMN assignee VARCHAR(254);
+ALTER TABLE step ADD COLUMN due_in_amount INTEGER;
+ALTER TABLE step ADD COLUMN due_in_unit VARCHAR(16);
+
+CREATE TABLE task (
+    id SERIAL PRIMARY KEY,
+    enrollment_id INTEGER NOT NULL,
+    sequence_id INTEGER NOT NULL,
+    step_id INTEGER,
+    contact_id INTEGER NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    assignee VARCHAR(254) NOT NULL,
+    instructions TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    due_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMPTZ,
+    CONSTRAINT task_enrollment_step_key UNIQUE (enrollment_id, step_id),
+    CONSTRAINT task_status_check CHECK (status IN ('open', 'completed', 'skipped')),
+    FOREIGN KEY (enrollment_id) REFERENCES enrollment (id) ON DELETE CASCADE,
+    FOREIGN KEY (sequence_id) REFERENCES sequence (id) ON DELETE CASCADE,
+    FOREIGN KEY (step_id) REFERENCES step (id) ON DELETE SET NULL,
+    FOREIGN KEY (contact_id) REFERENCES contact (id) ON DELETE CASCADE
+);
+
+CREATE INDEX task_assignee_idx ON task (assignee, status);
+CREATE INDEX task_sequence_id_idx ON task (sequence_id);
\ No newline at end of file
@@ -0,0 +1,13 @@
+UPDATE task SET step_id = NULL WHERE step_id NOT IN (SELECT id FROM step);
+UPDATE event SET step_id = NULL WHERE step_id NOT IN (SELECT id FROM step);
+UPDATE message SET variant_id = NULL WHERE variant_id NOT IN (SELECT id FROM step_variant);
+UPDATE message SET step_id = NULL WHERE step_id NOT IN (SELECT id FROM step);
+
+ALTER TABLE task ADD CONSTRAINT task_step_id_fkey FOREIGN KEY (step_id) REFERENCES step (id) ON DELETE SET NULL;
+ALTER TABLE event ADD CONSTRAINT event_step_id_fkey FOREIGN KEY (step_id) REFERENCES step (id) ON DELETE SET NULL;
+ALTER TABLE message ADD CONSTRAINT message_variant_id_fkey FOREIGN KEY (variant_id) REFERENCES step_variant (id) ON DELETE SET NULL;
+ALTER TABLE message ADD CONSTRAINT message_step_id_fkey FOREIGN KEY (step_id) REFERENCES step (id) ON DELETE SET NULL;
+
+ALTER TABLE enrollment DROP COLUMN version;
+
+DROP TABLE sequence_version;
\ No newline at end of file
@@ -0,0 +1,16 @@
+CREATE TABLE sequence_version (
+    id SERIAL PRIMARY KEY,
+    sequence_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    steps JSONB NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT sequence_version_sequence_version_key UNIQUE (sequence_id, version),
+    FOREIGN KEY (sequence_id) REFERENCES sequence (id) ON DELETE CASCADE
+);
+
+ALTER TABLE enrollment ADD COLUMN version INTEGER;
+
+ALTER TABLE message DROP CONSTRAINT message_step_id_fkey;
+ALTER TABLE message DROP CONSTRAINT message_variant_id_fkey;
+ALTER TABLE event DROP CONSTRAINT event_step_id_fkey;
+ALTER TABLE task DROP CONSTRAINT task_step_id_fkey;
\ No newline at end of file
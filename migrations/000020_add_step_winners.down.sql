@@ -0,0 +1 @@
+DROP TABLE step_winner;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE step_winner (
+    step_id INTEGER PRIMARY KEY,
+    sequence_id INTEGER NOT NULL,
+    variant_id INTEGER NOT NULL,
+    declared_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (sequence_id) REFERENCES sequence (id) ON DELETE CASCADE
+);
\ No newline at end of file
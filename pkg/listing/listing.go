@@ -0,0 +1,88 @@
+// Package listing pages through listings in order of ID with opaque cursors.
+package listing
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultLimit is the page size used when a list query does not set one.
+	DefaultLimit = 50
+
+	// MaxLimit is the largest page size a list query may request.
+	MaxLimit = 500
+)
+
+// Filter is the page of a listing as understood by repositories.
+type Filter struct {
+	// AfterID is the ID of the last item of the previous page, or zero for the first page.
+	AfterID int
+	// Limit is the number of items to fetch, which is one more than the page size to find out
+	// whether there is a next page.
+	Limit int
+}
+
+// NewFilter validates the page size and cursor of a list query and returns the filter for
+// fetching its page. The page size defaults to DefaultLimit when it is zero.
+func NewFilter(limit int, cursor string) (Filter, error) {
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	if limit < 1 || limit > MaxLimit {
+		return Filter{}, fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+	}
+
+	filter := Filter{Limit: limit + 1}
+	if cursor != "" {
+		id, err := DecodeCursor(cursor)
+		if err != nil {
+			return Filter{}, err
+		}
+
+		filter.AfterID = id
+	}
+
+	return filter, nil
+}
+
+// Trim trims the items fetched with a filter to the page size and returns them with the cursor
+// for the next page, which is empty on the last page.
+func Trim[T any](items []T, filter Filter, id func(T) int) ([]T, string) {
+	size := filter.Limit - 1
+	if len(items) <= size {
+		return items, ""
+	}
+
+	items = items[:size]
+	return items, EncodeCursor(id(items[size-1]))
+}
+
+// EncodeCursor encodes the ID of the last item of a page into an opaque cursor.
+func EncodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+// DecodeCursor decodes a cursor created with EncodeCursor.
+func DecodeCursor(s string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errors.New("cursor is malformed")
+	}
+
+	id, err := strconv.Atoi(string(data))
+	if err != nil || id < 1 {
+		return 0, errors.New("cursor is malformed")
+	}
+
+	return id, nil
+}
+
+// EscapeLike escapes the wildcard characters of a LIKE pattern.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
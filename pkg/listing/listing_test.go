@@ -0,0 +1,95 @@
+package listing_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/pkg/listing"
+)
+
+func TestNewFilter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		limit         int
+		cursor        string
+		expected      listing.Filter
+		expectedError bool
+	}{
+		{
+			name:     "Default limit",
+			expected: listing.Filter{Limit: listing.DefaultLimit + 1},
+		},
+		{
+			name:     "Next page",
+			limit:    10,
+			cursor:   listing.EncodeCursor(42),
+			expected: listing.Filter{AfterID: 42, Limit: 11},
+		},
+		{
+			name:          "Limit too large",
+			limit:         listing.MaxLimit + 1,
+			expectedError: true,
+		},
+		{
+			name:          "Negative limit",
+			limit:         -1,
+			expectedError: true,
+		},
+		{
+			name:          "Malformed cursor",
+			cursor:        "not a cursor",
+			expectedError: true,
+		},
+		{
+			name:          "Cursor of an invalid ID",
+			cursor:        listing.EncodeCursor(0),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := listing.NewFilter(tc.limit, tc.cursor)
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("Expected error, got: nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if filter != tc.expected {
+				t.Errorf("Expected filter: %+v, got: %+v", tc.expected, filter)
+			}
+		})
+	}
+}
+
+func TestTrim(t *testing.T) {
+	id := func(i int) int { return i }
+	filter := listing.Filter{Limit: 3}
+
+	items, cursor := listing.Trim([]int{1, 2, 3}, filter, id)
+	if !reflect.DeepEqual(items, []int{1, 2}) {
+		t.Errorf("Expected items: %v, got: %v", []int{1, 2}, items)
+	}
+
+	if after, err := listing.DecodeCursor(cursor); err != nil || after != 2 {
+		t.Errorf("Expected cursor after 2, got: %d, %v", after, err)
+	}
+
+	// The last page has no cursor
+	items, cursor = listing.Trim([]int{4, 5}, filter, id)
+	if !reflect.DeepEqual(items, []int{4, 5}) || cursor != "" {
+		t.Errorf("Expected last page, got: %v with cursor %q", items, cursor)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if escaped := listing.EscapeLike(`50%_off\`); escaped != `50\%\_off\\` {
+		t.Errorf("Expected %q, got: %q", `50\%\_off\\`, escaped)
+	}
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/bounce"
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+)
+
+func TestBounces(t *testing.T) {
+	ts := NewTestServer(t)
+	ctx := context.Background()
+
+	createSequence(ts, t)
+	jane := createContact(ts, t, "jane@example.com")
+	john := createContact(ts, t, "john@example.com")
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID, john.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	s := scheduler.NewScheduler(ts.SchedulerRepository, ts.Repository, ts.ContactRepository, mailer, scheduler.Config{From: "sales@example.com"})
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("failed to process due enrollments: %v", err)
+	}
+
+	messageIDs := map[string]string{}
+	for _, msg := range mailer.Messages() {
+		messageIDs[msg.To] = msg.MessageID
+	}
+
+	if len(messageIDs) != 2 {
+		t.Fatalf("expected 2 emails, but got %d", len(messageIDs))
+	}
+
+	// A full mailbox resends the step later
+	result := processDSN(ts, t, deliveryStatusNotification(john.Email, messageIDs[john.Email], "5.2.2"))
+	if r := result.Recipients[0]; r.Outcome != bounce.OutcomeSoftBounce || r.RetryAt == nil {
+		t.Errorf("expected the step to be resent, but got %+v", r)
+	}
+
+	// An unknown address stops the enrollment and suppresses the address
+	notification := deliveryStatusNotification(jane.Email, messageIDs[jane.Email], "5.1.1")
+	if r := processDSN(ts, t, notification).Recipients[0]; r.Outcome != bounce.OutcomeHardBounce {
+		t.Errorf("expected a hard bounce, but got %+v", r)
+	}
+
+	if r := processDSN(ts, t, notification).Recipients[0]; r.Outcome != bounce.OutcomeDuplicate {
+		t.Errorf("expected the notification to be processed once, but got %+v", r)
+	}
+
+	states := enrollmentStates(ts, t)
+	if states[jane.ID] != enrollment.StateBounced || states[john.ID] != enrollment.StateActive {
+		t.Errorf("expected only Jane's enrollment to be bounced, but got %v", states)
+	}
+
+	res := ts.ListSuppressions(t, url.Values{})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var page suppression.Page
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(page.Suppressions) != 1 || page.Suppressions[0].Value != jane.Email || page.Suppressions[0].Reason != suppression.ReasonBounce {
+		t.Errorf("expected Jane to be suppressed after bouncing, but got %+v", page.Suppressions)
+	}
+
+	if res := ts.ProcessDSN(t, "Subject: Out of office\r\n\r\nBack on Monday.\r\n"); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, but got %d", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func processDSN(ts *TestServer, t *testing.T, message string) bounce.Result {
+	res := ts.ProcessDSN(t, message)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var result bounce.Result
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(result.Recipients) != 1 {
+		t.Fatalf("expected 1 recipient, but got %d", len(result.Recipients))
+	}
+
+	return result
+}
+
+// deliveryStatusNotification builds a notification of a failed delivery of a sent message.
+func deliveryStatusNotification(recipient, messageID, status string) string {
+	return strings.ReplaceAll(fmt.Sprintf(`From: Mail Delivery System <MAILER-DAEMON@mx.example.net>
+To: sales@example.com
+Subject: Undelivered Mail Returned to Sender
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=delivery-status; boundary="report"
+
+--report
+Content-Type: text/plain
+
+Your message could not be delivered.
+
+--report
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.example.net
+
+Final-Recipient: rfc822; %s
+Action: failed
+Status: %s
+
+--report
+Content-Type: text/rfc822-headers
+
+Message-ID: <%s>
+From: sales@example.com
+To: %s
+
+--report--
+`, recipient, status, messageID, recipient), "\n", "\r\n")
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+)
+
+func TestCreateContact(t *testing.T) {
+	ts := NewTestServer(t)
+
+	res := ts.CreateContact(t, transporthttp.CreateContactRequest{
+		Email:        " Jane.Doe@Example.com ",
+		FirstName:    "Jane",
+		LastName:     "Doe",
+		Company:      "Acme",
+		CustomFields: map[string]any{"plan": "Pro"},
+	})
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, but got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	if location := res.Header.Get("Location"); location != "/contact/1" {
+		t.Errorf("expected location to be /contact/1, but got %s", location)
+	}
+
+	// Check if the contact was stored with a normalized email
+	c, found, err := ts.ContactRepository.GetContact(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("failed to fetch contact from the database: %v", err)
+	}
+
+	if !found {
+		t.Fatalf("expected contact to be found in the database")
+	}
+
+	if c.Email != "jane.doe@example.com" {
+		t.Errorf("expected email to be jane.doe@example.com, but got %s", c.Email)
+	}
+
+	if c.CustomFields["plan"] != "Pro" {
+		t.Errorf("expected custom field plan to be Pro, but got %v", c.CustomFields["plan"])
+	}
+
+	// Creating a contact with the same email should conflict
+	res = ts.CreateContact(t, transporthttp.CreateContactRequest{Email: "JANE.DOE@example.com"})
+	if res.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, but got %d", http.StatusConflict, res.StatusCode)
+	}
+
+	// Creating a contact with an invalid email should be rejected
+	res = ts.CreateContact(t, transporthttp.CreateContactRequest{Email: "jane"})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, but got %d", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestUpdateAndDeleteContact(t *testing.T) {
+	ts := NewTestServer(t)
+
+	created, err := ts.ContactRepository.CreateContact(context.Background(), contact.Contact{Email: "jane@example.com", CustomFields: map[string]any{}})
+	if err != nil {
+		t.Fatalf("failed to create contact: %v", err)
+	}
+
+	res := ts.UpdateContact(t, transporthttp.UpdateContactRequest{ID: created.ID, Email: "jane@acme.com", Company: "Acme"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	res = ts.GetContact(t, created.ID)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var c contact.Contact
+	if err := json.NewDecoder(res.Body).Decode(&c); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if c.Email != "jane@acme.com" || c.Company != "Acme" {
+		t.Errorf("expected updated contact, but got %+v", c)
+	}
+
+	res = ts.DeleteContact(t, created.ID)
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("expected status code %d, but got %d", http.StatusNoContent, res.StatusCode)
+	}
+
+	res = ts.GetContact(t, created.ID)
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestListContacts(t *testing.T) {
+	ts := NewTestServer(t)
+
+	for _, email := range []string{"jane@acme.com", "john@acme.com", "mary@globex.com"} {
+		if _, err := ts.ContactRepository.CreateContact(context.Background(), contact.Contact{Email: email, CustomFields: map[string]any{}}); err != nil {
+			t.Fatalf("failed to create contact: %v", err)
+		}
+	}
+
+	res := ts.ListContacts(t, url.Values{"search": {"acme"}, "limit": {"1"}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var page contact.Page
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if len(page.Contacts) != 1 || page.Contacts[0].Email != "jane@acme.com" || page.NextCursor == "" {
+		t.Fatalf("expected first page with jane@acme.com and a next cursor, but got %+v", page)
+	}
+
+	res = ts.ListContacts(t, url.Values{"search": {"acme"}, "limit": {"1"}, "cursor": {page.NextCursor}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	page = contact.Page{}
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if len(page.Contacts) != 1 || page.Contacts[0].Email != "john@acme.com" {
+		t.Fatalf("expected second page with john@acme.com, but got %+v", page)
+	}
+}
+
+func TestImportContacts(t *testing.T) {
+	ts := NewTestServer(t)
+
+	existing, err := ts.ContactRepository.CreateContact(context.Background(), contact.Contact{
+		Email:        "john@acme.com",
+		FirstName:    "John",
+		Company:      "Acme",
+		CustomFields: map[string]any{"source": "website"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create contact: %v", err)
+	}
+
+	csv := "E-mail,First name,Company,Plan\n" +
+		"jane@acme.com,Jane,Acme,Pro\n" +
+		"JOHN@acme.com,Johnny,,Free\n" +
+		"nobody,,,\n"
+
+	res := ts.ImportContacts(t, csv, contact.Mapping{"E-mail": "email", "First name": "firstName", "Company": "company", "Plan": "custom.plan"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var report contact.ImportReport
+	if err := json.NewDecoder(res.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if report.Created != 1 || report.Updated != 1 || report.Skipped != 1 || len(report.Errors) != 1 || report.Errors[0].Row != 4 {
+		t.Fatalf("unexpected import report: %+v", report)
+	}
+
+	// The existing contact should keep its company and merge the custom fields
+	c, _, err := ts.ContactRepository.GetContact(context.Background(), existing.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch contact from the database: %v", err)
+	}
+
+	if c.FirstName != "Johnny" || c.Company != "Acme" {
+		t.Errorf("expected first name Johnny and company Acme, but got %+v", c)
+	}
+
+	if c.CustomFields["source"] != "website" || c.CustomFields["plan"] != "Free" {
+		t.Errorf("expected merged custom fields, but got %v", c.CustomFields)
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/contact"
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+)
+
+func TestEnrollContacts(t *testing.T) {
+	ts := NewTestServer(t)
+
+	// Create a sequence and two contacts
+	createSequence(ts, t)
+	jane := createContact(ts, t, "jane@example.com")
+	john := createContact(ts, t, "john@example.com")
+
+	res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID, 999}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var result enrollment.EnrollResult
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if len(result.Enrolled) != 1 || result.Enrolled[0].ContactID != jane.ID || result.Enrolled[0].State != enrollment.StateActive || result.Enrolled[0].NextSendAt == nil {
+		t.Errorf("expected jane to be enrolled, but got %+v", result.Enrolled)
+	}
+
+	if len(result.Skipped) != 1 || result.Skipped[0].Reason != enrollment.SkipContactNotFound {
+		t.Errorf("expected contact 999 to be skipped, but got %+v", result.Skipped)
+	}
+
+	// Enrolling again should skip contacts that are already enrolled
+	res = ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID, john.ID}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	result = enrollment.EnrollResult{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if len(result.Enrolled) != 1 || result.Enrolled[0].ContactID != john.ID {
+		t.Errorf("expected john to be enrolled, but got %+v", result.Enrolled)
+	}
+
+	if len(result.Skipped) != 1 || result.Skipped[0].Reason != enrollment.SkipAlreadyEnrolled {
+		t.Errorf("expected jane to be skipped, but got %+v", result.Skipped)
+	}
+
+	// Both enrollments should be listed
+	res = ts.ListEnrollments(t, 1, url.Values{"state": {"active"}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var page enrollment.Page
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if len(page.Enrollments) != 2 {
+		t.Errorf("expected 2 enrollments, but got %d", len(page.Enrollments))
+	}
+}
+
+func TestEnrollContactsRefusesUnsendableSequences(t *testing.T) {
+	ts := NewTestServer(t)
+
+	createSequence(ts, t)
+	jane := createContact(ts, t, "jane@example.com")
+
+	// Remove every step of the sequence
+	for _, id := range []int{1, 2} {
+		if res := ts.DeleteStep(t, id); res.StatusCode != http.StatusNoContent {
+			t.Fatalf("expected status code %d, but got %d", http.StatusNoContent, res.StatusCode)
+		}
+	}
+
+	res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID}})
+	if res.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, but got %d", http.StatusConflict, res.StatusCode)
+	}
+
+	// Archived sequences cannot be enrolled into either
+	createSequence(ts, t)
+	if res := ts.ArchiveSequence(t, 2); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	res = ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 2, ContactIDs: []int{jane.ID}})
+	if res.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, but got %d", http.StatusConflict, res.StatusCode)
+	}
+}
+
+func createContact(ts *TestServer, t *testing.T, email string) contact.Contact {
+	c, err := ts.ContactRepository.CreateContact(context.Background(), contact.Contact{Email: email, CustomFields: map[string]any{}})
+	if err != nil {
+		t.Fatalf("failed to create contact: %v", err)
+	}
+
+	return c
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+)
+
+func TestMailboxRotation(t *testing.T) {
+	ts := NewTestServer(t)
+	ctx := context.Background()
+
+	createSequence(ts, t)
+	contacts := map[int]string{}
+	for _, email := range []string{"jane@example.com", "john@example.com", "jim@example.com"} {
+		c := createContact(ts, t, email)
+		contacts[c.ID] = email
+	}
+
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{1, 2, 3}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	alice := createMailbox(ts, t, transporthttp.CreateMailboxRequest{
+		Email:       "alice@sales.example",
+		DisplayName: "Alice",
+		SMTP:        mailbox.SMTP{Host: "smtp.sales.example", Port: "587", Username: "alice", Password: "alice-secret", Auth: mail.SMTPAuthPlain},
+		DailyLimit:  1,
+	})
+	bob := createMailbox(ts, t, transporthttp.CreateMailboxRequest{
+		Email:       "bob@sales.example",
+		DisplayName: "Bob",
+		SMTP:        mailbox.SMTP{Host: "smtp.sales.example", Port: "587"},
+		DailyLimit:  1,
+	})
+
+	if res := ts.CreateMailbox(t, transporthttp.CreateMailboxRequest{Email: "Alice@sales.example", SMTP: mailbox.SMTP{Host: "smtp.sales.example", Port: "587"}, DailyLimit: 1}); res.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, but got %d", http.StatusConflict, res.StatusCode)
+	}
+
+	if res := ts.SetSequenceMailboxes(t, transporthttp.SetSequenceMailboxesRequest{ID: 1, MailboxIDs: []int{alice.ID, 99}}); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, but got %d", http.StatusBadRequest, res.StatusCode)
+	}
+
+	if res := ts.SetSequenceMailboxes(t, transporthttp.SetSequenceMailboxesRequest{ID: 1, MailboxIDs: []int{alice.ID, bob.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	mailboxService := mailbox.NewService(ts.MailboxRepository, ts.Repository)
+	s := scheduler.NewScheduler(ts.SchedulerRepository, ts.Repository, ts.ContactRepository, mailer, scheduler.Config{From: "sales@example.com"},
+		scheduler.WithMailboxes(mailboxService, mailbox.NewMailers(mail.Config{Driver: mail.DriverCapture}, mailer)),
+	)
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("failed to process due enrollments: %v", err)
+	}
+
+	// Each mailbox sends one email and the third contact waits for tomorrow
+	sent := mailer.Messages()
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 emails, but got %d", len(sent))
+	}
+
+	senders := map[string]bool{}
+	for _, msg := range sent {
+		senders[msg.From] = true
+	}
+
+	if !senders[`"Alice" <alice@sales.example>`] || !senders[`"Bob" <bob@sales.example>`] {
+		t.Errorf("expected one email from each mailbox, but got %v", senders)
+	}
+
+	res := ts.ListEnrollments(t, 1, url.Values{})
+	var page enrollment.Page
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	tomorrow := mailbox.NextDay(time.Now())
+	waiting := 0
+	for _, e := range page.Enrollments {
+		if e.MailboxID == nil {
+			waiting++
+			if e.State != enrollment.StateActive || e.CurrentStep != 0 || e.NextSendAt == nil || e.NextSendAt.Before(tomorrow) {
+				t.Errorf("expected the enrollment of %s to wait for tomorrow, but got %+v", contacts[e.ContactID], e)
+			}
+		} else if e.CurrentStep != 1 {
+			t.Errorf("expected the enrollment of %s to advance, but got %+v", contacts[e.ContactID], e)
+		}
+	}
+
+	if waiting != 1 {
+		t.Errorf("expected one enrollment without a mailbox, but got %d", waiting)
+	}
+
+	res = ts.GetMailbox(t, alice.ID)
+	body, _ := io.ReadAll(res.Body)
+	if strings.Contains(string(body), "alice-secret") {
+		t.Errorf("expected the password not to be returned, but got %s", body)
+	}
+
+	var got mailbox.Mailbox
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if got.Capacity == nil || got.Capacity.Sent != 1 || got.Capacity.Remaining != 0 {
+		t.Errorf("expected the capacity of Alice to be used up, but got %+v", got.Capacity)
+	}
+}
+
+func createMailbox(ts *TestServer, t *testing.T, request transporthttp.CreateMailboxRequest) mailbox.Mailbox {
+	res := ts.CreateMailbox(t, request)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, but got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	var m mailbox.Mailbox
+	if err := json.NewDecoder(res.Body).Decode(&m); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	return m
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/reply"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+	"github.com/cybre/salesforge-assignment/internal/stats"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+)
+
+func TestReplies(t *testing.T) {
+	ts := NewTestServer(t)
+	ctx := context.Background()
+
+	createSequence(ts, t)
+	jane := createContact(ts, t, "jane@example.com")
+	john := createContact(ts, t, "john@example.com")
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID, john.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	s := scheduler.NewScheduler(ts.SchedulerRepository, ts.Repository, ts.ContactRepository, mailer, scheduler.Config{From: "sales@example.com"})
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("failed to process due enrollments: %v", err)
+	}
+
+	messageIDs := map[string]string{}
+	for _, msg := range mailer.Messages() {
+		messageIDs[msg.To] = msg.MessageID
+	}
+
+	if len(messageIDs) != 2 {
+		t.Fatalf("expected 2 emails, but got %d", len(messageIDs))
+	}
+
+	// John's out of office reply does not stop the sequence
+	outOfOffice := fmt.Sprintf("From: %s\r\nAuto-Submitted: auto-replied\r\nIn-Reply-To: <%s>\r\n\r\nAway until Monday.\r\n", john.Email, messageIDs[john.Email])
+	if result := processReply(ts, t, outOfOffice); result.Outcome != reply.OutcomeIgnored {
+		t.Errorf("expected the out of office reply to be ignored, but got %+v", result)
+	}
+
+	message := fmt.Sprintf("From: %s\r\nSubject: Re: Test Subject 1\r\nIn-Reply-To: <%s>\r\nReferences: <%s>\r\n\r\nLet's talk.\r\n", jane.Email, messageIDs[jane.Email], messageIDs[jane.Email])
+	if result := processReply(ts, t, message); result.Outcome != reply.OutcomeReplied {
+		t.Errorf("expected a reply, but got %+v", result)
+	}
+
+	if result := processReply(ts, t, message); result.Outcome != reply.OutcomeDuplicate {
+		t.Errorf("expected the reply to be processed once, but got %+v", result)
+	}
+
+	states := enrollmentStates(ts, t)
+	if states[jane.ID] != enrollment.StateReplied || states[john.ID] != enrollment.StateActive {
+		t.Errorf("expected only Jane's enrollment to be replied, but got %v", states)
+	}
+
+	res := ts.GetSequenceStats(t, 1, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var sequenceStats stats.SequenceStats
+	if err := json.NewDecoder(res.Body).Decode(&sequenceStats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if sequenceStats.Replied != 1 || sequenceStats.ReplyRate != 0.5 {
+		t.Errorf("expected 1 reply at a rate of 0.5, but got %d at %v", sequenceStats.Replied, sequenceStats.ReplyRate)
+	}
+
+	if res := ts.ProcessReply(t, "not a message"); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, but got %d", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func processReply(ts *TestServer, t *testing.T, message string) reply.Result {
+	res := ts.ProcessReply(t, message)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var result reply.Result
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	return result
+}
@@ -0,0 +1,427 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/task"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+)
+
+func TestSchedulerSendsDueSteps(t *testing.T) {
+	ts := NewTestServer(t)
+	ctx := context.Background()
+
+	// The first step of the sequence has no delay, so it is due right after enrolling
+	createSequence(ts, t)
+	jane := createContact(ts, t, "jane@example.com")
+
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	// Two schedulers share the work without sending any step twice
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	config := scheduler.Config{From: "sales@example.com"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			s := scheduler.NewScheduler(ts.SchedulerRepository, ts.Repository, ts.ContactRepository, mailer, config)
+			if _, err := s.ProcessDue(ctx); err != nil {
+				t.Errorf("failed to process due enrollments: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	sent := mailer.Messages()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 email to be sent, but got %d", len(sent))
+	}
+
+	if msg := sent[0]; msg.To != jane.Email || msg.From != "sales@example.com" || msg.Subject != "Test Subject 1" || msg.HTML != "<p>Test Content 1</p>\n" {
+		t.Errorf("unexpected email: %+v", msg)
+	}
+
+	if raw := string(sent[0].Raw); !strings.Contains(raw, "Message-ID: <"+sent[0].MessageID+">") || !strings.Contains(raw, "multipart/alternative") {
+		t.Errorf("expected a multipart email with a message id, but got %q", raw)
+	}
+
+	// The enrollment waits for the second step
+	res := ts.ListEnrollments(t, 1, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var page enrollment.Page
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if len(page.Enrollments) != 1 {
+		t.Fatalf("expected 1 enrollment, but got %d", len(page.Enrollments))
+	}
+
+	e := page.Enrollments[0]
+	if e.State != enrollment.StateActive || e.CurrentStep != 1 || e.NextSendAt == nil || !e.NextSendAt.After(time.Now().Add(24*time.Hour)) {
+		t.Errorf("expected enrollment to wait for the second step, but got %+v", e)
+	}
+}
+
+func TestManualSteps(t *testing.T) {
+	ts := NewTestServer(t)
+	ctx := context.Background()
+
+	res := ts.CreateSequence(t, transporthttp.CreateSequenceRequest{
+		Name: "Manual Sequence",
+		Steps: []transporthttp.CreateSequenceRequestStep{
+			{
+				Kind:       sequence.StepKindCall,
+				Content:    "Introduce yourself to {{email}}",
+				Assignment: &sequence.Assignment{Assignee: "sam@example.com", DueIn: sequence.Delay{Amount: 1}},
+			},
+			{Subject: "Follow-up", Content: "Great talking to you"},
+		},
+	})
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, but got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	jane := createContact(ts, t, "jane@example.com")
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	s := scheduler.NewScheduler(ts.SchedulerRepository, ts.Repository, ts.ContactRepository, mailer, scheduler.Config{From: "sales@example.com"})
+	listTasks := func() []task.Task {
+		res := ts.ListTasks(t, url.Values{"assignee": {"sam@example.com"}})
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var page task.Page
+		if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+			t.Fatalf("failed to decode response body: %v", err)
+		}
+
+		return page.Tasks
+	}
+
+	// Reaching the call step creates a task instead of sending an email, and processing the
+	// enrollment again does not create another one
+	for i := 0; i < 2; i++ {
+		if _, err := s.ProcessDue(ctx); err != nil {
+			t.Fatalf("failed to process due enrollments: %v", err)
+		}
+	}
+
+	if sent := mailer.Messages(); len(sent) != 0 {
+		t.Fatalf("expected no emails to be sent, but got %d", len(sent))
+	}
+
+	tasks := listTasks()
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task, but got %d", len(tasks))
+	}
+
+	if call := tasks[0]; call.Kind != sequence.StepKindCall || call.Status != task.StatusOpen || call.Instructions != "Introduce yourself to jane@example.com" || !call.DueAt.After(time.Now().Add(23*time.Hour)) {
+		t.Errorf("unexpected task: %+v", call)
+	}
+
+	// The enrollment continues once the task is completed
+	if res := ts.CompleteTask(t, tasks[0].ID); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	if res := ts.CompleteTask(t, tasks[0].ID); res.StatusCode != http.StatusConflict {
+		t.Fatalf("expected status code %d, but got %d", http.StatusConflict, res.StatusCode)
+	}
+
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("failed to process due enrollments: %v", err)
+	}
+
+	if sent := mailer.Messages(); len(sent) != 1 || sent[0].Subject != "Follow-up" {
+		t.Fatalf("expected the follow-up to be sent, but got %+v", sent)
+	}
+
+	if tasks := listTasks(); len(tasks) != 1 || tasks[0].Status != task.StatusCompleted || tasks[0].ClosedAt == nil {
+		t.Errorf("expected the task to be completed, but got %+v", tasks)
+	}
+}
+
+func TestSequenceVersions(t *testing.T) {
+	ts := NewTestServer(t)
+	ctx := context.Background()
+
+	// Enrolling into a sequence that was never published publishes its steps as version 1
+	createSequence(ts, t)
+	jane := createContact(ts, t, "jane@example.com")
+	john := createContact(ts, t, "john@example.com")
+
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	// Editing a step changes the draft, which is sent to contacts enrolled after publishing it
+	if res := ts.PutStep(t, transporthttp.UpdateStepRequest{ID: 1, Subject: "Edited Subject", Content: "Test Content 1"}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	res := ts.PublishSequence(t, 1)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, but got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	var published sequence.Version
+	if err := json.NewDecoder(res.Body).Decode(&published); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if published.Version != 2 || len(published.Steps) != 2 || published.Steps[0].Subject != "Edited Subject" {
+		t.Errorf("expected version 2 with the edited step, but got %+v", published)
+	}
+
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{john.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	s := scheduler.NewScheduler(ts.SchedulerRepository, ts.Repository, ts.ContactRepository, mailer, scheduler.Config{From: "sales@example.com"})
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("failed to process due enrollments: %v", err)
+	}
+
+	subjects := map[string]string{}
+	for _, msg := range mailer.Messages() {
+		subjects[msg.To] = msg.Subject
+	}
+
+	if subjects[jane.Email] != "Test Subject 1" || subjects[john.Email] != "Edited Subject" {
+		t.Errorf("expected each contact to be sent the version they were enrolled into, but got %v", subjects)
+	}
+
+	// Both versions are listed and their differences are reported
+	res = ts.ListVersions(t, 1)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var versions []sequence.Version
+	if err := json.NewDecoder(res.Body).Decode(&versions); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if len(versions) != 2 || versions[0].Version != 1 || versions[0].Steps[0].Subject != "Test Subject 1" {
+		t.Errorf("expected 2 versions, but got %+v", versions)
+	}
+
+	res = ts.DiffVersions(t, 1, 1, 2)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var diff sequence.VersionDiff
+	if err := json.NewDecoder(res.Body).Decode(&diff); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if len(diff.Steps) != 1 || diff.Steps[0].StepID != 1 || diff.Steps[0].Change != sequence.ChangeModified || len(diff.Steps[0].Fields) != 1 || diff.Steps[0].Fields[0] != "subject" {
+		t.Errorf("expected the subject of step 1 to be modified, but got %+v", diff.Steps)
+	}
+
+	if res := ts.DiffVersions(t, 1, 1, 3); res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestOpenTracking(t *testing.T) {
+	ts := NewTestServer(t)
+	ctx := context.Background()
+
+	// The sequence has open tracking enabled
+	createSequence(ts, t)
+	jane := createContact(ts, t, "jane@example.com")
+
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	trackingService, err := tracking.NewService(ts.TrackingRepository, tracking.Config{BaseURL: ts.Address, Secret: testTrackingSecret})
+	if err != nil {
+		t.Fatalf("failed to create tracking service: %v", err)
+	}
+
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	s := scheduler.NewScheduler(ts.SchedulerRepository, ts.Repository, ts.ContactRepository, mailer, scheduler.Config{From: "sales@example.com"}, scheduler.WithTracker(trackingService))
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("failed to process due enrollments: %v", err)
+	}
+
+	sent := mailer.Messages()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 email to be sent, but got %d", len(sent))
+	}
+
+	pixelURL := trackingService.OpenURL(sent[0].ID)
+	if !strings.Contains(sent[0].HTML, pixelURL) {
+		t.Fatalf("expected email to contain tracking pixel %s, but got %q", pixelURL, sent[0].HTML)
+	}
+
+	// Both valid and invalid tokens get the pixel
+	for _, url := range []string{pixelURL, ts.Address + "/t/o/invalid.gif"} {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko)")
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "image/gif" || !bytes.Equal(body, tracking.Pixel) {
+			t.Errorf("expected tracking pixel from %s, but got status %d and %q", url, res.StatusCode, body)
+		}
+	}
+
+	// The open was recorded, so another one right away is deduplicated
+	recorded, err := ts.TrackingRepository.RecordEvent(ctx, tracking.Event{
+		Type:        tracking.EventOpen,
+		MessageID:   sent[0].ID,
+		DedupeAfter: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("failed to record open: %v", err)
+	}
+
+	if recorded {
+		t.Errorf("expected repeated open to be deduplicated")
+	}
+}
+
+func TestClickTracking(t *testing.T) {
+	ts := NewTestServer(t)
+	ctx := context.Background()
+
+	request := transporthttp.CreateSequenceRequest{
+		Name:          "Click Tracking",
+		ClickTracking: true,
+		Steps: []transporthttp.CreateSequenceRequestStep{
+			{
+				Subject: "Pricing",
+				Content: `<p>See our <a href="https://example.com/pricing">pricing</a> or <a href="mailto:sales@example.com">write us</a>.</p>`,
+			},
+		},
+	}
+	if _, err := ts.Repository.CreateSequence(ctx, request.BuildSequenceModel()); err != nil {
+		t.Fatalf("failed to create sequence: %v", err)
+	}
+
+	jane := createContact(ts, t, "jane@example.com")
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	trackingService, err := tracking.NewService(ts.TrackingRepository, tracking.Config{BaseURL: ts.Address, Secret: testTrackingSecret})
+	if err != nil {
+		t.Fatalf("failed to create tracking service: %v", err)
+	}
+
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	s := scheduler.NewScheduler(ts.SchedulerRepository, ts.Repository, ts.ContactRepository, mailer, scheduler.Config{From: "sales@example.com"}, scheduler.WithTracker(trackingService))
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("failed to process due enrollments: %v", err)
+	}
+
+	sent := mailer.Messages()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 email to be sent, but got %d", len(sent))
+	}
+
+	clickURL := trackingService.ClickURL(sent[0].ID, "https://example.com/pricing")
+	if !strings.Contains(sent[0].HTML, `href="`+clickURL+`"`) || !strings.Contains(sent[0].HTML, `href="mailto:sales@example.com"`) {
+		t.Fatalf("expected only the pricing link to be tracked, but got %q", sent[0].HTML)
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(clickURL)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	if res.StatusCode != http.StatusFound || res.Header.Get("Location") != "https://example.com/pricing" {
+		t.Errorf("expected redirect to the pricing page, but got %d to %q", res.StatusCode, res.Header.Get("Location"))
+	}
+
+	// Tampered tokens are not redirected
+	res, err = client.Get(clickURL + "x")
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, but got %d", http.StatusNotFound, res.StatusCode)
+	}
+
+	// The click was recorded, so another one right away is deduplicated
+	recorded, err := ts.TrackingRepository.RecordEvent(ctx, tracking.Event{
+		Type:        tracking.EventClick,
+		MessageID:   sent[0].ID,
+		URL:         "https://example.com/pricing",
+		Bot:         true,
+		DedupeAfter: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("failed to record click: %v", err)
+	}
+
+	if recorded {
+		t.Errorf("expected repeated click to be deduplicated")
+	}
+}
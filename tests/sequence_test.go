@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"reflect"
 	"testing"
 
 	"github.com/cybre/salesforge-assignment/internal/sequence"
@@ -32,6 +34,50 @@ func TestCreateSequence(t *testing.T) {
 	}
 }
 
+func TestCreateSequenceEndpoint(t *testing.T) {
+	ts := NewTestServer(t)
+
+	request := transporthttp.CreateSequenceRequest{
+		Name: "Test Sequence",
+		Steps: []transporthttp.CreateSequenceRequestStep{
+			{Subject: "Test Subject 1", Content: "Test Content 1", Delay: sequence.Delay{Amount: 0, Unit: sequence.DelayUnitDays}},
+			// The unit defaults to days
+			{Subject: "Test Subject 2", Content: "Test Content 2", Delay: sequence.Delay{Amount: 1}},
+			// The assignee is trimmed and the due in unit defaults to days
+			{Kind: sequence.StepKindCall, Content: "Call them", Delay: sequence.Delay{Amount: 1}, Assignment: &sequence.Assignment{Assignee: " sam@example.com ", DueIn: sequence.Delay{Amount: 1}}},
+		},
+	}
+
+	// Create a sequence through the API
+	res := ts.CreateSequence(t, request)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, but got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	var created sequence.Sequence
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if location := res.Header.Get("Location"); location != fmt.Sprintf("/sequence/%d", created.ID) {
+		t.Errorf("expected location to point at sequence %d, but got %q", created.ID, location)
+	}
+
+	// The response should describe the persisted sequence
+	seq, found, err := ts.Repository.GetSequence(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch sequence from the database: %v", err)
+	}
+
+	if !found {
+		t.Fatalf("expected sequence to be found in the database")
+	}
+
+	if !reflect.DeepEqual(created, seq) {
+		t.Errorf("expected created sequence %+v to match persisted sequence %+v", created, seq)
+	}
+}
+
 func TestGetSequence(t *testing.T) {
 	ts := NewTestServer(t)
 
@@ -54,6 +100,152 @@ func TestGetSequence(t *testing.T) {
 	}
 }
 
+func TestListSequences(t *testing.T) {
+	ts := NewTestServer(t)
+
+	// Create three sequences
+	for i := 0; i < 3; i++ {
+		createSequence(ts, t)
+	}
+
+	// Page through the sequences one at a time, newest first
+	var ids []int
+	query := url.Values{"order": {"desc"}, "limit": {"1"}, "includeSteps": {"true"}}
+	for {
+		res := ts.ListSequences(t, query)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var page sequence.Page
+		if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		for _, seq := range page.Sequences {
+			ids = append(ids, seq.ID)
+			if len(seq.Steps) != 2 {
+				t.Errorf("expected sequence %d to have 2 steps, but got %d", seq.ID, len(seq.Steps))
+			}
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		query.Set("cursor", page.NextCursor)
+	}
+
+	if !reflect.DeepEqual(ids, []int{3, 2, 1}) {
+		t.Errorf("expected sequence IDs %v, but got %v", []int{3, 2, 1}, ids)
+	}
+
+	// Filter by tracking flag
+	res := ts.ListSequences(t, url.Values{"openTrackingEnabled": {"false"}})
+	var page sequence.Page
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(page.Sequences) != 0 {
+		t.Errorf("expected no sequences, but got %d", len(page.Sequences))
+	}
+
+	// Steps are an empty array unless they are included
+	res = ts.ListSequences(t, url.Values{"limit": {"1"}})
+	var rawPage struct {
+		Sequences []struct {
+			Steps json.RawMessage `json:"steps"`
+		} `json:"sequences"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rawPage); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(rawPage.Sequences) != 1 || string(rawPage.Sequences[0].Steps) != "[]" {
+		t.Errorf("expected one sequence with an empty steps array, but got %+v", rawPage.Sequences)
+	}
+}
+
+func TestDeleteSequence(t *testing.T) {
+	ts := NewTestServer(t)
+
+	// Create a sequence
+	createSequence(ts, t)
+
+	// Delete the sequence along with its steps
+	res := ts.DeleteSequence(t, 1)
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("expected status code %d, but got %d", http.StatusNoContent, res.StatusCode)
+	}
+
+	_, found, err := ts.Repository.GetSequence(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("failed to fetch sequence from the database: %v", err)
+	}
+
+	if found {
+		t.Errorf("expected sequence to be deleted from the database")
+	}
+
+	// Deleting it again should report that it does not exist
+	res = ts.DeleteSequence(t, 1)
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestArchiveSequence(t *testing.T) {
+	ts := NewTestServer(t)
+
+	// Create a sequence
+	createSequence(ts, t)
+
+	// Archive the sequence
+	res := ts.ArchiveSequence(t, 1)
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	// Archived sequences are hidden from reads
+	res = ts.GetSequence(t, 1)
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, but got %d", http.StatusNotFound, res.StatusCode)
+	}
+
+	// and cannot be modified
+	res = ts.PatchSequence(t, transporthttp.PatchSequenceRequest{ID: 1, OpenTracking: boolPtr(false)})
+	if res.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, but got %d", http.StatusConflict, res.StatusCode)
+	}
+
+	res = ts.PutStep(t, transporthttp.UpdateStepRequest{ID: 1, Subject: "Updated Subject", Content: "Updated Content"})
+	if res.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, but got %d", http.StatusConflict, res.StatusCode)
+	}
+
+	// but the row is kept
+	seq, found, err := ts.Repository.GetSequence(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("failed to fetch sequence from the database: %v", err)
+	}
+
+	if !found || !seq.Archived() {
+		t.Fatalf("expected archived sequence to be kept in the database")
+	}
+
+	// Unarchive the sequence
+	res = ts.UnarchiveSequence(t, 1)
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	res = ts.GetSequence(t, 1)
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+}
+
 func TestPatchSequence(t *testing.T) {
 	ts := NewTestServer(t)
 
@@ -90,6 +282,60 @@ func TestPatchSequence(t *testing.T) {
 	}
 }
 
+func TestAddStep(t *testing.T) {
+	ts := NewTestServer(t)
+
+	// Create a sequence
+	createSequence(ts, t)
+
+	// Insert a step at the start of the sequence
+	position := 0
+	res := ts.AddStep(t, transporthttp.AddStepRequest{
+		SequenceID: 1,
+		Subject:    "Inserted Subject",
+		Content:    "Inserted Content",
+		Position:   &position,
+	})
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, but got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	var response transporthttp.AddStepResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	// Check if the step was inserted in the database
+	seq, found, err := ts.Repository.GetSequence(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("failed to fetch sequence from the database: %v", err)
+	}
+
+	if !found {
+		t.Fatalf("expected sequence to be found in the database")
+	}
+
+	if len(seq.Steps) != 3 {
+		t.Fatalf("expected 3 steps, but got %d", len(seq.Steps))
+	}
+
+	if seq.Steps[0].ID != response.ID {
+		t.Errorf("expected first step ID to be %d, but got %d", response.ID, seq.Steps[0].ID)
+	}
+
+	for i, expectedID := range []int{response.ID, 1, 2} {
+		if seq.Steps[i].ID != expectedID || seq.Steps[i].Position != i {
+			t.Errorf("expected step %d to have ID %d, but got step %d at position %d", i, expectedID, seq.Steps[i].ID, seq.Steps[i].Position)
+		}
+	}
+
+	// Adding an invalid step should be rejected
+	res = ts.AddStep(t, transporthttp.AddStepRequest{SequenceID: 1, Subject: "Subject only"})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, but got %d", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
 func TestUpdateStep(t *testing.T) {
 	ts := NewTestServer(t)
 
@@ -101,6 +347,7 @@ func TestUpdateStep(t *testing.T) {
 		ID:      1,
 		Subject: "Updated Subject",
 		Content: "Updated Content",
+		Delay:   sequence.Delay{Amount: 6, Unit: sequence.DelayUnitHours},
 	}
 	res := ts.PutStep(t, updateStepRequest)
 	if res.StatusCode != http.StatusOK {
@@ -124,6 +371,10 @@ func TestUpdateStep(t *testing.T) {
 	if seq.Steps[0].Content != updateStepRequest.Content {
 		t.Errorf("expected step content to be %s, but got %s", updateStepRequest.Content, seq.Steps[0].Content)
 	}
+
+	if seq.Steps[0].Delay != updateStepRequest.Delay {
+		t.Errorf("expected step delay to be %+v, but got %+v", updateStepRequest.Delay, seq.Steps[0].Delay)
+	}
 }
 
 func TestDeleteStep(t *testing.T) {
@@ -155,6 +406,188 @@ func TestDeleteStep(t *testing.T) {
 	if seq.Steps[0].ID != 2 {
 		t.Errorf("expected step ID to be 2, but got %d", seq.Steps[0].ID)
 	}
+
+	if seq.Steps[0].Position != 0 {
+		t.Errorf("expected step position to be 0, but got %d", seq.Steps[0].Position)
+	}
+}
+
+func TestReorderSteps(t *testing.T) {
+	ts := NewTestServer(t)
+
+	// Create a sequence
+	createSequence(ts, t)
+
+	// Reorder the steps
+	res := ts.ReorderSteps(t, transporthttp.ReorderStepsRequest{ID: 1, StepIDs: []int{2, 1}})
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	// Check if the steps were reordered in the database
+	seq, found, err := ts.Repository.GetSequence(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("failed to fetch sequence from the database: %v", err)
+	}
+
+	if !found {
+		t.Fatalf("expected sequence to be found in the database")
+	}
+
+	for i, expectedID := range []int{2, 1} {
+		if seq.Steps[i].ID != expectedID {
+			t.Errorf("expected step %d to have ID %d, but got %d", i, expectedID, seq.Steps[i].ID)
+		}
+
+		if seq.Steps[i].Position != i {
+			t.Errorf("expected step %d to have position %d, but got %d", i, i, seq.Steps[i].Position)
+		}
+	}
+
+	// Submitting an incomplete order should be rejected
+	res = ts.ReorderSteps(t, transporthttp.ReorderStepsRequest{ID: 1, StepIDs: []int{2}})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, but got %d", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestPreviewStep(t *testing.T) {
+	ts := NewTestServer(t)
+
+	// Create a sequence
+	createSequence(ts, t)
+
+	// Give the first step some merge fields
+	res := ts.PutStep(t, transporthttp.UpdateStepRequest{
+		ID:      1,
+		Subject: "Hi {{firstName|there}}",
+		Content: "How are things at {{company}}, {{custom.role}}?",
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	// Preview the step
+	res = ts.PreviewStep(t, transporthttp.PreviewStepRequest{ID: 1, Fields: map[string]string{"company": "Globex"}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var rendered sequence.RenderedStep
+	if err := json.NewDecoder(res.Body).Decode(&rendered); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	expected := sequence.RenderedStep{
+		Subject:       "Hi Jane",
+		Content:       "How are things at Globex, ?",
+		MissingFields: []string{"custom.role"},
+	}
+	if !reflect.DeepEqual(rendered, expected) {
+		t.Errorf("expected rendered step to be %+v, but got %+v", expected, rendered)
+	}
+
+	// Malformed merge fields should be rejected
+	res = ts.PutStep(t, transporthttp.UpdateStepRequest{ID: 1, Subject: "Hi {{firstName", Content: "Content"})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, but got %d", http.StatusBadRequest, res.StatusCode)
+	}
+
+	// Previewing a missing step should return not found
+	res = ts.PreviewStep(t, transporthttp.PreviewStepRequest{ID: 100})
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestStepVariants(t *testing.T) {
+	ts := NewTestServer(t)
+
+	// Create a sequence
+	createSequence(ts, t)
+
+	// A/B test the first step
+	res := ts.PutStep(t, transporthttp.UpdateStepRequest{
+		ID: 1,
+		Variants: []transporthttp.UpdateStepVariantRequest{
+			{StepVariantRequest: transporthttp.StepVariantRequest{Label: "Short", Subject: "Hi", Content: "Quick question", Weight: 50}},
+			{StepVariantRequest: transporthttp.StepVariantRequest{Label: "Long", Subject: "Hello there", Content: "A longer question", Weight: 50}},
+		},
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	seq, _, err := ts.Repository.GetSequence(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("failed to fetch sequence from the database: %v", err)
+	}
+
+	variants := seq.Steps[0].Variants
+	if len(variants) != 2 || variants[0].Label != "Short" || variants[1].Label != "Long" || !variants[0].Active || !variants[1].Active {
+		t.Fatalf("expected two active variants in order, but got %+v", variants)
+	}
+
+	// Publish the A/B test, so contacts enrolled into it are pinned to it
+	res = ts.PublishSequence(t, 1)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, but got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	// Declare the second variant the winner
+	res = ts.DeclareWinner(t, 1, variants[1].ID)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	// The published version is left as it was published
+	version, _, err := ts.Repository.GetVersion(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("failed to fetch version from the database: %v", err)
+	}
+
+	published := version.Steps[0].Variants
+	if len(published) != 2 || !published[0].Active || !published[1].Active {
+		t.Errorf("expected both variants to stay active in the published version, but got %+v", published)
+	}
+
+	// Contacts pinned to the version are sent the winner
+	pinned, _, err := ts.Repository.GetSequenceVersion(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("failed to fetch version from the database: %v", err)
+	}
+
+	if _, variantID := pinned.Steps[0].ForEnrollment(1); variantID == nil || *variantID != variants[1].ID {
+		t.Errorf("expected the winner to be sent to contacts pinned to the version, but got %v", variantID)
+	}
+
+	// Updating the step keeps the losing variant inactive
+	res = ts.PutStep(t, transporthttp.UpdateStepRequest{
+		ID: 1,
+		Variants: []transporthttp.UpdateStepVariantRequest{
+			{ID: variants[0].ID, StepVariantRequest: transporthttp.StepVariantRequest{Label: "Short", Subject: "Hi", Content: "Quick question", Weight: 50}},
+			{ID: variants[1].ID, StepVariantRequest: transporthttp.StepVariantRequest{Label: "Long", Subject: "Hello there", Content: "An even longer question", Weight: 50}},
+		},
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	seq, _, err = ts.Repository.GetSequence(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("failed to fetch sequence from the database: %v", err)
+	}
+
+	variants = seq.Steps[0].Variants
+	if variants[0].Active || !variants[1].Active || variants[1].Content != "An even longer question" {
+		t.Errorf("expected only the updated winner to be active, but got %+v", variants)
+	}
+
+	// Unknown variants cannot win
+	res = ts.DeclareWinner(t, 1, 999)
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, but got %d", http.StatusNotFound, res.StatusCode)
+	}
 }
 
 func createSequence(ts *TestServer, t *testing.T) transporthttp.CreateSequenceRequest {
@@ -166,15 +599,17 @@ func createSequence(ts *TestServer, t *testing.T) transporthttp.CreateSequenceRe
 			{
 				Subject: "Test Subject 1",
 				Content: "Test Content 1",
+				Delay:   sequence.Delay{Amount: 0, Unit: sequence.DelayUnitDays},
 			},
 			{
 				Subject: "Test Subject 2",
 				Content: "Test Content 2",
+				Delay:   sequence.Delay{Amount: 2, Unit: sequence.DelayUnitBusinessDays},
 			},
 		},
 	}
 
-	err := ts.Repository.CreateSequence(context.Background(), request.BuildSequenceModel())
+	_, err := ts.Repository.CreateSequence(context.Background(), request.BuildSequenceModel())
 	if err != nil {
 		t.Fatalf("failed to create sequence: %v", err)
 	}
@@ -207,6 +642,10 @@ func compareSequenceWithRequest(request transporthttp.CreateSequenceRequest, seq
 		if step.Content != request.Steps[i].Content {
 			return fmt.Errorf("expected step %d content to be %s, but got %s", i, request.Steps[i].Content, step.Content)
 		}
+
+		if step.Delay != request.Steps[i].Delay {
+			return fmt.Errorf("expected step %d delay to be %+v, but got %+v", i, request.Steps[i].Delay, step.Delay)
+		}
 	}
 
 	return nil
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+	"github.com/cybre/salesforge-assignment/internal/stats"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+)
+
+func TestSequenceStats(t *testing.T) {
+	ts := NewTestServer(t)
+	ctx := context.Background()
+
+	createSequence(ts, t)
+	jane := createContact(ts, t, "jane@example.com")
+	john := createContact(ts, t, "john@example.com")
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID, john.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	s := scheduler.NewScheduler(ts.SchedulerRepository, ts.Repository, ts.ContactRepository, mailer, scheduler.Config{From: "sales@example.com"})
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("failed to process due enrollments: %v", err)
+	}
+
+	sent := mailer.Messages()
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 emails to be sent, but got %d", len(sent))
+	}
+
+	// Jane opens the email twice, both times outside the dedupe window, and a scanner fetches John's
+	events := []tracking.Event{
+		{Type: tracking.EventOpen, MessageID: sent[0].ID, PrefetchedAfter: time.Now(), DedupeAfter: time.Now()},
+		{Type: tracking.EventOpen, MessageID: sent[0].ID, PrefetchedAfter: time.Now(), DedupeAfter: time.Now()},
+		{Type: tracking.EventOpen, MessageID: sent[1].ID, Bot: true},
+	}
+	for _, event := range events {
+		if _, err := ts.TrackingRepository.RecordEvent(ctx, event); err != nil {
+			t.Fatalf("failed to record event: %v", err)
+		}
+	}
+
+	res := ts.GetSequenceStats(t, 1, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var result stats.SequenceStats
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	expected := stats.Counts{Sent: 2, Delivered: 2, UniqueOpens: 1, TotalOpens: 2}
+	if result.Counts != expected {
+		t.Errorf("expected counts %+v, but got %+v", expected, result.Counts)
+	}
+
+	if result.OpenRate == nil || *result.OpenRate != 0.5 {
+		t.Errorf("expected open rate 0.5, but got %v", result.OpenRate)
+	}
+
+	if result.ClickRate != nil {
+		t.Errorf("expected no click rate, but got %v", *result.ClickRate)
+	}
+
+	if len(result.Steps) != 2 || result.Steps[0].Counts != expected || result.Steps[1].Counts != (stats.Counts{}) {
+		t.Errorf("expected all events on the first step, but got %+v", result.Steps)
+	}
+
+	// Events before the range are left out
+	res = ts.GetSequenceStats(t, 1, url.Values{"from": []string{time.Now().Add(time.Hour).Format(time.RFC3339)}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	result = stats.SequenceStats{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if result.Counts != (stats.Counts{}) {
+		t.Errorf("expected no events, but got %+v", result.Counts)
+	}
+
+	if res := ts.GetSequenceStats(t, 1, url.Values{"from": []string{"2024-02-01"}, "to": []string{"2024-01-01"}}); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code %d, but got %d", http.StatusBadRequest, res.StatusCode)
+	}
+
+	if res := ts.GetSequenceStats(t, 99, nil); res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cybre/salesforge-assignment/internal/enrollment"
+	"github.com/cybre/salesforge-assignment/internal/mail"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
+	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
+)
+
+func TestUnsubscribe(t *testing.T) {
+	ts := NewTestServer(t)
+	ctx := context.Background()
+
+	createSequence(ts, t)
+	jane := createContact(ts, t, "jane@example.com")
+	john := createContact(ts, t, "john@blocked.example")
+	if res := ts.EnrollContacts(t, transporthttp.EnrollContactsRequest{SequenceID: 1, ContactIDs: []int{jane.ID, john.ID}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	// Suppress the domain of John through the API
+	res := ts.AddSuppression(t, transporthttp.AddSuppressionRequest{Type: "domain", Value: "Blocked.example"})
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, but got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	var domain suppression.Suppression
+	if err := json.NewDecoder(res.Body).Decode(&domain); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if res := ts.AddSuppression(t, transporthttp.AddSuppressionRequest{Type: "domain", Value: "blocked.example"}); res.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, but got %d", http.StatusConflict, res.StatusCode)
+	}
+
+	trackingService, err := tracking.NewService(ts.TrackingRepository, tracking.Config{BaseURL: ts.Address, Secret: testTrackingSecret})
+	if err != nil {
+		t.Fatalf("failed to create tracking service: %v", err)
+	}
+
+	suppressionService := suppression.NewService(ts.SuppressionRepository, trackingService)
+
+	mailer, err := mail.NewCaptureMailer("")
+	if err != nil {
+		t.Fatalf("failed to create mailer: %v", err)
+	}
+
+	s := scheduler.NewScheduler(ts.SchedulerRepository, ts.Repository, ts.ContactRepository, mailer, scheduler.Config{From: "sales@example.com"},
+		scheduler.WithTracker(trackingService),
+		scheduler.WithSuppressionList(suppressionService),
+	)
+	if _, err := s.ProcessDue(ctx); err != nil {
+		t.Fatalf("failed to process due enrollments: %v", err)
+	}
+
+	sent := mailer.Messages()
+	if len(sent) != 1 || sent[0].To != jane.Email {
+		t.Fatalf("expected only Jane to be emailed, but got %d emails", len(sent))
+	}
+
+	unsubscribeURL := trackingService.UnsubscribeURL(sent[0].ID)
+	raw := string(sent[0].Raw)
+	if !strings.Contains(raw, "List-Unsubscribe: <"+unsubscribeURL+">") || !strings.Contains(raw, "List-Unsubscribe-Post: List-Unsubscribe=One-Click") {
+		t.Errorf("expected one-click unsubscribe headers, but got %q", raw)
+	}
+
+	// Opening the link only shows the confirmation page
+	res, err = http.Get(unsubscribeURL)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	if state := enrollmentStates(ts, t)[jane.ID]; state != enrollment.StateActive {
+		t.Errorf("expected Jane's enrollment to stay active, but got %s", state)
+	}
+
+	// Mail clients unsubscribe with a one-click POST
+	for i := 0; i < 2; i++ {
+		res, err = http.PostForm(unsubscribeURL, url.Values{"List-Unsubscribe": []string{"One-Click"}})
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+		}
+	}
+
+	if res, err := http.PostForm(unsubscribeURL+"x", nil); err != nil || res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected tampered links to be rejected, but got %v, %v", res, err)
+	}
+
+	states := enrollmentStates(ts, t)
+	if states[jane.ID] != enrollment.StateUnsubscribed || states[john.ID] != enrollment.StateUnsubscribed {
+		t.Errorf("expected both enrollments to be unsubscribed, but got %v", states)
+	}
+
+	res = ts.ListSuppressions(t, url.Values{"type": []string{"email"}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var page suppression.Page
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(page.Suppressions) != 1 || page.Suppressions[0].Value != jane.Email || page.Suppressions[0].Reason != suppression.ReasonUnsubscribe {
+		t.Errorf("expected Jane to be suppressed once, but got %+v", page.Suppressions)
+	}
+
+	if res := ts.DeleteSuppression(t, domain.ID); res.StatusCode != http.StatusNoContent {
+		t.Errorf("expected status code %d, but got %d", http.StatusNoContent, res.StatusCode)
+	}
+
+	if res := ts.DeleteSuppression(t, domain.ID); res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code %d, but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+// enrollmentStates returns the states of the enrollments of the first sequence by contact ID.
+func enrollmentStates(ts *TestServer, t *testing.T) map[int]enrollment.State {
+	res := ts.ListEnrollments(t, 1, url.Values{})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var page enrollment.Page
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	states := map[int]enrollment.State{}
+	for _, e := range page.Enrollments {
+		states[e.ContactID] = e.State
+	}
+
+	return states
+}
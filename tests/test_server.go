@@ -5,12 +5,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/cybre/salesforge-assignment/internal/contact"
 	"github.com/cybre/salesforge-assignment/internal/database"
+	"github.com/cybre/salesforge-assignment/internal/mailbox"
+	"github.com/cybre/salesforge-assignment/internal/scheduler"
 	"github.com/cybre/salesforge-assignment/internal/sequence"
+	"github.com/cybre/salesforge-assignment/internal/suppression"
+	"github.com/cybre/salesforge-assignment/internal/tracking"
 	transporthttp "github.com/cybre/salesforge-assignment/internal/transport/http"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
@@ -18,9 +27,20 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
+// testTrackingSecret is the secret the server signs tracking tokens with.
+const testTrackingSecret = "integration-test-tracking-secret"
+
+// testMailboxKey is the key the server encrypts SMTP passwords of mailboxes with.
+const testMailboxKey = "6d61696c626f782d696e746567726174696f6e2d746573742d6b65792d313233"
+
 type TestServer struct {
-	Address    string
-	Repository sequence.Repository
+	Address               string
+	Repository            *sequence.PostgresRepository
+	ContactRepository     contact.Repository
+	SchedulerRepository   scheduler.Repository
+	TrackingRepository    tracking.Repository
+	SuppressionRepository suppression.Repository
+	MailboxRepository     mailbox.Repository
 }
 
 func NewTestServer(t *testing.T) *TestServer {
@@ -82,6 +102,17 @@ func NewTestServer(t *testing.T) *TestServer {
 	}
 
 	repository := sequence.NewPostgresRepository(database)
+	contactRepository := contact.NewPostgresRepository(database)
+	schedulerRepository := scheduler.NewPostgresRepository(database)
+	trackingRepository := tracking.NewPostgresRepository(database)
+	suppressionRepository := suppression.NewPostgresRepository(database)
+
+	cipher, err := mailbox.NewCipher(testMailboxKey)
+	if err != nil {
+		t.Fatalf("failed to create mailbox cipher: %v", err)
+	}
+
+	mailboxRepository := mailbox.NewPostgresRepository(database, cipher)
 
 	seqContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
 		ContainerRequest: testcontainers.ContainerRequest{
@@ -99,6 +130,12 @@ func NewTestServer(t *testing.T) *TestServer {
 				"DATABASE_NAME":     "salesforge",
 				"DATABASE_USER":     "salesforge",
 				"DATABASE_PASSWORD": "salesforge",
+				// Keep the server's scheduler from racing the tests that drive one themselves.
+				"SCHEDULER_INTERVAL": "1h",
+				"TRACKING_SECRET":    testTrackingSecret,
+				// Count opens right after sending as the recipient's rather than a scanner's.
+				"TRACKING_PREFETCH_WINDOW": "1ns",
+				"MAILBOX_ENCRYPTION_KEY":   testMailboxKey,
 			},
 			WaitingFor: wait.ForHTTP("/health").WithStartupTimeout(5 * time.Second),
 		},
@@ -124,8 +161,13 @@ func NewTestServer(t *testing.T) *TestServer {
 	}
 
 	return &TestServer{
-		Address:    fmt.Sprintf("http://%s:%s", seqContainerHost, seqContainerPort.Port()),
-		Repository: repository,
+		Address:               fmt.Sprintf("http://%s:%s", seqContainerHost, seqContainerPort.Port()),
+		Repository:            repository,
+		ContactRepository:     contactRepository,
+		SchedulerRepository:   schedulerRepository,
+		TrackingRepository:    trackingRepository,
+		SuppressionRepository: suppressionRepository,
+		MailboxRepository:     mailboxRepository,
 	}
 }
 
@@ -164,6 +206,69 @@ func (ts *TestServer) GetSequence(t *testing.T, id int) *http.Response {
 	return res
 }
 
+func (ts *TestServer) ListSequences(t *testing.T, query url.Values) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/sequence?%s", ts.Address, query.Encode()), nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	return res
+}
+
+func (ts *TestServer) DeleteSequence(t *testing.T, id int) *http.Response {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/sequence/%d", ts.Address, id), nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	return res
+}
+
+func (ts *TestServer) ArchiveSequence(t *testing.T, id int) *http.Response {
+	return ts.post(t, fmt.Sprintf("/sequence/%d/archive", id))
+}
+
+func (ts *TestServer) UnarchiveSequence(t *testing.T, id int) *http.Response {
+	return ts.post(t, fmt.Sprintf("/sequence/%d/unarchive", id))
+}
+
+func (ts *TestServer) PublishSequence(t *testing.T, id int) *http.Response {
+	return ts.post(t, fmt.Sprintf("/sequence/%d/publish", id))
+}
+
+func (ts *TestServer) ListVersions(t *testing.T, id int) *http.Response {
+	return ts.sendJSON(t, http.MethodGet, fmt.Sprintf("/sequence/%d/versions", id), nil)
+}
+
+func (ts *TestServer) DiffVersions(t *testing.T, id int, from int, to int) *http.Response {
+	return ts.sendJSON(t, http.MethodGet, fmt.Sprintf("/sequence/%d/versions/diff?from=%d&to=%d", id, from, to), nil)
+}
+
+func (ts *TestServer) post(t *testing.T, path string) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, ts.Address+path, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	return res
+}
+
 func (ts *TestServer) PatchSequence(t *testing.T, patch transporthttp.PatchSequenceRequest) *http.Response {
 	payload, err := json.Marshal(patch)
 	if err != nil {
@@ -184,6 +289,26 @@ func (ts *TestServer) PatchSequence(t *testing.T, patch transporthttp.PatchSeque
 	return res
 }
 
+func (ts *TestServer) AddStep(t *testing.T, request transporthttp.AddStepRequest) *http.Response {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/sequence/%d/steps", ts.Address, request.SequenceID), bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	return res
+}
+
 func (ts *TestServer) PutStep(t *testing.T, request transporthttp.UpdateStepRequest) *http.Response {
 	payload, err := json.Marshal(request)
 	if err != nil {
@@ -217,3 +342,200 @@ func (ts *TestServer) DeleteStep(t *testing.T, id int) *http.Response {
 
 	return res
 }
+
+func (ts *TestServer) ReorderSteps(t *testing.T, request transporthttp.ReorderStepsRequest) *http.Response {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/sequence/%d/steps/order", ts.Address, request.ID), bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	return res
+}
+
+func (ts *TestServer) PreviewStep(t *testing.T, request transporthttp.PreviewStepRequest) *http.Response {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/step/%d/preview", ts.Address, request.ID), bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	return res
+}
+
+func (ts *TestServer) DeclareWinner(t *testing.T, stepID int, variantID int) *http.Response {
+	return ts.post(t, fmt.Sprintf("/step/%d/variants/%d/winner", stepID, variantID))
+}
+
+func (ts *TestServer) CreateContact(t *testing.T, request transporthttp.CreateContactRequest) *http.Response {
+	return ts.sendJSON(t, http.MethodPost, "/contact", request)
+}
+
+func (ts *TestServer) GetContact(t *testing.T, id int) *http.Response {
+	return ts.sendJSON(t, http.MethodGet, fmt.Sprintf("/contact/%d", id), nil)
+}
+
+func (ts *TestServer) ListContacts(t *testing.T, query url.Values) *http.Response {
+	return ts.sendJSON(t, http.MethodGet, "/contact?"+query.Encode(), nil)
+}
+
+func (ts *TestServer) UpdateContact(t *testing.T, request transporthttp.UpdateContactRequest) *http.Response {
+	return ts.sendJSON(t, http.MethodPut, fmt.Sprintf("/contact/%d", request.ID), request)
+}
+
+func (ts *TestServer) DeleteContact(t *testing.T, id int) *http.Response {
+	return ts.sendJSON(t, http.MethodDelete, fmt.Sprintf("/contact/%d", id), nil)
+}
+
+// sendJSON sends a request to the given path, with the body encoded as JSON unless it is nil.
+func (ts *TestServer) sendJSON(t *testing.T, method, path string, body any) *http.Response {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to marshal request: %v", err)
+		}
+
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, ts.Address+path, payload)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	return res
+}
+
+func (ts *TestServer) ImportContacts(t *testing.T, csv string, mapping contact.Mapping) *http.Response {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile("file", "contacts.csv")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+
+	if _, err := part.Write([]byte(csv)); err != nil {
+		t.Fatalf("failed to write form file: %v", err)
+	}
+
+	if mapping != nil {
+		payload, err := json.Marshal(mapping)
+		if err != nil {
+			t.Fatalf("failed to marshal mapping: %v", err)
+		}
+
+		if err := writer.WriteField("mapping", string(payload)); err != nil {
+			t.Fatalf("failed to write mapping: %v", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close multipart writer: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, ts.Address+"/contacts/import", body)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	return res
+}
+
+func (ts *TestServer) EnrollContacts(t *testing.T, request transporthttp.EnrollContactsRequest) *http.Response {
+	return ts.sendJSON(t, http.MethodPost, fmt.Sprintf("/sequence/%d/enrollments", request.SequenceID), request)
+}
+
+func (ts *TestServer) ListEnrollments(t *testing.T, sequenceID int, query url.Values) *http.Response {
+	return ts.sendJSON(t, http.MethodGet, fmt.Sprintf("/sequence/%d/enrollments?%s", sequenceID, query.Encode()), nil)
+}
+
+func (ts *TestServer) GetSequenceStats(t *testing.T, sequenceID int, query url.Values) *http.Response {
+	return ts.sendJSON(t, http.MethodGet, fmt.Sprintf("/sequence/%d/stats?%s", sequenceID, query.Encode()), nil)
+}
+
+func (ts *TestServer) AddSuppression(t *testing.T, request transporthttp.AddSuppressionRequest) *http.Response {
+	return ts.sendJSON(t, http.MethodPost, "/suppression", request)
+}
+
+func (ts *TestServer) ListSuppressions(t *testing.T, query url.Values) *http.Response {
+	return ts.sendJSON(t, http.MethodGet, "/suppression?"+query.Encode(), nil)
+}
+
+func (ts *TestServer) DeleteSuppression(t *testing.T, id int) *http.Response {
+	return ts.sendJSON(t, http.MethodDelete, fmt.Sprintf("/suppression/%d", id), nil)
+}
+
+func (ts *TestServer) ProcessDSN(t *testing.T, message string) *http.Response {
+	res, err := http.Post(ts.Address+"/inbound/dsn", "message/rfc822", strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	return res
+}
+
+func (ts *TestServer) ProcessReply(t *testing.T, message string) *http.Response {
+	res, err := http.Post(ts.Address+"/inbound/reply", "message/rfc822", strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	return res
+}
+
+func (ts *TestServer) ListTasks(t *testing.T, query url.Values) *http.Response {
+	return ts.sendJSON(t, http.MethodGet, "/task?"+query.Encode(), nil)
+}
+
+func (ts *TestServer) CompleteTask(t *testing.T, id int) *http.Response {
+	return ts.post(t, fmt.Sprintf("/task/%d/complete", id))
+}
+
+func (ts *TestServer) CreateMailbox(t *testing.T, request transporthttp.CreateMailboxRequest) *http.Response {
+	return ts.sendJSON(t, http.MethodPost, "/mailbox", request)
+}
+
+func (ts *TestServer) GetMailbox(t *testing.T, id int) *http.Response {
+	return ts.sendJSON(t, http.MethodGet, fmt.Sprintf("/mailbox/%d", id), nil)
+}
+
+func (ts *TestServer) SetSequenceMailboxes(t *testing.T, request transporthttp.SetSequenceMailboxesRequest) *http.Response {
+	return ts.sendJSON(t, http.MethodPut, fmt.Sprintf("/sequence/%d/mailboxes", request.ID), request)
+}